@@ -36,7 +36,40 @@ func (f *Fosite) writeJsonError(ctx context.Context, rw http.ResponseWriter, req
 		return
 	}
 
-	rw.WriteHeader(rfcerr.CodeField)
+	status := f.errorStatus(ctx, rfcerr)
+	if status == http.StatusUnauthorized && rfcerr.ErrorField != ErrInvalidClient.ErrorField {
+		f.writeAuthenticateChallenges(ctx, rw, rfcerr)
+	}
+
+	rw.WriteHeader(status)
 	// ignoring the error because the connection is broken when it happens
 	_, _ = rw.Write(js)
 }
+
+// ErrorStatusMapper maps an error to the HTTP status code of the error response. Returning zero keeps the status
+// code of the error.
+type ErrorStatusMapper func(err *RFC6749Error) int
+
+func (f *Fosite) errorStatus(ctx context.Context, rfcerr *RFC6749Error) int {
+	if mapper := f.Config.GetErrorStatusMapper(ctx); mapper != nil {
+		if status := mapper(rfcerr); status != 0 {
+			return status
+		}
+	}
+	return rfcerr.CodeField
+}
+
+// writeAuthenticateChallenges adds a WWW-Authenticate challenge for each supported authentication scheme, as 401
+// responses must contain at least one challenge (https://datatracker.ietf.org/doc/html/rfc7235#section-3.1). The
+// "invalid_token" error code of https://datatracker.ietf.org/doc/html/rfc6750#section-3.1 is only added if the
+// presented token is invalid, expired or inactive, otherwise the challenges carry the scheme only.
+func (f *Fosite) writeAuthenticateChallenges(ctx context.Context, rw http.ResponseWriter, rfcerr *RFC6749Error) {
+	invalidToken := rfcerr.ErrorField == errInvalidTokenName || rfcerr.ErrorField == errTokenInactiveName
+	for _, scheme := range f.Config.GetWWWAuthenticateSchemes(ctx) {
+		if invalidToken {
+			rw.Header().Add("WWW-Authenticate", fmt.Sprintf(`%s error="invalid_token"`, scheme))
+		} else {
+			rw.Header().Add("WWW-Authenticate", scheme)
+		}
+	}
+}
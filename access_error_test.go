@@ -100,3 +100,97 @@ func TestWriteAccessError_RFC6749(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteAccessError_ErrorStatusMapper(t *testing.T) {
+	config := &Config{ErrorStatusMapper: func(err *RFC6749Error) int {
+		if err.ErrorField == ErrInvalidGrant.ErrorField {
+			return http.StatusUnauthorized
+		}
+		return 0
+	}}
+	f := &Fosite{Config: config}
+
+	for k, c := range []struct {
+		err          *RFC6749Error
+		expectStatus int
+		expectError  string
+	}{
+		{err: ErrInvalidGrant.WithDebug("some-debug"), expectStatus: http.StatusUnauthorized, expectError: "invalid_grant"},
+		{err: ErrInvalidRequest, expectStatus: http.StatusBadRequest, expectError: "invalid_request"},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			f.WriteAccessError(context.Background(), rw, nil, c.err)
+
+			assert.Equal(t, c.expectStatus, rw.Code)
+
+			var params struct {
+				Error string `json:"error"`
+			}
+			require.NoError(t, json.NewDecoder(rw.Body).Decode(&params))
+			assert.Equal(t, c.expectError, params.Error)
+		})
+	}
+}
+
+func TestWriteAccessError_WWWAuthenticate(t *testing.T) {
+	for k, c := range []struct {
+		d       string
+		schemes []string
+		err     *RFC6749Error
+		expect  []string
+	}{
+		{
+			d:       "should challenge every supported scheme",
+			schemes: []string{"Bearer", "DPoP"},
+			err:     ErrInactiveToken,
+			expect:  []string{`Bearer error="invalid_token"`, `DPoP error="invalid_token"`},
+		},
+		{
+			d:       "should challenge an invalid token",
+			schemes: []string{"Bearer"},
+			err:     ErrInvalidToken,
+			expect:  []string{`Bearer error="invalid_token"`},
+		},
+		{
+			d:       "should challenge an expired token",
+			schemes: []string{"Bearer"},
+			err:     ErrTokenExpired,
+			expect:  []string{`Bearer error="invalid_token"`},
+		},
+		{
+			d:       "should challenge without an error code if the token is missing",
+			schemes: []string{"Bearer", "DPoP"},
+			err:     ErrRequestUnauthorized,
+			expect:  []string{"Bearer", "DPoP"},
+		},
+		{
+			d:       "should challenge without an error code for other unauthorized errors",
+			schemes: []string{"Bearer"},
+			err:     &RFC6749Error{ErrorField: "invalid_grant", CodeField: http.StatusUnauthorized},
+			expect:  []string{"Bearer"},
+		},
+		{
+			d:       "should not challenge if the client failed to authenticate",
+			schemes: []string{"Bearer", "DPoP"},
+			err:     ErrInvalidClient,
+		},
+		{
+			d:       "should not challenge other errors",
+			schemes: []string{"Bearer", "DPoP"},
+			err:     ErrInvalidRequest,
+		},
+		{
+			d:   "should not challenge without supported schemes",
+			err: ErrInactiveToken,
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.d), func(t *testing.T) {
+			f := &Fosite{Config: &Config{WWWAuthenticateSchemes: c.schemes}}
+			rw := httptest.NewRecorder()
+			f.WriteAccessError(context.Background(), rw, nil, c.err)
+
+			assert.Equal(t, c.expect, rw.Header().Values("WWW-Authenticate"))
+		})
+	}
+}
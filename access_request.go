@@ -23,3 +23,13 @@ func NewAccessRequest(session Session) *AccessRequest {
 func (a *AccessRequest) GetGrantTypes() Arguments {
 	return a.GrantTypes
 }
+
+// Clone returns a copy of the access request which can be modified without affecting the original, see
+// Request.Clone. The grant types are deep copied.
+func (a *AccessRequest) Clone() *AccessRequest {
+	return &AccessRequest{
+		GrantTypes:       cloneArguments(a.GrantTypes),
+		HandledGrantType: cloneArguments(a.HandledGrantType),
+		Request:          *a.Request.Clone(),
+	}
+}
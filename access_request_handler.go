@@ -51,9 +51,20 @@ func (f *Fosite) NewAccessRequest(ctx context.Context, r *http.Request, session
 	ctx = context.WithValue(ctx, RequestContextKey, r)
 	ctx = context.WithValue(ctx, AccessRequestContextKey, accessRequest)
 
-	if r.Method != "POST" {
+	maxBytes := f.Config.GetMaxTokenRequestBytes(ctx)
+	if r.Body != nil {
+		// Limit the body before it is buffered by the form parser.
+		r.Body = http.MaxBytesReader(nil, r.Body, maxBytes)
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if err := f.checkTLS(ctx, r); err != nil {
+		return accessRequest, err
+	} else if r.Method != "POST" {
 		return accessRequest, errorsx.WithStack(ErrInvalidRequest.WithHintf("HTTP method is '%s', expected 'POST'.", r.Method))
-	} else if err := r.ParseMultipartForm(1 << 20); err != nil && err != http.ErrNotMultipart {
+	} else if err := parseTokenRequestForm(r, maxBytes); errors.As(err, &maxBytesErr) {
+		return accessRequest, errorsx.WithStack(ErrInvalidRequest.WithHintf("The HTTP body exceeds the maximum size of %d bytes.", maxBytes).WithWrap(err).WithDebug(err.Error()))
+	} else if err != nil {
 		return accessRequest, errorsx.WithStack(ErrInvalidRequest.WithHint("Unable to parse HTTP body, make sure to send a properly formatted form request body.").WithWrap(err).WithDebug(err.Error()))
 	} else if len(r.PostForm) == 0 {
 		return accessRequest, errorsx.WithStack(ErrInvalidRequest.WithHint("The POST body can not be empty."))
@@ -64,20 +75,61 @@ func (f *Fosite) NewAccessRequest(ctx context.Context, r *http.Request, session
 		return accessRequest, errors.New("Session must not be nil")
 	}
 
-	accessRequest.SetRequestedScopes(RemoveEmpty(strings.Split(r.PostForm.Get("scope"), " ")))
-	accessRequest.SetRequestedAudience(GetAudiences(r.PostForm))
 	accessRequest.GrantTypes = RemoveEmpty(strings.Split(r.PostForm.Get("grant_type"), " "))
 	if len(accessRequest.GrantTypes) < 1 {
-		return accessRequest, errorsx.WithStack(ErrInvalidRequest.WithHint("Request parameter 'grant_type' is missing"))
+		return accessRequest, errorsx.WithStack(ErrInvalidRequest.WithHint("The request parameter 'grant_type' is missing."))
+	}
+
+	if enabled := f.Config.GetGrantTypeEnabled(ctx); enabled != nil {
+		for _, grantType := range accessRequest.GrantTypes {
+			if !enabled(ctx, grantType) {
+				return accessRequest, errorsx.WithStack(ErrUnsupportedGrantType.WithHintf("The grant type '%s' is disabled.", grantType))
+			}
+		}
 	}
 
+	accessRequest.SetRequestedScopes(RemoveEmpty(strings.Split(r.PostForm.Get("scope"), " ")))
+	if err := validateScopeCount(ctx, f.Config, accessRequest.GetRequestedScopes()); err != nil {
+		return accessRequest, err
+	}
+
+	if _, err := GetClaimsRequest(accessRequest); err != nil {
+		return accessRequest, err
+	}
+
+	resources := GetResources(r.PostForm)
+	if err := validateResourceIndicators(resources); err != nil {
+		return accessRequest, err
+	}
+	// Resource indicators are granted as audiences.
+	accessRequest.SetRequestedAudience(appendResources(f.getAudiences(ctx, r.PostForm), resources))
+
 	client, clientErr := f.AuthenticateClient(ctx, r, r.PostForm)
 	if clientErr == nil {
 		accessRequest.Client = client
+		accessRequest.SetRequestedScopes(removeUnknownScopes(ctx, f.Config, client, accessRequest.GetRequestedScopes()))
+		f.logAccessRequest(ctx, r, accessRequest, LogEventClientAuthenticated, nil)
+		if hook := f.Config.GetClientAuthenticatedHook(ctx); hook != nil {
+			hook(ctx, client)
+		}
+
+		if err := f.Config.GetResourceStrategy(ctx)(client.GetAudience(), resources); err != nil {
+			return accessRequest, err
+		}
+
+		if err := validatePermittedResources(client, accessRequest.GetRequestedAudience()); err != nil {
+			return accessRequest, err
+		}
+
+		details, err := f.parseAuthorizationDetails(ctx, client, r.PostForm.Get("authorization_details"))
+		if err != nil {
+			return accessRequest, err
+		}
+		accessRequest.SetRequestedAuthorizationDetails(details)
 	}
 
 	var found = false
-	for _, loader := range f.Config.GetTokenEndpointHandlers(ctx) {
+	for _, loader := range f.getTokenEndpointHandlers(ctx, accessRequest) {
 		// Is the loader responsible for handling the request?
 		if !loader.CanHandleTokenEndpointRequest(ctx, accessRequest) {
 			continue
@@ -88,6 +140,7 @@ func (f *Fosite) NewAccessRequest(ctx context.Context, r *http.Request, session
 		// Is the client supplied in the request? If not can this handler skip client auth?
 		if !loader.CanSkipClientAuth(ctx, accessRequest) && clientErr != nil {
 			// No client and handler can not skip client auth -> error.
+			f.logAccessRequest(ctx, r, accessRequest, LogEventClientAuthenticationFailed, clientErr)
 			return accessRequest, clientErr
 		}
 
@@ -100,12 +153,49 @@ func (f *Fosite) NewAccessRequest(ctx context.Context, r *http.Request, session
 			//
 			continue
 		} else if err != nil {
+			if errors.Is(err, ErrInvalidScope) {
+				f.logAccessRequest(ctx, r, accessRequest, LogEventScopeDenied, err)
+			} else {
+				f.logAccessRequest(ctx, r, accessRequest, LogEventGrantRejected, err)
+			}
 			return accessRequest, err
 		}
 	}
 
 	if !found {
-		return nil, errorsx.WithStack(ErrInvalidRequest)
+		err := errorsx.WithStack(ErrInvalidRequest)
+		f.logAccessRequest(ctx, r, accessRequest, LogEventGrantRejected, err)
+		return nil, err
+	}
+
+	for _, decorator := range f.Config.GetTokenEndpointDecorators(ctx) {
+		if err := decorator.DecorateTokenEndpointRequest(ctx, accessRequest); err != nil {
+			f.logAccessRequest(ctx, r, accessRequest, LogEventGrantRejected, err)
+			return accessRequest, err
+		}
+	}
+
+	if err := validateScopeCount(ctx, f.Config, accessRequest.GetGrantedScopes()); err != nil {
+		return accessRequest, err
 	}
+
 	return accessRequest, nil
 }
+
+func (f *Fosite) logAccessRequest(ctx context.Context, r *http.Request, ar AccessRequester, event string, err error) {
+	f.Config.GetLogger(ctx).LogEvent(ctx, event, accessRequestLogFields(r, ar, err))
+}
+
+// parseTokenRequestForm parses URL encoded and multipart bodies. ParseMultipartForm does not return the errors of URL
+// encoded bodies, so exceeding the size limit is checked for them separately.
+func parseTokenRequestForm(r *http.Request, maxBytes int64) error {
+	var maxBytesErr *http.MaxBytesError
+	if err := r.ParseForm(); errors.As(err, &maxBytesErr) {
+		return err
+	}
+
+	if err := r.ParseMultipartForm(maxBytes); err != nil && err != http.ErrNotMultipart {
+		return err
+	}
+	return nil
+}
@@ -4,10 +4,13 @@
 package fosite_test
 
 import (
+	"context"
+	"crypto/tls"
 	"encoding/base64"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -16,7 +19,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	. "github.com/ory/fosite"
+	"github.com/ory/fosite/compose"
 	"github.com/ory/fosite/internal"
+	"github.com/ory/fosite/storage"
 )
 
 func TestNewAccessRequest(t *testing.T) {
@@ -443,6 +448,442 @@ func TestNewAccessRequestWithMixedClientAuth(t *testing.T) {
 	}
 }
 
+func TestNewAccessRequestWithPermittedResources(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := internal.NewMockStorage(ctrl)
+	handler := internal.NewMockTokenEndpointHandler(ctrl)
+	handler.EXPECT().CanHandleTokenEndpointRequest(gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+	handler.EXPECT().CanSkipClientAuth(gomock.Any(), gomock.Any()).Return(false).AnyTimes()
+	defer ctrl.Finish()
+
+	client := &DefaultResourceClient{
+		DefaultClient:      &DefaultClient{ID: "foo", Public: true},
+		PermittedResources: []string{"https://api.example.com/users"},
+	}
+	config := &Config{AudienceMatchingStrategy: DefaultAudienceMatchingStrategy, TokenEndpointHandlers: TokenEndpointHandlers{handler}}
+	fosite := &Fosite{Store: store, Config: config}
+
+	for k, c := range []struct {
+		audience  string
+		mock      func()
+		expectErr error
+	}{
+		{
+			audience: "https://api.example.com/users",
+			mock: func() {
+				handler.EXPECT().HandleTokenEndpointRequest(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			audience:  "https://api.example.com/billing",
+			mock:      func() {},
+			expectErr: ErrInvalidTarget,
+		},
+		{
+			audience:  "https://api.example.com/users https://api.example.com/billing",
+			mock:      func() {},
+			expectErr: ErrInvalidTarget,
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			store.EXPECT().GetClient(gomock.Any(), gomock.Eq("foo")).Return(client, nil)
+			c.mock()
+
+			form := url.Values{"grant_type": {"foo"}, "client_id": {"foo"}, "audience": {c.audience}}
+			r := &http.Request{Header: http.Header{}, PostForm: form, Form: form, Method: "POST"}
+			ar, err := fosite.NewAccessRequest(NewContext(), r, new(DefaultSession))
+
+			if c.expectErr != nil {
+				require.ErrorIs(t, err, c.expectErr)
+			} else {
+				require.NoError(t, err)
+				assert.EqualValues(t, client.PermittedResources, ar.GetRequestedAudience())
+			}
+		})
+	}
+}
+
+func TestNewAccessRequestWithResources(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := internal.NewMockStorage(ctrl)
+	handler := internal.NewMockTokenEndpointHandler(ctrl)
+	handler.EXPECT().CanHandleTokenEndpointRequest(gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+	handler.EXPECT().CanSkipClientAuth(gomock.Any(), gomock.Any()).Return(false).AnyTimes()
+	defer ctrl.Finish()
+
+	client := &DefaultClient{ID: "foo", Public: true, Audience: []string{"https://api.example.com", "https://files.example.com"}}
+	config := &Config{TokenEndpointHandlers: TokenEndpointHandlers{handler}}
+	fosite := &Fosite{Store: store, Config: config}
+
+	for k, c := range []struct {
+		form           url.Values
+		expectErr      error
+		expectAudience Arguments
+	}{
+		{
+			form:           url.Values{"resource": {"https://api.example.com/users", "https://files.example.com"}},
+			expectAudience: Arguments{"https://api.example.com/users", "https://files.example.com"},
+		},
+		{
+			form:           url.Values{"audience": {"https://files.example.com"}, "resource": {"https://api.example.com/users", "https://files.example.com"}},
+			expectAudience: Arguments{"https://files.example.com", "https://api.example.com/users"},
+		},
+		{
+			form:      url.Values{"resource": {"api.example.com"}},
+			expectErr: ErrInvalidTarget,
+		},
+		{
+			form:      url.Values{"resource": {"https://api.example.com/users#fragment"}},
+			expectErr: ErrInvalidTarget,
+		},
+		{
+			form:      url.Values{"resource": {"https://unknown.example.com"}},
+			expectErr: ErrInvalidTarget,
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			store.EXPECT().GetClient(gomock.Any(), gomock.Eq("foo")).Return(client, nil).MaxTimes(1)
+			if c.expectErr == nil {
+				handler.EXPECT().HandleTokenEndpointRequest(gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			c.form.Set("grant_type", "foo")
+			c.form.Set("client_id", "foo")
+			r := &http.Request{Header: http.Header{}, PostForm: c.form, Form: c.form, Method: "POST"}
+			ar, err := fosite.NewAccessRequest(NewContext(), r, new(DefaultSession))
+
+			if c.expectErr != nil {
+				require.ErrorIs(t, err, c.expectErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, c.expectAudience, ar.GetRequestedAudience())
+			}
+		})
+	}
+}
+
+func TestNewAccessRequestWithMaxScopeCount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := internal.NewMockStorage(ctrl)
+	handler := internal.NewMockTokenEndpointHandler(ctrl)
+	handler.EXPECT().CanHandleTokenEndpointRequest(gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+	handler.EXPECT().CanSkipClientAuth(gomock.Any(), gomock.Any()).Return(false).AnyTimes()
+	defer ctrl.Finish()
+
+	client := &DefaultClient{ID: "foo", Public: true}
+	config := &Config{MaxScopeCount: 2, TokenEndpointHandlers: TokenEndpointHandlers{handler}}
+	fosite := &Fosite{Store: store, Config: config}
+
+	for k, c := range []struct {
+		scope     string
+		grant     []string
+		expectErr error
+	}{
+		{scope: "foo bar", grant: []string{"foo", "bar"}},
+		{scope: "foo bar baz", expectErr: ErrInvalidScope},
+		{scope: "foo", grant: []string{"foo", "bar", "baz"}, expectErr: ErrInvalidScope},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			store.EXPECT().GetClient(gomock.Any(), gomock.Eq("foo")).Return(client, nil).MaxTimes(1)
+			if c.grant != nil {
+				handler.EXPECT().HandleTokenEndpointRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, ar AccessRequester) error {
+					for _, scope := range c.grant {
+						ar.GrantScope(scope)
+					}
+					return nil
+				})
+			}
+
+			form := url.Values{"grant_type": {"foo"}, "client_id": {"foo"}, "scope": {c.scope}}
+			r := &http.Request{Header: http.Header{}, PostForm: form, Form: form, Method: "POST"}
+			_, err := fosite.NewAccessRequest(NewContext(), r, new(DefaultSession))
+			if c.expectErr != nil {
+				require.ErrorIs(t, err, c.expectErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNewAccessRequestWithMaxTokenRequestBytes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := internal.NewMockStorage(ctrl)
+	handler := internal.NewMockTokenEndpointHandler(ctrl)
+	handler.EXPECT().CanHandleTokenEndpointRequest(gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+	handler.EXPECT().CanSkipClientAuth(gomock.Any(), gomock.Any()).Return(false).AnyTimes()
+	defer ctrl.Finish()
+
+	client := &DefaultClient{ID: "foo", Public: true}
+	config := &Config{MaxTokenRequestBytes: 256, TokenEndpointHandlers: TokenEndpointHandlers{handler}}
+	fosite := &Fosite{Store: store, Config: config}
+
+	newRequest := func(form url.Values) *http.Request {
+		r, err := http.NewRequest("POST", "https://auth.example.com/token", strings.NewReader(form.Encode()))
+		require.NoError(t, err)
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return r
+	}
+
+	t.Run("case=should pass a normal body", func(t *testing.T) {
+		store.EXPECT().GetClient(gomock.Any(), gomock.Eq("foo")).Return(client, nil)
+		handler.EXPECT().HandleTokenEndpointRequest(gomock.Any(), gomock.Any()).Return(nil)
+
+		_, err := fosite.NewAccessRequest(NewContext(), newRequest(url.Values{"grant_type": {"foo"}, "client_id": {"foo"}}), new(DefaultSession))
+		require.NoError(t, err)
+	})
+
+	t.Run("case=should reject an oversized body", func(t *testing.T) {
+		form := url.Values{"grant_type": {"foo"}, "client_id": {"foo"}, "scope": {strings.Repeat("a", 512)}}
+		_, err := fosite.NewAccessRequest(NewContext(), newRequest(form), new(DefaultSession))
+		require.ErrorIs(t, err, ErrInvalidRequest)
+		assert.Contains(t, ErrorToRFC6749Error(err).HintField, "maximum size of 256 bytes")
+	})
+}
+
+func TestNewAccessRequestWithRequireTLS(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := internal.NewMockStorage(ctrl)
+	handler := internal.NewMockTokenEndpointHandler(ctrl)
+	handler.EXPECT().CanHandleTokenEndpointRequest(gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+	handler.EXPECT().CanSkipClientAuth(gomock.Any(), gomock.Any()).Return(false).AnyTimes()
+	defer ctrl.Finish()
+
+	client := &DefaultClient{ID: "foo", Public: true}
+	config := &Config{RequireTLS: true, TLSForwardedProtoHeader: "X-Forwarded-Proto", TokenEndpointHandlers: TokenEndpointHandlers{handler}}
+	fosite := &Fosite{Store: store, Config: config}
+
+	for k, c := range []struct {
+		d         string
+		tls       bool
+		proto     string
+		expectErr bool
+	}{
+		{d: "plaintext request", expectErr: true},
+		{d: "plaintext request forwarded from plaintext", proto: "http", expectErr: true},
+		{d: "request over TLS", tls: true},
+		{d: "request forwarded from TLS", proto: "https"},
+		{d: "request forwarded from TLS by several proxies", proto: "HTTPS, http"},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.d), func(t *testing.T) {
+			form := url.Values{"grant_type": {"foo"}, "client_id": {"foo"}}
+			r := &http.Request{Header: http.Header{}, PostForm: form, Form: form, Method: "POST"}
+			if c.tls {
+				r.TLS = new(tls.ConnectionState)
+			}
+			if c.proto != "" {
+				r.Header.Set("X-Forwarded-Proto", c.proto)
+			}
+
+			if c.expectErr {
+				_, err := fosite.NewAccessRequest(NewContext(), r, new(DefaultSession))
+				require.ErrorIs(t, err, ErrInvalidRequest)
+				assert.Equal(t, "The request must be sent over TLS.", ErrorToRFC6749Error(err).HintField)
+				return
+			}
+
+			store.EXPECT().GetClient(gomock.Any(), gomock.Eq("foo")).Return(client, nil)
+			handler.EXPECT().HandleTokenEndpointRequest(gomock.Any(), gomock.Any()).Return(nil)
+			_, err := fosite.NewAccessRequest(NewContext(), r, new(DefaultSession))
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("case=forwarded header is ignored if not configured", func(t *testing.T) {
+		fosite := &Fosite{Store: store, Config: &Config{RequireTLS: true}}
+		form := url.Values{"grant_type": {"foo"}, "client_id": {"foo"}}
+		r := &http.Request{Header: http.Header{"X-Forwarded-Proto": {"https"}}, PostForm: form, Form: form, Method: "POST"}
+		_, err := fosite.NewAccessRequest(NewContext(), r, new(DefaultSession))
+		require.ErrorIs(t, err, ErrInvalidRequest)
+	})
+}
+
+func TestNewAccessRequestWithDisabledGrantType(t *testing.T) {
+	passwordEnabled := true
+	config := &Config{
+		GrantTypeEnabled: func(ctx context.Context, grantType string) bool {
+			return grantType != "password" || passwordEnabled
+		},
+	}
+	f := compose.ComposeAllEnabled(config, storage.NewExampleStore(), nil).(*Fosite)
+
+	newRequest := func(form url.Values) *http.Request {
+		r := &http.Request{Header: http.Header{}, PostForm: form, Form: form, Method: "POST"}
+		r.SetBasicAuth("my-client", "foobar")
+		return r
+	}
+	passwordRequest := func() *http.Request {
+		return newRequest(url.Values{"grant_type": {"password"}, "username": {"peter"}, "password": {"secret"}})
+	}
+	clientCredentialsRequest := func() *http.Request {
+		return newRequest(url.Values{"grant_type": {"client_credentials"}})
+	}
+
+	t.Run("case=password grant is enabled", func(t *testing.T) {
+		_, err := f.NewAccessRequest(context.Background(), passwordRequest(), new(DefaultSession))
+		require.NoError(t, err)
+	})
+
+	passwordEnabled = false
+
+	t.Run("case=password grant is disabled", func(t *testing.T) {
+		_, err := f.NewAccessRequest(context.Background(), passwordRequest(), new(DefaultSession))
+		require.ErrorIs(t, err, ErrUnsupportedGrantType)
+		assert.Equal(t, "The grant type 'password' is disabled.", ErrorToRFC6749Error(err).HintField)
+	})
+
+	t.Run("case=client credentials grant still works", func(t *testing.T) {
+		_, err := f.NewAccessRequest(context.Background(), clientCredentialsRequest(), new(DefaultSession))
+		require.NoError(t, err)
+	})
+}
+
+func TestNewAccessRequestWithMissingGrantType(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := internal.NewMockStorage(ctrl)
+	handler := internal.NewMockTokenEndpointHandler(ctrl)
+	defer ctrl.Finish()
+
+	config := &Config{MaxScopeCount: 1, TokenEndpointHandlers: TokenEndpointHandlers{handler}}
+	fosite := &Fosite{Store: store, Config: config}
+
+	for k, c := range []struct {
+		d    string
+		form url.Values
+	}{
+		{d: "absent", form: url.Values{"client_id": {"foo"}}},
+		{d: "empty", form: url.Values{"grant_type": {""}, "client_id": {"foo"}}},
+		{d: "whitespace", form: url.Values{"grant_type": {" "}, "client_id": {"foo"}}},
+		{d: "absent with other invalid parameters", form: url.Values{"client_id": {"foo"}, "scope": {"foo bar baz"}, "resource": {"not-a-uri"}}},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.d), func(t *testing.T) {
+			r := &http.Request{Header: http.Header{}, PostForm: c.form, Form: c.form, Method: "POST"}
+			_, err := fosite.NewAccessRequest(NewContext(), r, new(DefaultSession))
+			require.ErrorIs(t, err, ErrInvalidRequest)
+			assert.Contains(t, ErrorToRFC6749Error(err).HintField, "'grant_type'")
+		})
+	}
+}
+
+func TestNewAccessRequestInvokesClientAuthenticatedHook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := internal.NewMockStorage(ctrl)
+	handler := internal.NewMockTokenEndpointHandler(ctrl)
+	handler.EXPECT().CanHandleTokenEndpointRequest(gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+	handler.EXPECT().CanSkipClientAuth(gomock.Any(), gomock.Any()).Return(false).AnyTimes()
+	hasher := internal.NewMockHasher(ctrl)
+	defer ctrl.Finish()
+
+	client := &DefaultClient{ID: "foo", Secret: []byte("foo")}
+	var authenticated []Client
+	config := &Config{
+		ClientSecretsHasher:   hasher,
+		TokenEndpointHandlers: TokenEndpointHandlers{handler},
+		OnClientAuthenticated: func(_ context.Context, c Client) {
+			authenticated = append(authenticated, c)
+		},
+	}
+	fosite := &Fosite{Store: store, Config: config}
+
+	for k, c := range []struct {
+		d         string
+		mock      func()
+		expectErr error
+	}{
+		{
+			d: "should not invoke the hook if client authentication fails",
+			mock: func() {
+				hasher.EXPECT().Compare(gomock.Any(), gomock.Eq([]byte("foo")), gomock.Eq([]byte("bar"))).Return(errors.New(""))
+			},
+			expectErr: ErrInvalidClient,
+		},
+		{
+			d: "should invoke the hook once if client authentication succeeds",
+			mock: func() {
+				hasher.EXPECT().Compare(gomock.Any(), gomock.Eq([]byte("foo")), gomock.Eq([]byte("bar"))).Return(nil)
+				handler.EXPECT().HandleTokenEndpointRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ AccessRequester) error {
+					assert.Len(t, authenticated, 1, "the hook must be invoked before the grant is handled")
+					return nil
+				})
+			},
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.d), func(t *testing.T) {
+			authenticated = nil
+			store.EXPECT().GetClient(gomock.Any(), gomock.Eq("foo")).Return(client, nil)
+			c.mock()
+
+			r := &http.Request{
+				Header:   http.Header{"Authorization": {basicAuth("foo", "bar")}},
+				PostForm: url.Values{"grant_type": {"foo"}},
+				Method:   "POST",
+			}
+			_, err := fosite.NewAccessRequest(NewContext(), r, new(DefaultSession))
+			if c.expectErr != nil {
+				require.ErrorIs(t, err, c.expectErr)
+				assert.Empty(t, authenticated)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, authenticated, 1)
+			assert.Equal(t, client, authenticated[0])
+		})
+	}
+}
+
 func basicAuth(username, password string) string {
 	return "Basic " + base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", username, password)))
 }
+
+type recordingLogger struct {
+	events []string
+	fields []LogFields
+}
+
+func (l *recordingLogger) LogEvent(_ context.Context, event string, fields LogFields) {
+	l.events = append(l.events, event)
+	l.fields = append(l.fields, fields)
+}
+
+func TestNewAccessRequestLogsClientAuthenticationFailure(t *testing.T) {
+	for k, c := range []struct {
+		d          string
+		skip       bool
+		expectErr  error
+		expectLogs []string
+	}{
+		{d: "should not log if the handler skips client authentication", skip: true},
+		{d: "should log if the handler requires client authentication", expectErr: ErrInvalidClient, expectLogs: []string{LogEventClientAuthenticationFailed}},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.d), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := internal.NewMockStorage(ctrl)
+			handler := internal.NewMockTokenEndpointHandler(ctrl)
+			defer ctrl.Finish()
+
+			store.EXPECT().GetClient(gomock.Any(), "my:client").Return(nil, errors.New("not found"))
+			handler.EXPECT().CanHandleTokenEndpointRequest(gomock.Any(), gomock.Any()).Return(true)
+			handler.EXPECT().CanSkipClientAuth(gomock.Any(), gomock.Any()).Return(c.skip)
+			if c.skip {
+				handler.EXPECT().HandleTokenEndpointRequest(gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			logger := new(recordingLogger)
+			fosite := &Fosite{Store: store, Config: &Config{Logger: logger, TokenEndpointHandlers: TokenEndpointHandlers{handler}}}
+
+			form := url.Values{"grant_type": {"foo"}}
+			r := &http.Request{Header: http.Header{"Authorization": {basicAuth("my%3Aclient", "secret")}}, PostForm: form, Form: form, Method: "POST"}
+			_, err := fosite.NewAccessRequest(NewContext(), r, new(DefaultSession))
+			if c.expectErr != nil {
+				require.ErrorIs(t, err, c.expectErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, c.expectLogs, logger.events)
+			for _, fields := range logger.fields {
+				assert.Equal(t, "my:client", fields[LogFieldClientID])
+			}
+		})
+	}
+}
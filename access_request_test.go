@@ -23,3 +23,22 @@ func TestAccessRequest(t *testing.T) {
 	assert.Equal(t, Arguments{"foo", "bar"}, ar.RequestedScope)
 	assert.Equal(t, ar.Client, ar.GetClient())
 }
+
+func TestAccessRequestClone(t *testing.T) {
+	ar := NewAccessRequest(&DefaultSession{Subject: "peter"})
+	ar.GrantTypes = Arguments{"authorization_code"}
+	ar.GrantScope("foo")
+
+	c := ar.Clone()
+	assert.Equal(t, ar, c)
+
+	c.GrantTypes[0] = "changed"
+	c.HandledGrantType = append(c.HandledGrantType, "changed")
+	c.GrantScope("bar")
+	c.GetSession().(*DefaultSession).Subject = "alice"
+
+	assert.Equal(t, Arguments{"authorization_code"}, ar.GrantTypes)
+	assert.Empty(t, ar.HandledGrantType)
+	assert.Equal(t, Arguments{"foo"}, ar.GrantedScope)
+	assert.Equal(t, "peter", ar.GetSession().GetSubject())
+}
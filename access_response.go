@@ -18,8 +18,12 @@ type AccessResponse struct {
 	Extra       map[string]interface{}
 	AccessToken string
 	TokenType   string
+
+	signatures map[TokenType]string
 }
 
+var _ TokenSignatureResponder = (*AccessResponse)(nil)
+
 func (a *AccessResponse) SetScopes(scopes Arguments) {
 	a.SetExtra("scope", strings.Join(scopes, " "))
 }
@@ -52,6 +56,17 @@ func (a *AccessResponse) GetTokenType() string {
 	return a.TokenType
 }
 
+func (a *AccessResponse) SetTokenSignature(tokenType TokenType, signature string) {
+	if a.signatures == nil {
+		a.signatures = make(map[TokenType]string)
+	}
+	a.signatures[tokenType] = signature
+}
+
+func (a *AccessResponse) GetTokenSignature(tokenType TokenType) string {
+	return a.signatures[tokenType]
+}
+
 func (a *AccessResponse) ToMap() map[string]interface{} {
 	a.Extra["access_token"] = a.GetAccessToken()
 	a.Extra["token_type"] = a.GetTokenType()
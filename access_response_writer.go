@@ -24,7 +24,21 @@ func (f *Fosite) NewAccessResponse(ctx context.Context, requester AccessRequeste
 	ctx = context.WithValue(ctx, AccessRequestContextKey, requester)
 	ctx = context.WithValue(ctx, AccessResponseContextKey, response)
 
-	for _, tk = range f.Config.GetTokenEndpointHandlers(ctx) {
+	// Scopes and audiences may have been granted after the access request was validated.
+	if f.Config.GetMaxScopeCount(ctx) > 0 {
+		if err := validateScopeCount(ctx, f.Config, requester.GetGrantedScopes()); err != nil {
+			return nil, err
+		}
+	}
+
+	// The granted audiences are only validated here, as they are final once the response is created.
+	if requester != nil {
+		if err := validateAudienceLength(ctx, f.Config, requester.GetGrantedAudience()); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, tk = range f.getTokenEndpointHandlers(ctx, requester) {
 		if err = tk.PopulateTokenEndpointResponse(ctx, requester, response); err == nil {
 			// do nothing
 		} else if errors.Is(err, ErrUnknownRequest) {
@@ -34,6 +48,24 @@ func (f *Fosite) NewAccessResponse(ctx context.Context, requester AccessRequeste
 		}
 	}
 
+	for _, decorator := range f.Config.GetTokenEndpointDecorators(ctx) {
+		if err = decorator.DecorateTokenEndpointResponse(ctx, requester, response); err != nil {
+			return nil, err
+		}
+	}
+
+	// Only the response reports the requested casing, the request keeps the granted scopes as they are.
+	if f.Config.GetReportRequestedScopeCasing(ctx) {
+		if _, ok := response.GetExtra("scope").(string); ok {
+			response.SetScopes(withRequestedScopeCasing(requester, requester.GetGrantedScopes()))
+		}
+	}
+
+	// The granted authorization details are reported as defined in https://datatracker.ietf.org/doc/html/rfc9396#section-7.
+	if details, ok := requester.(AuthorizationDetailsRequester); ok && len(details.GetGrantedAuthorizationDetails()) > 0 {
+		response.SetExtra("authorization_details", details.GetGrantedAuthorizationDetails())
+	}
+
 	if response.GetAccessToken() == "" || response.GetTokenType() == "" {
 		return nil, errorsx.WithStack(ErrServerError.
 			WithHint("An internal server occurred while trying to complete the request.").
@@ -41,5 +73,22 @@ func (f *Fosite) NewAccessResponse(ctx context.Context, requester AccessRequeste
 			WithLocalizer(f.Config.GetMessageCatalog(ctx), getLangFromRequester(requester)))
 	}
 
+	if f.Config.GetAuditSink(ctx) != nil {
+		tokenTypes := []TokenType{AccessToken}
+		for _, tokenType := range []TokenType{RefreshToken, IDToken} {
+			if response.GetExtra(string(tokenType)) != nil {
+				tokenTypes = append(tokenTypes, tokenType)
+			}
+		}
+		event := NewAuditEvent(AuditEventTokenIssued, requester, tokenTypes...)
+		event.AccessTokenSignature = response.GetTokenSignature(AccessToken)
+		event.RefreshTokenSignature = response.GetTokenSignature(RefreshToken)
+		event.AccessTokenJTI = JWTID(response.GetAccessToken())
+		if err := RecordAuditEvent(ctx, f.Config, event); err != nil {
+			return nil, err
+		}
+	}
+
+	f.logAccessRequest(ctx, nil, requester, LogEventTokenIssued, nil)
 	return response, nil
 }
@@ -86,3 +86,83 @@ func TestNewAccessResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestNewAccessResponseWithRequestedScopeCasing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	handler := internal.NewMockTokenEndpointHandler(ctrl)
+	defer ctrl.Finish()
+
+	for k, c := range []struct {
+		d      string
+		report bool
+		expect string
+	}{
+		{d: "should report the normalized scopes by default", expect: "photos offline"},
+		{d: "should report the requested casing", report: true, expect: "Photos offline"},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.d), func(t *testing.T) {
+			f := &Fosite{Config: &Config{ReportRequestedScopeCasing: c.report, TokenEndpointHandlers: TokenEndpointHandlers{handler}}}
+
+			// The scopes have been normalized to lower case when the request was handled.
+			requester := NewAccessRequest(new(DefaultSession))
+			requester.Form.Set("scope", "Photos offline")
+			requester.SetRequestedScopes(Arguments{"photos", "offline"})
+			requester.GrantScope("photos")
+			requester.GrantScope("offline")
+
+			handler.EXPECT().PopulateTokenEndpointResponse(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(_ context.Context, requester AccessRequester, resp AccessResponder) {
+				resp.SetAccessToken("foo")
+				resp.SetTokenType("bearer")
+				resp.SetScopes(requester.GetGrantedScopes())
+			}).Return(nil)
+
+			resp, err := f.NewAccessResponse(context.Background(), requester)
+			require.NoError(t, err)
+			assert.Equal(t, c.expect, resp.GetExtra("scope"))
+			assert.Equal(t, Arguments{"photos", "offline"}, requester.GetGrantedScopes())
+		})
+	}
+}
+
+func TestNewAccessResponseWithMaxAudienceLength(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	handler := internal.NewMockTokenEndpointHandler(ctrl)
+	defer ctrl.Finish()
+
+	f := &Fosite{Config: &Config{MaxAudienceLength: 20, TokenEndpointHandlers: TokenEndpointHandlers{handler}}}
+	for k, c := range []struct {
+		d          string
+		grant      []string
+		expectErr  error
+		expectHint string
+	}{
+		{d: "should pass with an audience at the limit", grant: []string{"https://ory.sh/api12"}},
+		{
+			d:          "should fail with an audience over the limit without echoing it",
+			grant:      []string{"https://ory.sh/api12", "https://ory.sh/api123"},
+			expectErr:  ErrInvalidTarget,
+			expectHint: "The granted audience at position 2 must not exceed 20 characters.",
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.d), func(t *testing.T) {
+			requester := NewAccessRequest(new(DefaultSession))
+			for _, audience := range c.grant {
+				requester.GrantAudience(audience)
+			}
+
+			if c.expectErr != nil {
+				_, err := f.NewAccessResponse(context.Background(), requester)
+				require.ErrorIs(t, err, c.expectErr)
+				assert.Equal(t, c.expectHint, ErrorToRFC6749Error(err).HintField)
+				return
+			}
+
+			handler.EXPECT().PopulateTokenEndpointResponse(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(_ context.Context, _ AccessRequester, resp AccessResponder) {
+				resp.SetAccessToken("foo")
+				resp.SetTokenType("bearer")
+			}).Return(nil)
+			_, err := f.NewAccessResponse(context.Background(), requester)
+			require.NoError(t, err)
+		})
+	}
+}
@@ -7,13 +7,33 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strings"
+	"time"
 )
 
+// ExpiresInReporter returns the "expires_in" value reported in access token responses of the given grant type,
+// given the actual lifetime of the access token. It does not change the actual lifetime of the token, so reporting
+// a different value is not standard compliant and only meant as a workaround for legacy clients.
+type ExpiresInReporter func(grantType string, actual time.Duration) time.Duration
+
 func (f *Fosite) WriteAccessResponse(ctx context.Context, rw http.ResponseWriter, requester AccessRequester, responder AccessResponder) {
 	rw.Header().Set("Cache-Control", "no-store")
 	rw.Header().Set("Pragma", "no-cache")
 
-	js, err := json.Marshal(responder.ToMap())
+	response := responder.ToMap()
+	if reporter := f.Config.GetExpiresInReporter(ctx); reporter != nil && requester != nil {
+		if expiresIn, ok := response["expires_in"].(int64); ok {
+			reported := make(map[string]interface{}, len(response))
+			for k, v := range response {
+				reported[k] = v
+			}
+			grantType := strings.Join(requester.GetGrantTypes(), " ")
+			reported["expires_in"] = int64(reporter(grantType, time.Duration(expiresIn)*time.Second) / time.Second)
+			response = reported
+		}
+	}
+
+	js, err := json.Marshal(response)
 	if err != nil {
 		http.Error(rw, err.Error(), http.StatusInternalServerError)
 		return
@@ -5,11 +5,15 @@ package fosite_test
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	. "github.com/ory/fosite"
 	. "github.com/ory/fosite/internal"
@@ -34,3 +38,70 @@ func TestWriteAccessResponse(t *testing.T) {
 	assert.Equal(t, "no-store", header.Get("Cache-Control"))
 	assert.Equal(t, "no-cache", header.Get("Pragma"))
 }
+
+func TestWriteAccessResponseIsStable(t *testing.T) {
+	f := &Fosite{Config: new(Config)}
+	resp := NewAccessResponse()
+	resp.SetAccessToken("foo")
+	resp.SetTokenType("bearer")
+	resp.SetExpiresIn(time.Hour)
+	resp.SetScopes(Arguments{"foo", "bar"})
+	resp.SetExtra("refresh_token", "bar")
+	for _, extra := range []string{"zeta", "alpha", "mu", "beta", "omega", "gamma"} {
+		resp.SetExtra(extra, extra)
+	}
+
+	var expected string
+	for i := 0; i < 20; i++ {
+		rw := httptest.NewRecorder()
+		f.WriteAccessResponse(context.Background(), rw, nil, resp)
+		require.Equal(t, http.StatusOK, rw.Code)
+		if i == 0 {
+			expected = rw.Body.String()
+			continue
+		}
+		assert.Equal(t, expected, rw.Body.String())
+	}
+
+	// encoding/json sorts the keys of maps.
+	assert.Equal(t, `{"access_token":"foo","alpha":"alpha","beta":"beta","expires_in":3600,"gamma":"gamma","mu":"mu","omega":"omega",`+
+		`"refresh_token":"bar","scope":"foo bar","token_type":"bearer","zeta":"zeta"}`, expected)
+}
+
+func TestWriteAccessResponseWithExpiresInReporter(t *testing.T) {
+	var grantTypes []string
+	f := &Fosite{Config: &Config{ExpiresInReporter: func(grantType string, actual time.Duration) time.Duration {
+		grantTypes = append(grantTypes, grantType)
+		if actual > 10*time.Minute {
+			return 10 * time.Minute
+		}
+		return actual
+	}}}
+
+	ar := NewAccessRequest(nil)
+	ar.GrantTypes = Arguments{"client_credentials"}
+
+	for k, c := range []struct {
+		actual   time.Duration
+		reported float64
+	}{
+		{actual: time.Hour, reported: 600},
+		{actual: 5 * time.Minute, reported: 300},
+	} {
+		resp := NewAccessResponse()
+		resp.SetAccessToken("foo")
+		resp.SetTokenType("bearer")
+		resp.SetExpiresIn(c.actual)
+
+		rw := httptest.NewRecorder()
+		f.WriteAccessResponse(context.Background(), rw, ar, resp)
+		require.Equal(t, http.StatusOK, rw.Code, "%d", k)
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &body))
+		assert.Equal(t, c.reported, body["expires_in"], "%d", k)
+		assert.Equal(t, int64(c.actual/time.Second), resp.GetExtra("expires_in"), "%d", k)
+	}
+
+	assert.Equal(t, []string{"client_credentials", "client_credentials"}, grantTypes)
+}
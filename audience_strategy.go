@@ -75,6 +75,23 @@ func ExactAudienceMatchingStrategy(haystack []string, needle []string) error {
 	return nil
 }
 
+// TrailingSlashNormalizingAudienceMatchingStrategy wraps the given strategy so that trailing slashes are removed
+// from the whitelisted and requested audiences before comparing them. With it, a requested audience of
+// "https://api.example.com/" matches a whitelisted audience of "https://api.example.com" and vice versa.
+func TrailingSlashNormalizingAudienceMatchingStrategy(strategy AudienceMatchingStrategy) AudienceMatchingStrategy {
+	return func(haystack []string, needle []string) error {
+		return strategy(trimTrailingSlashes(haystack), trimTrailingSlashes(needle))
+	}
+}
+
+func trimTrailingSlashes(audience []string) []string {
+	trimmed := make([]string, len(audience))
+	for k, aud := range audience {
+		trimmed[k] = strings.TrimRight(aud, "/")
+	}
+	return trimmed
+}
+
 // GetAudiences allows audiences to be provided as repeated "audience" form parameter,
 // or as a space-delimited "audience" form parameter if it is not repeated.
 // RFC 8693 in section 2.1 specifies that multiple audience values should be multiple
@@ -92,13 +109,78 @@ func GetAudiences(form url.Values) []string {
 	}
 }
 
+// validateAudienceLength returns ErrInvalidTarget if an audience is longer than allowed by MaxAudienceLengthProvider.
+func validateAudienceLength(ctx context.Context, config MaxAudienceLengthProvider, audience Arguments) error {
+	max := config.GetMaxAudienceLength(ctx)
+	if max <= 0 {
+		return nil
+	}
+
+	// The audience is not part of the hint, as it is too long to be echoed.
+	for k, aud := range audience {
+		if len(aud) > max {
+			return errorsx.WithStack(ErrInvalidTarget.WithHintf("The granted audience at position %d must not exceed %d characters.", k+1, max))
+		}
+	}
+	return nil
+}
+
+// validatePermittedResources checks that all requested resources have been registered for the client. Clients which
+// do not implement ResourceClient are not restricted.
+func validatePermittedResources(client Client, requested []string) error {
+	rc, ok := client.(ResourceClient)
+	if !ok {
+		return nil
+	}
+
+	for _, resource := range requested {
+		var found bool
+		for _, permitted := range rc.GetPermittedResources() {
+			if resource == permitted {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return errorsx.WithStack(ErrInvalidTarget.WithHintf(`The OAuth 2.0 Client is not permitted to request resource "%s".`, resource))
+		}
+	}
+
+	return nil
+}
+
+// getAudiences returns the requested audiences like GetAudiences does, unless splitting a single "audience" form
+// parameter by space has been disabled, in which case every "audience" form parameter is one audience.
+func (f *Fosite) getAudiences(ctx context.Context, form url.Values) []string {
+	if !f.Config.GetDisableSpaceDelimitedAudience(ctx) {
+		return GetAudiences(form)
+	}
+
+	if audiences := RemoveEmpty(form["audience"]); audiences != nil {
+		return audiences
+	}
+	return []string{}
+}
+
 func (f *Fosite) validateAuthorizeAudience(ctx context.Context, r *http.Request, request *AuthorizeRequest) error {
-	audience := GetAudiences(request.Form)
+	audience := f.getAudiences(ctx, request.Form)
+	resources := GetResources(request.Form)
+
+	if err := f.Config.GetResourceStrategy(ctx)(request.Client.GetAudience(), resources); err != nil {
+		return err
+	}
 
 	if err := f.Config.GetAudienceStrategy(ctx)(request.Client.GetAudience(), audience); err != nil {
 		return err
 	}
 
+	// Resource indicators are granted as audiences.
+	audience = appendResources(audience, resources)
+	if err := validatePermittedResources(request.Client, audience); err != nil {
+		return err
+	}
+
 	request.SetRequestedAudience(audience)
 	return nil
 }
@@ -4,7 +4,9 @@
 package fosite
 
 import (
+	"context"
 	"fmt"
+	"net/url"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -255,3 +257,98 @@ func TestExactAudienceMatchingStrategy(t *testing.T) {
 		})
 	}
 }
+
+func TestTrailingSlashNormalizingAudienceMatchingStrategy(t *testing.T) {
+	for k, tc := range []struct {
+		d   string
+		h   []string
+		n   []string
+		err bool
+	}{
+		{
+			d: "should match a requested audience with a trailing slash",
+			h: []string{"https://api.example.com"},
+			n: []string{"https://api.example.com/"},
+		},
+		{
+			d: "should match a requested audience without a trailing slash",
+			h: []string{"https://api.example.com/"},
+			n: []string{"https://api.example.com"},
+		},
+		{
+			d: "should match a path with a trailing slash",
+			h: []string{"https://cloud.ory.sh/api/users"},
+			n: []string{"https://cloud.ory.sh/api/users/"},
+		},
+		{
+			d:   "should not match a different audience",
+			h:   []string{"https://api.example.com/"},
+			n:   []string{"https://api.example.org"},
+			err: true,
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, tc.d), func(t *testing.T) {
+			require.Error(t, ExactAudienceMatchingStrategy(tc.h, tc.n))
+
+			strategy := (&Config{AudienceMatchingStrategy: ExactAudienceMatchingStrategy, NormalizeAudienceTrailingSlashes: true}).GetAudienceStrategy(context.Background())
+			if tc.err {
+				require.Error(t, strategy(tc.h, tc.n))
+			} else {
+				require.NoError(t, strategy(tc.h, tc.n))
+			}
+		})
+	}
+}
+
+func TestGetAudiences(t *testing.T) {
+	client := &DefaultClient{Audience: []string{"https://a.example.com", "https://b.example.com"}}
+
+	for k, tc := range []struct {
+		form     url.Values
+		disable  bool
+		expected []string
+	}{
+		{
+			form:     url.Values{},
+			expected: []string{},
+		},
+		{
+			form:     url.Values{"audience": {"https://a.example.com https://b.example.com"}},
+			expected: []string{"https://a.example.com", "https://b.example.com"},
+		},
+		{
+			form:     url.Values{"audience": {"https://a.example.com", "https://b.example.com"}},
+			expected: []string{"https://a.example.com", "https://b.example.com"},
+		},
+		{
+			form:     url.Values{},
+			disable:  true,
+			expected: []string{},
+		},
+		{
+			form:     url.Values{"audience": {"https://a.example.com", "https://b.example.com"}},
+			disable:  true,
+			expected: []string{"https://a.example.com", "https://b.example.com"},
+		},
+		{
+			form:     url.Values{"audience": {"some audience"}},
+			disable:  true,
+			expected: []string{"some audience"},
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			f := &Fosite{Config: &Config{DisableSpaceDelimitedAudience: tc.disable}}
+			require.Equal(t, tc.expected, f.getAudiences(context.Background(), tc.form))
+
+			if tc.disable {
+				return
+			}
+
+			request := NewAuthorizeRequest()
+			request.Client = client
+			request.Form = tc.form
+			require.NoError(t, f.validateAuthorizeAudience(context.Background(), nil, request))
+			require.ElementsMatch(t, tc.expected, request.GetRequestedAudience())
+		})
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/ory/x/errorsx"
+)
+
+// Types of the events passed to the AuditSink.
+const (
+	AuditEventTokenIssued  = "token_issued"
+	AuditEventTokenRevoked = "token_revoked"
+)
+
+// AuditEvent describes tokens which were issued or revoked. It never contains token values or other secrets.
+type AuditEvent struct {
+	// Type is either AuditEventTokenIssued or AuditEventTokenRevoked.
+	Type string
+
+	// RequestID identifies the request the tokens belong to. It is shared by all tokens of a grant, including the
+	// tokens issued by refreshing it, and is the ID used by the TokenRevocationStorage.
+	RequestID string
+
+	// ClientID is the ID of the client the tokens were issued to.
+	ClientID string
+
+	// Subject is the subject of the tokens, which is empty for grants without a resource owner.
+	Subject string
+
+	// GrantType is the grant type which issued the tokens.
+	GrantType string
+
+	// Scopes are the granted scopes.
+	Scopes Arguments
+
+	// Audience is the granted audience.
+	Audience Arguments
+
+	// TokenTypes are the types of the issued or revoked tokens.
+	TokenTypes []TokenType
+
+	// AccessTokenSignature and RefreshTokenSignature identify the issued or revoked tokens in the storage, if they are
+	// known. Unlike the tokens, the signatures can not be used to access resources. Events of tokens revoked by their
+	// request, subject or client leave them empty.
+	AccessTokenSignature  string
+	RefreshTokenSignature string
+
+	// AccessTokenJTI is the "jti" claim of the access token, if it is a JWT.
+	AccessTokenJTI string
+}
+
+// TokenSignatureResponder is implemented by AccessResponders which keep the signatures of the issued tokens for the
+// audit trail. The signatures are not part of the response.
+type TokenSignatureResponder interface {
+	SetTokenSignature(tokenType TokenType, signature string)
+	GetTokenSignature(tokenType TokenType) string
+}
+
+// SetTokenSignature records the signature of an issued token on the responder, if it is a TokenSignatureResponder.
+func SetTokenSignature(responder AccessResponder, tokenType TokenType, signature string) {
+	if r, ok := responder.(TokenSignatureResponder); ok {
+		r.SetTokenSignature(tokenType, signature)
+	}
+}
+
+// AuditSink records an audit trail of issued and revoked tokens. It is called synchronously, after the tokens were
+// stored and before the response is written.
+type AuditSink interface {
+	// Audit records the event. An error blocks the response only if strict auditing is enabled.
+	Audit(ctx context.Context, event AuditEvent) error
+}
+
+// NewAuditEvent returns an event of the given type describing the tokens of the request.
+func NewAuditEvent(eventType string, requester Requester, tokenTypes ...TokenType) AuditEvent {
+	event := AuditEvent{
+		Type:       eventType,
+		RequestID:  requester.GetID(),
+		Scopes:     requester.GetGrantedScopes(),
+		Audience:   requester.GetGrantedAudience(),
+		TokenTypes: tokenTypes,
+	}
+
+	if client := requester.GetClient(); client != nil {
+		event.ClientID = client.GetID()
+	}
+
+	if session := requester.GetSession(); session != nil {
+		event.Subject = session.GetSubject()
+	}
+
+	if ar, ok := requester.(AccessRequester); ok {
+		event.GrantType = strings.Join(ar.GetGrantTypes(), " ")
+	}
+
+	return event
+}
+
+// RecordAuditEvent passes the event to the configured AuditSink. The error of the sink is only returned if strict
+// auditing is enabled. A nil config records nothing.
+func RecordAuditEvent(ctx context.Context, config interface {
+	AuditSinkProvider
+	StrictAuditProvider
+}, event AuditEvent) error {
+	if config == nil {
+		return nil
+	}
+
+	sink := config.GetAuditSink(ctx)
+	if sink == nil {
+		return nil
+	}
+
+	if err := sink.Audit(ctx, event); err != nil && config.GetStrictAudit(ctx) {
+		return errorsx.WithStack(ErrServerError.WithHint("Unable to record the audit event.").WithWrap(err).WithDebug(err.Error()))
+	}
+	return nil
+}
+
+// JWTID returns the "jti" claim of the token if it is a JWT, or an empty string otherwise. The signature of the token
+// is not verified, so it must only be used for tokens the caller issued or validated.
+func JWTID(token string) string {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ""
+	}
+
+	var claims struct {
+		JTI string `json:"jti"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return ""
+	}
+	return claims.JTI
+}
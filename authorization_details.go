@@ -0,0 +1,128 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"github.com/ory/x/errorsx"
+)
+
+// AuthorizationDetail is an element of the "authorization_details" parameter as defined in
+// https://datatracker.ietf.org/doc/html/rfc9396#section-2. Every element has a "type" which determines the other
+// fields of the element.
+type AuthorizationDetail map[string]interface{}
+
+// GetType returns the "type" field of the authorization detail, or an empty string if it is not set.
+func (d AuthorizationDetail) GetType() string {
+	t, _ := d["type"].(string)
+	return t
+}
+
+// AuthorizationDetails is the "authorization_details" parameter as defined in
+// https://datatracker.ietf.org/doc/html/rfc9396#section-2.
+type AuthorizationDetails []AuthorizationDetail
+
+// append adds the authorization detail unless an equal one is already present.
+func (d AuthorizationDetails) append(detail AuthorizationDetail) AuthorizationDetails {
+	for _, has := range d {
+		if reflect.DeepEqual(has, detail) {
+			return d
+		}
+	}
+	return append(d, detail)
+}
+
+func (d AuthorizationDetails) clone() AuthorizationDetails {
+	if d == nil {
+		return nil
+	}
+
+	details := make(AuthorizationDetails, len(d))
+	for k, detail := range d {
+		details[k] = make(AuthorizationDetail, len(detail))
+		for name, value := range detail {
+			details[k][name] = value
+		}
+	}
+	return details
+}
+
+// AuthorizationDetailValidator validates an authorization detail of the type it was registered for, for example
+// whether all required fields are set and whether the client may request it.
+type AuthorizationDetailValidator func(ctx context.Context, client Client, detail AuthorizationDetail) error
+
+// AuthorizationDetailsRequester is implemented by requests which carry rich authorization requests as defined in
+// https://datatracker.ietf.org/doc/html/rfc9396.
+type AuthorizationDetailsRequester interface {
+	// GetRequestedAuthorizationDetails returns the authorization details sent by the client.
+	GetRequestedAuthorizationDetails() AuthorizationDetails
+
+	// SetRequestedAuthorizationDetails sets the authorization details sent by the client.
+	SetRequestedAuthorizationDetails(details AuthorizationDetails)
+
+	// GetGrantedAuthorizationDetails returns the authorization details which were granted.
+	GetGrantedAuthorizationDetails() AuthorizationDetails
+
+	// GrantAuthorizationDetail marks an authorization detail as granted.
+	GrantAuthorizationDetail(detail AuthorizationDetail)
+}
+
+// GrantAuthorizationDetailsOf grants the authorization details which were granted in the original request, for
+// example an authorize request, to the request exchanging it for tokens.
+func GrantAuthorizationDetailsOf(request, original Requester) {
+	to, ok := request.(AuthorizationDetailsRequester)
+	if !ok {
+		return
+	}
+
+	from, ok := original.(AuthorizationDetailsRequester)
+	if !ok {
+		return
+	}
+
+	for _, detail := range from.GetGrantedAuthorizationDetails() {
+		to.GrantAuthorizationDetail(detail)
+	}
+}
+
+// parseAuthorizationDetails parses and validates the "authorization_details" parameter. Every element must be of a
+// type registered in the configuration and is passed to the validator of its type.
+func (f *Fosite) parseAuthorizationDetails(ctx context.Context, client Client, raw string) (AuthorizationDetails, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	var details AuthorizationDetails
+	if err := json.Unmarshal([]byte(raw), &details); err != nil {
+		return nil, errorsx.WithStack(ErrInvalidAuthorizationDetails.WithHint("The 'authorization_details' parameter must be a JSON array of objects.").WithWrap(err).WithDebug(err.Error()))
+	}
+
+	validators := f.Config.GetAuthorizationDetailValidators(ctx)
+	for _, detail := range details {
+		if detail == nil {
+			return nil, errorsx.WithStack(ErrInvalidAuthorizationDetails.WithHint("The 'authorization_details' parameter must be a JSON array of objects."))
+		}
+
+		t := detail.GetType()
+		if t == "" {
+			return nil, errorsx.WithStack(ErrInvalidAuthorizationDetails.WithHint("Every element of the 'authorization_details' parameter must have a 'type'."))
+		}
+
+		validator, ok := validators[t]
+		if !ok {
+			return nil, errorsx.WithStack(ErrInvalidAuthorizationDetails.WithHintf("The authorization details type '%s' is not supported.", t))
+		} else if validator == nil {
+			continue
+		}
+
+		if err := validator(ctx, client, detail); err != nil {
+			return nil, errorsx.WithStack(ErrInvalidAuthorizationDetails.WithHintf("The authorization details of type '%s' are invalid.", t).WithWrap(err).WithDebug(err.Error()))
+		}
+	}
+
+	return details, nil
+}
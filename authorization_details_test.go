@@ -0,0 +1,169 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ory/fosite"
+	"github.com/ory/fosite/internal"
+)
+
+func TestNewAccessRequestWithAuthorizationDetails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := internal.NewMockStorage(ctrl)
+	handler := internal.NewMockTokenEndpointHandler(ctrl)
+	handler.EXPECT().CanHandleTokenEndpointRequest(gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+	handler.EXPECT().CanSkipClientAuth(gomock.Any(), gomock.Any()).Return(false).AnyTimes()
+	defer ctrl.Finish()
+
+	client := &DefaultClient{ID: "foo", Public: true}
+	config := &Config{
+		TokenEndpointHandlers: TokenEndpointHandlers{handler},
+		AuthorizationDetailValidators: map[string]AuthorizationDetailValidator{
+			"payment_initiation": func(ctx context.Context, client Client, detail AuthorizationDetail) error {
+				if _, ok := detail["instructedAmount"]; !ok {
+					return errors.New("the instructed amount is missing")
+				}
+				return nil
+			},
+			"account_information": nil,
+		},
+	}
+	fosite := &Fosite{Store: store, Config: config}
+
+	for k, c := range []struct {
+		d         string
+		details   string
+		expectErr error
+		expect    AuthorizationDetails
+	}{
+		{
+			d:      "no authorization details",
+			expect: nil,
+		},
+		{
+			d:       "single authorization detail",
+			details: `[{"type":"payment_initiation","instructedAmount":{"currency":"EUR","amount":"123.50"}}]`,
+			expect: AuthorizationDetails{
+				{"type": "payment_initiation", "instructedAmount": map[string]interface{}{"currency": "EUR", "amount": "123.50"}},
+			},
+		},
+		{
+			d:       "multiple authorization details",
+			details: `[{"type":"account_information","actions":["list_accounts"]},{"type":"payment_initiation","instructedAmount":{"currency":"EUR","amount":"1"}}]`,
+			expect: AuthorizationDetails{
+				{"type": "account_information", "actions": []interface{}{"list_accounts"}},
+				{"type": "payment_initiation", "instructedAmount": map[string]interface{}{"currency": "EUR", "amount": "1"}},
+			},
+		},
+		{
+			d:         "unregistered type",
+			details:   `[{"type":"account_information"},{"type":"photo_upload"}]`,
+			expectErr: ErrInvalidAuthorizationDetails,
+		},
+		{
+			d:         "rejected by the validator of the type",
+			details:   `[{"type":"payment_initiation"}]`,
+			expectErr: ErrInvalidAuthorizationDetails,
+		},
+		{
+			d:         "missing type",
+			details:   `[{"actions":["list_accounts"]}]`,
+			expectErr: ErrInvalidAuthorizationDetails,
+		},
+		{
+			d:         "not an array",
+			details:   `{"type":"account_information"}`,
+			expectErr: ErrInvalidAuthorizationDetails,
+		},
+		{
+			d:         "array of non-objects",
+			details:   `[null]`,
+			expectErr: ErrInvalidAuthorizationDetails,
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.d), func(t *testing.T) {
+			form := url.Values{"grant_type": {"foo"}, "client_id": {"foo"}}
+			if c.details != "" {
+				form.Set("authorization_details", c.details)
+			}
+			r := &http.Request{Header: http.Header{}, PostForm: form, Form: form, Method: "POST"}
+
+			store.EXPECT().GetClient(gomock.Any(), gomock.Eq("foo")).Return(client, nil)
+			if c.expectErr == nil {
+				handler.EXPECT().HandleTokenEndpointRequest(gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			ar, err := fosite.NewAccessRequest(NewContext(), r, new(DefaultSession))
+			if c.expectErr != nil {
+				require.ErrorIs(t, err, c.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.expect, ar.(AuthorizationDetailsRequester).GetRequestedAuthorizationDetails())
+		})
+	}
+}
+
+func TestNewAccessResponseWithAuthorizationDetails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	handler := internal.NewMockTokenEndpointHandler(ctrl)
+	defer ctrl.Finish()
+
+	f := &Fosite{Config: &Config{TokenEndpointHandlers: TokenEndpointHandlers{handler}}}
+	handler.EXPECT().PopulateTokenEndpointResponse(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(_ context.Context, _ AccessRequester, resp AccessResponder) {
+		resp.SetAccessToken("foo")
+		resp.SetTokenType("bar")
+	}).Return(nil)
+
+	ar := NewAccessRequest(new(DefaultSession))
+	ar.GrantAuthorizationDetail(AuthorizationDetail{"type": "account_information", "actions": []interface{}{"list_accounts"}})
+
+	resp, err := f.NewAccessResponse(context.Background(), ar)
+	require.NoError(t, err)
+	assert.Equal(t, AuthorizationDetails{{"type": "account_information", "actions": []interface{}{"list_accounts"}}}, resp.ToMap()["authorization_details"])
+}
+
+func TestWriteIntrospectionResponseWithAuthorizationDetails(t *testing.T) {
+	f := &Fosite{Config: new(Config)}
+	ar := NewAccessRequest(&DefaultSession{Extra: map[string]interface{}{"authorization_details": "spoofed"}})
+	ar.Client = &DefaultClient{ID: "foo"}
+	ar.GrantAuthorizationDetail(AuthorizationDetail{"type": "account_information"})
+
+	rw := httptest.NewRecorder()
+	f.WriteIntrospectionResponse(context.Background(), rw, &IntrospectionResponse{Active: true, AccessRequester: ar})
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &body))
+	assert.Equal(t, []interface{}{map[string]interface{}{"type": "account_information"}}, body["authorization_details"])
+}
+
+func TestGrantAuthorizationDetailsOf(t *testing.T) {
+	original := NewAuthorizeRequest()
+	original.SetRequestedAuthorizationDetails(AuthorizationDetails{{"type": "a"}, {"type": "b"}})
+	original.GrantAuthorizationDetail(AuthorizationDetail{"type": "a"})
+
+	request := NewAccessRequest(new(DefaultSession))
+	GrantAuthorizationDetailsOf(request, original)
+	GrantAuthorizationDetailsOf(request, original)
+	assert.Equal(t, AuthorizationDetails{{"type": "a"}}, request.GetGrantedAuthorizationDetails())
+	assert.Empty(t, request.GetRequestedAuthorizationDetails())
+
+	merged := NewAccessRequest(new(DefaultSession))
+	merged.Merge(original)
+	assert.Equal(t, AuthorizationDetails{{"type": "a"}, {"type": "b"}}, merged.GetRequestedAuthorizationDetails())
+	assert.Equal(t, AuthorizationDetails{{"type": "a"}}, merged.GetGrantedAuthorizationDetails())
+}
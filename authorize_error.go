@@ -8,19 +8,28 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+
+	"github.com/ory/x/errorsx"
 )
 
 func (f *Fosite) WriteAuthorizeError(ctx context.Context, rw http.ResponseWriter, ar AuthorizeRequester, err error) {
 	rw.Header().Set("Cache-Control", "no-store")
 	rw.Header().Set("Pragma", "no-cache")
 
+	if retryAfter, ok := f.retryAfter(ctx, err); ok {
+		seconds := retryAfterSeconds(retryAfter)
+		rw.Header().Set("Retry-After", strconv.Itoa(seconds))
+		err = errorsx.WithStack(ErrTemporarilyUnavailable.WithHintf("Please retry the request in %d seconds.", seconds).WithWrap(err).WithDebug(err.Error()))
+	}
+
 	if f.ResponseModeHandler(ctx).ResponseModes().Has(ar.GetResponseMode()) {
 		f.ResponseModeHandler(ctx).WriteAuthorizeError(ctx, rw, ar, err)
 		return
 	}
 
 	rfcerr := ErrorToRFC6749Error(err).WithLegacyFormat(f.Config.GetUseLegacyErrorFormat(ctx)).WithExposeDebug(f.Config.GetSendDebugMessagesToClients(ctx)).WithLocalizer(f.Config.GetMessageCatalog(ctx), getLangFromRequester(ar))
-	if !ar.IsRedirectURIValid() {
+	if !IsRedirectURIValid(ctx, f.Config, ar) {
 		rw.Header().Set("Content-Type", "application/json;charset=UTF-8")
 
 		js, err := json.Marshal(rfcerr)
@@ -34,7 +43,7 @@ func (f *Fosite) WriteAuthorizeError(ctx context.Context, rw http.ResponseWriter
 			return
 		}
 
-		rw.WriteHeader(rfcerr.CodeField)
+		rw.WriteHeader(f.errorStatus(ctx, rfcerr))
 		_, _ = rw.Write(js)
 		return
 	}
@@ -7,8 +7,10 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -447,3 +449,86 @@ func copyUrl(u *url.URL) *url.URL {
 	u2, _ := url.Parse(u.String())
 	return u2
 }
+
+func TestWriteAuthorizeError_ErrorStatusMapper(t *testing.T) {
+	f := &Fosite{Config: &Config{ErrorStatusMapper: func(err *RFC6749Error) int {
+		if err.ErrorField == ErrInvalidGrant.ErrorField {
+			return http.StatusUnauthorized
+		}
+		return 0
+	}}}
+
+	ar := NewAuthorizeRequest()
+	rw := httptest.NewRecorder()
+	f.WriteAuthorizeError(context.Background(), rw, ar, ErrInvalidGrant)
+
+	assert.Equal(t, http.StatusUnauthorized, rw.Code)
+	assert.Contains(t, rw.Body.String(), `"error":"invalid_grant"`)
+}
+
+type storageError struct {
+	retriable  bool
+	retryAfter time.Duration
+}
+
+func (e *storageError) Error() string             { return "the database is unavailable" }
+func (e *storageError) IsRetriable() bool         { return e.retriable }
+func (e *storageError) RetryAfter() time.Duration { return e.retryAfter }
+
+func TestWriteAuthorizeError_RetriableError(t *testing.T) {
+	for k, c := range []struct {
+		d                string
+		err              error
+		expectStatus     int
+		expectError      string
+		expectRetryAfter string
+	}{
+		{
+			d:                "should respond with temporarily_unavailable to retriable errors",
+			err:              ErrServerError.WithWrap(&storageError{retriable: true}),
+			expectStatus:     http.StatusServiceUnavailable,
+			expectError:      "temporarily_unavailable",
+			expectRetryAfter: "5",
+		},
+		{
+			d:                "should use the delay of the retriable error",
+			err:              ErrServerError.WithWrap(&storageError{retriable: true, retryAfter: 1500 * time.Millisecond}),
+			expectStatus:     http.StatusServiceUnavailable,
+			expectError:      "temporarily_unavailable",
+			expectRetryAfter: "2",
+		},
+		{
+			d:            "should respond with server_error to non-retriable errors",
+			err:          ErrServerError.WithWrap(&storageError{}),
+			expectStatus: http.StatusInternalServerError,
+			expectError:  "server_error",
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.d), func(t *testing.T) {
+			f := &Fosite{Config: new(Config)}
+			rw := httptest.NewRecorder()
+			f.WriteAuthorizeError(context.Background(), rw, NewAuthorizeRequest(), c.err)
+
+			assert.Equal(t, c.expectStatus, rw.Code)
+			assert.Contains(t, rw.Body.String(), fmt.Sprintf(`"error":"%s"`, c.expectError))
+			assert.Equal(t, c.expectRetryAfter, rw.Header().Get("Retry-After"))
+		})
+	}
+
+	t.Run("case=retriable error of the storage while looking up the client", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		store := NewMockStorage(ctrl)
+		store.EXPECT().GetClient(gomock.Any(), "foo").Return(nil, &storageError{retriable: true, retryAfter: time.Minute})
+
+		f := &Fosite{Store: store, Config: new(Config)}
+		ar, err := f.NewAuthorizeRequest(context.Background(), &http.Request{Form: url.Values{"client_id": {"foo"}}})
+		assert.ErrorIs(t, err, ErrInvalidClient)
+
+		rw := httptest.NewRecorder()
+		f.WriteAuthorizeError(context.Background(), rw, ar, err)
+		assert.Equal(t, http.StatusServiceUnavailable, rw.Code)
+		assert.Contains(t, rw.Body.String(), `"error":"temporarily_unavailable"`)
+		assert.Equal(t, "60", rw.Header().Get("Retry-After"))
+	})
+}
@@ -64,12 +64,18 @@ var DefaultFormPostTemplate = template.Must(template.New("form_post").Parse(`<ht
 //     with the redirect URI passed to the token's endpoint, such an
 //     attack is detected (see Section 5.2.4.5).
 func MatchRedirectURIWithClientRedirectURIs(rawurl string, client Client) (*url.URL, error) {
+	return MatchRedirectURIWithStrategy(rawurl, client, DefaultRedirectURIMatchingStrategy)
+}
+
+// MatchRedirectURIWithStrategy works like MatchRedirectURIWithClientRedirectURIs, but compares the given uri to the
+// registered redirect uris using the strategy.
+func MatchRedirectURIWithStrategy(rawurl string, client Client, strategy RedirectURIMatchingStrategy) (*url.URL, error) {
 	if rawurl == "" && len(client.GetRedirectURIs()) == 1 {
 		if redirectURIFromClient, err := url.Parse(client.GetRedirectURIs()[0]); err == nil && IsValidRedirectURI(redirectURIFromClient) {
 			// If no redirect_uri was given and the client has exactly one valid redirect_uri registered, use that instead
 			return redirectURIFromClient, nil
 		}
-	} else if redirectTo, ok := isMatchingRedirectURI(rawurl, client.GetRedirectURIs()); rawurl != "" && ok {
+	} else if redirectTo, ok := isMatchingRedirectURI(rawurl, client.GetRedirectURIs(), strategy); rawurl != "" && ok {
 		// If a redirect_uri was given and the clients knows it (simple string comparison!)
 		// return it.
 		if parsed, err := url.Parse(redirectTo); err == nil && IsValidRedirectURI(parsed) {
@@ -81,12 +87,43 @@ func MatchRedirectURIWithClientRedirectURIs(rawurl string, client Client) (*url.
 	return nil, errorsx.WithStack(ErrInvalidRequest.WithHint("The 'redirect_uri' parameter does not match any of the OAuth 2.0 Client's pre-registered redirect urls."))
 }
 
-// Match a requested  redirect URI against a pool of registered client URIs
-//
-// Test a given redirect URI against a pool of URIs provided by a registered client.
-// If the OAuth 2.0 Client has loopback URIs registered either an IPv4 URI http://127.0.0.1 or
-// an IPv6 URI http://[::1] a client is allowed to request a dynamic port and the server MUST accept
-// it as a valid redirection uri.
+// ValidateRedirectURIs returns ErrInvalidRequest if one of the redirect URIs contains a fragment component, even an
+// empty one, which is not allowed by https://tools.ietf.org/html/rfc6749#section-3.1.2. It is meant to validate the
+// redirect URIs of clients when they are registered.
+func ValidateRedirectURIs(redirectURIs []string) error {
+	for _, redirectURI := range redirectURIs {
+		if strings.Contains(redirectURI, "#") {
+			// "The endpoint URI MUST NOT include a fragment component."
+			return errorsx.WithStack(ErrInvalidRequest.WithHintf("The redirect URI '%s' must not include a fragment component.", redirectURI))
+		}
+	}
+	return nil
+}
+
+// IsRedirectURIValid returns true if the redirect URI of the authorize request is valid and matches one of the
+// client's redirect URIs using the configured RedirectURIMatchingStrategy. The strategy is looked up each time, as it
+// is not part of the authorize request once that has been stored and restored. Authorize requesters which cannot
+// match using a strategy are validated using their IsRedirectURIValid method.
+func IsRedirectURIValid(ctx context.Context, config RedirectURIMatchingStrategyProvider, ar AuthorizeRequester) bool {
+	if r, ok := ar.(interface {
+		IsRedirectURIValidWithStrategy(strategy RedirectURIMatchingStrategy) bool
+	}); ok {
+		return r.IsRedirectURIValidWithStrategy(config.GetRedirectURIMatchingStrategy(ctx))
+	}
+	return ar.IsRedirectURIValid()
+}
+
+// RedirectURIMatchingStrategy returns true if the requested redirect URI matches the registered redirect URI.
+type RedirectURIMatchingStrategy func(requested, registered string) bool
+
+// ExactRedirectURIMatchingStrategy matches redirect URIs using simple string comparison, see
+// https://tools.ietf.org/html/rfc6749#section-3.1.2.3
+func ExactRedirectURIMatchingStrategy(requested, registered string) bool {
+	return requested == registered
+}
+
+// DefaultRedirectURIMatchingStrategy matches redirect URIs using simple string comparison, except for redirect URIs
+// using a loopback IP literal, for example http://127.0.0.1 or http://[::1], whose port is ignored.
 //
 // https://tools.ietf.org/html/rfc8252#section-7.3
 // Native apps that are able to open a port on the loopback network
@@ -96,18 +133,136 @@ func MatchRedirectURIWithClientRedirectURIs(rawurl string, client Client) (*url.
 //
 // Loopback redirect URIs use the "http" scheme and are constructed with
 // the loopback IP literal and whatever port the client is listening on.
-func isMatchingRedirectURI(uri string, haystack []string) (string, bool) {
-	requested, err := url.Parse(uri)
+func DefaultRedirectURIMatchingStrategy(requested, registered string) bool {
+	if requested == registered {
+		return true
+	}
+
+	parsed, err := url.Parse(requested)
+	if err != nil {
+		return false
+	}
+	return isMatchingAsLoopback(parsed, registered)
+}
+
+// LoopbackRedirectURIMatchingStrategy works like DefaultRedirectURIMatchingStrategy, but also ignores the port of
+// redirect URIs using http://localhost. Scheme, host, path and query must still match exactly.
+func LoopbackRedirectURIMatchingStrategy(requested, registered string) bool {
+	if requested == registered {
+		return true
+	}
+
+	parsedRequested, err := url.Parse(requested)
+	if err != nil {
+		return false
+	}
+
+	parsedRegistered, err := url.Parse(registered)
 	if err != nil {
-		return "", false
+		return false
 	}
 
+	hostname := parsedRequested.Hostname()
+	return parsedRequested.Scheme == "http" && parsedRegistered.Scheme == "http" &&
+		(isLoopbackAddress(hostname) || hostname == "localhost") &&
+		parsedRegistered.Hostname() == hostname &&
+		parsedRegistered.Path == parsedRequested.Path &&
+		parsedRegistered.RawQuery == parsedRequested.RawQuery
+}
+
+// QuerySupersetRedirectURIMatchingStrategy matches redirect URIs whose scheme, host, port and path equal the registered
+// redirect URI and whose query contains all query parameters of the registered redirect URI. Additional query
+// parameters are allowed and retained. Redirect URIs matching using DefaultRedirectURIMatchingStrategy match as well.
+// This weakens the protection of redirect URI matching and is meant for legacy clients only.
+func QuerySupersetRedirectURIMatchingStrategy(requested, registered string) bool {
+	if DefaultRedirectURIMatchingStrategy(requested, registered) {
+		return true
+	}
+
+	parsedRequested, parsedRegistered, ok := parseRedirectURIs(requested, registered)
+	if !ok || parsedRequested.Scheme != parsedRegistered.Scheme ||
+		parsedRequested.Host != parsedRegistered.Host ||
+		parsedRequested.Path != parsedRegistered.Path {
+		return false
+	}
+
+	query := parsedRequested.Query()
+	for key, values := range parsedRegistered.Query() {
+		for _, value := range values {
+			if !Arguments(query[key]).Has(value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// WildcardSubdomainRedirectURIMatchingStrategy matches redirect URIs against registered redirect URIs whose host
+// starts with a single wildcard label, for example https://*.example.com/cb. The wildcard matches exactly one label,
+// so https://a.example.com/cb matches, while https://example.com/cb and https://a.b.example.com/cb do not. Scheme,
+// port, path and query must be equal. Redirect URIs matching using DefaultRedirectURIMatchingStrategy match as well.
+// This weakens the protection of redirect URI matching and is meant for legacy clients only.
+func WildcardSubdomainRedirectURIMatchingStrategy(requested, registered string) bool {
+	if DefaultRedirectURIMatchingStrategy(requested, registered) {
+		return true
+	}
+
+	parsedRequested, parsedRegistered, ok := parseRedirectURIs(requested, registered)
+	if !ok || parsedRequested.Scheme != parsedRegistered.Scheme ||
+		parsedRequested.Port() != parsedRegistered.Port() ||
+		parsedRequested.Path != parsedRegistered.Path ||
+		parsedRequested.RawQuery != parsedRegistered.RawQuery {
+		return false
+	}
+
+	suffix := strings.TrimPrefix(parsedRegistered.Hostname(), "*")
+	if suffix == parsedRegistered.Hostname() || !strings.HasPrefix(suffix, ".") || strings.Contains(suffix, "*") {
+		return false
+	}
+
+	label := strings.TrimSuffix(parsedRequested.Hostname(), suffix)
+	return label != parsedRequested.Hostname() && label != "" && !strings.Contains(label, ".")
+}
+
+// ChainRedirectURIMatchingStrategies returns a strategy which matches redirect URIs matching using any of the given
+// strategies, for example to allow both wildcard subdomains and additional query parameters:
+//
+//	fosite.ChainRedirectURIMatchingStrategies(
+//		fosite.WildcardSubdomainRedirectURIMatchingStrategy,
+//		fosite.QuerySupersetRedirectURIMatchingStrategy,
+//	)
+func ChainRedirectURIMatchingStrategies(strategies ...RedirectURIMatchingStrategy) RedirectURIMatchingStrategy {
+	return func(requested, registered string) bool {
+		for _, strategy := range strategies {
+			if strategy(requested, registered) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func parseRedirectURIs(requested, registered string) (*url.URL, *url.URL, bool) {
+	parsedRequested, err := url.Parse(requested)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	parsedRegistered, err := url.Parse(registered)
+	if err != nil {
+		return nil, nil, false
+	}
+	return parsedRequested, parsedRegistered, true
+}
+
+// Match a requested  redirect URI against a pool of registered client URIs
+//
+// Test a given redirect URI against a pool of URIs provided by a registered client using the strategy.
+func isMatchingRedirectURI(uri string, haystack []string, strategy RedirectURIMatchingStrategy) (string, bool) {
 	for _, b := range haystack {
-		if b == uri {
-			return b, true
-		} else if isMatchingAsLoopback(requested, b) {
-			// We have to return the requested URL here because otherwise the port might get lost (see isMatchingAsLoopback)
-			// description.
+		if strategy(uri, b) {
+			// We have to return the requested URL here because otherwise the port might get lost if the strategy
+			// ignores it.
 			return uri, true
 		}
 	}
@@ -6,13 +6,17 @@ package fosite_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
+	"net/http"
 	"net/url"
 	"strings"
 	"testing"
 
 	"github.com/ory/fosite"
 	"github.com/ory/fosite/internal"
+	"github.com/ory/fosite/storage"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -213,6 +217,15 @@ func TestDoesClientWhiteListRedirect(t *testing.T) {
 			isError:  false,
 			expected: "https://google.com/?foo=bar%20foo+baz",
 		},
+		{
+			client:  &fosite.DefaultClient{RedirectURIs: []string{"https://foo.com/cb#bar"}},
+			url:     "https://foo.com/cb#bar",
+			isError: true,
+		},
+		{
+			client:  &fosite.DefaultClient{RedirectURIs: []string{"https://foo.com/cb#bar"}},
+			isError: true,
+		},
 	} {
 		redir, err := fosite.MatchRedirectURIWithClientRedirectURIs(c.url, c.client)
 		assert.Equal(t, c.isError, err != nil, "%d: %+v", k, c)
@@ -224,6 +237,107 @@ func TestDoesClientWhiteListRedirect(t *testing.T) {
 	}
 }
 
+func TestValidateRedirectURIs(t *testing.T) {
+	assert.NoError(t, fosite.ValidateRedirectURIs(nil))
+	assert.NoError(t, fosite.ValidateRedirectURIs([]string{"https://foo.com/cb", "https://foo.com/cb?foo=bar"}))
+
+	for _, u := range []string{"https://foo.com/cb#bar", "https://foo.com/cb#"} {
+		err := fosite.ValidateRedirectURIs([]string{"https://foo.com/cb", u})
+		require.ErrorIs(t, err, fosite.ErrInvalidRequest, u)
+		assert.Contains(t, fosite.ErrorToRFC6749Error(err).HintField, "fragment", u)
+	}
+}
+
+func TestMatchRedirectURIWithStrategy(t *testing.T) {
+	for k, c := range []struct {
+		d          string
+		strategy   fosite.RedirectURIMatchingStrategy
+		registered string
+		requested  string
+		expectErr  bool
+	}{
+		{d: "exact matches identical URIs", strategy: fosite.ExactRedirectURIMatchingStrategy, registered: "http://127.0.0.1:8080/cb", requested: "http://127.0.0.1:8080/cb"},
+		{d: "exact rejects a different loopback port", strategy: fosite.ExactRedirectURIMatchingStrategy, registered: "http://127.0.0.1:8080/cb", requested: "http://127.0.0.1:53123/cb", expectErr: true},
+		{d: "default ignores the port of IPv4 loopback URIs", strategy: fosite.DefaultRedirectURIMatchingStrategy, registered: "http://127.0.0.1/cb", requested: "http://127.0.0.1:53123/cb"},
+		{d: "default does not ignore the port of localhost", strategy: fosite.DefaultRedirectURIMatchingStrategy, registered: "http://localhost/cb", requested: "http://localhost:53123/cb", expectErr: true},
+		{d: "loopback ignores the port of IPv4 loopback URIs", strategy: fosite.LoopbackRedirectURIMatchingStrategy, registered: "http://127.0.0.1:8080/cb", requested: "http://127.0.0.1:53123/cb"},
+		{d: "loopback ignores the port of IPv6 loopback URIs", strategy: fosite.LoopbackRedirectURIMatchingStrategy, registered: "http://[::1]/cb", requested: "http://[::1]:53123/cb"},
+		{d: "loopback ignores the port of localhost", strategy: fosite.LoopbackRedirectURIMatchingStrategy, registered: "http://localhost/cb", requested: "http://localhost:53123/cb"},
+		{d: "loopback requires the same host", strategy: fosite.LoopbackRedirectURIMatchingStrategy, registered: "http://localhost/cb", requested: "http://127.0.0.1:53123/cb", expectErr: true},
+		{d: "loopback requires the same path", strategy: fosite.LoopbackRedirectURIMatchingStrategy, registered: "http://localhost/cb", requested: "http://localhost:53123/other", expectErr: true},
+		{d: "loopback requires the http scheme", strategy: fosite.LoopbackRedirectURIMatchingStrategy, registered: "https://localhost/cb", requested: "https://localhost:53123/cb", expectErr: true},
+		{d: "loopback rejects a port mismatch of other hosts", strategy: fosite.LoopbackRedirectURIMatchingStrategy, registered: "http://example.com:8080/cb", requested: "http://example.com:53123/cb", expectErr: true},
+		{d: "default rejects additional query parameters", strategy: fosite.DefaultRedirectURIMatchingStrategy, registered: "https://example.com/cb?tenant=a", requested: "https://example.com/cb?tenant=a&session=b", expectErr: true},
+		{d: "query superset allows additional query parameters", strategy: fosite.QuerySupersetRedirectURIMatchingStrategy, registered: "https://example.com/cb?tenant=a", requested: "https://example.com/cb?session=b&tenant=a"},
+		{d: "query superset requires the registered query parameters", strategy: fosite.QuerySupersetRedirectURIMatchingStrategy, registered: "https://example.com/cb?tenant=a", requested: "https://example.com/cb?tenant=b&session=b", expectErr: true},
+		{d: "query superset requires the same path", strategy: fosite.QuerySupersetRedirectURIMatchingStrategy, registered: "https://example.com/cb?tenant=a", requested: "https://example.com/other?tenant=a", expectErr: true},
+		{d: "query superset requires the same host", strategy: fosite.QuerySupersetRedirectURIMatchingStrategy, registered: "https://example.com/cb", requested: "https://example.com.evil.com/cb?tenant=a", expectErr: true},
+		{d: "query superset requires the same scheme", strategy: fosite.QuerySupersetRedirectURIMatchingStrategy, registered: "https://example.com/cb", requested: "http://example.com/cb?tenant=a", expectErr: true},
+		{d: "default does not expand wildcards", strategy: fosite.DefaultRedirectURIMatchingStrategy, registered: "https://*.example.com/cb", requested: "https://tenant.example.com/cb", expectErr: true},
+		{d: "wildcard matches a subdomain", strategy: fosite.WildcardSubdomainRedirectURIMatchingStrategy, registered: "https://*.example.com/cb", requested: "https://tenant.example.com/cb"},
+		{d: "wildcard matches a single label only", strategy: fosite.WildcardSubdomainRedirectURIMatchingStrategy, registered: "https://*.example.com/cb", requested: "https://a.tenant.example.com/cb", expectErr: true},
+		{d: "wildcard does not match the parent domain", strategy: fosite.WildcardSubdomainRedirectURIMatchingStrategy, registered: "https://*.example.com/cb", requested: "https://example.com/cb", expectErr: true},
+		{d: "wildcard does not match other domains", strategy: fosite.WildcardSubdomainRedirectURIMatchingStrategy, registered: "https://*.example.com/cb", requested: "https://tenant.evilexample.com/cb", expectErr: true},
+		{d: "wildcard requires the same path", strategy: fosite.WildcardSubdomainRedirectURIMatchingStrategy, registered: "https://*.example.com/cb", requested: "https://tenant.example.com/other", expectErr: true},
+		{d: "wildcard requires the same scheme", strategy: fosite.WildcardSubdomainRedirectURIMatchingStrategy, registered: "https://*.example.com/cb", requested: "http://tenant.example.com/cb", expectErr: true},
+		{d: "query superset ignores the port of IPv4 loopback URIs", strategy: fosite.QuerySupersetRedirectURIMatchingStrategy, registered: "http://127.0.0.1/cb", requested: "http://127.0.0.1:53123/cb"},
+		{d: "wildcard ignores the port of IPv4 loopback URIs", strategy: fosite.WildcardSubdomainRedirectURIMatchingStrategy, registered: "http://127.0.0.1/cb", requested: "http://127.0.0.1:53123/cb"},
+		{d: "chain matches using the first strategy", strategy: fosite.ChainRedirectURIMatchingStrategies(fosite.WildcardSubdomainRedirectURIMatchingStrategy, fosite.QuerySupersetRedirectURIMatchingStrategy), registered: "https://*.example.com/cb", requested: "https://tenant.example.com/cb"},
+		{d: "chain matches using the second strategy", strategy: fosite.ChainRedirectURIMatchingStrategies(fosite.WildcardSubdomainRedirectURIMatchingStrategy, fosite.QuerySupersetRedirectURIMatchingStrategy), registered: "https://example.com/cb?tenant=a", requested: "https://example.com/cb?tenant=a&session=b"},
+		{d: "chain rejects what no strategy matches", strategy: fosite.ChainRedirectURIMatchingStrategies(fosite.WildcardSubdomainRedirectURIMatchingStrategy, fosite.QuerySupersetRedirectURIMatchingStrategy), registered: "https://*.example.com/cb", requested: "https://tenant.example.com/cb?session=b", expectErr: true},
+		{d: "empty chain matches nothing", strategy: fosite.ChainRedirectURIMatchingStrategies(), registered: "https://example.com/cb", requested: "https://example.com/cb", expectErr: true},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.d), func(t *testing.T) {
+			client := &fosite.DefaultClient{RedirectURIs: []string{c.registered}}
+			redir, err := fosite.MatchRedirectURIWithStrategy(c.requested, client, c.strategy)
+			if c.expectErr {
+				require.ErrorIs(t, err, fosite.ErrInvalidRequest)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.requested, redir.String())
+		})
+	}
+}
+
+func TestNewAuthorizeRequestWithRedirectURIMatchingStrategy(t *testing.T) {
+	store := storage.NewMemoryStore()
+	store.Clients["native"] = &fosite.DefaultClient{
+		ID:            "native",
+		RedirectURIs:  []string{"http://localhost/cb"},
+		ResponseTypes: []string{"code"},
+		Public:        true,
+	}
+
+	newRequest := func() *http.Request {
+		return &http.Request{Form: url.Values{
+			"client_id":     {"native"},
+			"redirect_uri":  {"http://localhost:53123/cb"},
+			"response_type": {"code"},
+			"state":         {"strong-state"},
+		}}
+	}
+
+	f := &fosite.Fosite{Store: store, Config: &fosite.Config{}}
+	_, err := f.NewAuthorizeRequest(context.Background(), newRequest())
+	require.ErrorIs(t, err, fosite.ErrInvalidRequest)
+
+	f = &fosite.Fosite{Store: store, Config: &fosite.Config{RedirectURIMatchingStrategy: fosite.LoopbackRedirectURIMatchingStrategy}}
+	ar, err := f.NewAuthorizeRequest(context.Background(), newRequest())
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:53123/cb", ar.GetRedirectURI().String())
+	assert.True(t, fosite.IsRedirectURIValid(context.Background(), f.Config, ar))
+
+	// The strategy is taken from the configuration, so it applies to stored and restored requests as well.
+	raw, err := json.Marshal(ar)
+	require.NoError(t, err)
+	restored := fosite.NewAuthorizeRequest()
+	require.NoError(t, json.Unmarshal(raw, restored))
+	restored.Client = store.Clients["native"]
+	assert.True(t, fosite.IsRedirectURIValid(context.Background(), f.Config, restored))
+	assert.False(t, restored.IsRedirectURIValid())
+}
+
 func TestIsRedirectURISecure(t *testing.T) {
 	for d, c := range []struct {
 		u   string
@@ -14,6 +14,12 @@ const (
 	ResponseModeFormPost = ResponseModeType("form_post")
 	ResponseModeQuery    = ResponseModeType("query")
 	ResponseModeFragment = ResponseModeType("fragment")
+
+	// JWT secured authorization response modes, see https://openid.net/specs/oauth-v2-jarm.html#section-2.3
+	ResponseModeJWT         = ResponseModeType("jwt")
+	ResponseModeQueryJWT    = ResponseModeType("query.jwt")
+	ResponseModeFragmentJWT = ResponseModeType("fragment.jwt")
+	ResponseModeFormPostJWT = ResponseModeType("form_post.jwt")
 )
 
 // AuthorizeRequest is an implementation of AuthorizeRequester
@@ -39,7 +45,15 @@ func NewAuthorizeRequest() *AuthorizeRequest {
 	}
 }
 
+// IsRedirectURIValid returns true if the redirect URI is valid and matches one of the client's redirect URIs using
+// DefaultRedirectURIMatchingStrategy. Use fosite.IsRedirectURIValid to match using the configured strategy instead.
 func (d *AuthorizeRequest) IsRedirectURIValid() bool {
+	return d.IsRedirectURIValidWithStrategy(DefaultRedirectURIMatchingStrategy)
+}
+
+// IsRedirectURIValidWithStrategy returns true if the redirect URI is valid and matches one of the client's redirect
+// URIs using the given strategy.
+func (d *AuthorizeRequest) IsRedirectURIValidWithStrategy(strategy RedirectURIMatchingStrategy) bool {
 	if d.GetRedirectURI() == nil {
 		return false
 	}
@@ -49,7 +63,7 @@ func (d *AuthorizeRequest) IsRedirectURIValid() bool {
 		return false
 	}
 
-	redirectURI, err := MatchRedirectURIWithClientRedirectURIs(raw, d.GetClient())
+	redirectURI, err := MatchRedirectURIWithStrategy(raw, d.GetClient(), strategy)
 	if err != nil {
 		return false
 	}
@@ -96,3 +110,17 @@ func (d *AuthorizeRequest) SetDefaultResponseMode(defaultResponseMode ResponseMo
 func (d *AuthorizeRequest) GetDefaultResponseMode() ResponseModeType {
 	return d.DefaultResponseMode
 }
+
+// Clone returns a copy of the authorize request which can be modified without affecting the original, see
+// Request.Clone. The response types and the redirect URI are deep copied.
+func (d *AuthorizeRequest) Clone() *AuthorizeRequest {
+	c := *d
+	c.ResponseTypes = cloneArguments(d.ResponseTypes)
+	c.HandledResponseTypes = cloneArguments(d.HandledResponseTypes)
+	if d.RedirectURI != nil {
+		u := *d.RedirectURI
+		c.RedirectURI = &u
+	}
+	c.Request = *d.Request.Clone()
+	return &c
+}
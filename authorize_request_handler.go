@@ -5,10 +5,13 @@ package fosite
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/go-jose/go-jose/v3"
 	"go.opentelemetry.io/otel/trace"
@@ -76,9 +79,19 @@ func (f *Fosite) authorizeRequestParametersFromOpenIDConnectRequest(ctx context.
 			return errorsx.WithStack(ErrInvalidRequestURI.WithHintf("Unable to fetch OpenID Connect request parameters from 'request_uri' because status code '%d' was expected, but got '%d'.", http.StatusOK, response.StatusCode))
 		}
 
-		body, err := io.ReadAll(response.Body)
+		if contentTypes := f.Config.GetRequestObjectContentTypes(ctx); len(contentTypes) > 0 {
+			contentType, _, err := mime.ParseMediaType(response.Header.Get("Content-Type"))
+			if err != nil || !stringslice.Has(contentTypes, contentType) {
+				return errorsx.WithStack(ErrInvalidRequestURI.WithHintf("Unable to fetch OpenID Connect request parameters from 'request_uri' because content type '%s' is not allowed.", response.Header.Get("Content-Type")))
+			}
+		}
+
+		maxSize := f.Config.GetRequestObjectMaxSize(ctx)
+		body, err := io.ReadAll(io.LimitReader(response.Body, maxSize+1))
 		if err != nil {
 			return errorsx.WithStack(ErrInvalidRequestURI.WithHintf("Unable to fetch OpenID Connect request parameters from 'request_uri' because body parsing failed with: %s.", err).WithWrap(err).WithDebug(err.Error()))
+		} else if int64(len(body)) > maxSize {
+			return errorsx.WithStack(ErrInvalidRequestURI.WithHintf("Unable to fetch OpenID Connect request parameters from 'request_uri' because the request object exceeds the maximum size of %d bytes.", maxSize))
 		}
 
 		assertion = string(body)
@@ -145,7 +158,30 @@ func (f *Fosite) authorizeRequestParametersFromOpenIDConnectRequest(ctx context.
 		return errorsx.WithStack(ErrInvalidRequestObject.WithHint("Pushed Authorization Requests can not contain the 'request_uri' parameter."))
 	}
 
+	// The request object must not contain the 'request' or 'request_uri' parameters, see
+	// https://datatracker.ietf.org/doc/html/rfc9101#section-4
+	for _, parameter := range []string{"request", "request_uri"} {
+		if _, ok := claims[parameter]; ok {
+			return errorsx.WithStack(ErrInvalidRequestObject.WithHintf("The request object must not contain the '%s' parameter.", parameter))
+		}
+	}
+
+	// The 'client_id' of the request object must match the one of the authorization request, see
+	// https://datatracker.ietf.org/doc/html/rfc9101#section-5
+	if clientID, ok := claims["client_id"]; ok && fmt.Sprintf("%s", clientID) != request.Client.GetID() {
+		return errorsx.WithStack(ErrInvalidRequestObject.WithHint("The 'client_id' of the request object does not match the 'client_id' of the authorization request."))
+	}
+
 	for k, v := range claims {
+		// The "claims" parameter is a JSON object within the request object, but a JSON string as form parameter.
+		if object, ok := v.(map[string]interface{}); ok && k == "claims" {
+			raw, err := json.Marshal(object)
+			if err != nil {
+				return errorsx.WithStack(ErrInvalidRequestObject.WithHint("Unable to encode the 'claims' parameter of the request object.").WithWrap(err).WithDebug(err.Error()))
+			}
+			request.Form.Set(k, string(raw))
+			continue
+		}
 		request.Form.Set(k, fmt.Sprintf("%s", v))
 	}
 
@@ -161,7 +197,7 @@ func (f *Fosite) authorizeRequestParametersFromOpenIDConnectRequest(ctx context.
 	return nil
 }
 
-func (f *Fosite) validateAuthorizeRedirectURI(_ *http.Request, request *AuthorizeRequest) error {
+func (f *Fosite) validateAuthorizeRedirectURI(ctx context.Context, _ *http.Request, request *AuthorizeRequest) error {
 	// Fetch redirect URI from request
 	rawRedirURI := request.Form.Get("redirect_uri")
 
@@ -176,8 +212,14 @@ func (f *Fosite) validateAuthorizeRedirectURI(_ *http.Request, request *Authoriz
 		return errorsx.WithStack(ErrInvalidRequest.WithHint("The 'redirect_uri' parameter is required when using OpenID Connect 1.0."))
 	}
 
+	if f.Config.GetRejectRedirectURIFragments(ctx) {
+		if err := ValidateRedirectURIs(append([]string{rawRedirURI}, request.Client.GetRedirectURIs()...)); err != nil {
+			return err
+		}
+	}
+
 	// Validate redirect uri
-	redirectURI, err := MatchRedirectURIWithClientRedirectURIs(rawRedirURI, request.Client)
+	redirectURI, err := MatchRedirectURIWithStrategy(rawRedirURI, request.Client, f.Config.GetRedirectURIMatchingStrategy(ctx))
 	if err != nil {
 		return err
 	} else if !IsValidRedirectURI(redirectURI) {
@@ -194,16 +236,17 @@ func (f *Fosite) parseAuthorizeScope(_ *http.Request, request *AuthorizeRequest)
 }
 
 func (f *Fosite) validateAuthorizeScope(ctx context.Context, _ *http.Request, request *AuthorizeRequest) error {
+	request.SetRequestedScopes(removeUnknownScopes(ctx, f.Config, request.Client, request.GetRequestedScopes()))
 	for _, permission := range request.GetRequestedScopes() {
 		if !f.Config.GetScopeStrategy(ctx)(request.Client.GetScopes(), permission) {
 			return errorsx.WithStack(ErrInvalidScope.WithHintf("The OAuth 2.0 Client is not allowed to request scope '%s'.", permission))
 		}
 	}
 
-	return nil
+	return validateScopeCount(ctx, f.Config, request.GetRequestedScopes())
 }
 
-func (f *Fosite) validateResponseTypes(r *http.Request, request *AuthorizeRequest) error {
+func (f *Fosite) validateResponseTypes(ctx context.Context, r *http.Request, request *AuthorizeRequest) error {
 	// https://tools.ietf.org/html/rfc6749#section-3.1.1
 	// Extension response types MAY contain a space-delimited (%x20) list of
 	// values, where the order of values does not matter (e.g., response
@@ -214,6 +257,20 @@ func (f *Fosite) validateResponseTypes(r *http.Request, request *AuthorizeReques
 		return errorsx.WithStack(ErrUnsupportedResponseType.WithHint("`The request is missing the 'response_type' parameter."))
 	}
 
+	if allowed := f.Config.GetAllowedResponseTypes(ctx); len(allowed) > 0 {
+		var found bool
+		for _, t := range allowed {
+			if Arguments(responseTypes).Matches(RemoveEmpty(strings.Split(t, " "))...) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return errorsx.WithStack(ErrUnsupportedResponseType.WithHintf("The authorization server does not allow response_type '%s'.", r.Form.Get("response_type")))
+		}
+	}
+
 	var found bool
 	for _, t := range request.GetClient().GetResponseTypes() {
 		if Arguments(responseTypes).Matches(RemoveEmpty(strings.Split(t, " "))...) {
@@ -277,7 +334,7 @@ func (f *Fosite) validateResponseMode(r *http.Request, request *AuthorizeRequest
 	return nil
 }
 
-func (f *Fosite) authorizeRequestFromPAR(ctx context.Context, r *http.Request, request *AuthorizeRequest) (bool, error) {
+func (f *Fosite) authorizeRequestFromPAR(ctx context.Context, r *http.Request, request *AuthorizeRequest, dryRun bool) (bool, error) {
 	configProvider, ok := f.Config.(PushedAuthorizeRequestConfigProvider)
 	if !ok {
 		// If the config provider is not implemented, PAR cannot be used.
@@ -304,6 +361,16 @@ func (f *Fosite) authorizeRequestFromPAR(ctx context.Context, r *http.Request, r
 		return false, errorsx.WithStack(ErrInvalidRequestURI.WithHint("Invalid PAR session").WithWrap(err).WithDebug(err.Error()))
 	}
 
+	if exp := parRequest.GetSession().GetExpiresAt(PushedAuthorizeRequestContext); !exp.IsZero() && exp.Before(time.Now().UTC()) {
+		if dryRun {
+			return false, errorsx.WithStack(ErrInvalidRequestURI.WithHint("The 'request_uri' of the pushed authorization request has expired."))
+		}
+		if err := storage.DeletePARSession(ctx, requestURI); err != nil {
+			return false, errorsx.WithStack(ErrServerError.WithWrap(err).WithDebug(err.Error()))
+		}
+		return false, errorsx.WithStack(ErrInvalidRequestURI.WithHint("The 'request_uri' of the pushed authorization request has expired."))
+	}
+
 	// hydrate the request object
 	request.Merge(parRequest)
 	request.RedirectURI = parRequest.GetRedirectURI()
@@ -311,8 +378,10 @@ func (f *Fosite) authorizeRequestFromPAR(ctx context.Context, r *http.Request, r
 	request.State = parRequest.GetState()
 	request.ResponseMode = parRequest.GetResponseMode()
 
-	if err := storage.DeletePARSession(ctx, requestURI); err != nil {
-		return false, errorsx.WithStack(ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	if !dryRun {
+		if err := storage.DeletePARSession(ctx, requestURI); err != nil {
+			return false, errorsx.WithStack(ErrServerError.WithWrap(err).WithDebug(err.Error()))
+		}
 	}
 
 	// validate the clients match
@@ -327,10 +396,43 @@ func (f *Fosite) NewAuthorizeRequest(ctx context.Context, r *http.Request) (_ Au
 	ctx, span := trace.SpanFromContext(ctx).TracerProvider().Tracer("github.com/ory/fosite").Start(ctx, "Fosite.NewAuthorizeRequest")
 	defer otelx.End(span, &err)
 
-	return f.newAuthorizeRequest(ctx, r, false)
+	return f.newAuthorizeRequest(ctx, r, false, false)
 }
 
-func (f *Fosite) newAuthorizeRequest(ctx context.Context, r *http.Request, isPARRequest bool) (AuthorizeRequester, error) {
+// ValidateAuthorizeRequest validates the authorize request like NewAuthorizeRequest and NewAuthorizeResponse do, but
+// without side effects: pushed authorization requests are not consumed, request parameters are not recorded for
+// reuse detection, and no codes or tokens are issued. Of the authorize endpoint handlers, only those implementing
+// AuthorizeEndpointRequestValidator take part in the validation, and response types no validator marked as handled
+// are rejected as unsupported. Checks which need the authenticated end-user, such as whether the session satisfies
+// prompt, max_age or id_token_hint, can only happen once the authorize response is created.
+//
+// This allows frontends to reject invalid authorize requests before driving the user through login and consent.
+// The returned error is the one the full flow would return and can be written using WriteAuthorizeError.
+func (f *Fosite) ValidateAuthorizeRequest(ctx context.Context, r *http.Request) (err error) {
+	ctx, span := trace.SpanFromContext(ctx).TracerProvider().Tracer("github.com/ory/fosite").Start(ctx, "Fosite.ValidateAuthorizeRequest")
+	defer otelx.End(span, &err)
+
+	request, err := f.newAuthorizeRequest(ctx, r, false, true)
+	if err != nil {
+		return err
+	}
+
+	for _, h := range f.Config.GetAuthorizeEndpointHandlers(ctx) {
+		if v, ok := h.(AuthorizeEndpointRequestValidator); ok {
+			if err := v.ValidateAuthorizeEndpointRequest(ctx, request); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !request.DidHandleAllResponseTypes() {
+		return errorsx.WithStack(ErrUnsupportedResponseType)
+	}
+
+	return validateResponseMode(request)
+}
+
+func (f *Fosite) newAuthorizeRequest(ctx context.Context, r *http.Request, isPARRequest, dryRun bool) (AuthorizeRequester, error) {
 	request := NewAuthorizeRequest()
 	request.Request.Lang = i18n.GetLangFromRequest(f.Config.GetMessageCatalog(ctx), r)
 
@@ -347,7 +449,7 @@ func (f *Fosite) newAuthorizeRequest(ctx context.Context, r *http.Request, isPAR
 
 	// Check if this is a continuation from a pushed authorization request
 	if !isPARRequest {
-		if isPAR, err := f.authorizeRequestFromPAR(ctx, r, request); err != nil {
+		if isPAR, err := f.authorizeRequestFromPAR(ctx, r, request, dryRun); err != nil {
 			return request, err
 		} else if isPAR {
 			// No need to continue
@@ -382,7 +484,7 @@ func (f *Fosite) newAuthorizeRequest(ctx context.Context, r *http.Request, isPAR
 		return request, err
 	}
 
-	if err = f.validateAuthorizeRedirectURI(r, request); err != nil {
+	if err = f.validateAuthorizeRedirectURI(ctx, r, request); err != nil {
 		return request, err
 	}
 
@@ -394,11 +496,25 @@ func (f *Fosite) newAuthorizeRequest(ctx context.Context, r *http.Request, isPAR
 		return request, err
 	}
 
+	if _, err = GetClaimsRequest(request); err != nil {
+		return request, err
+	}
+
+	details, err := f.parseAuthorizationDetails(ctx, client, request.Form.Get("authorization_details"))
+	if err != nil {
+		return request, err
+	}
+	request.SetRequestedAuthorizationDetails(details)
+
+	if _, err = GetPrompts(request); err != nil {
+		return request, err
+	}
+
 	if len(request.Form.Get("registration")) > 0 {
 		return request, errorsx.WithStack(ErrRegistrationNotSupported)
 	}
 
-	if err = f.validateResponseTypes(r, request); err != nil {
+	if err = f.validateResponseTypes(ctx, r, request); err != nil {
 		return request, err
 	}
 
@@ -428,5 +544,80 @@ func (f *Fosite) newAuthorizeRequest(ctx context.Context, r *http.Request, isPAR
 		return request, errorsx.WithStack(ErrInvalidState.WithHintf("Request parameter 'state' must be at least be %d characters long to ensure sufficient entropy.", f.GetMinParameterEntropy(ctx)))
 	}
 
+	if err = f.validateAuthorizeParameterReuse(ctx, request); err != nil {
+		return request, err
+	}
+
 	return request, nil
 }
+
+// validateAuthorizeParameterReuse rejects "state" and "nonce" values which the client has already used within the
+// configured window. The values are recorded by NewAuthorizeResponse, so that requests which are never responded to
+// do not consume them.
+func (f *Fosite) validateAuthorizeParameterReuse(ctx context.Context, request AuthorizeRequester) error {
+	storage, err := f.authorizeParameterReuseStorage(ctx)
+	if storage == nil || err != nil {
+		return err
+	}
+
+	clientID := request.GetClient().GetID()
+	for _, parameter := range []string{"state", "nonce"} {
+		value := request.GetRequestForm().Get(parameter)
+		if value == "" {
+			continue
+		}
+
+		if used, err := storage.IsAuthorizeParameterUsed(ctx, clientID, parameter, value); err != nil {
+			return errorsx.WithStack(ErrServerError.WithWrap(err).WithDebug(err.Error()))
+		} else if used {
+			return errorsx.WithStack(ErrInvalidRequest.WithHintf("Request parameter '%s' has already been used in a previous authorization request.", parameter))
+		}
+	}
+
+	return nil
+}
+
+// recordAuthorizeParameters rejects reused "state" and "nonce" values and marks the values of the request as used for
+// the configured window.
+func (f *Fosite) recordAuthorizeParameters(ctx context.Context, request AuthorizeRequester) error {
+	storage, err := f.authorizeParameterReuseStorage(ctx)
+	if storage == nil || err != nil {
+		return err
+	}
+
+	// The values may have been used since the request was validated.
+	if err := f.validateAuthorizeParameterReuse(ctx, request); err != nil {
+		return err
+	}
+
+	clientID := request.GetClient().GetID()
+	exp := time.Now().UTC().Add(f.Config.GetAuthorizeParameterReuseWindow(ctx))
+	for _, parameter := range []string{"state", "nonce"} {
+		value := request.GetRequestForm().Get(parameter)
+		if value == "" {
+			continue
+		}
+
+		if err := storage.MarkAuthorizeParameterUsedForTime(ctx, clientID, parameter, value, exp); errors.Is(err, ErrJTIKnown) {
+			return errorsx.WithStack(ErrInvalidRequest.WithHintf("Request parameter '%s' has already been used in a previous authorization request.", parameter))
+		} else if err != nil {
+			return errorsx.WithStack(ErrServerError.WithWrap(err).WithDebug(err.Error()))
+		}
+	}
+
+	return nil
+}
+
+// authorizeParameterReuseStorage returns the storage tracking used authorization request parameters, or nil if reuse
+// detection is disabled.
+func (f *Fosite) authorizeParameterReuseStorage(ctx context.Context) (AuthorizeParameterReuseStorage, error) {
+	if f.Config.GetAuthorizeParameterReuseWindow(ctx) <= 0 {
+		return nil, nil
+	}
+
+	storage, ok := f.Store.(AuthorizeParameterReuseStorage)
+	if !ok {
+		return nil, errorsx.WithStack(ErrServerError.WithHint("The OAuth 2.0 provider is unable to detect reused authorization request parameters.").WithDebug("'AuthorizeParameterReuseStorage' not implemented"))
+	}
+	return storage, nil
+}
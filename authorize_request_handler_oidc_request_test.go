@@ -65,6 +65,8 @@ func TestAuthorizeRequestParametersFromOpenIDConnectRequest(t *testing.T) {
 	validRequestObject := mustGenerateAssertion(t, jwt.MapClaims{"scope": "foo", "foo": "bar", "baz": "baz", "response_type": "token", "response_mode": "post_form"}, key, "kid-foo")
 	validRequestObjectWithoutKid := mustGenerateAssertion(t, jwt.MapClaims{"scope": "foo", "foo": "bar", "baz": "baz"}, key, "")
 	validNoneRequestObject := mustGenerateNoneAssertion(t, jwt.MapClaims{"scope": "foo", "foo": "bar", "baz": "baz", "state": "some-state"})
+	requestObjectWithClientID := mustGenerateAssertion(t, jwt.MapClaims{"scope": "foo", "foo": "bar", "client_id": "foo"}, key, "kid-foo")
+	requestObjectWithRequestURI := mustGenerateAssertion(t, jwt.MapClaims{"scope": "foo", "request_uri": "https://foo.bar/request"}, key, "kid-foo")
 
 	var reqH http.HandlerFunc = func(rw http.ResponseWriter, r *http.Request) {
 		rw.Write([]byte(validRequestObject))
@@ -180,6 +182,30 @@ func TestAuthorizeRequestParametersFromOpenIDConnectRequest(t *testing.T) {
 			client:     &DefaultOpenIDConnectClient{JSONWebKeysURI: reqJWK.URL, RequestObjectSigningAlgorithm: "none"},
 			expectForm: url.Values{"state": {"some-state"}, "scope": {"foo openid"}, "request": {validNoneRequestObject}, "foo": {"bar"}, "baz": {"baz"}},
 		},
+		{
+			d:         "should fail because the client requires signed request objects",
+			form:      url.Values{"scope": {"openid"}, "request": {validNoneRequestObject}},
+			client:    &DefaultOpenIDConnectClient{JSONWebKeys: jwks, RequestObjectSigningAlgorithm: "RS256"},
+			expectErr: ErrInvalidRequestObject,
+		},
+		{
+			d:          "should pass because the client_id of the request object matches",
+			form:       url.Values{"scope": {"openid"}, "client_id": {"foo"}, "request": {requestObjectWithClientID}},
+			client:     &DefaultOpenIDConnectClient{DefaultClient: &DefaultClient{ID: "foo"}, JSONWebKeys: jwks, RequestObjectSigningAlgorithm: "RS256"},
+			expectForm: url.Values{"scope": {"foo openid"}, "client_id": {"foo"}, "request": {requestObjectWithClientID}, "foo": {"bar"}},
+		},
+		{
+			d:         "should fail because the client_id of the request object conflicts with the authorization request",
+			form:      url.Values{"scope": {"openid"}, "client_id": {"bar"}, "request": {requestObjectWithClientID}},
+			client:    &DefaultOpenIDConnectClient{DefaultClient: &DefaultClient{ID: "bar"}, JSONWebKeys: jwks, RequestObjectSigningAlgorithm: "RS256"},
+			expectErr: ErrInvalidRequestObject,
+		},
+		{
+			d:         "should fail because the request object contains request_uri",
+			form:      url.Values{"scope": {"openid"}, "request": {requestObjectWithRequestURI}},
+			client:    &DefaultOpenIDConnectClient{JSONWebKeys: jwks, RequestObjectSigningAlgorithm: "RS256"},
+			expectErr: ErrInvalidRequestObject,
+		},
 		{
 			d:          "should pass when request object uses algorithm none and the client did not explicitly allow any algorithm",
 			form:       url.Values{"scope": {"openid"}, "request": {validNoneRequestObject}},
@@ -218,3 +244,65 @@ func TestAuthorizeRequestParametersFromOpenIDConnectRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthorizeRequestParametersFromRequestURILimits(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	jwks := &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{KeyID: "kid-foo", Use: "sig", Key: &key.PublicKey}}}
+	requestObject := mustGenerateAssertion(t, jwt.MapClaims{"scope": "foo", "foo": "bar"}, key, "kid-foo")
+
+	var reqH http.HandlerFunc = func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", r.URL.Query().Get("type"))
+		rw.Write([]byte(requestObject))
+	}
+	reqTS := httptest.NewServer(reqH)
+	defer reqTS.Close()
+
+	for k, tc := range []struct {
+		d         string
+		config    *Config
+		location  string
+		expectErr error
+	}{
+		{
+			d:        "should pass with the default limits",
+			config:   &Config{},
+			location: reqTS.URL + "?type=text/plain",
+		},
+		{
+			d:        "should pass because the content type is allowed",
+			config:   &Config{RequestObjectContentTypes: []string{"application/oauth-authz-req+jwt"}},
+			location: reqTS.URL + "?type=application/oauth-authz-req%2Bjwt%3Bcharset%3Dutf-8",
+		},
+		{
+			d:         "should fail because the content type is not allowed",
+			config:    &Config{RequestObjectContentTypes: []string{"application/oauth-authz-req+jwt"}},
+			location:  reqTS.URL + "?type=text/plain",
+			expectErr: ErrInvalidRequestURI,
+		},
+		{
+			d:         "should fail because the request object is too large",
+			config:    &Config{RequestObjectMaxSize: int64(len(requestObject) - 1)},
+			location:  reqTS.URL + "?type=text/plain",
+			expectErr: ErrInvalidRequestURI,
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, tc.d), func(t *testing.T) {
+			f := &Fosite{Config: tc.config}
+			req := &AuthorizeRequest{
+				Request: Request{
+					Client: &DefaultOpenIDConnectClient{JSONWebKeys: jwks, RequestObjectSigningAlgorithm: "RS256", RequestURIs: []string{tc.location}},
+					Form:   url.Values{"scope": {"openid"}, "request_uri": {tc.location}},
+				},
+			}
+
+			err := f.authorizeRequestParametersFromOpenIDConnectRequest(context.Background(), req, false)
+			if tc.expectErr != nil {
+				require.ErrorIs(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "bar", req.Form.Get("foo"))
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/pkg/errors"
@@ -16,7 +17,12 @@ import (
 	"github.com/stretchr/testify/require"
 
 	. "github.com/ory/fosite"
+	"github.com/ory/fosite/compose"
+	"github.com/ory/fosite/handler/openid"
 	. "github.com/ory/fosite/internal"
+	"github.com/ory/fosite/internal/gen"
+	"github.com/ory/fosite/storage"
+	"github.com/ory/fosite/token/jwt"
 )
 
 // Should pass
@@ -172,6 +178,76 @@ func TestNewAuthorizeRequest(t *testing.T) {
 			},
 			expectedError: ErrInvalidRequest,
 		},
+		{
+			desc: "should fail because the claims parameter is not a JSON object",
+			conf: &Fosite{Store: store, Config: &Config{ScopeStrategy: ExactScopeStrategy, AudienceMatchingStrategy: DefaultAudienceMatchingStrategy}},
+			query: url.Values{
+				"redirect_uri":  {"https://foo.bar/cb"},
+				"client_id":     {"1234"},
+				"response_type": {"code"},
+				"state":         {"strong-state"},
+				"scope":         {"foo"},
+				"claims":        {`{"id_token":`},
+			},
+			mock: func() {
+				store.EXPECT().GetClient(gomock.Any(), "1234").Return(&DefaultClient{RedirectURIs: []string{"https://foo.bar/cb"}, Scopes: []string{"foo"}}, nil)
+			},
+			expectedError: ErrInvalidRequest,
+		},
+		{
+			desc: "should fail because prompt=none is combined with other values",
+			conf: &Fosite{Store: store, Config: &Config{ScopeStrategy: ExactScopeStrategy, AudienceMatchingStrategy: DefaultAudienceMatchingStrategy}},
+			query: url.Values{
+				"redirect_uri":  {"https://foo.bar/cb"},
+				"client_id":     {"1234"},
+				"response_type": {"code"},
+				"state":         {"strong-state"},
+				"scope":         {"foo"},
+				"prompt":        {"none consent"},
+			},
+			mock: func() {
+				store.EXPECT().GetClient(gomock.Any(), "1234").Return(&DefaultClient{RedirectURIs: []string{"https://foo.bar/cb"}, Scopes: []string{"foo"}}, nil)
+			},
+			expectedError: ErrInvalidRequest,
+		},
+		{
+			desc: "should fail because the response type is not allowed by the authorization server",
+			conf: &Fosite{Store: store, Config: &Config{ScopeStrategy: ExactScopeStrategy, AudienceMatchingStrategy: DefaultAudienceMatchingStrategy, AllowedResponseTypes: []string{"code"}}},
+			query: url.Values{
+				"redirect_uri":  {"https://foo.bar/cb"},
+				"client_id":     {"1234"},
+				"response_type": {"token"},
+				"state":         {"strong-state"},
+				"scope":         {"foo"},
+			},
+			mock: func() {
+				store.EXPECT().GetClient(gomock.Any(), "1234").Return(&DefaultClient{RedirectURIs: []string{"https://foo.bar/cb"}, Scopes: []string{"foo"}, ResponseTypes: []string{"code", "token"}}, nil)
+			},
+			expectedError: ErrUnsupportedResponseType,
+		},
+		{
+			desc: "should pass because the response type is allowed by the authorization server",
+			conf: &Fosite{Store: store, Config: &Config{ScopeStrategy: ExactScopeStrategy, AudienceMatchingStrategy: DefaultAudienceMatchingStrategy, AllowedResponseTypes: []string{"code"}}},
+			query: url.Values{
+				"redirect_uri":  {"https://foo.bar/cb"},
+				"client_id":     {"1234"},
+				"response_type": {"code"},
+				"state":         {"strong-state"},
+				"scope":         {"foo"},
+			},
+			mock: func() {
+				store.EXPECT().GetClient(gomock.Any(), "1234").Return(&DefaultClient{RedirectURIs: []string{"https://foo.bar/cb"}, Scopes: []string{"foo"}, ResponseTypes: []string{"code", "token"}}, nil)
+			},
+			expect: &AuthorizeRequest{
+				RedirectURI:   redir,
+				ResponseTypes: []string{"code"},
+				State:         "strong-state",
+				Request: Request{
+					Client:         &DefaultClient{RedirectURIs: []string{"https://foo.bar/cb"}, Scopes: []string{"foo"}, ResponseTypes: []string{"code", "token"}},
+					RequestedScope: []string{"foo"},
+				},
+			},
+		},
 		/* success case */
 		{
 			desc: "should pass",
@@ -560,3 +636,288 @@ func TestNewAuthorizeRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestNewAuthorizeRequestWithParameterReuse(t *testing.T) {
+	store := storage.NewMemoryStore()
+	store.Clients["foo"] = &DefaultClient{
+		ID:            "foo",
+		RedirectURIs:  []string{"https://foo.bar/cb"},
+		ResponseTypes: []string{"code"},
+		Scopes:        []string{"openid"},
+	}
+
+	newRequest := func(state, nonce string) *http.Request {
+		return &http.Request{Form: url.Values{
+			"client_id":     {"foo"},
+			"redirect_uri":  {"https://foo.bar/cb"},
+			"response_type": {"code"},
+			"scope":         {"openid"},
+			"state":         {state},
+			"nonce":         {nonce},
+		}}
+	}
+
+	t.Run("case=reuse is allowed by default", func(t *testing.T) {
+		f := &Fosite{Store: store, Config: &Config{}}
+		for i := 0; i < 2; i++ {
+			_, err := f.NewAuthorizeRequest(context.Background(), newRequest("strong-state", "strong-nonce"))
+			require.NoError(t, err)
+		}
+	})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	handler := NewMockAuthorizeEndpointHandler(ctrl)
+	handler.EXPECT().HandleAuthorizeEndpointRequest(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, ar AuthorizeRequester, _ AuthorizeResponder) error {
+		ar.SetResponseTypeHandled("code")
+		return nil
+	}).AnyTimes()
+
+	f := &Fosite{Store: store, Config: &Config{AuthorizeParameterReuseWindow: time.Hour, AuthorizeEndpointHandlers: AuthorizeEndpointHandlers{handler}}}
+	authorize := func(state, nonce string) error {
+		ar, err := f.NewAuthorizeRequest(context.Background(), newRequest(state, nonce))
+		if err != nil {
+			return err
+		}
+		_, err = f.NewAuthorizeResponse(context.Background(), ar, new(DefaultSession))
+		return err
+	}
+
+	for k, c := range []struct {
+		state     string
+		nonce     string
+		expectErr error
+	}{
+		{state: "first-state", nonce: "first-nonce"},
+		{state: "second-state", nonce: "first-nonce", expectErr: ErrInvalidRequest},
+		{state: "first-state", nonce: "second-nonce", expectErr: ErrInvalidRequest},
+		{state: "second-state", nonce: "second-nonce"},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			err := authorize(c.state, c.nonce)
+			if c.expectErr != nil {
+				require.ErrorIs(t, err, c.expectErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("case=requests which are not responded to do not consume the parameters", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			_, err := f.NewAuthorizeRequest(context.Background(), newRequest("third-state", "third-nonce"))
+			require.NoError(t, err)
+		}
+		require.NoError(t, authorize("third-state", "third-nonce"))
+	})
+
+	t.Run("case=rejects parameters used after the request was validated", func(t *testing.T) {
+		first, err := f.NewAuthorizeRequest(context.Background(), newRequest("fourth-state", "fourth-nonce"))
+		require.NoError(t, err)
+		second, err := f.NewAuthorizeRequest(context.Background(), newRequest("fourth-state", "fourth-nonce"))
+		require.NoError(t, err)
+
+		_, err = f.NewAuthorizeResponse(context.Background(), first, new(DefaultSession))
+		require.NoError(t, err)
+		_, err = f.NewAuthorizeResponse(context.Background(), second, new(DefaultSession))
+		require.ErrorIs(t, err, ErrInvalidRequest)
+	})
+
+	t.Run("case=fails if the storage can not track parameters", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockStore := NewMockStorage(ctrl)
+		mockStore.EXPECT().GetClient(gomock.Any(), "foo").Return(store.Clients["foo"], nil)
+
+		f := &Fosite{Store: mockStore, Config: &Config{AuthorizeParameterReuseWindow: time.Hour}}
+		_, err := f.NewAuthorizeRequest(context.Background(), newRequest("third-state", "third-nonce"))
+		require.ErrorIs(t, err, ErrServerError)
+	})
+}
+
+func TestValidateAuthorizeRequest(t *testing.T) {
+	config := &Config{
+		GlobalSecret:                  []byte("some-secret-thats-random-some-secret-thats-random-"),
+		EnforcePKCE:                   true,
+		AuthorizeParameterReuseWindow: time.Hour,
+	}
+	store := storage.NewExampleStore()
+	store.BlacklistedJTIs = make(map[string]time.Time)
+	f := compose.ComposeAllEnabled(config, store, gen.MustRSAKey()).(*Fosite)
+
+	newRequest := func(state string, modify func(form url.Values)) *http.Request {
+		form := url.Values{
+			"client_id":             {"my-client"},
+			"redirect_uri":          {"http://localhost:3846/callback"},
+			"response_type":         {"code"},
+			"scope":                 {"photos"},
+			"state":                 {state},
+			"nonce":                 {state + "-nonce"},
+			"code_challenge":        {"E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"},
+			"code_challenge_method": {"S256"},
+		}
+		if modify != nil {
+			modify(form)
+		}
+		return &http.Request{Form: form}
+	}
+
+	authorize := func(r *http.Request) error {
+		ar, err := f.NewAuthorizeRequest(context.Background(), r)
+		if err != nil {
+			return err
+		}
+		for _, scope := range ar.GetRequestedScopes() {
+			ar.GrantScope(scope)
+		}
+		now := time.Now().UTC()
+		_, err = f.NewAuthorizeResponse(context.Background(), ar, &openid.DefaultSession{
+			Subject: "peter",
+			Claims:  &jwt.IDTokenClaims{Subject: "peter", AuthTime: now, RequestedAt: now},
+		})
+		return err
+	}
+
+	implicit := func(form url.Values) {
+		form.Set("response_type", "id_token token")
+		form.Set("scope", "openid")
+		form.Del("code_challenge")
+		form.Del("code_challenge_method")
+	}
+
+	for k, c := range []struct {
+		d         string
+		modify    func(form url.Values)
+		expectErr error
+	}{
+		{
+			d: "should pass a valid request",
+		},
+		{
+			d:         "should fail because of an unknown client",
+			modify:    func(form url.Values) { form.Set("client_id", "unknown-client") },
+			expectErr: ErrInvalidClient,
+		},
+		{
+			d:         "should fail because of a mismatching redirect URI",
+			modify:    func(form url.Values) { form.Set("redirect_uri", "https://evil.example.com/callback") },
+			expectErr: ErrInvalidRequest,
+		},
+		{
+			d:         "should fail because of a disallowed scope",
+			modify:    func(form url.Values) { form.Set("scope", "photos admin") },
+			expectErr: ErrInvalidScope,
+		},
+		{
+			d: "should fail because of missing PKCE",
+			modify: func(form url.Values) {
+				form.Del("code_challenge")
+				form.Del("code_challenge_method")
+			},
+			expectErr: ErrInvalidRequest,
+		},
+		{
+			d:      "should pass a valid OpenID Connect request",
+			modify: implicit,
+		},
+		{
+			d: "should fail because of a missing nonce",
+			modify: func(form url.Values) {
+				implicit(form)
+				form.Del("nonce")
+			},
+			expectErr: ErrInvalidRequest,
+		},
+		{
+			d: "should fail because prompt=none is combined with other values",
+			modify: func(form url.Values) {
+				implicit(form)
+				form.Set("prompt", "none login")
+			},
+			expectErr: ErrInvalidRequest,
+		},
+		{
+			d: "should fail because of an undecodable id_token_hint",
+			modify: func(form url.Values) {
+				implicit(form)
+				form.Set("id_token_hint", "not-a-jwt")
+			},
+			expectErr: ErrInvalidRequest,
+		},
+		{
+			d: "should fail because of an insecure response mode",
+			modify: func(form url.Values) {
+				implicit(form)
+				form.Set("response_mode", "query")
+			},
+			expectErr: ErrUnsupportedResponseMode,
+		},
+		{
+			d: "should fail because no handler supports the response type",
+			modify: func(form url.Values) {
+				implicit(form)
+				form.Set("response_type", "id_token")
+				form.Set("scope", "photos")
+			},
+			expectErr: ErrUnsupportedResponseType,
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.d), func(t *testing.T) {
+			state := fmt.Sprintf("validate-state-%d", k)
+
+			validateErr := f.ValidateAuthorizeRequest(context.Background(), newRequest(state, c.modify))
+			authorizeErr := authorize(newRequest(state, c.modify))
+			if c.expectErr == nil {
+				require.NoError(t, validateErr)
+				require.NoError(t, authorizeErr, "the validation must not record the state as used")
+				return
+			}
+
+			require.ErrorIs(t, validateErr, c.expectErr)
+			require.ErrorIs(t, authorizeErr, c.expectErr)
+			assert.Equal(t, ErrorToRFC6749Error(authorizeErr).GetDescription(), ErrorToRFC6749Error(validateErr).GetDescription())
+		})
+	}
+}
+
+func TestNewAuthorizeRequestWithRedirectURIFragment(t *testing.T) {
+	store := storage.NewMemoryStore()
+	store.Clients["foo"] = &DefaultClient{ID: "foo", RedirectURIs: []string{"https://foo.bar/cb"}, ResponseTypes: []string{"code"}}
+	store.Clients["fragment"] = &DefaultClient{ID: "fragment", RedirectURIs: []string{"https://foo.bar/cb#"}, ResponseTypes: []string{"code"}}
+
+	for k, c := range []struct {
+		d           string
+		client      string
+		redirectURI string
+		reject      bool
+		expectHint  string
+	}{
+		{d: "fragment in the redirect_uri", client: "foo", redirectURI: "https://foo.bar/cb#foo", expectHint: "does not match"},
+		{d: "fragment in the redirect_uri", client: "foo", redirectURI: "https://foo.bar/cb#foo", reject: true, expectHint: "must not include a fragment"},
+		{d: "empty fragment in the registered redirect URI", client: "fragment", redirectURI: "https://foo.bar/cb#", reject: true, expectHint: "must not include a fragment"},
+		{d: "empty fragment in the registered redirect URI without a redirect_uri", client: "fragment", reject: true, expectHint: "must not include a fragment"},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.d), func(t *testing.T) {
+			f := &Fosite{Store: store, Config: &Config{RejectRedirectURIFragments: c.reject}}
+			_, err := f.NewAuthorizeRequest(context.Background(), &http.Request{Form: url.Values{
+				"client_id":     {c.client},
+				"redirect_uri":  {c.redirectURI},
+				"response_type": {"code"},
+				"state":         {"strong-state"},
+			}})
+			require.ErrorIs(t, err, ErrInvalidRequest)
+			assert.Contains(t, ErrorToRFC6749Error(err).HintField, c.expectHint)
+		})
+	}
+
+	t.Run("case=should pass without a fragment", func(t *testing.T) {
+		f := &Fosite{Store: store, Config: &Config{RejectRedirectURIFragments: true}}
+		_, err := f.NewAuthorizeRequest(context.Background(), &http.Request{Form: url.Values{
+			"client_id":     {"foo"},
+			"redirect_uri":  {"https://foo.bar/cb"},
+			"response_type": {"code"},
+			"state":         {"strong-state"},
+		}})
+		require.NoError(t, err)
+	})
+}
@@ -112,3 +112,26 @@ func TestAuthorizeRequest(t *testing.T) {
 		assert.Equal(t, &DefaultSession{}, c.ar.GetSession())
 	}
 }
+
+func TestAuthorizeRequestClone(t *testing.T) {
+	ar := NewAuthorizeRequest()
+	ar.ResponseTypes = Arguments{"code"}
+	ar.RedirectURI, _ = url.Parse("https://foobar/cb")
+	ar.State = "state"
+	ar.Session = &DefaultSession{Subject: "peter"}
+
+	c := ar.Clone()
+	assert.Equal(t, ar, c)
+
+	c.ResponseTypes[0] = "token"
+	c.SetResponseTypeHandled("code")
+	c.RedirectURI.Host = "changed"
+	c.State = "changed"
+	c.GetSession().(*DefaultSession).Subject = "alice"
+
+	assert.Equal(t, Arguments{"code"}, ar.ResponseTypes)
+	assert.Empty(t, ar.HandledResponseTypes)
+	assert.Equal(t, "https://foobar/cb", ar.RedirectURI.String())
+	assert.Equal(t, "state", ar.State)
+	assert.Equal(t, "peter", ar.GetSession().GetSubject())
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/ory/x/errorsx"
 	"github.com/ory/x/otelx"
@@ -25,6 +26,20 @@ func (f *Fosite) NewAuthorizeResponse(ctx context.Context, ar AuthorizeRequester
 	ctx = context.WithValue(ctx, AuthorizeRequestContextKey, ar)
 	ctx = context.WithValue(ctx, AuthorizeResponseContextKey, resp)
 
+	if f.Config.GetMaxScopeCount(ctx) > 0 {
+		if err := validateScopeCount(ctx, f.Config, ar.GetGrantedScopes()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateAudienceLength(ctx, f.Config, ar.GetGrantedAudience()); err != nil {
+		return nil, err
+	}
+
+	if err := f.recordAuthorizeParameters(ctx, ar); err != nil {
+		return nil, err
+	}
+
 	ar.SetSession(session)
 	for _, h := range f.Config.GetAuthorizeEndpointHandlers(ctx) {
 		if err := h.HandleAuthorizeEndpointRequest(ctx, ar, resp); err != nil {
@@ -32,13 +47,45 @@ func (f *Fosite) NewAuthorizeResponse(ctx context.Context, ar AuthorizeRequester
 		}
 	}
 
+	// Only the response reports the requested casing, the request keeps the granted scopes as they are.
+	if f.Config.GetReportRequestedScopeCasing(ctx) && resp.GetParameters().Get("scope") != "" {
+		resp.GetParameters().Set("scope", strings.Join(withRequestedScopeCasing(ar, ar.GetGrantedScopes()), " "))
+	}
+
 	if !ar.DidHandleAllResponseTypes() {
 		return nil, errorsx.WithStack(ErrUnsupportedResponseType)
 	}
 
-	if ar.GetDefaultResponseMode() == ResponseModeFragment && ar.GetResponseMode() == ResponseModeQuery {
-		return nil, ErrUnsupportedResponseMode.WithHintf("Insecure response_mode '%s' for the response_type '%s'.", ar.GetResponseMode(), ar.GetResponseTypes())
+	if err := validateResponseMode(ar); err != nil {
+		return nil, err
+	}
+
+	if f.Config.GetAuditSink(ctx) != nil {
+		// Tokens issued by the authorization endpoint are issued through the implicit grant.
+		var tokenTypes []TokenType
+		for _, tokenType := range []TokenType{AccessToken, IDToken} {
+			if resp.GetParameters().Get(string(tokenType)) != "" {
+				tokenTypes = append(tokenTypes, tokenType)
+			}
+		}
+		if len(tokenTypes) > 0 {
+			event := NewAuditEvent(AuditEventTokenIssued, ar, tokenTypes...)
+			event.GrantType = string(GrantTypeImplicit)
+			if err := RecordAuditEvent(ctx, f.Config, event); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	return resp, nil
 }
+
+// validateResponseMode rejects response modes which are insecure for the response types of the request.
+func validateResponseMode(ar AuthorizeRequester) error {
+	if ar.GetDefaultResponseMode() == ResponseModeFragment {
+		if rm := ar.GetResponseMode(); rm == ResponseModeQuery || rm == ResponseModeQueryJWT {
+			return ErrUnsupportedResponseMode.WithHintf("Insecure response_mode '%s' for the response_type '%s'.", rm, ar.GetResponseTypes())
+		}
+	}
+	return nil
+}
@@ -5,11 +5,13 @@ package fosite_test
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/ory/fosite"
 	. "github.com/ory/fosite"
@@ -26,6 +28,7 @@ func TestNewAuthorizeResponse(t *testing.T) {
 	oauth2 := &Fosite{Config: &Config{AuthorizeEndpointHandlers: AuthorizeEndpointHandlers{handlers[0]}}}
 	duo := &Fosite{Config: &Config{AuthorizeEndpointHandlers: AuthorizeEndpointHandlers{handlers[0], handlers[0]}}}
 	ar.EXPECT().SetSession(gomock.Eq(new(DefaultSession))).AnyTimes()
+	ar.EXPECT().GetGrantedAudience().Return(Arguments{}).AnyTimes()
 	fooErr := errors.New("foo")
 	for k, c := range []struct {
 		isErr     bool
@@ -75,12 +78,25 @@ func TestNewAuthorizeResponse(t *testing.T) {
 				handlers[0].EXPECT().HandleAuthorizeEndpointRequest(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 				ar.EXPECT().DidHandleAllResponseTypes().Return(true)
 				ar.EXPECT().GetDefaultResponseMode().Return(ResponseModeFragment)
-				ar.EXPECT().GetResponseMode().Return(ResponseModeQuery).Times(2)
+				ar.EXPECT().GetResponseMode().Return(ResponseModeQuery)
 				ar.EXPECT().GetResponseTypes().Return([]string{"token", "code"})
 			},
 			isErr:     true,
 			expectErr: ErrUnsupportedResponseMode.WithHintf("Insecure response_mode '%s' for the response_type '%s'.", ResponseModeQuery, fosite.Arguments{"token", "code"}),
 		},
+		{
+			mock: func() {
+				oauth2 = duo
+				handlers[0].EXPECT().HandleAuthorizeEndpointRequest(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				handlers[0].EXPECT().HandleAuthorizeEndpointRequest(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				ar.EXPECT().DidHandleAllResponseTypes().Return(true)
+				ar.EXPECT().GetDefaultResponseMode().Return(ResponseModeFragment)
+				ar.EXPECT().GetResponseMode().Return(ResponseModeQueryJWT)
+				ar.EXPECT().GetResponseTypes().Return([]string{"token", "code"})
+			},
+			isErr:     true,
+			expectErr: ErrUnsupportedResponseMode.WithHintf("Insecure response_mode '%s' for the response_type '%s'.", ResponseModeQueryJWT, fosite.Arguments{"token", "code"}),
+		},
 	} {
 		c.mock()
 		responder, err := oauth2.NewAuthorizeResponse(ctx, ar, new(DefaultSession))
@@ -94,3 +110,51 @@ func TestNewAuthorizeResponse(t *testing.T) {
 		t.Logf("Passed test case %d", k)
 	}
 }
+
+func TestNewAuthorizeResponseWithMaxScopeCount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	handlers := []*MockAuthorizeEndpointHandler{NewMockAuthorizeEndpointHandler(ctrl)}
+	defer ctrl.Finish()
+
+	oauth2 := &Fosite{Config: &Config{
+		MaxScopeCount:             2,
+		AuthorizeEndpointHandlers: AuthorizeEndpointHandlers{handlers[0]},
+	}}
+
+	ar := NewAuthorizeRequest()
+	ar.GrantScope("foo")
+	ar.GrantScope("bar")
+	ar.GrantScope("baz")
+
+	_, err := oauth2.NewAuthorizeResponse(context.Background(), ar, new(DefaultSession))
+	assert.ErrorIs(t, err, ErrInvalidScope)
+}
+
+func TestNewAuthorizeResponseWithRequestedScopeCasing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	handler := NewMockAuthorizeEndpointHandler(ctrl)
+	defer ctrl.Finish()
+
+	oauth2 := &Fosite{Config: &Config{
+		ReportRequestedScopeCasing: true,
+		AuthorizeEndpointHandlers:  AuthorizeEndpointHandlers{handler},
+	}}
+
+	ar := NewAuthorizeRequest()
+	ar.Form.Set("scope", "Photos offline")
+	ar.SetRequestedScopes(Arguments{"photos", "offline"})
+	ar.GrantScope("photos")
+	ar.GrantScope("offline")
+	ar.ResponseTypes = Arguments{"code"}
+
+	handler.EXPECT().HandleAuthorizeEndpointRequest(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, ar AuthorizeRequester, resp AuthorizeResponder) error {
+		ar.SetResponseTypeHandled("code")
+		resp.AddParameter("scope", strings.Join(ar.GetGrantedScopes(), " "))
+		return nil
+	})
+
+	resp, err := oauth2.NewAuthorizeResponse(context.Background(), ar, new(DefaultSession))
+	require.NoError(t, err)
+	assert.Equal(t, "Photos offline", resp.GetParameters().Get("scope"))
+	assert.Equal(t, Arguments{"photos", "offline"}, ar.GetGrantedScopes())
+}
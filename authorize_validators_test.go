@@ -4,6 +4,7 @@
 package fosite
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -15,10 +16,10 @@ import (
 )
 
 func TestValidateResponseTypes(t *testing.T) {
-	f := &Fosite{Config: new(Config)}
 	for k, tc := range []struct {
 		rt        string
 		art       []string
+		allowed   []string
 		expectErr bool
 	}{
 		{
@@ -63,6 +64,28 @@ func TestValidateResponseTypes(t *testing.T) {
 			art:       []string{"token", "code token id_token"},
 			expectErr: true,
 		},
+		{
+			rt:        "token",
+			art:       []string{"token", "code"},
+			allowed:   []string{"code"},
+			expectErr: true,
+		},
+		{
+			rt:      "code",
+			art:     []string{"token", "code"},
+			allowed: []string{"code"},
+		},
+		{
+			rt:        "id_token token",
+			art:       []string{"id_token token"},
+			allowed:   []string{"code", "code id_token"},
+			expectErr: true,
+		},
+		{
+			rt:      "id_token code",
+			art:     []string{"code id_token"},
+			allowed: []string{"code", "code id_token"},
+		},
 	} {
 		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
 			r := &http.Request{Form: url.Values{"response_type": {tc.rt}}}
@@ -72,7 +95,8 @@ func TestValidateResponseTypes(t *testing.T) {
 			ar := NewAuthorizeRequest()
 			ar.Request.Client = &DefaultClient{ResponseTypes: tc.art}
 
-			err := f.validateResponseTypes(r, ar)
+			f := &Fosite{Config: &Config{AllowedResponseTypes: tc.allowed}}
+			err := f.validateResponseTypes(context.Background(), r, ar)
 			if tc.expectErr {
 				require.Error(t, err)
 			} else {
@@ -0,0 +1,94 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/ory/x/errorsx"
+)
+
+// ClaimsRequest is the parsed "claims" request parameter which requests individual claims to be returned in the ID
+// token or from the UserInfo endpoint, see https://openid.net/specs/openid-connect-core-1_0.html#ClaimsParameter.
+// A claim requested without constraints, using the JSON value null, is mapped to a nil ClaimRequest.
+type ClaimsRequest struct {
+	UserInfo map[string]*ClaimRequest `json:"userinfo,omitempty"`
+	IDToken  map[string]*ClaimRequest `json:"id_token,omitempty"`
+}
+
+// ClaimRequest holds the constraints of a requested claim.
+type ClaimRequest struct {
+	// Essential indicates whether the claim is necessary for the client to work as intended.
+	Essential bool `json:"essential,omitempty"`
+
+	// Value requests the claim to be returned with a particular value.
+	Value interface{} `json:"value,omitempty"`
+
+	// Values requests the claim to be returned with one of a set of values.
+	Values []interface{} `json:"values,omitempty"`
+}
+
+// IsSatisfiedBy returns whether the given claim value satisfies the value constraints of the claim request. It
+// ignores whether the claim is essential. A nil ClaimRequest is satisfied by any value.
+func (c *ClaimRequest) IsSatisfiedBy(value interface{}) bool {
+	if c == nil {
+		return true
+	}
+
+	if c.Value != nil && !claimValueEquals(c.Value, value) {
+		return false
+	}
+
+	if len(c.Values) > 0 {
+		for _, v := range c.Values {
+			if claimValueEquals(v, value) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// claimValueEquals compares the values by their JSON encoding, as claim values may be of different Go types, for
+// example int64 and float64, depending on whether they have been decoded or not.
+func claimValueEquals(expected, actual interface{}) bool {
+	a, err := json.Marshal(expected)
+	if err != nil {
+		return false
+	}
+	b, err := json.Marshal(actual)
+	if err != nil {
+		return false
+	}
+
+	var x, y interface{}
+	if err := json.Unmarshal(a, &x); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(b, &y); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+// ParseClaimsRequest parses the value of the "claims" request parameter. It returns ErrInvalidRequest if the value
+// is not a JSON object as defined by OpenID Connect.
+func ParseClaimsRequest(raw string) (*ClaimsRequest, error) {
+	var request ClaimsRequest
+	if err := json.Unmarshal([]byte(raw), &request); err != nil {
+		return nil, errorsx.WithStack(ErrInvalidRequest.WithHint("The 'claims' parameter must be a JSON object with the members 'userinfo' and 'id_token'.").WithWrap(err).WithDebug(err.Error()))
+	}
+	return &request, nil
+}
+
+// GetClaimsRequest returns the parsed "claims" request parameter of the request, or nil if it was not sent.
+func GetClaimsRequest(requester Requester) (*ClaimsRequest, error) {
+	raw := requester.GetRequestForm().Get("claims")
+	if raw == "" {
+		return nil, nil
+	}
+	return ParseClaimsRequest(raw)
+}
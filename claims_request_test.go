@@ -0,0 +1,66 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite_test
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ory/fosite"
+)
+
+func TestParseClaimsRequest(t *testing.T) {
+	t.Run("case=should parse a well-formed claims request", func(t *testing.T) {
+		r, err := ParseClaimsRequest(`{
+			"userinfo": {"given_name": {"essential": true}, "email": null},
+			"id_token": {"auth_time": {"essential": true}, "acr": {"values": ["urn:mace:incommon:iap:silver"]}, "sub": {"value": "peter"}}
+		}`)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]*ClaimRequest{"given_name": {Essential: true}, "email": nil}, r.UserInfo)
+		assert.Equal(t, map[string]*ClaimRequest{
+			"auth_time": {Essential: true},
+			"acr":       {Values: []interface{}{"urn:mace:incommon:iap:silver"}},
+			"sub":       {Value: "peter"},
+		}, r.IDToken)
+	})
+
+	for k, raw := range []string{`{"id_token":`, `"id_token"`, `[]`, `{"id_token": {"sub": "peter"}}`, `{"id_token": {"sub": {"essential": "yes"}}}`} {
+		t.Run(fmt.Sprintf("case=%d/description=should fail on invalid claims request %s", k, raw), func(t *testing.T) {
+			_, err := ParseClaimsRequest(raw)
+			require.ErrorIs(t, err, ErrInvalidRequest)
+		})
+	}
+
+	t.Run("case=should return nil if the claims parameter was not sent", func(t *testing.T) {
+		r, err := GetClaimsRequest(&Request{Form: url.Values{}})
+		require.NoError(t, err)
+		assert.Nil(t, r)
+	})
+}
+
+func TestClaimRequestIsSatisfiedBy(t *testing.T) {
+	for k, c := range []struct {
+		d      string
+		claim  *ClaimRequest
+		value  interface{}
+		expect bool
+	}{
+		{d: "no constraints", value: "peter", expect: true},
+		{d: "essential without value", claim: &ClaimRequest{Essential: true}, value: "peter", expect: true},
+		{d: "matching value", claim: &ClaimRequest{Value: "peter"}, value: "peter", expect: true},
+		{d: "mismatching value", claim: &ClaimRequest{Value: "peter"}, value: "alice"},
+		{d: "matching numeric value of another type", claim: &ClaimRequest{Value: float64(1)}, value: int64(1), expect: true},
+		{d: "one of the values", claim: &ClaimRequest{Values: []interface{}{"0", "1"}}, value: "1", expect: true},
+		{d: "none of the values", claim: &ClaimRequest{Values: []interface{}{"0", "1"}}, value: "2"},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.d), func(t *testing.T) {
+			assert.Equal(t, c.expect, c.claim.IsSatisfiedBy(c.value))
+		})
+	}
+}
@@ -71,12 +71,96 @@ type OpenIDConnectClient interface {
 	GetTokenEndpointAuthSigningAlgorithm() string
 }
 
+// IDTokenSigningAlgorithmClient represents a client which registered the algorithm its ID tokens must be signed with,
+// see https://openid.net/specs/openid-connect-registration-1_0.html#ClientMetadata
+type IDTokenSigningAlgorithmClient interface {
+	Client
+
+	// GetIDTokenSignedResponseAlgorithm returns the JWS alg algorithm [JWA] required for signing the ID Tokens issued
+	// to this Client. An empty value indicates the default algorithm of the provider.
+	GetIDTokenSignedResponseAlgorithm() string
+}
+
+// IDTokenEncryptionClient represents a client which registered the algorithms its ID tokens must be encrypted with,
+// see https://openid.net/specs/openid-connect-registration-1_0.html#ClientMetadata
+type IDTokenEncryptionClient interface {
+	OpenIDConnectClient
+
+	// GetIDTokenEncryptedResponseAlgorithm returns the JWE alg algorithm [JWA] required for encrypting the ID Tokens
+	// issued to this Client. An empty value indicates that ID Tokens are not encrypted.
+	GetIDTokenEncryptedResponseAlgorithm() string
+
+	// GetIDTokenEncryptedResponseEncryption returns the JWE enc algorithm [JWA] required for encrypting the ID Tokens
+	// issued to this Client. It defaults to A128CBC-HS256 if an alg algorithm is registered.
+	GetIDTokenEncryptedResponseEncryption() string
+}
+
+// SubjectIdentifierClient represents a client which registered the type of subject identifiers it receives, see
+// https://openid.net/specs/openid-connect-core-1_0.html#SubjectIDTypes
+type SubjectIdentifierClient interface {
+	OpenIDConnectClient
+
+	// GetSubjectType returns the subject identifier type ("public" or "pairwise") requested by this Client. An empty
+	// value indicates the default of the provider.
+	GetSubjectType() string
+
+	// GetSectorIdentifierURI returns the URL whose host is used to derive pairwise subject identifiers. If empty,
+	// the host of the redirect URIs is used, which must be the same for all of them.
+	GetSectorIdentifierURI() string
+}
+
+// MutualTLSClient represents a client which authenticates using the tls_client_auth method, see
+// https://datatracker.ietf.org/doc/html/rfc8705#section-2.1
+type MutualTLSClient interface {
+	OpenIDConnectClient
+
+	// GetTLSClientAuthSubjectDN returns the expected subject distinguished name of the client certificate.
+	GetTLSClientAuthSubjectDN() string
+}
+
 // ResponseModeClient represents a client capable of handling response_mode
 type ResponseModeClient interface {
 	// GetResponseMode returns the response modes that client is allowed to send
 	GetResponseModes() []ResponseModeType
 }
 
+// ResourceClient represents a client which may only request the resources (audiences) it has been registered for.
+type ResourceClient interface {
+	// GetPermittedResources returns the resources this client is permitted to request.
+	GetPermittedResources() []string
+}
+
+// TokenEndpointAuthMethodClient represents a client which is pinned to a single client authentication method at
+// the token endpoint. OpenIDConnectClient implements it as well.
+type TokenEndpointAuthMethodClient interface {
+	// GetTokenEndpointAuthMethod returns the client authentication method the client must use, for example
+	// client_secret_basic or private_key_jwt. An empty value allows every supported method.
+	GetTokenEndpointAuthMethod() string
+}
+
+// Access token strategies a client may select, see AccessTokenStrategyClient.
+const (
+	AccessTokenStrategyOpaque = "opaque"
+	AccessTokenStrategyJWT    = "jwt"
+)
+
+// AccessTokenStrategyClient represents a client which selects whether it is issued opaque or JWT access tokens.
+type AccessTokenStrategyClient interface {
+	// GetAccessTokenStrategy returns AccessTokenStrategyOpaque or AccessTokenStrategyJWT. An empty value uses the
+	// strategy configured for all clients.
+	GetAccessTokenStrategy() string
+}
+
+// BackChannelLogoutClient represents a client which registered a back-channel logout URI to receive logout tokens,
+// see https://openid.net/specs/openid-connect-backchannel-1_0.html.
+type BackChannelLogoutClient interface {
+	Client
+
+	// GetBackChannelLogoutURI returns the URI to which logout tokens are delivered. An empty value means the client
+	// does not support back-channel logout.
+	GetBackChannelLogoutURI() string
+}
+
 // DefaultClient is a simple default implementation of the Client interface.
 type DefaultClient struct {
 	ID             string   `json:"id"`
@@ -92,12 +176,18 @@ type DefaultClient struct {
 
 type DefaultOpenIDConnectClient struct {
 	*DefaultClient
-	JSONWebKeysURI                    string              `json:"jwks_uri"`
-	JSONWebKeys                       *jose.JSONWebKeySet `json:"jwks"`
-	TokenEndpointAuthMethod           string              `json:"token_endpoint_auth_method"`
-	RequestURIs                       []string            `json:"request_uris"`
-	RequestObjectSigningAlgorithm     string              `json:"request_object_signing_alg"`
-	TokenEndpointAuthSigningAlgorithm string              `json:"token_endpoint_auth_signing_alg"`
+	JSONWebKeysURI                     string              `json:"jwks_uri"`
+	JSONWebKeys                        *jose.JSONWebKeySet `json:"jwks"`
+	TokenEndpointAuthMethod            string              `json:"token_endpoint_auth_method"`
+	RequestURIs                        []string            `json:"request_uris"`
+	RequestObjectSigningAlgorithm      string              `json:"request_object_signing_alg"`
+	TokenEndpointAuthSigningAlgorithm  string              `json:"token_endpoint_auth_signing_alg"`
+	IDTokenSignedResponseAlgorithm     string              `json:"id_token_signed_response_alg"`
+	IDTokenEncryptedResponseAlgorithm  string              `json:"id_token_encrypted_response_alg"`
+	IDTokenEncryptedResponseEncryption string              `json:"id_token_encrypted_response_enc"`
+	TLSClientAuthSubjectDN             string              `json:"tls_client_auth_subject_dn"`
+	SubjectType                        string              `json:"subject_type"`
+	SectorIdentifierURI                string              `json:"sector_identifier_uri"`
 }
 
 type DefaultResponseModeClient struct {
@@ -105,6 +195,26 @@ type DefaultResponseModeClient struct {
 	ResponseModes []ResponseModeType `json:"response_modes"`
 }
 
+type DefaultResourceClient struct {
+	*DefaultClient
+	PermittedResources []string `json:"permitted_resources"`
+}
+
+type DefaultTokenEndpointAuthMethodClient struct {
+	*DefaultClient
+	TokenEndpointAuthMethod string `json:"token_endpoint_auth_method"`
+}
+
+type DefaultAccessTokenStrategyClient struct {
+	*DefaultClient
+	AccessTokenStrategy string `json:"access_token_strategy"`
+}
+
+type DefaultBackChannelLogoutClient struct {
+	*DefaultOpenIDConnectClient
+	BackChannelLogoutURI string `json:"backchannel_logout_uri"`
+}
+
 func (c *DefaultClient) GetID() string {
 	return c.ID
 }
@@ -145,6 +255,10 @@ func (c *DefaultClient) GetGrantTypes() Arguments {
 	return Arguments(c.GrantTypes)
 }
 
+func (c *DefaultClient) GetRegisteredGrantTypes() Arguments {
+	return Arguments(c.GrantTypes)
+}
+
 func (c *DefaultClient) GetResponseTypes() Arguments {
 	// https://openid.net/specs/openid-connect-registration-1_0.html#ClientMetadata
 	//
@@ -177,10 +291,37 @@ func (c *DefaultOpenIDConnectClient) GetRequestObjectSigningAlgorithm() string {
 	return c.RequestObjectSigningAlgorithm
 }
 
+func (c *DefaultOpenIDConnectClient) GetIDTokenSignedResponseAlgorithm() string {
+	return c.IDTokenSignedResponseAlgorithm
+}
+
+func (c *DefaultOpenIDConnectClient) GetIDTokenEncryptedResponseAlgorithm() string {
+	return c.IDTokenEncryptedResponseAlgorithm
+}
+
+func (c *DefaultOpenIDConnectClient) GetIDTokenEncryptedResponseEncryption() string {
+	if c.IDTokenEncryptedResponseAlgorithm != "" && c.IDTokenEncryptedResponseEncryption == "" {
+		return string(jose.A128CBC_HS256)
+	}
+	return c.IDTokenEncryptedResponseEncryption
+}
+
 func (c *DefaultOpenIDConnectClient) GetTokenEndpointAuthMethod() string {
 	return c.TokenEndpointAuthMethod
 }
 
+func (c *DefaultOpenIDConnectClient) GetSubjectType() string {
+	return c.SubjectType
+}
+
+func (c *DefaultOpenIDConnectClient) GetSectorIdentifierURI() string {
+	return c.SectorIdentifierURI
+}
+
+func (c *DefaultOpenIDConnectClient) GetTLSClientAuthSubjectDN() string {
+	return c.TLSClientAuthSubjectDN
+}
+
 func (c *DefaultOpenIDConnectClient) GetRequestURIs() []string {
 	return c.RequestURIs
 }
@@ -188,3 +329,19 @@ func (c *DefaultOpenIDConnectClient) GetRequestURIs() []string {
 func (c *DefaultResponseModeClient) GetResponseModes() []ResponseModeType {
 	return c.ResponseModes
 }
+
+func (c *DefaultResourceClient) GetPermittedResources() []string {
+	return c.PermittedResources
+}
+
+func (c *DefaultTokenEndpointAuthMethodClient) GetTokenEndpointAuthMethod() string {
+	return c.TokenEndpointAuthMethod
+}
+
+func (c *DefaultAccessTokenStrategyClient) GetAccessTokenStrategy() string {
+	return c.AccessTokenStrategy
+}
+
+func (c *DefaultBackChannelLogoutClient) GetBackChannelLogoutURI() string {
+	return c.BackChannelLogoutURI
+}
@@ -25,6 +25,10 @@ import (
 // ClientAuthenticationStrategy provides a method signature for authenticating a client request
 type ClientAuthenticationStrategy func(context.Context, *http.Request, url.Values) (Client, error)
 
+// ClientAuthenticatedHook is invoked after a client authenticated successfully at the token endpoint, for example
+// to record when the client was last seen.
+type ClientAuthenticatedHook func(ctx context.Context, client Client)
+
 // #nosec:gosec G101 - False Positive
 const clientAssertionJWTBearerType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
 
@@ -197,8 +201,17 @@ func (f *Fosite) DefaultClientAuthenticationStrategy(ctx context.Context, r *htt
 		return nil, err
 	}
 
+	if _, _, ok := r.BasicAuth(); ok && f.Config.GetRequireBodyClientIDMatch(ctx) {
+		if bodyClientID := form.Get("client_id"); bodyClientID != "" && bodyClientID != clientID {
+			return nil, errorsx.WithStack(ErrInvalidRequest.WithHint("The 'client_id' in the HTTP POST body does not match the client ID in the HTTP Authorization header."))
+		}
+	}
+
 	client, err := f.Store.GetClient(ctx, clientID)
 	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			f.compareDummySecret(ctx, []byte(clientSecret))
+		}
 		return nil, errorsx.WithStack(ErrInvalidClient.WithWrap(err).WithDebug(err.Error()))
 	}
 
@@ -212,10 +225,21 @@ func (f *Fosite) DefaultClientAuthenticationStrategy(ctx context.Context, r *htt
 		return nil, errorsx.WithStack(ErrInvalidClient.WithHintf("The OAuth 2.0 Client supports client authentication method '%s', but method 'none' was requested. You must configure the OAuth 2.0 client's 'token_endpoint_auth_method' value to accept 'none'.", oidcClient.GetTokenEndpointAuthMethod()))
 	}
 
+	if err := validateTokenEndpointAuthMethod(client, clientAuthMethodFromRequest(r, form)); err != nil {
+		return nil, err
+	}
+
 	if client.IsPublic() {
 		return client, nil
 	}
 
+	if oidcClient, ok := client.(OpenIDConnectClient); ok && IsMutualTLSClientAuthMethod(oidcClient.GetTokenEndpointAuthMethod()) {
+		if err := f.authenticateClientWithCertificate(ctx, r, oidcClient); err != nil {
+			return nil, err
+		}
+		return client, nil
+	}
+
 	// Enforce client authentication
 	if err := f.checkClientSecret(ctx, client, []byte(clientSecret)); err != nil {
 		return nil, errorsx.WithStack(ErrInvalidClient.WithWrap(err).WithDebug(err.Error()))
@@ -224,6 +248,33 @@ func (f *Fosite) DefaultClientAuthenticationStrategy(ctx context.Context, r *htt
 	return client, nil
 }
 
+// clientAuthMethodFromRequest returns the secret-based client authentication method used by the request, or "none"
+// if no client secret was sent.
+func clientAuthMethodFromRequest(r *http.Request, form url.Values) string {
+	if _, secret, ok := r.BasicAuth(); ok && secret != "" {
+		return "client_secret_basic"
+	} else if form.Get("client_secret") != "" {
+		return "client_secret_post"
+	}
+	return "none"
+}
+
+// validateTokenEndpointAuthMethod rejects requests which authenticate the client with a different method than the
+// one the client is pinned to. Clients which do not declare a method may use any method.
+func validateTokenEndpointAuthMethod(client Client, method string) error {
+	pinned, ok := client.(TokenEndpointAuthMethodClient)
+	if !ok || pinned.GetTokenEndpointAuthMethod() == "" || pinned.GetTokenEndpointAuthMethod() == method {
+		return nil
+	}
+
+	// The client certificate is verified after the client has been resolved.
+	if IsMutualTLSClientAuthMethod(pinned.GetTokenEndpointAuthMethod()) && method == "none" {
+		return nil
+	}
+
+	return errorsx.WithStack(ErrInvalidClient.WithHintf("The OAuth 2.0 Client only supports client authentication method '%s', but method '%s' was requested.", pinned.GetTokenEndpointAuthMethod(), method))
+}
+
 func audienceMatchesTokenURLs(claims jwt.MapClaims, tokenURLs []string) bool {
 	for _, tokenURL := range tokenURLs {
 		if audienceMatchesTokenURL(claims, tokenURL) {
@@ -245,6 +296,23 @@ func audienceMatchesTokenURL(claims jwt.MapClaims, tokenURL string) bool {
 	return claims.VerifyAudience(tokenURL, true)
 }
 
+// compareDummySecret compares the secret against a fixed hash, so that authenticating an unknown client takes as long
+// as authenticating a known client with a wrong secret, which prevents enumerating clients by timing.
+func (f *Fosite) compareDummySecret(ctx context.Context, clientSecret []byte) {
+	hasher := f.Config.GetSecretsHasher(ctx)
+	hash := f.dummySecretHash.Load()
+	if hash == nil {
+		h, err := hasher.Hash(ctx, []byte("dummy-client-secret"))
+		if err != nil {
+			return
+		}
+		hash = &h
+		f.dummySecretHash.Store(hash)
+	}
+
+	_ = hasher.Compare(ctx, *hash, clientSecret)
+}
+
 func (f *Fosite) checkClientSecret(ctx context.Context, client Client, clientSecret []byte) error {
 	var err error
 	err = f.Config.GetSecretsHasher(ctx).Compare(ctx, client.GetHashedSecret(), clientSecret)
@@ -21,6 +21,7 @@ import (
 	"github.com/ory/fosite/internal/gen"
 
 	"github.com/go-jose/go-jose/v3"
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -544,6 +545,130 @@ func TestAuthenticateClient(t *testing.T) {
 	}
 }
 
+func TestAuthenticateClientWithPinnedAuthMethod(t *testing.T) {
+	const at = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+	hasher := &BCrypt{Config: &Config{HashCost: 6}}
+	secret, err := hasher.Hash(context.TODO(), []byte("bar"))
+	require.NoError(t, err)
+
+	key := gen.MustRSAKey()
+	assertion := func() url.Values {
+		return url.Values{"client_assertion_type": {at}, "client_assertion": {mustGenerateRSAAssertion(t, jwt.MapClaims{
+			"sub": "foo",
+			"exp": time.Now().Add(time.Hour).Unix(),
+			"iss": "foo",
+			"jti": uuid.New().String(),
+			"aud": "token-url",
+		}, key, "kid-foo")}}
+	}
+
+	pinned := func(method string) Client {
+		return &DefaultTokenEndpointAuthMethodClient{
+			DefaultClient:           &DefaultClient{ID: "foo", Secret: secret},
+			TokenEndpointAuthMethod: method,
+		}
+	}
+	pinnedOIDC := &DefaultOpenIDConnectClient{
+		DefaultClient:                     &DefaultClient{ID: "foo", Secret: secret},
+		JSONWebKeys:                       &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{KeyID: "kid-foo", Use: "sig", Key: &key.PublicKey}}},
+		TokenEndpointAuthMethod:           "private_key_jwt",
+		TokenEndpointAuthSigningAlgorithm: "RS256",
+	}
+
+	basic := &http.Request{Header: clientBasicAuthHeader("foo", "bar")}
+	post := url.Values{"client_id": {"foo"}, "client_secret": {"bar"}}
+
+	for k, tc := range []struct {
+		d         string
+		client    Client
+		r         *http.Request
+		form      url.Values
+		expectErr error
+	}{
+		{
+			d:      "should pass because the client is pinned to private_key_jwt and uses it",
+			client: pinnedOIDC,
+			r:      new(http.Request),
+			form:   assertion(),
+		},
+		{
+			d:         "should fail because the client is pinned to private_key_jwt but uses client_secret_basic",
+			client:    pinnedOIDC,
+			r:         basic,
+			form:      url.Values{},
+			expectErr: ErrInvalidClient,
+		},
+		{
+			d:         "should fail because the client is pinned to private_key_jwt but uses client_secret_post",
+			client:    pinnedOIDC,
+			r:         new(http.Request),
+			form:      post,
+			expectErr: ErrInvalidClient,
+		},
+		{
+			d:         "should fail because the non-OpenID Connect client is pinned to private_key_jwt but uses client_secret_basic",
+			client:    pinned("private_key_jwt"),
+			r:         basic,
+			form:      url.Values{},
+			expectErr: ErrInvalidClient,
+		},
+		{
+			d:         "should fail because the non-OpenID Connect client is pinned to private_key_jwt but uses client_secret_post",
+			client:    pinned("private_key_jwt"),
+			r:         new(http.Request),
+			form:      post,
+			expectErr: ErrInvalidClient,
+		},
+		{
+			d:      "should pass because the client is pinned to client_secret_basic and uses it",
+			client: pinned("client_secret_basic"),
+			r:      basic,
+			form:   url.Values{},
+		},
+		{
+			d:         "should fail because the client is pinned to client_secret_basic but uses client_secret_post",
+			client:    pinned("client_secret_basic"),
+			r:         new(http.Request),
+			form:      post,
+			expectErr: ErrInvalidClient,
+		},
+		{
+			d:      "should pass with client_secret_basic because the client does not declare a method",
+			client: pinned(""),
+			r:      basic,
+			form:   url.Values{},
+		},
+		{
+			d:      "should pass with client_secret_post because the client does not declare a method",
+			client: &DefaultClient{ID: "foo", Secret: secret},
+			r:      new(http.Request),
+			form:   post,
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, tc.d), func(t *testing.T) {
+			store := storage.NewMemoryStore()
+			store.Clients["foo"] = tc.client
+			f := &Fosite{
+				Store: store,
+				Config: &Config{
+					ClientSecretsHasher: hasher,
+					TokenURL:            "token-url",
+				},
+			}
+
+			c, err := f.AuthenticateClient(context.Background(), tc.r, tc.form)
+			if tc.expectErr != nil {
+				require.ErrorIs(t, err, tc.expectErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.client, c)
+		})
+	}
+}
+
 func TestAuthenticateClientTwice(t *testing.T) {
 	const at = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
 
@@ -595,3 +720,74 @@ func TestAuthenticateClientTwice(t *testing.T) {
 	assert.EqualError(t, err, ErrJTIKnown.Error())
 	assert.Nil(t, c)
 }
+
+type countingHasher struct {
+	Hasher
+	compares int
+}
+
+func (h *countingHasher) Compare(ctx context.Context, hash, data []byte) error {
+	h.compares++
+	return h.Hasher.Compare(ctx, hash, data)
+}
+
+func TestAuthenticateClientComparesSecretOfUnknownClients(t *testing.T) {
+	hasher := &countingHasher{Hasher: &BCrypt{Config: &Config{HashCost: 4}}}
+	secret, err := hasher.Hash(context.Background(), []byte("bar"))
+	require.NoError(t, err)
+
+	store := storage.NewMemoryStore()
+	store.Clients["foo"] = &DefaultClient{ID: "foo", Secret: secret}
+	f := &Fosite{Store: store, Config: &Config{ClientSecretsHasher: hasher}}
+
+	for k, c := range []struct {
+		d        string
+		clientID string
+	}{
+		{d: "known client with a wrong secret", clientID: "foo"},
+		{d: "unknown client", clientID: "unknown"},
+		{d: "unknown client again", clientID: "unknown"},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.d), func(t *testing.T) {
+			hasher.compares = 0
+			r := &http.Request{Header: clientBasicAuthHeader(c.clientID, "wrong")}
+			_, err := f.AuthenticateClient(context.Background(), r, url.Values{})
+			require.ErrorIs(t, err, ErrInvalidClient)
+			assert.Equal(t, 1, hasher.compares)
+		})
+	}
+}
+
+func TestAuthenticateClientWithBodyClientID(t *testing.T) {
+	hasher := &BCrypt{Config: &Config{HashCost: 4}}
+	secret, err := hasher.Hash(context.Background(), []byte("bar"))
+	require.NoError(t, err)
+
+	store := storage.NewMemoryStore()
+	store.Clients["foo"] = &DefaultClient{ID: "foo", Secret: secret}
+
+	for k, c := range []struct {
+		d         string
+		require   bool
+		form      url.Values
+		expectErr error
+	}{
+		{d: "should pass without a body client_id", require: true, form: url.Values{}},
+		{d: "should pass with a matching body client_id", require: true, form: url.Values{"client_id": {"foo"}}},
+		{d: "should fail with a mismatching body client_id", require: true, form: url.Values{"client_id": {"baz"}}, expectErr: ErrInvalidRequest},
+		{d: "should ignore a mismatching body client_id by default", form: url.Values{"client_id": {"baz"}}},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.d), func(t *testing.T) {
+			f := &Fosite{Store: store, Config: &Config{ClientSecretsHasher: hasher, RequireBodyClientIDMatch: c.require}}
+			r := &http.Request{Header: clientBasicAuthHeader("foo", "bar")}
+
+			client, err := f.AuthenticateClient(context.Background(), r, c.form)
+			if c.expectErr != nil {
+				require.ErrorIs(t, err, c.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "foo", client.GetID())
+		})
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/url"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/ory/x/errorsx"
+)
+
+// Client authentication methods using mutual TLS, see https://datatracker.ietf.org/doc/html/rfc8705#section-2
+const (
+	ClientAuthMethodTLS           = "tls_client_auth"
+	ClientAuthMethodSelfSignedTLS = "self_signed_tls_client_auth"
+)
+
+// ClientCertificateExtractor returns the client certificate of the request, or nil if the request does not carry
+// one. The certificate chain of the tls_client_auth method must already have been verified, for example by the
+// TLS server or by the proxy terminating TLS.
+type ClientCertificateExtractor func(r *http.Request) (*x509.Certificate, error)
+
+// DefaultClientCertificateExtractor returns the leaf certificate the peer presented during the TLS handshake.
+func DefaultClientCertificateExtractor(r *http.Request) (*x509.Certificate, error) {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return nil, nil
+	}
+	return r.TLS.PeerCertificates[0], nil
+}
+
+// ClientCertificateFromHeader returns a ClientCertificateExtractor which reads the URL encoded PEM certificate
+// from the given HTTP header. Use it if TLS is terminated by a proxy which forwards the client certificate. The
+// header must not be settable by clients.
+func ClientCertificateFromHeader(header string) ClientCertificateExtractor {
+	return func(r *http.Request) (*x509.Certificate, error) {
+		value := r.Header.Get(header)
+		if value == "" {
+			return nil, nil
+		}
+
+		decoded, err := url.QueryUnescape(value)
+		if err != nil {
+			return nil, errorsx.WithStack(ErrInvalidRequest.WithHint("Unable to decode the client certificate.").WithWrap(err).WithDebug(err.Error()))
+		}
+
+		block, _ := pem.Decode([]byte(decoded))
+		if block == nil || block.Type != "CERTIFICATE" {
+			return nil, errorsx.WithStack(ErrInvalidRequest.WithHint("The client certificate is not a PEM encoded certificate."))
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errorsx.WithStack(ErrInvalidRequest.WithHint("Unable to parse the client certificate.").WithWrap(err).WithDebug(err.Error()))
+		}
+		return cert, nil
+	}
+}
+
+// CertificateThumbprint returns the base64url encoded SHA-256 thumbprint of the certificate, which is used as the
+// "x5t#S256" confirmation method of certificate-bound tokens, see
+// https://datatracker.ietf.org/doc/html/rfc8705#section-3.1
+func CertificateThumbprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// IsMutualTLSClientAuthMethod returns true if the client authentication method uses mutual TLS.
+func IsMutualTLSClientAuthMethod(method string) bool {
+	return method == ClientAuthMethodTLS || method == ClientAuthMethodSelfSignedTLS
+}
+
+// authenticateClientWithCertificate authenticates the client using the certificate of the request.
+func (f *Fosite) authenticateClientWithCertificate(ctx context.Context, r *http.Request, client OpenIDConnectClient) error {
+	cert, err := f.Config.GetClientCertificateExtractor(ctx)(r)
+	if err != nil {
+		return err
+	} else if cert == nil {
+		return errorsx.WithStack(ErrInvalidClient.WithHintf("The OAuth 2.0 Client supports client authentication method '%s', but no client certificate was presented.", client.GetTokenEndpointAuthMethod()))
+	}
+
+	switch client.GetTokenEndpointAuthMethod() {
+	case ClientAuthMethodTLS:
+		tlsClient, ok := client.(MutualTLSClient)
+		if !ok || tlsClient.GetTLSClientAuthSubjectDN() == "" {
+			return errorsx.WithStack(ErrInvalidClient.WithHint("The OAuth 2.0 Client has no certificate subject distinguished name registered, but it is needed to complete the request."))
+		}
+
+		if cert.Subject.String() != tlsClient.GetTLSClientAuthSubjectDN() {
+			return errorsx.WithStack(ErrInvalidClient.WithHint("The subject distinguished name of the client certificate does not match the one registered for the OAuth 2.0 Client."))
+		}
+		return nil
+	case ClientAuthMethodSelfSignedTLS:
+		return f.matchClientCertificateKey(ctx, client, cert)
+	}
+
+	return errorsx.WithStack(ErrInvalidClient.WithHintf("This requested OAuth 2.0 client only supports client authentication method '%s', however that method is not supported by this server.", client.GetTokenEndpointAuthMethod()))
+}
+
+// matchClientCertificateKey checks that the public key of the self-signed certificate is part of the JSON Web Key
+// Set of the client, see https://datatracker.ietf.org/doc/html/rfc8705#section-2.2
+func (f *Fosite) matchClientCertificateKey(ctx context.Context, client OpenIDConnectClient, cert *x509.Certificate) error {
+	expected, err := (&jose.JSONWebKey{Key: cert.PublicKey}).Thumbprint(crypto.SHA256)
+	if err != nil {
+		return errorsx.WithStack(ErrInvalidClient.WithHint("Unable to compute the thumbprint of the client certificate's public key.").WithWrap(err).WithDebug(err.Error()))
+	}
+
+	matches := func(set *jose.JSONWebKeySet) bool {
+		for _, key := range set.Keys {
+			public := key.Public()
+			if thumbprint, err := public.Thumbprint(crypto.SHA256); err == nil && bytes.Equal(thumbprint, expected) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if set := client.GetJSONWebKeys(); set != nil {
+		if matches(set) {
+			return nil
+		}
+	} else if location := client.GetJSONWebKeysURI(); len(location) > 0 {
+		for _, forceRefresh := range []bool{false, true} {
+			set, err := f.Config.GetJWKSFetcherStrategy(ctx).Resolve(ctx, location, forceRefresh)
+			if err != nil {
+				return err
+			}
+			if matches(set) {
+				return nil
+			}
+		}
+	} else {
+		return errorsx.WithStack(ErrInvalidClient.WithHint("The OAuth 2.0 Client has no JSON Web Keys set registered, but they are needed to complete the request."))
+	}
+
+	return errorsx.WithStack(ErrInvalidClient.WithHint("The public key of the client certificate is not registered for the OAuth 2.0 Client."))
+}
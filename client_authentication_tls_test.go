@@ -0,0 +1,154 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ory/fosite"
+	"github.com/ory/fosite/internal/gen"
+	"github.com/ory/fosite/storage"
+)
+
+func TestAuthenticateClientWithMutualTLS(t *testing.T) {
+	key := gen.MustES256Key()
+	cert := gen.MustSelfSignedCertificate(key, "foo")
+	otherCert := gen.MustSelfSignedCertificate(gen.MustES256Key(), "bar")
+
+	withCert := func(cert *x509.Certificate) *http.Request {
+		r := new(http.Request)
+		if cert != nil {
+			r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+		}
+		return r
+	}
+
+	newClient := func(method string) *DefaultOpenIDConnectClient {
+		return &DefaultOpenIDConnectClient{
+			DefaultClient:           &DefaultClient{ID: "foo"},
+			TokenEndpointAuthMethod: method,
+			TLSClientAuthSubjectDN:  cert.Subject.String(),
+			JSONWebKeys: &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+				{KeyID: "foo", Use: "sig", Key: key.Public()},
+			}},
+		}
+	}
+
+	form := url.Values{"client_id": {"foo"}}
+	for k, tc := range []struct {
+		d         string
+		client    *DefaultOpenIDConnectClient
+		r         *http.Request
+		form      url.Values
+		expectErr error
+	}{
+		{
+			d:      "passes because the subject distinguished name matches",
+			client: newClient(ClientAuthMethodTLS),
+			r:      withCert(cert),
+			form:   form,
+		},
+		{
+			d:         "fails because the subject distinguished name does not match",
+			client:    newClient(ClientAuthMethodTLS),
+			r:         withCert(otherCert),
+			form:      form,
+			expectErr: ErrInvalidClient,
+		},
+		{
+			d: "fails because no subject distinguished name is registered",
+			client: func() *DefaultOpenIDConnectClient {
+				c := newClient(ClientAuthMethodTLS)
+				c.TLSClientAuthSubjectDN = ""
+				return c
+			}(),
+			r:         withCert(cert),
+			form:      form,
+			expectErr: ErrInvalidClient,
+		},
+		{
+			d:         "fails because no certificate was presented",
+			client:    newClient(ClientAuthMethodTLS),
+			r:         withCert(nil),
+			form:      form,
+			expectErr: ErrInvalidClient,
+		},
+		{
+			d:         "fails because the client secret is sent instead",
+			client:    newClient(ClientAuthMethodTLS),
+			r:         withCert(cert),
+			form:      url.Values{"client_id": {"foo"}, "client_secret": {"bar"}},
+			expectErr: ErrInvalidClient,
+		},
+		{
+			d:      "passes because the public key of the self-signed certificate is registered",
+			client: newClient(ClientAuthMethodSelfSignedTLS),
+			r:      withCert(cert),
+			form:   form,
+		},
+		{
+			d:         "fails because the public key of the self-signed certificate is not registered",
+			client:    newClient(ClientAuthMethodSelfSignedTLS),
+			r:         withCert(otherCert),
+			form:      form,
+			expectErr: ErrInvalidClient,
+		},
+		{
+			d: "fails because no JSON Web Keys are registered",
+			client: func() *DefaultOpenIDConnectClient {
+				c := newClient(ClientAuthMethodSelfSignedTLS)
+				c.JSONWebKeys = nil
+				return c
+			}(),
+			r:         withCert(cert),
+			form:      form,
+			expectErr: ErrInvalidClient,
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, tc.d), func(t *testing.T) {
+			store := storage.NewMemoryStore()
+			store.Clients[tc.client.ID] = tc.client
+			f := &Fosite{Store: store, Config: &Config{}}
+
+			c, err := f.AuthenticateClient(context.Background(), tc.r, tc.form)
+			if tc.expectErr != nil {
+				require.ErrorIs(t, err, tc.expectErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.client, c)
+		})
+	}
+}
+
+func TestClientCertificateFromHeader(t *testing.T) {
+	cert := gen.MustSelfSignedCertificate(gen.MustES256Key(), "foo")
+	extract := ClientCertificateFromHeader("X-Client-Cert")
+
+	r := &http.Request{Header: http.Header{}}
+	actual, err := extract(r)
+	require.NoError(t, err)
+	assert.Nil(t, actual)
+
+	r.Header.Set("X-Client-Cert", url.QueryEscape(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))))
+	actual, err = extract(r)
+	require.NoError(t, err)
+	assert.Equal(t, cert.Raw, actual.Raw)
+
+	r.Header.Set("X-Client-Cert", "not-a-certificate")
+	_, err = extract(r)
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+}
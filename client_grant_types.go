@@ -0,0 +1,52 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import "context"
+
+// EmptyClientGrantTypesPolicy determines which grant types a client may use if it did not register any.
+type EmptyClientGrantTypesPolicy int
+
+const (
+	// EmptyClientGrantTypesAuthorizationCode permits the grant types returned by Client.GetGrantTypes, which is only
+	// the "authorization_code" grant for a DefaultClient as defined in
+	// https://openid.net/specs/openid-connect-registration-1_0.html#ClientMetadata.
+	EmptyClientGrantTypesAuthorizationCode EmptyClientGrantTypesPolicy = iota
+
+	// EmptyClientGrantTypesDenyAll denies every grant type.
+	EmptyClientGrantTypesDenyAll
+
+	// EmptyClientGrantTypesAllowStandard permits the StandardGrantTypes.
+	EmptyClientGrantTypesAllowStandard
+)
+
+// StandardGrantTypes are the grant types defined in https://tools.ietf.org/html/rfc6749.
+var StandardGrantTypes = Arguments{
+	string(GrantTypeAuthorizationCode),
+	string(GrantTypeImplicit),
+	string(GrantTypePassword),
+	string(GrantTypeClientCredentials),
+	string(GrantTypeRefreshToken),
+}
+
+// ClientWithRegisteredGrantTypes is a client which tells the grant types it registered apart from the grant types
+// assumed if it did not register any.
+type ClientWithRegisteredGrantTypes interface {
+	// GetRegisteredGrantTypes returns the grant types the client registered, which may be empty.
+	GetRegisteredGrantTypes() Arguments
+}
+
+// ClientHasGrantType returns true if the client may use the grant type. If the client did not register any grant
+// types, the configured EmptyClientGrantTypesPolicy applies.
+func ClientHasGrantType(ctx context.Context, config EmptyClientGrantTypesPolicyProvider, client Client, grantType string) bool {
+	if c, ok := client.(ClientWithRegisteredGrantTypes); ok && len(c.GetRegisteredGrantTypes()) == 0 {
+		switch config.GetEmptyClientGrantTypesPolicy(ctx) {
+		case EmptyClientGrantTypesDenyAll:
+			return false
+		case EmptyClientGrantTypesAllowStandard:
+			return StandardGrantTypes.Has(grantType)
+		}
+	}
+	return client.GetGrantTypes().Has(grantType)
+}
@@ -0,0 +1,64 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/ory/fosite"
+)
+
+func TestClientHasGrantType(t *testing.T) {
+	for k, c := range []struct {
+		d      string
+		policy EmptyClientGrantTypesPolicy
+		client Client
+		expect map[string]bool
+	}{
+		{
+			d:      "client without grant types may only use the authorization code grant by default",
+			policy: EmptyClientGrantTypesAuthorizationCode,
+			client: &DefaultClient{ID: "foo"},
+			expect: map[string]bool{"authorization_code": true, "client_credentials": false, "refresh_token": false},
+		},
+		{
+			d:      "client without grant types is denied every grant",
+			policy: EmptyClientGrantTypesDenyAll,
+			client: &DefaultClient{ID: "foo"},
+			expect: map[string]bool{"authorization_code": false, "client_credentials": false, "refresh_token": false},
+		},
+		{
+			d:      "client without grant types may use the standard grants",
+			policy: EmptyClientGrantTypesAllowStandard,
+			client: &DefaultOpenIDConnectClient{DefaultClient: &DefaultClient{ID: "foo"}},
+			expect: map[string]bool{
+				"authorization_code":           true,
+				"implicit":                     true,
+				"password":                     true,
+				"client_credentials":           true,
+				"refresh_token":                true,
+				string(GrantTypeDeviceCode):    false,
+				string(GrantTypeJWTBearer):     false,
+				string(GrantTypeTokenExchange): false,
+			},
+		},
+		{
+			d:      "the policy does not apply to clients with grant types",
+			policy: EmptyClientGrantTypesAllowStandard,
+			client: &DefaultClient{ID: "foo", GrantTypes: []string{"client_credentials"}},
+			expect: map[string]bool{"authorization_code": false, "client_credentials": true},
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.d), func(t *testing.T) {
+			config := &Config{EmptyClientGrantTypesPolicy: c.policy}
+			for grantType, expected := range c.expect {
+				assert.Equal(t, expected, ClientHasGrantType(context.Background(), config, c.client, grantType), grantType)
+			}
+		})
+	}
+}
@@ -23,7 +23,8 @@ type ClientWithCustomTokenLifespans interface {
 // precedence over fosite's instance-wide default lifespan, but it may be
 // overridden by a session's expires_at claim.
 //
-// The OIDC Hybrid grant type inherits token lifespan configuration from the implicit grant.
+// The OIDC Hybrid grant type inherits token lifespan configuration from the implicit grant. The token exchange
+// grant does not issue refresh tokens.
 type ClientLifespanConfig struct {
 	AuthorizationCodeGrantAccessTokenLifespan  *time.Duration `json:"authorization_code_grant_access_token_lifespan"`
 	AuthorizationCodeGrantIDTokenLifespan      *time.Duration `json:"authorization_code_grant_id_token_lifespan"`
@@ -37,6 +38,9 @@ type ClientLifespanConfig struct {
 	RefreshTokenGrantIDTokenLifespan           *time.Duration `json:"refresh_token_grant_id_token_lifespan"`
 	RefreshTokenGrantAccessTokenLifespan       *time.Duration `json:"refresh_token_grant_access_token_lifespan"`
 	RefreshTokenGrantRefreshTokenLifespan      *time.Duration `json:"refresh_token_grant_refresh_token_lifespan"`
+	DeviceCodeGrantAccessTokenLifespan         *time.Duration `json:"device_code_grant_access_token_lifespan"`
+	DeviceCodeGrantRefreshTokenLifespan        *time.Duration `json:"device_code_grant_refresh_token_lifespan"`
+	TokenExchangeGrantAccessTokenLifespan      *time.Duration `json:"token_exchange_grant_access_token_lifespan"`
 	//Hybrid grant tokens are not independently configurable, see the comment above.
 }
 
@@ -95,6 +99,16 @@ func (c *DefaultClientWithCustomTokenLifespans) GetEffectiveLifespan(gt GrantTyp
 		} else if tt == RefreshToken {
 			cl = c.TokenLifespans.RefreshTokenGrantRefreshTokenLifespan
 		}
+	} else if gt == GrantTypeDeviceCode {
+		if tt == AccessToken {
+			cl = c.TokenLifespans.DeviceCodeGrantAccessTokenLifespan
+		} else if tt == RefreshToken {
+			cl = c.TokenLifespans.DeviceCodeGrantRefreshTokenLifespan
+		}
+	} else if gt == GrantTypeTokenExchange {
+		if tt == AccessToken {
+			cl = c.TokenLifespans.TokenExchangeGrantAccessTokenLifespan
+		}
 	}
 
 	if cl == nil {
@@ -27,3 +27,21 @@ func TestDefaultClientWithCustomTokenLifespans(t *testing.T) {
 	require.Equal(t, customLifespan, GetEffectiveLifespan(clc, GrantTypeImplicit, IDToken, time.Minute*42))
 	var _ ClientWithCustomTokenLifespans = clc
 }
+
+func TestGetEffectiveLifespanPerGrantRefreshTokens(t *testing.T) {
+	authorizeCodeLifespan := 24 * time.Hour
+	deviceCodeLifespan := 30 * 24 * time.Hour
+	clc := &DefaultClientWithCustomTokenLifespans{
+		DefaultClient: &DefaultClient{},
+		TokenLifespans: &ClientLifespanConfig{
+			AuthorizationCodeGrantRefreshTokenLifespan: &authorizeCodeLifespan,
+			DeviceCodeGrantRefreshTokenLifespan:        &deviceCodeLifespan,
+		},
+	}
+
+	assert.Equal(t, authorizeCodeLifespan, GetEffectiveLifespan(clc, GrantTypeAuthorizationCode, RefreshToken, time.Hour))
+	assert.Equal(t, deviceCodeLifespan, GetEffectiveLifespan(clc, GrantTypeDeviceCode, RefreshToken, time.Hour))
+	assert.Equal(t, time.Hour, GetEffectiveLifespan(clc, GrantTypePassword, RefreshToken, time.Hour))
+	assert.Equal(t, time.Hour, GetEffectiveLifespan(clc, GrantTypeTokenExchange, RefreshToken, time.Hour))
+	assert.Equal(t, time.Hour, GetEffectiveLifespan(clc, GrantTypeDeviceCode, AccessToken, time.Hour))
+}
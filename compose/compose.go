@@ -44,6 +44,9 @@ func Compose(config *fosite.Config, storage interface{}, strategy interface{}, f
 		if th, ok := res.(fosite.TokenEndpointHandler); ok {
 			config.TokenEndpointHandlers.Append(th)
 		}
+		if td, ok := res.(fosite.TokenEndpointDecorator); ok {
+			config.TokenEndpointDecorators.Append(td)
+		}
 		if tv, ok := res.(fosite.TokenIntrospector); ok {
 			config.TokenIntrospectionHandlers.Append(tv)
 		}
@@ -53,8 +56,12 @@ func Compose(config *fosite.Config, storage interface{}, strategy interface{}, f
 		if ph, ok := res.(fosite.PushedAuthorizeEndpointHandler); ok {
 			config.PushedAuthorizeEndpointHandlers.Append(ph)
 		}
+		if dh, ok := res.(fosite.DeviceEndpointHandler); ok {
+			config.DeviceEndpointHandlers.Append(dh)
+		}
 	}
 
+	f.IndexTokenEndpointHandlers(context.Background())
 	return f
 }
 
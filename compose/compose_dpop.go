@@ -0,0 +1,17 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package compose
+
+import (
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/dpop"
+)
+
+// DPoPFactory creates a token endpoint decorator which binds access tokens to the key of a DPoP proof (RFC9449).
+func DPoPFactory(config fosite.Configurator, storage interface{}, strategy interface{}) interface{} {
+	return &dpop.Handler{
+		Storage: storage.(dpop.ProofStorage),
+		Config:  config,
+	}
+}
@@ -77,10 +77,13 @@ func OAuth2ResourceOwnerPasswordCredentialsFactory(config fosite.Configurator, s
 
 // OAuth2TokenRevocationFactory creates an OAuth2 token revocation handler.
 func OAuth2TokenRevocationFactory(config fosite.Configurator, storage interface{}, strategy interface{}) interface{} {
+	denylist, _ := storage.(oauth2.JWTDenylistStorage)
 	return &oauth2.TokenRevocationHandler{
 		TokenRevocationStorage: storage.(oauth2.TokenRevocationStorage),
 		AccessTokenStrategy:    strategy.(oauth2.AccessTokenStrategy),
 		RefreshTokenStrategy:   strategy.(oauth2.RefreshTokenStrategy),
+		Config:                 config,
+		JWTDenylistStorage:     denylist,
 	}
 }
 
@@ -99,11 +102,14 @@ func OAuth2TokenIntrospectionFactory(config fosite.Configurator, storage interfa
 // statelessly, meaning it uses only the data available in the JWT itself, and does not access the
 // storage implementation at all.
 //
-// Due to the stateless nature of this factory, THE BUILT-IN REVOCATION MECHANISMS WILL NOT WORK.
-// If you need revocation, you can validate JWTs statefully, using the other factories.
+// Due to the stateless nature of this factory, THE BUILT-IN REVOCATION MECHANISMS WILL NOT WORK,
+// unless the storage implements oauth2.JWTDenylistStorage, in which case revoked access tokens are
+// looked up by their "jti". Otherwise, you can validate JWTs statefully, using the other factories.
 func OAuth2StatelessJWTIntrospectionFactory(config fosite.Configurator, storage interface{}, strategy interface{}) interface{} {
+	denylist, _ := storage.(oauth2.JWTDenylistStorage)
 	return &oauth2.StatelessJWTValidator{
-		Signer: strategy.(jwt.Signer),
-		Config: config,
+		Signer:             strategy.(jwt.Signer),
+		Config:             config,
+		JWTDenylistStorage: denylist,
 	}
 }
@@ -12,8 +12,10 @@ import (
 // RFC7523AssertionGrantFactory creates an OAuth2 Authorize JWT Grant (using JWTs as Authorization Grants) handler
 // and registers an access token, refresh token and authorize code validator.
 func RFC7523AssertionGrantFactory(config fosite.Configurator, storage interface{}, strategy interface{}) interface{} {
+	assertionStorage, _ := storage.(rfc7523.RFC7523AssertionStorage)
 	return &rfc7523.Handler{
-		Storage: storage.(rfc7523.RFC7523KeyStorage),
+		Storage:          storage.(rfc7523.RFC7523KeyStorage),
+		AssertionStorage: assertionStorage,
 		HandleHelper: &oauth2.HandleHelper{
 			AccessTokenStrategy: strategy.(oauth2.AccessTokenStrategy),
 			AccessTokenStorage:  storage.(oauth2.AccessTokenStorage),
@@ -0,0 +1,37 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package compose
+
+import (
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/oauth2"
+	"github.com/ory/fosite/handler/rfc8628"
+)
+
+// RFC8628DeviceFactory creates the device authorization endpoint handler. The strategy must implement
+// rfc8628.RFC8628CodeStrategy, see NewDeviceStrategy, and the storage must implement rfc8628.RFC8628CodeStorage.
+// The factory is not part of ComposeAllEnabled and has to be passed to Compose explicitly.
+func RFC8628DeviceFactory(config fosite.Configurator, storage interface{}, strategy interface{}) interface{} {
+	return &rfc8628.DeviceAuthHandler{
+		Strategy: strategy.(rfc8628.RFC8628CodeStrategy),
+		Storage:  storage.(rfc8628.RFC8628CodeStorage),
+		Config:   config,
+	}
+}
+
+// RFC8628DeviceAuthorizationTokenFactory creates the token endpoint handler of the device authorization grant. Like
+// RFC8628DeviceFactory, it requires device code storage and has to be passed to Compose explicitly.
+func RFC8628DeviceAuthorizationTokenFactory(config fosite.Configurator, storage interface{}, strategy interface{}) interface{} {
+	return &rfc8628.DeviceCodeTokenEndpointHandler{
+		DeviceCodeStrategy:   strategy.(rfc8628.DeviceCodeStrategy),
+		AccessTokenStrategy:  strategy.(oauth2.AccessTokenStrategy),
+		RefreshTokenStrategy: strategy.(oauth2.RefreshTokenStrategy),
+		Storage: storage.(interface {
+			rfc8628.DeviceCodeStorage
+			oauth2.AccessTokenStorage
+			oauth2.RefreshTokenStorage
+		}),
+		Config: config,
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package compose
+
+import (
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/oauth2"
+	"github.com/ory/fosite/handler/rfc8693"
+)
+
+// RFC8693TokenExchangeFactory creates an OAuth 2.0 Token Exchange handler. Subject and actor tokens are validated
+// using the token introspection handlers, so an introspection factory must be registered as well.
+func RFC8693TokenExchangeFactory(config fosite.Configurator, storage interface{}, strategy interface{}) interface{} {
+	// The handler only issues tokens of the JWT token type if it can tell that the access token strategy issues JWTs.
+	accessTokenStrategy := strategy.(oauth2.AccessTokenStrategy)
+	if cs, ok := strategy.(*CommonStrategy); ok {
+		accessTokenStrategy = cs.CoreStrategy
+	}
+
+	return &rfc8693.Handler{
+		TokenValidator: &rfc8693.IntrospectionTokenValidator{Config: config},
+		HandleHelper: &oauth2.HandleHelper{
+			AccessTokenStrategy: accessTokenStrategy,
+			AccessTokenStorage:  storage.(oauth2.AccessTokenStorage),
+			Config:              config,
+		},
+		Config: config,
+	}
+}
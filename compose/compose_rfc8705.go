@@ -0,0 +1,17 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package compose
+
+import (
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/rfc8705"
+)
+
+// RFC8705CertificateBoundTokenFactory creates a token endpoint decorator which binds the tokens issued to clients
+// authenticating with mutual TLS to the client certificate (RFC8705).
+func RFC8705CertificateBoundTokenFactory(config fosite.Configurator, storage interface{}, strategy interface{}) interface{} {
+	return &rfc8705.Handler{
+		Config: config,
+	}
+}
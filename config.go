@@ -7,6 +7,7 @@ import (
 	"context"
 	"hash"
 	"html/template"
+	"io"
 	"net/url"
 	"time"
 
@@ -28,6 +29,18 @@ type RefreshTokenLifespanProvider interface {
 	GetRefreshTokenLifespan(ctx context.Context) time.Duration
 }
 
+// RefreshTokenLineageRetentionProvider returns the provider for configuring how long inactive refresh tokens are
+// retained for reuse detection.
+type RefreshTokenLineageRetentionProvider interface {
+	// GetRefreshTokenLineageMaxAge returns how long refresh tokens are retained after they became inactive. Zero
+	// retains them indefinitely.
+	GetRefreshTokenLineageMaxAge(ctx context.Context) time.Duration
+
+	// GetRefreshTokenLineageMaxCount returns how many inactive refresh tokens are retained per token chain. Zero
+	// retains all of them.
+	GetRefreshTokenLineageMaxCount(ctx context.Context) int
+}
+
 // AccessTokenLifespanProvider returns the provider for configuring the access token lifespan.
 type AccessTokenLifespanProvider interface {
 	// GetAccessTokenLifespan returns the access token lifespan.
@@ -46,18 +59,65 @@ type IDTokenLifespanProvider interface {
 	GetIDTokenLifespan(ctx context.Context) time.Duration
 }
 
+// IDTokenMaxAudiencesProvider returns the provider for configuring the maximum number of ID token audiences.
+type IDTokenMaxAudiencesProvider interface {
+	// GetIDTokenMaxAudiences returns the maximum number of audiences of an ID token. A value of zero or less disables
+	// the limit.
+	GetIDTokenMaxAudiences(ctx context.Context) int
+}
+
+// IDTokenDropExcessAudiencesProvider returns the provider for configuring how ID tokens with too many audiences are
+// handled.
+type IDTokenDropExcessAudiencesProvider interface {
+	// GetIDTokenDropExcessAudiences returns true if the audiences exceeding the maximum are dropped from ID tokens, and
+	// false if such ID tokens are rejected.
+	GetIDTokenDropExcessAudiences(ctx context.Context) bool
+}
+
 // ScopeStrategyProvider returns the provider for configuring the scope strategy.
 type ScopeStrategyProvider interface {
 	// GetScopeStrategy returns the scope strategy.
 	GetScopeStrategy(ctx context.Context) ScopeStrategy
 }
 
+// MaxScopeCountProvider returns the provider for configuring the maximum number of scopes of a request.
+type MaxScopeCountProvider interface {
+	// GetMaxScopeCount returns the maximum number of scopes which may be requested or granted. A value of zero
+	// or less disables the limit.
+	GetMaxScopeCount(ctx context.Context) int
+}
+
+// MaxTokenRequestBytesProvider returns the provider for configuring the maximum size of token requests.
+type MaxTokenRequestBytesProvider interface {
+	// GetMaxTokenRequestBytes returns the maximum size in bytes of the body of a token request.
+	GetMaxTokenRequestBytes(ctx context.Context) int64
+}
+
+// MaxAudienceLengthProvider returns the provider for configuring the maximum length of an audience.
+type MaxAudienceLengthProvider interface {
+	// GetMaxAudienceLength returns the maximum length of each granted audience. A value of zero or less disables
+	// the limit.
+	GetMaxAudienceLength(ctx context.Context) int
+}
+
 // AudienceStrategyProvider returns the provider for configuring the audience strategy.
 type AudienceStrategyProvider interface {
 	// GetAudienceStrategy returns the audience strategy.
 	GetAudienceStrategy(ctx context.Context) AudienceMatchingStrategy
 }
 
+// SubjectValidatorProvider returns the provider for configuring the subject validator.
+type SubjectValidatorProvider interface {
+	// GetSubjectValidator returns the subject validator.
+	GetSubjectValidator(ctx context.Context) SubjectValidator
+}
+
+// ResourceStrategyProvider returns the provider for configuring the resource indicator strategy.
+type ResourceStrategyProvider interface {
+	// GetResourceStrategy returns the resource indicator strategy.
+	GetResourceStrategy(ctx context.Context) ResourceMatchingStrategy
+}
+
 // RedirectSecureCheckerProvider returns the provider for configuring the redirect URL security validator.
 type RedirectSecureCheckerProvider interface {
 	// GetRedirectSecureChecker returns the redirect URL security validator.
@@ -70,6 +130,41 @@ type RefreshTokenScopesProvider interface {
 	GetRefreshTokenScopes(ctx context.Context) []string
 }
 
+// RefreshTokenScopeStrategyProvider returns the provider for configuring the refresh token scope strategy.
+type RefreshTokenScopeStrategyProvider interface {
+	// GetRefreshTokenScopeStrategy returns the strategy deciding which scopes are granted by a refresh token grant.
+	GetRefreshTokenScopeStrategy(ctx context.Context) RefreshTokenScopeStrategy
+}
+
+// UnknownScopePolicyProvider returns the provider for configuring how scopes the client is not allowed to request
+// are treated.
+type UnknownScopePolicyProvider interface {
+	// GetUnknownScopePolicy returns the unknown scope policy.
+	GetUnknownScopePolicy(ctx context.Context) UnknownScopePolicy
+}
+
+// EnforceOfflineAccessConsentProvider returns the provider for configuring the enforcement of consent for the
+// OpenID Connect "offline_access" scope.
+type EnforceOfflineAccessConsentProvider interface {
+	// GetEnforceOfflineAccessConsent returns true if "offline_access" only yields a refresh token when the
+	// authorization request contained "prompt=consent" or the consent was remembered.
+	GetEnforceOfflineAccessConsent(ctx context.Context) bool
+}
+
+// AuthorizeParameterReuseWindowProvider returns the provider for configuring the detection of reused "state" and
+// "nonce" values.
+type AuthorizeParameterReuseWindowProvider interface {
+	// GetAuthorizeParameterReuseWindow returns the window within which a client must not reuse the "state" or
+	// "nonce" of a previous authorization request. A zero value disables the check.
+	GetAuthorizeParameterReuseWindow(ctx context.Context) time.Duration
+}
+
+// DisableSpaceDelimitedAudienceProvider returns the provider for configuring how the "audience" parameter is parsed.
+type DisableSpaceDelimitedAudienceProvider interface {
+	// GetDisableSpaceDelimitedAudience returns true if a single "audience" parameter must not be split by space.
+	GetDisableSpaceDelimitedAudience(ctx context.Context) bool
+}
+
 // DisableRefreshTokenValidationProvider returns the provider for configuring the refresh token validation.
 type DisableRefreshTokenValidationProvider interface {
 	// GetDisableRefreshTokenValidation returns the disable refresh token validation flag.
@@ -100,6 +195,29 @@ type IDTokenIssuerProvider interface {
 	GetIDTokenIssuer(ctx context.Context) string
 }
 
+// RequireJWTAccessTokenAudienceProvider returns the provider for configuring whether JWT access tokens must have an
+// audience.
+type RequireJWTAccessTokenAudienceProvider interface {
+	// GetRequireJWTAccessTokenAudience returns whether issuing a JWT access token without a granted audience fails.
+	GetRequireJWTAccessTokenAudience(ctx context.Context) bool
+}
+
+// IncludeJWTAccessTokenAuthClaimsProvider returns the provider for configuring whether JWT access tokens include the
+// authentication time and context of the session.
+type IncludeJWTAccessTokenAuthClaimsProvider interface {
+	// GetIncludeJWTAccessTokenAuthClaims returns whether the "auth_time" and "acr" claims of the ID token claims of
+	// the session are included in JWT access tokens.
+	GetIncludeJWTAccessTokenAuthClaims(ctx context.Context) bool
+}
+
+// JWTAudienceClaimNameProvider returns the provider for configuring the name of the audience claim of JWT access
+// tokens.
+type JWTAudienceClaimNameProvider interface {
+	// GetJWTAudienceClaimName returns the name of the claim which carries the audience of JWT access tokens in
+	// addition to the standard "aud" claim.
+	GetJWTAudienceClaimName(ctx context.Context) string
+}
+
 // JWTScopeFieldProvider returns the provider for configuring the JWT scope field.
 type JWTScopeFieldProvider interface {
 	// GetJWTScopeField returns the JWT scope field.
@@ -112,12 +230,60 @@ type AllowedPromptsProvider interface {
 	GetAllowedPrompts(ctx context.Context) []string
 }
 
+// AllowedResponseTypesProvider returns the provider for configuring the allowed response types.
+type AllowedResponseTypesProvider interface {
+	// GetAllowedResponseTypes returns the response types the authorization endpoint accepts. An empty list allows all
+	// response types.
+	GetAllowedResponseTypes(ctx context.Context) []string
+}
+
+// KeepAccessTokensOnSessionRevocationProvider returns the provider for configuring whether revoking a session keeps
+// its access tokens.
+type KeepAccessTokensOnSessionRevocationProvider interface {
+	// GetKeepAccessTokensOnSessionRevocation returns whether RevokeSession only revokes the refresh token of the
+	// session and keeps its access tokens valid until they expire.
+	GetKeepAccessTokensOnSessionRevocation(ctx context.Context) bool
+}
+
+// EnforceUniqueJTIProvider returns the provider for configuring the enforcement of unique JWT IDs.
+type EnforceUniqueJTIProvider interface {
+	// GetEnforceUniqueJTI returns whether the "jti" of each issued JWT access token is recorded, so that no two
+	// tokens share a "jti".
+	GetEnforceUniqueJTI(ctx context.Context) bool
+}
+
+// EnforceEssentialClaimsProvider returns the provider for configuring the enforcement of essential claims.
+type EnforceEssentialClaimsProvider interface {
+	// GetEnforceEssentialClaims returns whether issuing an ID token fails if an essential claim requested using the
+	// "claims" parameter can not be fulfilled.
+	GetEnforceEssentialClaims(ctx context.Context) bool
+}
+
+// SubjectIdentifierAlgorithmProvider returns the provider for configuring the subject identifier algorithm.
+type SubjectIdentifierAlgorithmProvider interface {
+	// GetSubjectIdentifierAlgorithm returns the subject identifier algorithm ("public" or "pairwise") used for
+	// clients which did not register a "subject_type".
+	GetSubjectIdentifierAlgorithm(ctx context.Context) string
+}
+
+// PairwiseSubjectSaltProvider returns the provider for configuring the salt of pairwise subject identifiers.
+type PairwiseSubjectSaltProvider interface {
+	// GetPairwiseSubjectSalt returns the salt pairwise subject identifiers are derived with.
+	GetPairwiseSubjectSalt(ctx context.Context) []byte
+}
+
 // MinParameterEntropyProvider returns the provider for configuring the minimum parameter entropy.
 type MinParameterEntropyProvider interface {
 	// GetMinParameterEntropy returns the minimum parameter entropy.
 	GetMinParameterEntropy(_ context.Context) int
 }
 
+// MinNonceEntropyProvider returns the provider for configuring the minimum entropy of the nonce parameter.
+type MinNonceEntropyProvider interface {
+	// GetMinNonceEntropy returns the minimum number of characters of the "nonce" parameter.
+	GetMinNonceEntropy(ctx context.Context) int
+}
+
 // SanitationAllowedProvider returns the provider for configuring the sanitation white list.
 type SanitationAllowedProvider interface {
 	// GetSanitationWhiteList is a whitelist of form values that are required by the token endpoint. These values
@@ -132,6 +298,13 @@ type OmitRedirectScopeParamProvider interface {
 	GetOmitRedirectScopeParam(ctx context.Context) bool
 }
 
+// ReportRequestedScopeCasingProvider returns the provider for configuring the casing of scopes in responses.
+type ReportRequestedScopeCasingProvider interface {
+	// GetReportRequestedScopeCasing returns whether granted scopes are reported in responses in the casing the client
+	// requested them in.
+	GetReportRequestedScopeCasing(ctx context.Context) bool
+}
+
 // EnforcePKCEProvider returns the provider for configuring the enforcement of PKCE.
 type EnforcePKCEProvider interface {
 	// GetEnforcePKCE returns the enforcement of PKCE.
@@ -144,6 +317,21 @@ type EnforcePKCEForPublicClientsProvider interface {
 	GetEnforcePKCEForPublicClients(ctx context.Context) bool
 }
 
+// RestrictPublicClientRefreshTokensProvider returns the provider for configuring refresh token issuance to public
+// clients.
+type RestrictPublicClientRefreshTokensProvider interface {
+	// GetRestrictPublicClientRefreshTokens returns true if refresh tokens are only issued to public clients when
+	// PKCE is enforced for them.
+	GetRestrictPublicClientRefreshTokens(ctx context.Context) bool
+}
+
+// PKCEMinCodeChallengeLengthProvider returns the provider for configuring the minimum PKCE code challenge length.
+type PKCEMinCodeChallengeLengthProvider interface {
+	// GetPKCEMinCodeChallengeLength returns the minimum length of a PKCE code challenge, or a negative value if
+	// the length should not be checked.
+	GetPKCEMinCodeChallengeLength(ctx context.Context) int
+}
+
 // EnablePKCEPlainChallengeMethodProvider returns the provider for configuring the enable PKCE plain challenge method.
 type EnablePKCEPlainChallengeMethodProvider interface {
 	// GetEnablePKCEPlainChallengeMethod returns the enable PKCE plain challenge method.
@@ -168,12 +356,41 @@ type GrantTypeJWTBearerIssuedDateOptionalProvider interface {
 	GetGrantTypeJWTBearerIssuedDateOptional(ctx context.Context) bool
 }
 
+// GrantTypeJWTBearerHashAssertionProvider returns the provider for configuring whether only the hash of a JWT
+// bearer assertion is recorded.
+type GrantTypeJWTBearerHashAssertionProvider interface {
+	// GetGrantTypeJWTBearerHashAssertion returns true if only the SHA-256 hash of an assertion should be recorded
+	// instead of the raw assertion.
+	GetGrantTypeJWTBearerHashAssertion(ctx context.Context) bool
+}
+
+// GrantTypeJWTBearerExtraClaimsProvider returns the provider for configuring which private claims of a JWT bearer
+// assertion are copied into the extra claims of the session.
+type GrantTypeJWTBearerExtraClaimsProvider interface {
+	// GetGrantTypeJWTBearerExtraClaims returns a map from the names of the allowed private claims of an assertion to
+	// the names of the extra session claims they are copied to.
+	GetGrantTypeJWTBearerExtraClaims(ctx context.Context) map[string]string
+}
+
 // GetJWTMaxDurationProvider returns the provider for configuring the JWT max duration.
 type GetJWTMaxDurationProvider interface {
 	// GetJWTMaxDuration returns the JWT max duration.
 	GetJWTMaxDuration(ctx context.Context) time.Duration
 }
 
+// MaxAssertionExpiryFromNowProvider returns the provider for configuring how far in the future the expiry of a JWT
+// bearer assertion may be.
+type MaxAssertionExpiryFromNowProvider interface {
+	// GetMaxAssertionExpiryFromNow returns how far from now the "exp" claim of an assertion may be. Zero disables the check.
+	GetMaxAssertionExpiryFromNow(ctx context.Context) time.Duration
+}
+
+// DPoPProofMaxAgeProvider returns the provider for configuring the maximum age of a DPoP proof.
+type DPoPProofMaxAgeProvider interface {
+	// GetDPoPProofMaxAge returns the maximum age of a DPoP proof, measured from its "iat" claim.
+	GetDPoPProofMaxAge(ctx context.Context) time.Duration
+}
+
 // TokenEntropyProvider returns the provider for configuring the token entropy.
 type TokenEntropyProvider interface {
 	// GetTokenEntropy returns the token entropy.
@@ -222,6 +439,13 @@ type HTTPClientProvider interface {
 	GetHTTPClient(ctx context.Context) *retryablehttp.Client
 }
 
+// ClientCertificateExtractorProvider returns the provider for configuring how client certificates are read from
+// requests.
+type ClientCertificateExtractorProvider interface {
+	// GetClientCertificateExtractor returns the client certificate extractor.
+	GetClientCertificateExtractor(ctx context.Context) ClientCertificateExtractor
+}
+
 // ClientAuthenticationStrategyProvider returns the provider for configuring the client authentication strategy.
 type ClientAuthenticationStrategyProvider interface {
 	// GetClientAuthenticationStrategy returns the client authentication strategy.
@@ -263,6 +487,40 @@ type TokenEndpointHandlersProvider interface {
 	GetTokenEndpointHandlers(ctx context.Context) TokenEndpointHandlers
 }
 
+// TokenEndpointDecoratorsProvider returns the provider for configuring the token endpoint decorators.
+type TokenEndpointDecoratorsProvider interface {
+	// GetTokenEndpointDecorators returns the token endpoint decorators.
+	GetTokenEndpointDecorators(ctx context.Context) TokenEndpointDecorators
+}
+
+// IntrospectionCacheProvider returns the provider for configuring the introspection cache.
+type IntrospectionCacheProvider interface {
+	// GetIntrospectionCache returns the introspection cache, or nil if introspection results are not cached.
+	GetIntrospectionCache(ctx context.Context) IntrospectionCache
+}
+
+// IntrospectionRespondInactiveOnErrorProvider returns the provider for configuring introspection error responses.
+type IntrospectionRespondInactiveOnErrorProvider interface {
+	// GetIntrospectionRespondInactiveOnError returns true if introspection requests which fail for reasons other
+	// than failed caller authentication are answered with an inactive token.
+	GetIntrospectionRespondInactiveOnError(ctx context.Context) bool
+}
+
+// RejectTokensWithEmptySubjectProvider returns the provider for configuring whether tokens without a subject are
+// considered inactive.
+type RejectTokensWithEmptySubjectProvider interface {
+	// GetRejectTokensWithEmptySubject returns true if introspected tokens whose session has an empty subject are
+	// considered inactive.
+	GetRejectTokensWithEmptySubject(ctx context.Context) bool
+}
+
+// RejectUnknownTokenTypeHintProvider returns the provider for configuring how unknown token type hints are handled.
+type RejectUnknownTokenTypeHintProvider interface {
+	// GetRejectUnknownTokenTypeHint returns true if revocation and introspection requests with a "token_type_hint"
+	// other than "access_token" or "refresh_token" are rejected instead of ignoring the hint.
+	GetRejectUnknownTokenTypeHint(ctx context.Context) bool
+}
+
 // TokenIntrospectionHandlersProvider returns the provider for configuring the token introspection handlers.
 type TokenIntrospectionHandlersProvider interface {
 	// GetTokenIntrospectionHandlers returns the token introspection handlers.
@@ -275,6 +533,12 @@ type RevocationHandlersProvider interface {
 	GetRevocationHandlers(ctx context.Context) RevocationHandlers
 }
 
+// DeviceEndpointHandlersProvider returns the provider for configuring the device authorization endpoint handlers.
+type DeviceEndpointHandlersProvider interface {
+	// GetDeviceEndpointHandlers returns the handlers.
+	GetDeviceEndpointHandlers(ctx context.Context) DeviceEndpointHandlers
+}
+
 // PushedAuthorizeEndpointHandlersProvider returns the provider for configuring the PAR handlers.
 type PushedAuthorizeRequestHandlersProvider interface {
 	// GetPushedAuthorizeEndpointHandlers returns the handlers.
@@ -291,6 +555,149 @@ type UseLegacyErrorFormatProvider interface {
 	GetUseLegacyErrorFormat(ctx context.Context) bool
 }
 
+// LoggerProvider returns the provider for configuring the logger.
+type LoggerProvider interface {
+	// GetLogger returns the logger. It never returns nil.
+	GetLogger(ctx context.Context) Logger
+}
+
+// ClientAuthenticatedHookProvider returns the provider for configuring the hook invoked after client authentication.
+type ClientAuthenticatedHookProvider interface {
+	// GetClientAuthenticatedHook returns the hook invoked after a client authenticated at the token endpoint, or nil
+	// if no hook is set.
+	GetClientAuthenticatedHook(ctx context.Context) ClientAuthenticatedHook
+}
+
+// ExpiresInReporterProvider returns the provider for configuring the "expires_in" value of access token responses.
+type ExpiresInReporterProvider interface {
+	// GetExpiresInReporter returns the function which computes the reported "expires_in" value of access token
+	// responses, or nil if the actual lifetime is reported.
+	GetExpiresInReporter(ctx context.Context) ExpiresInReporter
+}
+
+// IntrospectionResponseModifierProvider returns the provider for configuring the introspection response modifier.
+type IntrospectionResponseModifierProvider interface {
+	// GetIntrospectionResponseModifier returns the function which modifies introspection responses before they are
+	// written, or nil if the responses are written unmodified.
+	GetIntrospectionResponseModifier(ctx context.Context) IntrospectionResponseModifier
+}
+
+// RequireTLSProvider returns the provider for configuring whether the token and introspection endpoints require TLS.
+type RequireTLSProvider interface {
+	// GetRequireTLS returns true if requests to the token and introspection endpoints must be sent over TLS.
+	GetRequireTLS(ctx context.Context) bool
+}
+
+// TLSForwardedProtoHeaderProvider returns the provider for configuring the header which carries the protocol of
+// requests forwarded by a TLS-terminating proxy.
+type TLSForwardedProtoHeaderProvider interface {
+	// GetTLSForwardedProtoHeader returns the name of the header which carries the protocol used by the client, or an
+	// empty string if no such header is trusted.
+	GetTLSForwardedProtoHeader(ctx context.Context) string
+}
+
+// AuthorizationDetailValidatorsProvider returns the provider for configuring the supported types of rich
+// authorization requests (https://datatracker.ietf.org/doc/html/rfc9396).
+type AuthorizationDetailValidatorsProvider interface {
+	// GetAuthorizationDetailValidators returns a map from the supported authorization details types to their
+	// validators.
+	GetAuthorizationDetailValidators(ctx context.Context) map[string]AuthorizationDetailValidator
+}
+
+// EmptyClientGrantTypesPolicyProvider returns the provider for configuring the grant types of clients which did not
+// register any.
+type EmptyClientGrantTypesPolicyProvider interface {
+	// GetEmptyClientGrantTypesPolicy returns the policy for clients which did not register any grant types.
+	GetEmptyClientGrantTypesPolicy(ctx context.Context) EmptyClientGrantTypesPolicy
+}
+
+// GrantTypeEnabledProvider returns the provider for configuring which grant types are enabled at the token endpoint.
+type GrantTypeEnabledProvider interface {
+	// GetGrantTypeEnabled returns the function which decides whether a grant type is enabled, or nil if all grant
+	// types of the registered handlers are enabled.
+	GetGrantTypeEnabled(ctx context.Context) func(ctx context.Context, grantType string) bool
+}
+
+// AuditSinkProvider returns the provider for configuring the audit trail of issued and revoked tokens.
+type AuditSinkProvider interface {
+	// GetAuditSink returns the sink of the audit events, or nil if no audit trail is recorded.
+	GetAuditSink(ctx context.Context) AuditSink
+}
+
+// StrictAuditProvider returns the provider for configuring whether audit failures block responses.
+type StrictAuditProvider interface {
+	// GetStrictAudit returns true if tokens must not be issued or revoked without recording the audit event.
+	GetStrictAudit(ctx context.Context) bool
+}
+
+// IntrospectionIssuerProvider returns the provider for configuring whether introspection responses contain the issuer.
+type IntrospectionIssuerProvider interface {
+	// GetIntrospectionIncludeIssuer returns true if the access token issuer is included as "iss" in introspection
+	// responses.
+	GetIntrospectionIncludeIssuer(ctx context.Context) bool
+}
+
+// BodyClientIDMatchProvider returns the provider for configuring whether the client_id in the request body must match
+// the client authenticated by HTTP Basic Authentication.
+type BodyClientIDMatchProvider interface {
+	// GetRequireBodyClientIDMatch returns true if a client_id in the request body must match the client ID of the HTTP
+	// Authorization header.
+	GetRequireBodyClientIDMatch(ctx context.Context) bool
+}
+
+// CallerNetworkExtractorProvider returns the provider for configuring how the network of the caller is read from
+// requests which use network-bound tokens.
+type CallerNetworkExtractorProvider interface {
+	// GetCallerNetworkExtractor returns the caller network extractor.
+	GetCallerNetworkExtractor(ctx context.Context) CallerNetworkExtractor
+}
+
+// TemporarilyUnavailableRetryAfterProvider returns the provider for configuring the delay after which clients may retry
+// requests which failed because of a retriable error.
+type TemporarilyUnavailableRetryAfterProvider interface {
+	// GetTemporarilyUnavailableRetryAfter returns the delay used if the RetriableError does not provide one.
+	GetTemporarilyUnavailableRetryAfter(ctx context.Context) time.Duration
+}
+
+// TokenExchangeRequestedTokenTypesProvider returns the provider for configuring which token types may be requested
+// using token exchange.
+type TokenExchangeRequestedTokenTypesProvider interface {
+	// GetTokenExchangeRequestedTokenTypes returns the allowed values of the "requested_token_type" parameter of token
+	// exchange requests, or nil if only access tokens may be requested.
+	GetTokenExchangeRequestedTokenTypes(ctx context.Context) []string
+}
+
+// RedirectURIMatchingStrategyProvider returns the provider for configuring how redirect URIs are matched.
+type RedirectURIMatchingStrategyProvider interface {
+	// GetRedirectURIMatchingStrategy returns the strategy which matches requested and registered redirect URIs.
+	GetRedirectURIMatchingStrategy(ctx context.Context) RedirectURIMatchingStrategy
+}
+
+// RejectRedirectURIFragmentsProvider returns the provider for configuring how redirect URIs with a fragment are handled.
+type RejectRedirectURIFragmentsProvider interface {
+	// GetRejectRedirectURIFragments returns true if authorize requests are rejected if their redirect URI or one of
+	// the redirect URIs of the client contains a fragment component, even an empty one.
+	GetRejectRedirectURIFragments(ctx context.Context) bool
+}
+
+// RandomSourceProvider returns the provider for configuring the source of randomness.
+type RandomSourceProvider interface {
+	// GetRandomSource returns the source of randomness of generated tokens, identifiers and salts.
+	GetRandomSource(ctx context.Context) io.Reader
+}
+
+// WWWAuthenticateSchemesProvider returns the provider for configuring the challenges of unauthorized error responses.
+type WWWAuthenticateSchemesProvider interface {
+	// GetWWWAuthenticateSchemes returns the authentication schemes for which a WWW-Authenticate challenge is sent.
+	GetWWWAuthenticateSchemes(ctx context.Context) []string
+}
+
+// ErrorStatusMapperProvider returns the provider for configuring the HTTP status of error responses.
+type ErrorStatusMapperProvider interface {
+	// GetErrorStatusMapper returns the error status mapper, or nil if the default status codes should be used.
+	GetErrorStatusMapper(ctx context.Context) ErrorStatusMapper
+}
+
 // PushedAuthorizeRequestConfigProvider is the configuration provider for pushed
 // authorization request.
 type PushedAuthorizeRequestConfigProvider interface {
@@ -306,3 +713,38 @@ type PushedAuthorizeRequestConfigProvider interface {
 	// must contain the PAR request_uri.
 	EnforcePushedAuthorize(ctx context.Context) bool
 }
+
+// RequestObjectConfigProvider is the configuration provider for request objects passed by
+// reference (https://datatracker.ietf.org/doc/html/rfc9101#section-5.2).
+type RequestObjectConfigProvider interface {
+	// GetRequestObjectMaxSize returns the maximum size in bytes of a request object fetched from a 'request_uri'.
+	GetRequestObjectMaxSize(ctx context.Context) int64
+
+	// GetRequestObjectContentTypes returns the content types a 'request_uri' may respond with. An empty list
+	// allows any content type.
+	GetRequestObjectContentTypes(ctx context.Context) []string
+}
+
+// DeviceAuthorizeConfigProvider is the configuration provider for the device authorization grant
+// (https://datatracker.ietf.org/doc/html/rfc8628).
+type DeviceAuthorizeConfigProvider interface {
+	// GetDeviceAndUserCodeLifespan returns how long device and user codes are valid.
+	GetDeviceAndUserCodeLifespan(ctx context.Context) time.Duration
+
+	// GetDeviceAuthTokenPollingInterval returns the minimum amount of time the client must wait between polling
+	// requests to the token endpoint.
+	GetDeviceAuthTokenPollingInterval(ctx context.Context) time.Duration
+
+	// GetDeviceVerificationURL returns the end-user verification URI on the authorization server.
+	GetDeviceVerificationURL(ctx context.Context) string
+}
+
+// JWTSecuredAuthorizeResponseConfigProvider is the configuration provider for JWT secured authorization
+// responses (https://openid.net/specs/oauth-v2-jarm.html).
+type JWTSecuredAuthorizeResponseConfigProvider interface {
+	// GetJWTSecuredAuthorizeResponseIssuer returns the issuer of the authorization response JWT.
+	GetJWTSecuredAuthorizeResponseIssuer(ctx context.Context) string
+
+	// GetJWTSecuredAuthorizeResponseLifespan returns how long the authorization response JWT is valid.
+	GetJWTSecuredAuthorizeResponseLifespan(ctx context.Context) time.Duration
+}
@@ -5,8 +5,10 @@ package fosite
 
 import (
 	"context"
+	"crypto/rand"
 	"hash"
 	"html/template"
+	"io"
 	"net/url"
 	"time"
 
@@ -18,36 +20,83 @@ import (
 )
 
 const (
-	defaultPARPrefix          = "urn:ietf:params:oauth:request_uri:"
-	defaultPARContextLifetime = 5 * time.Minute
+	defaultPARPrefix            = "urn:ietf:params:oauth:request_uri:"
+	defaultPARContextLifetime   = 5 * time.Minute
+	defaultRequestObjectMaxSize = 64 << 10
+	defaultTokenRequestMaxBytes = 1 << 20
+
+	defaultJWTSecuredAuthorizeResponseLifespan = 10 * time.Minute
+
+	// defaultPKCEMinCodeChallengeLength is the minimum length of a code verifier, see
+	// https://datatracker.ietf.org/doc/html/rfc7636#section-4.1
+	defaultPKCEMinCodeChallengeLength = 43
+
+	defaultDeviceAndUserCodeLifespan      = 10 * time.Minute
+	defaultDeviceAuthTokenPollingInterval = 5 * time.Second
+
+	defaultTemporarilyUnavailableRetryAfter = 5 * time.Second
 )
 
 var (
 	_ AuthorizeCodeLifespanProvider                = (*Config)(nil)
 	_ RefreshTokenLifespanProvider                 = (*Config)(nil)
+	_ RefreshTokenLineageRetentionProvider         = (*Config)(nil)
 	_ AccessTokenLifespanProvider                  = (*Config)(nil)
 	_ ScopeStrategyProvider                        = (*Config)(nil)
+	_ MaxScopeCountProvider                        = (*Config)(nil)
+	_ MaxTokenRequestBytesProvider                 = (*Config)(nil)
+	_ MaxAudienceLengthProvider                    = (*Config)(nil)
 	_ AudienceStrategyProvider                     = (*Config)(nil)
+	_ ResourceStrategyProvider                     = (*Config)(nil)
+	_ DisableSpaceDelimitedAudienceProvider        = (*Config)(nil)
+	_ SubjectValidatorProvider                     = (*Config)(nil)
 	_ RedirectSecureCheckerProvider                = (*Config)(nil)
 	_ RefreshTokenScopesProvider                   = (*Config)(nil)
+	_ RefreshTokenScopeStrategyProvider            = (*Config)(nil)
+	_ UnknownScopePolicyProvider                   = (*Config)(nil)
+	_ EnforceOfflineAccessConsentProvider          = (*Config)(nil)
+	_ AuthorizeParameterReuseWindowProvider        = (*Config)(nil)
+	_ RequestObjectConfigProvider                  = (*Config)(nil)
+	_ JWTSecuredAuthorizeResponseConfigProvider    = (*Config)(nil)
+	_ DeviceAuthorizeConfigProvider                = (*Config)(nil)
 	_ DisableRefreshTokenValidationProvider        = (*Config)(nil)
 	_ AccessTokenIssuerProvider                    = (*Config)(nil)
 	_ JWTScopeFieldProvider                        = (*Config)(nil)
+	_ JWTAudienceClaimNameProvider                 = (*Config)(nil)
+	_ RequireJWTAccessTokenAudienceProvider        = (*Config)(nil)
+	_ IncludeJWTAccessTokenAuthClaimsProvider      = (*Config)(nil)
 	_ AllowedPromptsProvider                       = (*Config)(nil)
+	_ AllowedResponseTypesProvider                 = (*Config)(nil)
+	_ KeepAccessTokensOnSessionRevocationProvider  = (*Config)(nil)
+	_ EnforceUniqueJTIProvider                     = (*Config)(nil)
 	_ OmitRedirectScopeParamProvider               = (*Config)(nil)
+	_ ReportRequestedScopeCasingProvider           = (*Config)(nil)
 	_ MinParameterEntropyProvider                  = (*Config)(nil)
+	_ MinNonceEntropyProvider                      = (*Config)(nil)
+	_ EnforceEssentialClaimsProvider               = (*Config)(nil)
+	_ SubjectIdentifierAlgorithmProvider           = (*Config)(nil)
+	_ PairwiseSubjectSaltProvider                  = (*Config)(nil)
 	_ SanitationAllowedProvider                    = (*Config)(nil)
 	_ EnforcePKCEForPublicClientsProvider          = (*Config)(nil)
 	_ EnablePKCEPlainChallengeMethodProvider       = (*Config)(nil)
+	_ PKCEMinCodeChallengeLengthProvider           = (*Config)(nil)
+	_ RestrictPublicClientRefreshTokensProvider    = (*Config)(nil)
 	_ EnforcePKCEProvider                          = (*Config)(nil)
 	_ GrantTypeJWTBearerCanSkipClientAuthProvider  = (*Config)(nil)
 	_ GrantTypeJWTBearerIDOptionalProvider         = (*Config)(nil)
 	_ GrantTypeJWTBearerIssuedDateOptionalProvider = (*Config)(nil)
+	_ GrantTypeJWTBearerExtraClaimsProvider        = (*Config)(nil)
+	_ GrantTypeJWTBearerHashAssertionProvider      = (*Config)(nil)
 	_ GetJWTMaxDurationProvider                    = (*Config)(nil)
+	_ MaxAssertionExpiryFromNowProvider            = (*Config)(nil)
+	_ DPoPProofMaxAgeProvider                      = (*Config)(nil)
 	_ IDTokenLifespanProvider                      = (*Config)(nil)
+	_ IDTokenMaxAudiencesProvider                  = (*Config)(nil)
+	_ IDTokenDropExcessAudiencesProvider           = (*Config)(nil)
 	_ IDTokenIssuerProvider                        = (*Config)(nil)
 	_ JWKSFetcherStrategyProvider                  = (*Config)(nil)
 	_ ClientAuthenticationStrategyProvider         = (*Config)(nil)
+	_ ClientCertificateExtractorProvider           = (*Config)(nil)
 	_ SendDebugMessagesToClientsProvider           = (*Config)(nil)
 	_ ResponseModeHandlerExtensionProvider         = (*Config)(nil)
 	_ MessageCatalogProvider                       = (*Config)(nil)
@@ -58,10 +107,37 @@ var (
 	_ HMACHashingProvider                          = (*Config)(nil)
 	_ AuthorizeEndpointHandlersProvider            = (*Config)(nil)
 	_ TokenEndpointHandlersProvider                = (*Config)(nil)
+	_ TokenEndpointDecoratorsProvider              = (*Config)(nil)
 	_ TokenIntrospectionHandlersProvider           = (*Config)(nil)
+	_ IntrospectionRespondInactiveOnErrorProvider  = (*Config)(nil)
+	_ RejectUnknownTokenTypeHintProvider           = (*Config)(nil)
+	_ RejectTokensWithEmptySubjectProvider         = (*Config)(nil)
+	_ IntrospectionCacheProvider                   = (*Config)(nil)
 	_ RevocationHandlersProvider                   = (*Config)(nil)
 	_ PushedAuthorizeRequestHandlersProvider       = (*Config)(nil)
+	_ DeviceEndpointHandlersProvider               = (*Config)(nil)
 	_ PushedAuthorizeRequestConfigProvider         = (*Config)(nil)
+	_ ErrorStatusMapperProvider                    = (*Config)(nil)
+	_ WWWAuthenticateSchemesProvider               = (*Config)(nil)
+	_ LoggerProvider                               = (*Config)(nil)
+	_ ClientAuthenticatedHookProvider              = (*Config)(nil)
+	_ ExpiresInReporterProvider                    = (*Config)(nil)
+	_ IntrospectionResponseModifierProvider        = (*Config)(nil)
+	_ RequireTLSProvider                           = (*Config)(nil)
+	_ TLSForwardedProtoHeaderProvider              = (*Config)(nil)
+	_ AuthorizationDetailValidatorsProvider        = (*Config)(nil)
+	_ EmptyClientGrantTypesPolicyProvider          = (*Config)(nil)
+	_ GrantTypeEnabledProvider                     = (*Config)(nil)
+	_ AuditSinkProvider                            = (*Config)(nil)
+	_ StrictAuditProvider                          = (*Config)(nil)
+	_ IntrospectionIssuerProvider                  = (*Config)(nil)
+	_ BodyClientIDMatchProvider                    = (*Config)(nil)
+	_ CallerNetworkExtractorProvider               = (*Config)(nil)
+	_ TemporarilyUnavailableRetryAfterProvider     = (*Config)(nil)
+	_ TokenExchangeRequestedTokenTypesProvider     = (*Config)(nil)
+	_ RedirectURIMatchingStrategyProvider          = (*Config)(nil)
+	_ RandomSourceProvider                         = (*Config)(nil)
+	_ RejectRedirectURIFragmentsProvider           = (*Config)(nil)
 )
 
 type Config struct {
@@ -75,21 +151,58 @@ type Config struct {
 	// refresh tokens that never expire.
 	RefreshTokenLifespan time.Duration
 
+	// RefreshTokenLineageMaxAge sets how long rotated or revoked refresh tokens are retained to detect their reuse.
+	// Reusing a refresh token which is no longer retained is rejected, but no longer revokes the token chain.
+	// Defaults to zero, which retains them indefinitely.
+	RefreshTokenLineageMaxAge time.Duration
+
+	// RefreshTokenLineageMaxCount sets how many rotated or revoked refresh tokens are retained per token chain to
+	// detect their reuse. Defaults to zero, which retains all of them.
+	RefreshTokenLineageMaxCount int
+
 	// AuthorizeCodeLifespan sets how long an authorize code is going to be valid. Defaults to fifteen minutes.
 	AuthorizeCodeLifespan time.Duration
 
 	// IDTokenLifespan sets the default id token lifetime. Defaults to one hour.
 	IDTokenLifespan time.Duration
 
+	// IDTokenMaxAudiences limits the number of audiences of an ID token, which bounds its size. The audience of the
+	// client is always kept. Defaults to zero, which disables the limit.
+	IDTokenMaxAudiences int
+
+	// IDTokenDropExcessAudiences drops the audiences exceeding IDTokenMaxAudiences from ID tokens instead of rejecting
+	// them. Defaults to false.
+	IDTokenDropExcessAudiences bool
+
 	// IDTokenIssuer sets the default issuer of the ID Token.
 	IDTokenIssuer string
 
-	// HashCost sets the cost of the password hashing cost. Defaults to 12.
+	// HashCost sets the cost of the password hashing cost. Defaults to 12. TuneBCryptCost determines a cost suitable for
+	// the hardware.
 	HashCost int
 
 	// DisableRefreshTokenValidation sets the introspection endpoint to disable refresh token validation.
 	DisableRefreshTokenValidation bool
 
+	// IntrospectionRespondInactiveOnError, if set to true, answers introspection requests which fail for reasons
+	// other than failed caller authentication, for example malformed requests, with {"active": false} and HTTP 200
+	// instead of an error.
+	IntrospectionRespondInactiveOnError bool
+
+	// RejectUnknownTokenTypeHint, if set to true, rejects revocation and introspection requests whose "token_type_hint"
+	// is neither "access_token" nor "refresh_token" with ErrInvalidRequest. Defaults to false, which ignores the hint
+	// and looks the token up as any type, as recommended by RFC7009 and RFC7662.
+	RejectUnknownTokenTypeHint bool
+
+	// RejectTokensWithEmptySubject, if set to true, considers introspected tokens whose session has an empty subject
+	// inactive, as such tokens usually indicate a bug. Tokens issued using the client credentials grant have no
+	// subject unless the session sets one. Defaults to false.
+	RejectTokensWithEmptySubject bool
+
+	// IntrospectionCache, if set, caches the requests of introspected access tokens, for example a
+	// MemoryIntrospectionCache. Defaults to nil, which does not cache introspection results.
+	IntrospectionCache IntrospectionCache
+
 	// SendDebugMessagesToClients if set to true, includes error debug messages in response payloads. Be aware that sensitive
 	// data may be exposed, depending on your implementation of Fosite. Such sensitive data might include database error
 	// codes or other information. Proceed with caution!
@@ -98,21 +211,72 @@ type Config struct {
 	// ScopeStrategy sets the scope strategy that should be supported, for example fosite.WildcardScopeStrategy.
 	ScopeStrategy ScopeStrategy
 
+	// MaxScopeCount limits the number of scopes which may be requested or granted, and thus stored on a session.
+	// Defaults to zero, which disables the limit.
+	MaxScopeCount int
+
+	// MaxTokenRequestBytes limits the size of the body of token requests, which is enforced while the body is read.
+	// Defaults to 1 MiB.
+	MaxTokenRequestBytes int64
+
 	// AudienceMatchingStrategy sets the audience matching strategy that should be supported, defaults to fosite.DefaultsAudienceMatchingStrategy.
 	AudienceMatchingStrategy AudienceMatchingStrategy
 
+	// NormalizeAudienceTrailingSlashes, if set to true, removes trailing slashes from audiences before they are
+	// compared by the AudienceMatchingStrategy. Defaults to false.
+	NormalizeAudienceTrailingSlashes bool
+
+	// MaxAudienceLength limits the length of each granted audience, which bounds the size of issued tokens.
+	// Defaults to zero, which disables the limit.
+	MaxAudienceLength int
+
+	// DisableSpaceDelimitedAudience, if set to true, treats every "audience" request parameter as a single audience.
+	// By default, a single "audience" parameter is split by space while repeated parameters are taken as-is.
+	DisableSpaceDelimitedAudience bool
+
+	// ResourceMatchingStrategy sets the resource indicator (RFC8707) matching strategy, defaults to fosite.DefaultResourceMatchingStrategy.
+	ResourceMatchingStrategy ResourceMatchingStrategy
+
+	// SubjectValidator validates subjects before they are set on the session, for example fosite.DefaultSubjectValidator.
+	// Defaults to accepting any subject.
+	SubjectValidator SubjectValidator
+
 	// EnforcePKCE, if set to true, requires clients to perform authorize code flows with PKCE. Defaults to false.
 	EnforcePKCE bool
 
 	// EnforcePKCEForPublicClients requires only public clients to use PKCE with the authorize code flow. Defaults to false.
 	EnforcePKCEForPublicClients bool
 
+	// RestrictPublicClientRefreshTokens, if set to true, issues refresh tokens to public clients only if PKCE is
+	// enforced for them using EnforcePKCE or EnforcePKCEForPublicClients. Otherwise, public clients only receive an
+	// access token. Refresh tokens are always rotated by the refresh token grant. Defaults to false.
+	RestrictPublicClientRefreshTokens bool
+
 	// EnablePKCEPlainChallengeMethod sets whether or not to allow the plain challenge method (S256 should be used whenever possible, plain is really discouraged). Defaults to false.
 	EnablePKCEPlainChallengeMethod bool
 
-	// AllowedPromptValues sets which OpenID Connect prompt values the server supports. Defaults to []string{"login", "none", "consent", "select_account"}.
+	// PKCEMinCodeChallengeLength sets the minimum length of a PKCE code challenge. Defaults to 43, the minimum
+	// length of a code verifier. Set to -1 to disable the check. S256 challenges must always be exactly 43
+	// characters long.
+	PKCEMinCodeChallengeLength int
+
+	// AllowedPromptValues sets which OpenID Connect prompt values the server supports. Defaults to []string{"login", "none", "consent", "select_account", "create"}.
 	AllowedPromptValues []string
 
+	// AllowedResponseTypes, if set, restricts the response types the authorization endpoint accepts, regardless of
+	// the handlers and clients, for example []string{"code"} to disable the implicit and hybrid flows. Composite
+	// response types must be listed as such, e.g. "code id_token". Defaults to allowing all response types.
+	AllowedResponseTypes []string
+
+	// KeepAccessTokensOnSessionRevocation, if set to true, makes RevokeSession only revoke the refresh token of the
+	// session, so that its access tokens stay valid until they expire. Defaults to false, which revokes them as well.
+	KeepAccessTokensOnSessionRevocation bool
+
+	// EnforceUniqueJTI, if set to true, records the "jti" of each issued JWT access token in the JTI storage of the
+	// JWT strategy for as long as the token is valid, and generates a new "jti" if it is already in use. Defaults to
+	// false.
+	EnforceUniqueJTI bool
+
 	// TokenURL is the the URL of the Authorization Server's Token Endpoint. If the authorization server is intended
 	// to be compatible with the private_key_jwt client authentication method (see http://openid.net/specs/openid-connect-core-1_0.html#CodeFlowAuth),
 	// this value MUST be set.
@@ -132,13 +296,145 @@ type Config struct {
 	// RefreshTokenScopes defines which OAuth scopes will be given refresh tokens during the authorization code grant exchange. This defaults to "offline" and "offline_access". When set to an empty array, all exchanges will be given refresh tokens.
 	RefreshTokenScopes []string
 
+	// RefreshTokenScopeStrategy decides which scopes are granted to the access token issued by a refresh token
+	// grant. Defaults to DefaultRefreshTokenScopeStrategy.
+	RefreshTokenScopeStrategy RefreshTokenScopeStrategy
+
+	// UnknownScopePolicy decides whether requests containing scopes the client is not allowed to request are rejected
+	// or whether these scopes are dropped. Defaults to UnknownScopePolicyReject.
+	UnknownScopePolicy UnknownScopePolicy
+
+	// EnforceOfflineAccessConsent, if set to true, only issues a refresh token for the "offline_access" scope if the
+	// authorization request contained "prompt=consent" or the consent was remembered, as required by OpenID Connect.
+	// Defaults to false, which issues the refresh token regardless (lenient).
+	EnforceOfflineAccessConsent bool
+
+	// AuthorizeParameterReuseWindow, if set, rejects authorization requests which reuse the "state" or "nonce" of
+	// an earlier request of the same client within this window. The store must implement
+	// fosite.AuthorizeParameterReuseStorage. Defaults to zero, which disables the check.
+	AuthorizeParameterReuseWindow time.Duration
+
 	// MinParameterEntropy controls the minimum size of state and nonce parameters. Defaults to fosite.MinParameterEntropy.
 	MinParameterEntropy int
 
+	// MinNonceEntropy controls the minimum size of the nonce parameter of OpenID Connect flows. Defaults to the value of
+	// MinParameterEntropy.
+	MinNonceEntropy int
+
+	// EnforceEssentialClaims, if set to true, fails issuing an ID token with ErrInvalidRequest if an essential claim
+	// requested using the "claims" parameter is missing or does not have the requested value. OpenID Connect does not
+	// require this and recommends to omit such claims instead, which is the default.
+	EnforceEssentialClaims bool
+
+	// SubjectIdentifierAlgorithm is the subject identifier algorithm, "public" or "pairwise", used for clients which
+	// did not register a "subject_type". It applies to ID tokens and the userinfo endpoint only, JWT access tokens
+	// and introspection responses always contain the subject of the session. Defaults to "public".
+	SubjectIdentifierAlgorithm string
+
+	// PairwiseSubjectSalt is the secret salt pairwise subject identifiers are derived with. It must be set if
+	// pairwise subject identifiers are used, and must not change, as that would change all pairwise subjects.
+	PairwiseSubjectSalt []byte
+
 	// UseLegacyErrorFormat controls whether the legacy error format (with `error_debug`, `error_hint`, ...)
 	// should be used or not.
 	UseLegacyErrorFormat bool
 
+	// ErrorStatusMapper, if set, overrides the HTTP status code of error responses written by WriteAccessError and
+	// WriteAuthorizeError. Defaults to nil, which uses the status code of the error.
+	ErrorStatusMapper ErrorStatusMapper
+
+	// WWWAuthenticateSchemes sets the authentication schemes, for example "Bearer" and "DPoP", for which
+	// WriteAccessError adds a WWW-Authenticate challenge to unauthorized responses caused by an invalid or missing
+	// token. Defaults to none.
+	WWWAuthenticateSchemes []string
+
+	// Logger receives structured events at the decision points of the token endpoint. Defaults to a logger which
+	// discards all events.
+	Logger Logger
+
+	// OnClientAuthenticated is invoked once per token endpoint request after the client authenticated successfully
+	// and before the grant is handled. Defaults to nil, which does not invoke any hook.
+	OnClientAuthenticated ClientAuthenticatedHook
+
+	// ExpiresInReporter, if set, computes the "expires_in" value of access token responses from the actual lifetime
+	// of the access token, for example to report a capped value to legacy clients. This is not standard compliant.
+	// Defaults to nil, which reports the actual lifetime.
+	ExpiresInReporter ExpiresInReporter
+
+	// IntrospectionResponseModifier, if set, is called before an introspection response is written, for example to
+	// add custom fields to it. Defaults to nil, which writes the response unmodified.
+	IntrospectionResponseModifier IntrospectionResponseModifier
+
+	// RequireTLS rejects requests to the token and introspection endpoints which were not sent over TLS. Defaults to
+	// false.
+	RequireTLS bool
+
+	// TLSForwardedProtoHeader is the name of the header, for example "X-Forwarded-Proto", which a TLS-terminating
+	// proxy sets to the protocol used by the client. Only set this if the proxy overwrites the header, because clients
+	// can set it themselves otherwise. Defaults to an empty string, which only accepts requests received over TLS.
+	TLSForwardedProtoHeader string
+
+	// AuthorizationDetailValidators maps the supported types of the "authorization_details" parameter
+	// (https://datatracker.ietf.org/doc/html/rfc9396) to their validators. A nil validator accepts every authorization
+	// detail of its type. Defaults to no supported types, which rejects requests carrying authorization details.
+	AuthorizationDetailValidators map[string]AuthorizationDetailValidator
+
+	// EmptyClientGrantTypesPolicy determines which grant types a client may use if it did not register any. Defaults to
+	// EmptyClientGrantTypesAuthorizationCode, which only permits the "authorization_code" grant.
+	EmptyClientGrantTypesPolicy EmptyClientGrantTypesPolicy
+
+	// GrantTypeEnabled, if set, is consulted for every token request and rejects the request with
+	// "unsupported_grant_type" if it returns false for its grant type, for example to disable a grant at runtime.
+	// Defaults to nil, which enables the grant types of all registered handlers.
+	GrantTypeEnabled func(ctx context.Context, grantType string) bool
+
+	// AuditSink, if set, is called synchronously whenever tokens are issued or revoked. Defaults to nil, which does not
+	// record an audit trail.
+	AuditSink AuditSink
+
+	// StrictAudit responds with an error instead of the issued tokens or the revocation result if the AuditSink fails.
+	// The tokens have been stored at that point, so they are not delivered but remain valid until they expire or
+	// are revoked. Defaults to false, which ignores errors of the AuditSink.
+	StrictAudit bool
+
+	// IntrospectionIncludeIssuer includes the AccessTokenIssuer as "iss" in introspection responses of active tokens,
+	// as expected by https://datatracker.ietf.org/doc/html/rfc9701. Defaults to false, which only includes "iss" if it
+	// is set in the extra claims of the session.
+	IntrospectionIncludeIssuer bool
+
+	// RequireBodyClientIDMatch rejects requests of clients authenticating with HTTP Basic Authentication whose body
+	// contains a different client_id. Defaults to false, which ignores the client_id in the body.
+	RequireBodyClientIDMatch bool
+
+	// CallerNetworkExtractor reads the IP address of the caller from requests which use tokens bound to a network.
+	// Defaults to fosite.DefaultCallerNetworkExtractor, which uses the remote address of the request.
+	CallerNetworkExtractor CallerNetworkExtractor
+
+	// TemporarilyUnavailableRetryAfter is the delay after which clients may retry authorize requests which failed
+	// because of a RetriableError which does not provide a delay. Defaults to 5 seconds.
+	TemporarilyUnavailableRetryAfter time.Duration
+
+	// TokenExchangeRequestedTokenTypes are the allowed values of the "requested_token_type" parameter of token
+	// exchange requests. The token exchange handler issues access tokens, so besides
+	// "urn:ietf:params:oauth:token-type:access_token" only "urn:ietf:params:oauth:token-type:jwt" is supported, which
+	// is rejected unless the access token strategy issues JWTs. Defaults to nil, which only allows access tokens.
+	TokenExchangeRequestedTokenTypes []string
+
+	// RedirectURIMatchingStrategy matches the redirect URIs of authorize requests with the registered redirect URIs.
+	// Strategies can be combined using fosite.ChainRedirectURIMatchingStrategies. Defaults to
+	// fosite.DefaultRedirectURIMatchingStrategy.
+	RedirectURIMatchingStrategy RedirectURIMatchingStrategy
+
+	// RejectRedirectURIFragments rejects authorize requests with invalid_request if their redirect URI or one of the
+	// redirect URIs registered by the client contains a fragment component, even an empty one, see
+	// ValidateRedirectURIs. Otherwise, redirect URIs with a fragment are only rejected as invalid. Defaults to false.
+	RejectRedirectURIFragments bool
+
+	// RandomSource is the source of randomness of generated tokens, identifiers and salts, for example an approved
+	// random number generator in FIPS environments. It must be safe for concurrent use. Defaults to
+	// crypto/rand.Reader.
+	RandomSource io.Reader
+
 	// GrantTypeJWTBearerCanSkipClientAuth indicates, if client authentication can be skipped, when using jwt as assertion.
 	GrantTypeJWTBearerCanSkipClientAuth bool
 
@@ -148,12 +444,33 @@ type Config struct {
 	// GrantTypeJWTBearerIssuedDateOptional indicates, if "iat" (issued at) claim required or not in JWT.
 	GrantTypeJWTBearerIssuedDateOptional bool
 
+	// GrantTypeJWTBearerHashAssertion indicates, if only the hex-encoded SHA-256 hash of a JWT bearer assertion
+	// is passed to the assertion storage instead of the raw assertion. Defaults to false.
+	GrantTypeJWTBearerHashAssertion bool
+
+	// GrantTypeJWTBearerExtraClaims maps the names of private claims of a JWT bearer assertion to the names of the
+	// extra session claims they are copied to, which for example makes them part of the introspection response. Private
+	// claims which are not in this map are ignored. Defaults to no claims being copied.
+	GrantTypeJWTBearerExtraClaims map[string]string
+
 	// GrantTypeJWTBearerMaxDuration sets the maximum time after JWT issued date, during which the JWT is considered valid.
 	GrantTypeJWTBearerMaxDuration time.Duration
 
+	// MaxAssertionExpiryFromNow caps how far from now the "exp" claim of a JWT bearer assertion may be, regardless of its
+	// "iat" claim. Defaults to zero, which disables the check.
+	MaxAssertionExpiryFromNow time.Duration
+
+	// DPoPProofMaxAge sets how old (or how far in the future) the "iat" claim of a DPoP proof may be. Defaults to five minutes.
+	DPoPProofMaxAge time.Duration
+
 	// ClientAuthenticationStrategy indicates the Strategy to authenticate client requests
 	ClientAuthenticationStrategy ClientAuthenticationStrategy
 
+	// ClientCertificateExtractor reads the client certificate used for mutual TLS client authentication from the
+	// request. Defaults to fosite.DefaultClientCertificateExtractor, which uses the certificate of the TLS
+	// connection.
+	ClientCertificateExtractor ClientCertificateExtractor
+
 	// ResponseModeHandlerExtension provides a handler for custom response modes
 	ResponseModeHandlerExtension ResponseModeHandler
 
@@ -166,6 +483,11 @@ type Config struct {
 	// OmitRedirectScopeParam indicates whether the "scope" parameter should be omitted from the redirect URL.
 	OmitRedirectScopeParam bool
 
+	// ReportRequestedScopeCasing, if set to true, reports granted scopes in the "scope" response parameter in the
+	// casing the client requested them in, for example if scopes are normalized to lower case by the scope strategy
+	// or the consent flow. The requests keep the granted scopes as they are. Defaults to false.
+	ReportRequestedScopeCasing bool
+
 	// SanitationWhiteList is a whitelist of form values that are required by the token endpoint. These values
 	// are safe for storage in a database (cleartext).
 	SanitationWhiteList []string
@@ -173,10 +495,23 @@ type Config struct {
 	// JWTScopeClaimKey defines the claim key to be used to set the scope in. Valid fields are "scope" or "scp" or both.
 	JWTScopeClaimKey jwt.JWTScopeFieldEnum
 
+	// JWTAudienceClaimKey defines an additional claim key to set the audience of JWT access tokens in, for resource
+	// servers which do not read the "aud" claim. The "aud" claim is always set. Defaults to "aud".
+	JWTAudienceClaimKey string
+
+	// RequireJWTAccessTokenAudience, if set to true, fails issuing a JWT access token with ErrInvalidRequest if no
+	// audience was granted, which ensures that resource servers can validate the "aud" claim. Defaults to false.
+	RequireJWTAccessTokenAudience bool
+
+	// IncludeJWTAccessTokenAuthClaims, if set to true, includes the "auth_time" and "acr" claims in JWT access tokens
+	// if they are set in the ID token claims of the session. Defaults to false.
+	IncludeJWTAccessTokenAuthClaims bool
+
 	// AccessTokenIssuer is the issuer to be used when generating access tokens.
 	AccessTokenIssuer string
 
-	// ClientSecretsHasher is the hasher used to hash OAuth2 Client Secrets.
+	// ClientSecretsHasher is the hasher used to hash OAuth2 Client Secrets. Defaults to a MultiHasher which creates
+	// bcrypt hashes and compares both bcrypt and argon2id hashes.
 	ClientSecretsHasher Hasher
 
 	// HTTPClient is the HTTP client to use for requests.
@@ -188,6 +523,9 @@ type Config struct {
 	// TokenEndpointHandlers is a list of handlers that are called before the token endpoint is served.
 	TokenEndpointHandlers TokenEndpointHandlers
 
+	// TokenEndpointDecorators is a list of decorators that are called after the token endpoint handlers.
+	TokenEndpointDecorators TokenEndpointDecorators
+
 	// TokenIntrospectionHandlers is a list of handlers that are called before the token introspection endpoint is served.
 	TokenIntrospectionHandlers TokenIntrospectionHandlers
 
@@ -197,6 +535,9 @@ type Config struct {
 	// PushedAuthorizeEndpointHandlers is a list of handlers that are called before the PAR endpoint is served.
 	PushedAuthorizeEndpointHandlers PushedAuthorizeEndpointHandlers
 
+	// DeviceEndpointHandlers is a list of handlers that are called before the device authorization endpoint is served.
+	DeviceEndpointHandlers DeviceEndpointHandlers
+
 	// GlobalSecret is the global secret used to sign and verify signatures.
 	GlobalSecret []byte
 
@@ -215,6 +556,32 @@ type Config struct {
 
 	// IsPushedAuthorizeEnforced enforces pushed authorization request for /authorize
 	IsPushedAuthorizeEnforced bool
+
+	// RequestObjectMaxSize is the maximum size in bytes of a request object fetched from a 'request_uri'.
+	// Defaults to 64 KiB.
+	RequestObjectMaxSize int64
+
+	// RequestObjectContentTypes restricts the content types a 'request_uri' may respond with, for example to
+	// "application/oauth-authz-req+jwt". Defaults to allowing any content type.
+	RequestObjectContentTypes []string
+
+	// JWTSecuredAuthorizeResponseIssuer sets the issuer of JWT secured authorization responses. Defaults to
+	// IDTokenIssuer.
+	JWTSecuredAuthorizeResponseIssuer string
+
+	// JWTSecuredAuthorizeResponseLifespan sets how long a JWT secured authorization response is valid.
+	// Defaults to ten minutes.
+	JWTSecuredAuthorizeResponseLifespan time.Duration
+
+	// DeviceAndUserCodeLifespan sets how long device and user codes are valid. Defaults to ten minutes.
+	DeviceAndUserCodeLifespan time.Duration
+
+	// DeviceAuthTokenPollingInterval sets the minimum amount of time a client must wait between polling requests
+	// to the token endpoint. Defaults to five seconds.
+	DeviceAuthTokenPollingInterval time.Duration
+
+	// DeviceVerificationURL sets the end-user verification URI on the authorization server.
+	DeviceVerificationURL string
 }
 
 func (c *Config) GetGlobalSecret(ctx context.Context) ([]byte, error) {
@@ -225,6 +592,128 @@ func (c *Config) GetUseLegacyErrorFormat(ctx context.Context) bool {
 	return c.UseLegacyErrorFormat
 }
 
+// GetErrorStatusMapper returns the error status mapper.
+func (c *Config) GetErrorStatusMapper(_ context.Context) ErrorStatusMapper {
+	return c.ErrorStatusMapper
+}
+
+// GetWWWAuthenticateSchemes returns the authentication schemes challenged by unauthorized responses.
+func (c *Config) GetWWWAuthenticateSchemes(_ context.Context) []string {
+	return c.WWWAuthenticateSchemes
+}
+
+// GetLogger returns the logger, or a no-op logger if none is set.
+func (c *Config) GetLogger(_ context.Context) Logger {
+	if c.Logger == nil {
+		return noopLogger{}
+	}
+	return c.Logger
+}
+
+// GetClientAuthenticatedHook returns the hook invoked after client authentication.
+func (c *Config) GetClientAuthenticatedHook(_ context.Context) ClientAuthenticatedHook {
+	return c.OnClientAuthenticated
+}
+
+// GetExpiresInReporter returns ExpiresInReporter. Defaults to nil.
+func (c *Config) GetExpiresInReporter(_ context.Context) ExpiresInReporter {
+	return c.ExpiresInReporter
+}
+
+// GetIntrospectionResponseModifier returns IntrospectionResponseModifier. Defaults to nil.
+func (c *Config) GetIntrospectionResponseModifier(_ context.Context) IntrospectionResponseModifier {
+	return c.IntrospectionResponseModifier
+}
+
+// GetRequireTLS returns RequireTLS. Defaults to false.
+func (c *Config) GetRequireTLS(_ context.Context) bool {
+	return c.RequireTLS
+}
+
+// GetTLSForwardedProtoHeader returns TLSForwardedProtoHeader. Defaults to an empty string.
+func (c *Config) GetTLSForwardedProtoHeader(_ context.Context) string {
+	return c.TLSForwardedProtoHeader
+}
+
+// GetAuthorizationDetailValidators returns AuthorizationDetailValidators. Defaults to nil.
+func (c *Config) GetAuthorizationDetailValidators(_ context.Context) map[string]AuthorizationDetailValidator {
+	return c.AuthorizationDetailValidators
+}
+
+// GetEmptyClientGrantTypesPolicy returns EmptyClientGrantTypesPolicy. Defaults to
+// EmptyClientGrantTypesAuthorizationCode.
+func (c *Config) GetEmptyClientGrantTypesPolicy(_ context.Context) EmptyClientGrantTypesPolicy {
+	return c.EmptyClientGrantTypesPolicy
+}
+
+// GetGrantTypeEnabled returns GrantTypeEnabled. Defaults to nil.
+func (c *Config) GetGrantTypeEnabled(_ context.Context) func(ctx context.Context, grantType string) bool {
+	return c.GrantTypeEnabled
+}
+
+// GetAuditSink returns AuditSink. Defaults to nil.
+func (c *Config) GetAuditSink(_ context.Context) AuditSink {
+	return c.AuditSink
+}
+
+// GetStrictAudit returns StrictAudit. Defaults to false.
+func (c *Config) GetStrictAudit(_ context.Context) bool {
+	return c.StrictAudit
+}
+
+// GetIntrospectionIncludeIssuer returns IntrospectionIncludeIssuer. Defaults to false.
+func (c *Config) GetIntrospectionIncludeIssuer(_ context.Context) bool {
+	return c.IntrospectionIncludeIssuer
+}
+
+// GetRequireBodyClientIDMatch returns RequireBodyClientIDMatch. Defaults to false.
+func (c *Config) GetRequireBodyClientIDMatch(_ context.Context) bool {
+	return c.RequireBodyClientIDMatch
+}
+
+// GetCallerNetworkExtractor returns CallerNetworkExtractor. Defaults to fosite.DefaultCallerNetworkExtractor.
+func (c *Config) GetCallerNetworkExtractor(_ context.Context) CallerNetworkExtractor {
+	if c.CallerNetworkExtractor == nil {
+		return DefaultCallerNetworkExtractor
+	}
+	return c.CallerNetworkExtractor
+}
+
+// GetTemporarilyUnavailableRetryAfter returns TemporarilyUnavailableRetryAfter. Defaults to 5 seconds.
+func (c *Config) GetTemporarilyUnavailableRetryAfter(_ context.Context) time.Duration {
+	if c.TemporarilyUnavailableRetryAfter == 0 {
+		return defaultTemporarilyUnavailableRetryAfter
+	}
+	return c.TemporarilyUnavailableRetryAfter
+}
+
+// GetTokenExchangeRequestedTokenTypes returns TokenExchangeRequestedTokenTypes. Defaults to nil.
+func (c *Config) GetTokenExchangeRequestedTokenTypes(_ context.Context) []string {
+	return c.TokenExchangeRequestedTokenTypes
+}
+
+// GetRedirectURIMatchingStrategy returns RedirectURIMatchingStrategy. Defaults to
+// fosite.DefaultRedirectURIMatchingStrategy.
+func (c *Config) GetRedirectURIMatchingStrategy(_ context.Context) RedirectURIMatchingStrategy {
+	if c.RedirectURIMatchingStrategy == nil {
+		return DefaultRedirectURIMatchingStrategy
+	}
+	return c.RedirectURIMatchingStrategy
+}
+
+// GetRejectRedirectURIFragments returns RejectRedirectURIFragments. Defaults to false.
+func (c *Config) GetRejectRedirectURIFragments(_ context.Context) bool {
+	return c.RejectRedirectURIFragments
+}
+
+// GetRandomSource returns RandomSource. Defaults to crypto/rand.Reader.
+func (c *Config) GetRandomSource(_ context.Context) io.Reader {
+	if c.RandomSource == nil {
+		return rand.Reader
+	}
+	return c.RandomSource
+}
+
 func (c *Config) GetRotatedGlobalSecrets(ctx context.Context) ([][]byte, error) {
 	return c.RotatedGlobalSecrets, nil
 }
@@ -241,10 +730,35 @@ func (c *Config) GetTokenEndpointHandlers(ctx context.Context) TokenEndpointHand
 	return c.TokenEndpointHandlers
 }
 
+func (c *Config) GetTokenEndpointDecorators(ctx context.Context) TokenEndpointDecorators {
+	return c.TokenEndpointDecorators
+}
+
 func (c *Config) GetTokenIntrospectionHandlers(ctx context.Context) TokenIntrospectionHandlers {
 	return c.TokenIntrospectionHandlers
 }
 
+// GetIntrospectionCache returns the introspection cache.
+func (c *Config) GetIntrospectionCache(_ context.Context) IntrospectionCache {
+	return c.IntrospectionCache
+}
+
+// GetIntrospectionRespondInactiveOnError returns whether failed introspection requests are answered with an
+// inactive token unless the caller failed to authenticate.
+func (c *Config) GetIntrospectionRespondInactiveOnError(_ context.Context) bool {
+	return c.IntrospectionRespondInactiveOnError
+}
+
+// GetRejectTokensWithEmptySubject returns whether tokens without a subject are considered inactive.
+func (c *Config) GetRejectTokensWithEmptySubject(_ context.Context) bool {
+	return c.RejectTokensWithEmptySubject
+}
+
+// GetRejectUnknownTokenTypeHint returns whether unknown token type hints are rejected instead of ignored.
+func (c *Config) GetRejectUnknownTokenTypeHint(_ context.Context) bool {
+	return c.RejectUnknownTokenTypeHint
+}
+
 func (c *Config) GetRevocationHandlers(ctx context.Context) RevocationHandlers {
 	return c.RevocationHandlers
 }
@@ -258,7 +772,8 @@ func (c *Config) GetHTTPClient(ctx context.Context) *retryablehttp.Client {
 
 func (c *Config) GetSecretsHasher(ctx context.Context) Hasher {
 	if c.ClientSecretsHasher == nil {
-		c.ClientSecretsHasher = &BCrypt{Config: c}
+		bcrypt := &BCrypt{Config: c}
+		c.ClientSecretsHasher = &MultiHasher{Hasher: bcrypt, BCrypt: bcrypt, Argon2id: &Argon2id{Rand: c.GetRandomSource(ctx)}}
 	}
 	return c.ClientSecretsHasher
 }
@@ -292,6 +807,16 @@ func (c *Config) GetGrantTypeJWTBearerIssuedDateOptional(ctx context.Context) bo
 	return c.GrantTypeJWTBearerIssuedDateOptional
 }
 
+// GetGrantTypeJWTBearerHashAssertion returns the GrantTypeJWTBearerHashAssertion field.
+func (c *Config) GetGrantTypeJWTBearerHashAssertion(ctx context.Context) bool {
+	return c.GrantTypeJWTBearerHashAssertion
+}
+
+// GetGrantTypeJWTBearerExtraClaims returns the GrantTypeJWTBearerExtraClaims field.
+func (c *Config) GetGrantTypeJWTBearerExtraClaims(ctx context.Context) map[string]string {
+	return c.GrantTypeJWTBearerExtraClaims
+}
+
 // GetGrantTypeJWTBearerIDOptional returns the GrantTypeJWTBearerIDOptional field.
 func (c *Config) GetGrantTypeJWTBearerIDOptional(ctx context.Context) bool {
 	return c.GrantTypeJWTBearerIDOptional
@@ -307,6 +832,14 @@ func (c *Config) GetEnforcePKCE(ctx context.Context) bool {
 	return c.EnforcePKCE
 }
 
+// GetPKCEMinCodeChallengeLength returns the minimum length of a PKCE code challenge. Defaults to 43.
+func (c *Config) GetPKCEMinCodeChallengeLength(_ context.Context) int {
+	if c.PKCEMinCodeChallengeLength == 0 {
+		return defaultPKCEMinCodeChallengeLength
+	}
+	return c.PKCEMinCodeChallengeLength
+}
+
 // GetEnablePKCEPlainChallengeMethod returns whether or not to allow the plain challenge method (S256 should be used whenever possible, plain is really discouraged).
 func (c *Config) GetEnablePKCEPlainChallengeMethod(ctx context.Context) bool {
 	return c.EnablePKCEPlainChallengeMethod
@@ -317,6 +850,11 @@ func (c *Config) GetEnforcePKCEForPublicClients(ctx context.Context) bool {
 	return c.EnforcePKCEForPublicClients
 }
 
+// GetRestrictPublicClientRefreshTokens returns the value of RestrictPublicClientRefreshTokens.
+func (c *Config) GetRestrictPublicClientRefreshTokens(_ context.Context) bool {
+	return c.RestrictPublicClientRefreshTokens
+}
+
 // GetSanitationWhiteList returns a list of allowed form values that are required by the token endpoint. These values
 // are safe for storage in a database (cleartext).
 func (c *Config) GetSanitationWhiteList(ctx context.Context) []string {
@@ -327,6 +865,11 @@ func (c *Config) GetOmitRedirectScopeParam(ctx context.Context) bool {
 	return c.OmitRedirectScopeParam
 }
 
+// GetReportRequestedScopeCasing returns whether scopes are reported in the requested casing. Defaults to false.
+func (c *Config) GetReportRequestedScopeCasing(_ context.Context) bool {
+	return c.ReportRequestedScopeCasing
+}
+
 func (c *Config) GetAccessTokenIssuer(ctx context.Context) string {
 	return c.AccessTokenIssuer
 }
@@ -335,10 +878,44 @@ func (c *Config) GetJWTScopeField(ctx context.Context) jwt.JWTScopeFieldEnum {
 	return c.JWTScopeClaimKey
 }
 
+// GetJWTAudienceClaimName returns JWTAudienceClaimKey. Defaults to "aud".
+func (c *Config) GetJWTAudienceClaimName(_ context.Context) string {
+	if c.JWTAudienceClaimKey == "" {
+		return "aud"
+	}
+	return c.JWTAudienceClaimKey
+}
+
+// GetRequireJWTAccessTokenAudience returns whether JWT access tokens must have an audience. Defaults to false.
+func (c *Config) GetRequireJWTAccessTokenAudience(_ context.Context) bool {
+	return c.RequireJWTAccessTokenAudience
+}
+
+// GetIncludeJWTAccessTokenAuthClaims returns whether JWT access tokens include the "auth_time" and "acr" claims.
+// Defaults to false.
+func (c *Config) GetIncludeJWTAccessTokenAuthClaims(_ context.Context) bool {
+	return c.IncludeJWTAccessTokenAuthClaims
+}
+
 func (c *Config) GetAllowedPrompts(_ context.Context) []string {
 	return c.AllowedPromptValues
 }
 
+// GetAllowedResponseTypes returns AllowedResponseTypes. Defaults to nil, which allows all response types.
+func (c *Config) GetAllowedResponseTypes(_ context.Context) []string {
+	return c.AllowedResponseTypes
+}
+
+// GetKeepAccessTokensOnSessionRevocation returns KeepAccessTokensOnSessionRevocation. Defaults to false.
+func (c *Config) GetKeepAccessTokensOnSessionRevocation(_ context.Context) bool {
+	return c.KeepAccessTokensOnSessionRevocation
+}
+
+// GetEnforceUniqueJTI returns EnforceUniqueJTI. Defaults to false.
+func (c *Config) GetEnforceUniqueJTI(_ context.Context) bool {
+	return c.EnforceUniqueJTI
+}
+
 // GetScopeStrategy returns the scope strategy to be used. Defaults to glob scope strategy.
 func (c *Config) GetScopeStrategy(_ context.Context) ScopeStrategy {
 	if c.ScopeStrategy == nil {
@@ -347,14 +924,56 @@ func (c *Config) GetScopeStrategy(_ context.Context) ScopeStrategy {
 	return c.ScopeStrategy
 }
 
+// GetMaxScopeCount returns the maximum number of scopes which may be requested or granted.
+func (c *Config) GetMaxScopeCount(_ context.Context) int {
+	return c.MaxScopeCount
+}
+
+// GetMaxTokenRequestBytes returns the maximum size in bytes of the body of a token request. Defaults to 1 MiB.
+func (c *Config) GetMaxTokenRequestBytes(_ context.Context) int64 {
+	if c.MaxTokenRequestBytes <= 0 {
+		return defaultTokenRequestMaxBytes
+	}
+	return c.MaxTokenRequestBytes
+}
+
+// GetDisableSpaceDelimitedAudience returns whether a single "audience" parameter must not be split by space.
+func (c *Config) GetDisableSpaceDelimitedAudience(_ context.Context) bool {
+	return c.DisableSpaceDelimitedAudience
+}
+
 // GetAudienceStrategy returns the scope strategy to be used. Defaults to glob scope strategy.
 func (c *Config) GetAudienceStrategy(_ context.Context) AudienceMatchingStrategy {
 	if c.AudienceMatchingStrategy == nil {
 		c.AudienceMatchingStrategy = DefaultAudienceMatchingStrategy
 	}
+	if c.NormalizeAudienceTrailingSlashes {
+		return TrailingSlashNormalizingAudienceMatchingStrategy(c.AudienceMatchingStrategy)
+	}
 	return c.AudienceMatchingStrategy
 }
 
+// GetMaxAudienceLength returns the maximum length of each granted audience.
+func (c *Config) GetMaxAudienceLength(_ context.Context) int {
+	return c.MaxAudienceLength
+}
+
+// GetResourceStrategy returns the resource indicator strategy to be used. Defaults to DefaultResourceMatchingStrategy.
+func (c *Config) GetResourceStrategy(_ context.Context) ResourceMatchingStrategy {
+	if c.ResourceMatchingStrategy == nil {
+		return DefaultResourceMatchingStrategy
+	}
+	return c.ResourceMatchingStrategy
+}
+
+// GetSubjectValidator returns the subject validator to be used. Defaults to accepting any subject.
+func (c *Config) GetSubjectValidator(_ context.Context) SubjectValidator {
+	if c.SubjectValidator == nil {
+		return func(string) error { return nil }
+	}
+	return c.SubjectValidator
+}
+
 // GetAuthorizeCodeLifespan returns how long an authorize code should be valid. Defaults to one fifteen minutes.
 func (c *Config) GetAuthorizeCodeLifespan(_ context.Context) time.Duration {
 	if c.AuthorizeCodeLifespan == 0 {
@@ -363,6 +982,16 @@ func (c *Config) GetAuthorizeCodeLifespan(_ context.Context) time.Duration {
 	return c.AuthorizeCodeLifespan
 }
 
+// GetIDTokenMaxAudiences returns the maximum number of audiences of an ID token. Defaults to zero.
+func (c *Config) GetIDTokenMaxAudiences(_ context.Context) int {
+	return c.IDTokenMaxAudiences
+}
+
+// GetIDTokenDropExcessAudiences returns IDTokenDropExcessAudiences. Defaults to false.
+func (c *Config) GetIDTokenDropExcessAudiences(_ context.Context) bool {
+	return c.IDTokenDropExcessAudiences
+}
+
 // GetIDTokenLifespan returns how long an id token should be valid. Defaults to one hour.
 func (c *Config) GetIDTokenLifespan(_ context.Context) time.Duration {
 	if c.IDTokenLifespan == 0 {
@@ -396,6 +1025,17 @@ func (c *Config) GetRefreshTokenLifespan(_ context.Context) time.Duration {
 	return c.RefreshTokenLifespan
 }
 
+// GetRefreshTokenLineageMaxAge returns how long inactive refresh tokens are retained. Defaults to zero (indefinitely).
+func (c *Config) GetRefreshTokenLineageMaxAge(_ context.Context) time.Duration {
+	return c.RefreshTokenLineageMaxAge
+}
+
+// GetRefreshTokenLineageMaxCount returns how many inactive refresh tokens are retained per token chain. Defaults to
+// zero (all).
+func (c *Config) GetRefreshTokenLineageMaxCount(_ context.Context) int {
+	return c.RefreshTokenLineageMaxCount
+}
+
 // GetBCryptCost returns the bcrypt cost factor. Defaults to 12.
 func (c *Config) GetBCryptCost(_ context.Context) int {
 	if c.HashCost == 0 {
@@ -436,6 +1076,50 @@ func (c *Config) GetRefreshTokenScopes(_ context.Context) []string {
 	return c.RefreshTokenScopes
 }
 
+// GetRefreshTokenScopeStrategy returns the refresh token scope strategy. Defaults to DefaultRefreshTokenScopeStrategy.
+func (c *Config) GetRefreshTokenScopeStrategy(_ context.Context) RefreshTokenScopeStrategy {
+	if c.RefreshTokenScopeStrategy == nil {
+		return DefaultRefreshTokenScopeStrategy
+	}
+	return c.RefreshTokenScopeStrategy
+}
+
+// GetUnknownScopePolicy returns the unknown scope policy. Defaults to UnknownScopePolicyReject.
+func (c *Config) GetUnknownScopePolicy(_ context.Context) UnknownScopePolicy {
+	if c.UnknownScopePolicy == "" {
+		return UnknownScopePolicyReject
+	}
+	return c.UnknownScopePolicy
+}
+
+// GetEnforceOfflineAccessConsent returns whether "offline_access" requires "prompt=consent" or remembered consent.
+func (c *Config) GetEnforceOfflineAccessConsent(_ context.Context) bool {
+	return c.EnforceOfflineAccessConsent
+}
+
+// GetAuthorizeParameterReuseWindow returns the window within which "state" and "nonce" must not be reused.
+func (c *Config) GetAuthorizeParameterReuseWindow(_ context.Context) time.Duration {
+	return c.AuthorizeParameterReuseWindow
+}
+
+// GetEnforceEssentialClaims returns whether essential claims are enforced. Defaults to false.
+func (c *Config) GetEnforceEssentialClaims(_ context.Context) bool {
+	return c.EnforceEssentialClaims
+}
+
+// GetSubjectIdentifierAlgorithm returns SubjectIdentifierAlgorithm. Defaults to "public".
+func (c *Config) GetSubjectIdentifierAlgorithm(_ context.Context) string {
+	if c.SubjectIdentifierAlgorithm == "" {
+		return "public"
+	}
+	return c.SubjectIdentifierAlgorithm
+}
+
+// GetPairwiseSubjectSalt returns PairwiseSubjectSalt.
+func (c *Config) GetPairwiseSubjectSalt(_ context.Context) []byte {
+	return c.PairwiseSubjectSalt
+}
+
 // GetMinParameterEntropy returns MinParameterEntropy if set. Defaults to fosite.MinParameterEntropy.
 func (c *Config) GetMinParameterEntropy(_ context.Context) int {
 	if c.MinParameterEntropy == 0 {
@@ -445,6 +1129,14 @@ func (c *Config) GetMinParameterEntropy(_ context.Context) int {
 	}
 }
 
+// GetMinNonceEntropy returns MinNonceEntropy if set. Defaults to the minimum parameter entropy.
+func (c *Config) GetMinNonceEntropy(ctx context.Context) int {
+	if c.MinNonceEntropy == 0 {
+		return c.GetMinParameterEntropy(ctx)
+	}
+	return c.MinNonceEntropy
+}
+
 // GetJWTMaxDuration specified the maximum amount of allowed `exp` time for a JWT. It compares
 // the time with the JWT's `exp` time if the JWT time is larger, will cause the JWT to be invalid.
 //
@@ -456,6 +1148,32 @@ func (c *Config) GetJWTMaxDuration(_ context.Context) time.Duration {
 	return c.GrantTypeJWTBearerMaxDuration
 }
 
+// GetMaxAssertionExpiryFromNow returns how far from now the `exp` time of a JWT bearer assertion may be.
+//
+// Defaults to zero, which disables the check.
+func (c *Config) GetMaxAssertionExpiryFromNow(_ context.Context) time.Duration {
+	return c.MaxAssertionExpiryFromNow
+}
+
+// GetDPoPProofMaxAge returns the maximum age of a DPoP proof, measured from its "iat" claim.
+//
+// Defaults to five minutes.
+func (c *Config) GetDPoPProofMaxAge(_ context.Context) time.Duration {
+	if c.DPoPProofMaxAge == 0 {
+		return time.Minute * 5
+	}
+	return c.DPoPProofMaxAge
+}
+
+// GetClientCertificateExtractor returns the configured client certificate extractor. Defaults to
+// fosite.DefaultClientCertificateExtractor.
+func (c *Config) GetClientCertificateExtractor(_ context.Context) ClientCertificateExtractor {
+	if c.ClientCertificateExtractor == nil {
+		return DefaultClientCertificateExtractor
+	}
+	return c.ClientCertificateExtractor
+}
+
 // GetClientAuthenticationStrategy returns the configured client authentication strategy.
 // Defaults to nil.
 // Note that on a nil strategy `fosite.Fosite` fallbacks to its default client authentication strategy
@@ -474,6 +1192,11 @@ func (c *Config) GetPushedAuthorizeEndpointHandlers(ctx context.Context) PushedA
 	return c.PushedAuthorizeEndpointHandlers
 }
 
+// GetDeviceEndpointHandlers returns the handlers.
+func (c *Config) GetDeviceEndpointHandlers(ctx context.Context) DeviceEndpointHandlers {
+	return c.DeviceEndpointHandlers
+}
+
 // GetPushedAuthorizeRequestURIPrefix is the request URI prefix. This is
 // usually 'urn:ietf:params:oauth:request_uri:'.
 func (c *Config) GetPushedAuthorizeRequestURIPrefix(ctx context.Context) string {
@@ -499,3 +1222,57 @@ func (c *Config) GetPushedAuthorizeContextLifespan(ctx context.Context) time.Dur
 func (c *Config) EnforcePushedAuthorize(ctx context.Context) bool {
 	return c.IsPushedAuthorizeEnforced
 }
+
+// GetRequestObjectMaxSize returns the maximum size in bytes of a request object fetched from a 'request_uri'.
+func (c *Config) GetRequestObjectMaxSize(_ context.Context) int64 {
+	if c.RequestObjectMaxSize <= 0 {
+		return defaultRequestObjectMaxSize
+	}
+
+	return c.RequestObjectMaxSize
+}
+
+// GetRequestObjectContentTypes returns the content types a 'request_uri' may respond with.
+func (c *Config) GetRequestObjectContentTypes(_ context.Context) []string {
+	return c.RequestObjectContentTypes
+}
+
+// GetJWTSecuredAuthorizeResponseIssuer returns the issuer of JWT secured authorization responses. Defaults to
+// the ID token issuer.
+func (c *Config) GetJWTSecuredAuthorizeResponseIssuer(ctx context.Context) string {
+	if c.JWTSecuredAuthorizeResponseIssuer == "" {
+		return c.GetIDTokenIssuer(ctx)
+	}
+	return c.JWTSecuredAuthorizeResponseIssuer
+}
+
+// GetJWTSecuredAuthorizeResponseLifespan returns how long a JWT secured authorization response is valid.
+// Defaults to ten minutes.
+func (c *Config) GetJWTSecuredAuthorizeResponseLifespan(_ context.Context) time.Duration {
+	if c.JWTSecuredAuthorizeResponseLifespan == 0 {
+		return defaultJWTSecuredAuthorizeResponseLifespan
+	}
+	return c.JWTSecuredAuthorizeResponseLifespan
+}
+
+// GetDeviceAndUserCodeLifespan returns how long device and user codes are valid. Defaults to ten minutes.
+func (c *Config) GetDeviceAndUserCodeLifespan(_ context.Context) time.Duration {
+	if c.DeviceAndUserCodeLifespan == 0 {
+		return defaultDeviceAndUserCodeLifespan
+	}
+	return c.DeviceAndUserCodeLifespan
+}
+
+// GetDeviceAuthTokenPollingInterval returns the minimum amount of time a client must wait between polling
+// requests. Defaults to five seconds.
+func (c *Config) GetDeviceAuthTokenPollingInterval(_ context.Context) time.Duration {
+	if c.DeviceAuthTokenPollingInterval == 0 {
+		return defaultDeviceAuthTokenPollingInterval
+	}
+	return c.DeviceAuthTokenPollingInterval
+}
+
+// GetDeviceVerificationURL returns the end-user verification URI on the authorization server.
+func (c *Config) GetDeviceVerificationURL(_ context.Context) string {
+	return c.DeviceVerificationURL
+}
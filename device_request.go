@@ -0,0 +1,49 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import "time"
+
+// UserCodeState is the state of the end user's decision on a device authorization request.
+type UserCodeState int
+
+const (
+	// UserCodeStateUnused means that the end user has not yet decided on the request.
+	UserCodeStateUnused UserCodeState = iota
+	// UserCodeStateAccepted means that the end user approved the request.
+	UserCodeStateAccepted
+	// UserCodeStateRejected means that the end user denied the request.
+	UserCodeStateRejected
+)
+
+// DeviceRequest is an implementation of DeviceRequester
+type DeviceRequest struct {
+	UserCodeState UserCodeState `json:"userCodeState" gorethink:"userCodeState"`
+	LastPolledAt  time.Time     `json:"lastPolledAt" gorethink:"lastPolledAt"`
+
+	Request
+}
+
+func NewDeviceRequest() *DeviceRequest {
+	return &DeviceRequest{
+		UserCodeState: UserCodeStateUnused,
+		Request:       *NewRequest(),
+	}
+}
+
+func (d *DeviceRequest) GetUserCodeState() UserCodeState {
+	return d.UserCodeState
+}
+
+func (d *DeviceRequest) SetUserCodeState(state UserCodeState) {
+	d.UserCodeState = state
+}
+
+func (d *DeviceRequest) GetLastPolledAt() time.Time {
+	return d.LastPolledAt
+}
+
+func (d *DeviceRequest) SetLastPolledAt(polledAt time.Time) {
+	d.LastPolledAt = polledAt
+}
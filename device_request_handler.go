@@ -0,0 +1,65 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/ory/x/errorsx"
+	"github.com/ory/x/otelx"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ory/fosite/i18n"
+)
+
+// NewDeviceRequest validates the request at the device authorization endpoint as defined in
+// https://datatracker.ietf.org/doc/html/rfc8628#section-3.1
+func (f *Fosite) NewDeviceRequest(ctx context.Context, r *http.Request) (_ DeviceRequester, err error) {
+	ctx, span := trace.SpanFromContext(ctx).TracerProvider().Tracer("github.com/ory/fosite").Start(ctx, "Fosite.NewDeviceRequest")
+	defer otelx.End(span, &err)
+
+	request := NewDeviceRequest()
+	request.Lang = i18n.GetLangFromRequest(f.Config.GetMessageCatalog(ctx), r)
+
+	if r.Method != "POST" {
+		return request, errorsx.WithStack(ErrInvalidRequest.WithHintf("HTTP method is '%s', expected 'POST'.", r.Method))
+	}
+
+	if err := r.ParseMultipartForm(1 << 20); err != nil && err != http.ErrNotMultipart {
+		return request, errorsx.WithStack(ErrInvalidRequest.WithHint("Unable to parse HTTP body, make sure to send a properly formatted form request body.").WithWrap(err).WithDebug(err.Error()))
+	}
+	request.Form = r.PostForm
+
+	// The client authentication requirements of Section 3.2.1 of [RFC6749] apply to requests on this endpoint,
+	// which means that confidential clients MUST authenticate.
+	client, err := f.AuthenticateClient(ctx, r, r.PostForm)
+	if err != nil {
+		return request, err
+	}
+	request.Client = client
+
+	if !ClientHasGrantType(ctx, f.Config, client, string(GrantTypeDeviceCode)) {
+		return request, errorsx.WithStack(ErrUnauthorizedClient.WithHintf("The OAuth 2.0 Client is not allowed to use authorization grant '%s'.", GrantTypeDeviceCode))
+	}
+
+	request.SetRequestedScopes(removeUnknownScopes(ctx, f.Config, client, RemoveEmpty(strings.Split(r.PostForm.Get("scope"), " "))))
+	for _, scope := range request.GetRequestedScopes() {
+		if !f.Config.GetScopeStrategy(ctx)(client.GetScopes(), scope) {
+			return request, errorsx.WithStack(ErrInvalidScope.WithHintf("The OAuth 2.0 Client is not allowed to request scope '%s'.", scope))
+		}
+	}
+
+	if err := validateScopeCount(ctx, f.Config, request.GetRequestedScopes()); err != nil {
+		return request, err
+	}
+
+	request.SetRequestedAudience(f.getAudiences(ctx, r.PostForm))
+	if err := f.Config.GetAudienceStrategy(ctx)(client.GetAudience(), request.GetRequestedAudience()); err != nil {
+		return request, err
+	}
+
+	return request, nil
+}
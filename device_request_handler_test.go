@@ -0,0 +1,100 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ory/fosite"
+	"github.com/ory/fosite/storage"
+)
+
+func TestNewDeviceRequest(t *testing.T) {
+	store := storage.NewMemoryStore()
+	store.Clients["device-client"] = &DefaultClient{
+		ID:         "device-client",
+		Public:     true,
+		GrantTypes: []string{string(GrantTypeDeviceCode)},
+		Scopes:     []string{"foo", "bar"},
+		Audience:   []string{"https://api.example.com"},
+	}
+	store.Clients["other-client"] = &DefaultClient{
+		ID:         "other-client",
+		Public:     true,
+		GrantTypes: []string{"authorization_code"},
+	}
+
+	f := &Fosite{Store: store, Config: &Config{
+		ScopeStrategy:            ExactScopeStrategy,
+		AudienceMatchingStrategy: DefaultAudienceMatchingStrategy,
+	}}
+
+	for _, c := range []struct {
+		description string
+		method      string
+		form        url.Values
+		expectErr   error
+	}{
+		{
+			description: "should fail because the method is not POST",
+			method:      http.MethodGet,
+			form:        url.Values{"client_id": {"device-client"}},
+			expectErr:   ErrInvalidRequest,
+		},
+		{
+			description: "should fail because the client is unknown",
+			form:        url.Values{"client_id": {"unknown"}},
+			expectErr:   ErrInvalidClient,
+		},
+		{
+			description: "should fail because the client may not use the grant",
+			form:        url.Values{"client_id": {"other-client"}},
+			expectErr:   ErrUnauthorizedClient,
+		},
+		{
+			description: "should fail because the client may not request the scope",
+			form:        url.Values{"client_id": {"device-client"}, "scope": {"foo baz"}},
+			expectErr:   ErrInvalidScope,
+		},
+		{
+			description: "should fail because the client may not request the audience",
+			form:        url.Values{"client_id": {"device-client"}, "audience": {"https://other.example.com"}},
+			expectErr:   ErrInvalidRequest,
+		},
+		{
+			description: "should pass",
+			form:        url.Values{"client_id": {"device-client"}, "scope": {"foo bar"}, "audience": {"https://api.example.com"}},
+		},
+	} {
+		t.Run("case="+c.description, func(t *testing.T) {
+			method := c.method
+			if method == "" {
+				method = http.MethodPost
+			}
+
+			r, err := http.NewRequest(method, "https://auth.example.com/device", strings.NewReader(c.form.Encode()))
+			require.NoError(t, err)
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			ar, err := f.NewDeviceRequest(context.Background(), r)
+			if c.expectErr != nil {
+				require.ErrorIs(t, err, c.expectErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "device-client", ar.GetClient().GetID())
+			assert.Equal(t, Arguments{"foo", "bar"}, ar.GetRequestedScopes())
+			assert.Equal(t, Arguments{"https://api.example.com"}, ar.GetRequestedAudience())
+			assert.Equal(t, UserCodeStateUnused, ar.GetUserCodeState())
+		})
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ory/x/otelx"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewDeviceResponse executes the device endpoint handlers and builds the response
+func (f *Fosite) NewDeviceResponse(ctx context.Context, requester DeviceRequester, session Session) (_ DeviceResponder, err error) {
+	ctx, span := trace.SpanFromContext(ctx).TracerProvider().Tracer("github.com/ory/fosite").Start(ctx, "Fosite.NewDeviceResponse")
+	defer otelx.End(span, &err)
+
+	var resp = &DeviceResponse{
+		Header: http.Header{},
+		Extra:  map[string]interface{}{},
+	}
+
+	requester.SetSession(session)
+	for _, h := range f.Config.GetDeviceEndpointHandlers(ctx) {
+		if err := h.HandleDeviceEndpointRequest(ctx, requester, resp); err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
+
+// WriteDeviceResponse writes the device authorization response
+func (f *Fosite) WriteDeviceResponse(ctx context.Context, rw http.ResponseWriter, requester DeviceRequester, resp DeviceResponder) {
+	// Set custom headers, e.g. "X-MySuperCoolCustomHeader" or "X-DONT-CACHE-ME"...
+	wh := rw.Header()
+	rh := resp.GetHeader()
+	for k := range rh {
+		wh.Set(k, rh.Get(k))
+	}
+
+	wh.Set("Cache-Control", "no-store")
+	wh.Set("Pragma", "no-cache")
+	wh.Set("Content-Type", "application/json;charset=UTF-8")
+
+	js, err := json.Marshal(resp.ToMap())
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write(js)
+}
+
+// WriteDeviceError writes the device authorization error
+func (f *Fosite) WriteDeviceError(ctx context.Context, rw http.ResponseWriter, requester DeviceRequester, err error) {
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.Header().Set("Pragma", "no-cache")
+	rw.Header().Set("Content-Type", "application/json;charset=UTF-8")
+
+	sendDebugMessagesToClient := f.Config.GetSendDebugMessagesToClients(ctx)
+	rfcerr := ErrorToRFC6749Error(err).WithLegacyFormat(f.Config.GetUseLegacyErrorFormat(ctx)).
+		WithExposeDebug(sendDebugMessagesToClient).WithLocalizer(f.Config.GetMessageCatalog(ctx), getLangFromRequester(requester))
+
+	js, err := json.Marshal(rfcerr)
+	if err != nil {
+		if sendDebugMessagesToClient {
+			errorMessage := EscapeJSONString(err.Error())
+			http.Error(rw, fmt.Sprintf(`{"error":"server_error","error_description":"%s"}`, errorMessage), http.StatusInternalServerError)
+		} else {
+			http.Error(rw, `{"error":"server_error"}`, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	rw.WriteHeader(rfcerr.CodeField)
+	_, _ = rw.Write(js)
+}
@@ -145,6 +145,16 @@ var (
 		HintField:        "The resource owner did not grant the requested scope.",
 		CodeField:        http.StatusForbidden,
 	}
+	ErrInvalidToken = &RFC6749Error{
+		ErrorField:       errInvalidTokenName,
+		DescriptionField: "The access token provided is expired, revoked, malformed, or invalid for other reasons.",
+		CodeField:        http.StatusUnauthorized,
+	}
+	ErrInsufficientScope = &RFC6749Error{
+		ErrorField:       errInsufficientScopeName,
+		DescriptionField: "The request requires higher privileges than provided by the access token.",
+		CodeField:        http.StatusForbidden,
+	}
 	ErrTokenClaim = &RFC6749Error{
 		ErrorField:       errTokenClaimName,
 		DescriptionField: "The token failed validation due to a claim mismatch.",
@@ -202,6 +212,36 @@ var (
 		ErrorField:       errJTIKnownName,
 		CodeField:        http.StatusBadRequest,
 	}
+	ErrInvalidTarget = &RFC6749Error{
+		DescriptionField: "The requested resource is invalid, missing, unknown, or malformed.",
+		ErrorField:       errInvalidTargetName,
+		CodeField:        http.StatusBadRequest,
+	}
+	ErrInvalidAuthorizationDetails = &RFC6749Error{
+		DescriptionField: "The requested authorization details are invalid, unknown, or malformed.",
+		ErrorField:       errInvalidAuthDetailsName,
+		CodeField:        http.StatusBadRequest,
+	}
+	ErrInvalidDPoPProof = &RFC6749Error{
+		DescriptionField: "The DPoP proof is invalid.",
+		ErrorField:       errInvalidDPoPProofName,
+		CodeField:        http.StatusBadRequest,
+	}
+	ErrAuthorizationPending = &RFC6749Error{
+		DescriptionField: "The authorization request is still pending as the end user hasn't yet completed the user-interaction steps.",
+		ErrorField:       errAuthorizationPendingName,
+		CodeField:        http.StatusBadRequest,
+	}
+	ErrSlowDown = &RFC6749Error{
+		DescriptionField: "The authorization request is still pending and polling should continue, but the interval must be increased by 5 seconds for this and all subsequent requests.",
+		ErrorField:       errSlowDownName,
+		CodeField:        http.StatusBadRequest,
+	}
+	ErrDeviceExpiredToken = &RFC6749Error{
+		DescriptionField: "The device_code has expired, and the device authorization session has concluded.",
+		ErrorField:       errDeviceExpiredTokenName,
+		CodeField:        http.StatusBadRequest,
+	}
 )
 
 const (
@@ -231,6 +271,8 @@ const (
 	errTokenSignatureMismatchName  = "token_signature_mismatch"
 	errTokenExpiredName            = "invalid_token" // https://tools.ietf.org/html/rfc6750#section-3.1
 	errScopeNotGrantedName         = "scope_not_granted"
+	errInvalidTokenName            = "invalid_token"      // https://tools.ietf.org/html/rfc6750#section-3.1
+	errInsufficientScopeName       = "insufficient_scope" // https://tools.ietf.org/html/rfc6750#section-3.1
 	errTokenClaimName              = "token_claim"
 	errTokenInactiveName           = "token_inactive"
 	// errAuthorizationCodeInactiveName = "authorization_code_inactive"
@@ -239,6 +281,12 @@ const (
 	errRequestURINotSupportedName   = "request_uri_not_supported"
 	errRegistrationNotSupportedName = "registration_not_supported"
 	errJTIKnownName                 = "jti_known"
+	errInvalidTargetName            = "invalid_target"
+	errInvalidAuthDetailsName       = "invalid_authorization_details"
+	errInvalidDPoPProofName         = "invalid_dpop_proof"
+	errAuthorizationPendingName     = "authorization_pending"
+	errSlowDownName                 = "slow_down"
+	errDeviceExpiredTokenName       = "expired_token"
 )
 
 type (
@@ -6,6 +6,7 @@ package fosite
 import (
 	"context"
 	"reflect"
+	"sync/atomic"
 )
 
 const MinParameterEntropy = 8
@@ -40,6 +41,20 @@ func (t *TokenEndpointHandlers) Append(h TokenEndpointHandler) {
 	*t = append(*t, h)
 }
 
+// TokenEndpointDecorators is a list of TokenEndpointDecorator
+type TokenEndpointDecorators []TokenEndpointDecorator
+
+// Append adds a TokenEndpointDecorator to this list. Ignores duplicates based on reflect.TypeOf.
+func (t *TokenEndpointDecorators) Append(d TokenEndpointDecorator) {
+	for _, this := range *t {
+		if reflect.TypeOf(this) == reflect.TypeOf(d) {
+			return
+		}
+	}
+
+	*t = append(*t, d)
+}
+
 // TokenIntrospectionHandlers is a list of TokenValidator
 type TokenIntrospectionHandlers []TokenIntrospector
 
@@ -82,28 +97,73 @@ func (a *PushedAuthorizeEndpointHandlers) Append(h PushedAuthorizeEndpointHandle
 	*a = append(*a, h)
 }
 
+// DeviceEndpointHandlers is a list of DeviceEndpointHandler
+type DeviceEndpointHandlers []DeviceEndpointHandler
+
+// Append adds a DeviceEndpointHandler to this list. Ignores duplicates based on reflect.TypeOf.
+func (a *DeviceEndpointHandlers) Append(h DeviceEndpointHandler) {
+	for _, this := range *a {
+		if reflect.TypeOf(this) == reflect.TypeOf(h) {
+			return
+		}
+	}
+
+	*a = append(*a, h)
+}
+
 var _ OAuth2Provider = (*Fosite)(nil)
 
 type Configurator interface {
 	IDTokenIssuerProvider
 	IDTokenLifespanProvider
 	AllowedPromptsProvider
+	AllowedResponseTypesProvider
+	KeepAccessTokensOnSessionRevocationProvider
+	EnforceUniqueJTIProvider
 	EnforcePKCEProvider
 	EnforcePKCEForPublicClientsProvider
 	EnablePKCEPlainChallengeMethodProvider
+	PKCEMinCodeChallengeLengthProvider
+	RestrictPublicClientRefreshTokensProvider
+	ClientCertificateExtractorProvider
 	GrantTypeJWTBearerCanSkipClientAuthProvider
 	GrantTypeJWTBearerIDOptionalProvider
 	GrantTypeJWTBearerIssuedDateOptionalProvider
+	GrantTypeJWTBearerExtraClaimsProvider
+	GrantTypeJWTBearerHashAssertionProvider
 	GetJWTMaxDurationProvider
+	MaxAssertionExpiryFromNowProvider
+	DPoPProofMaxAgeProvider
 	AudienceStrategyProvider
+	DisableSpaceDelimitedAudienceProvider
+	ResourceStrategyProvider
+	SubjectValidatorProvider
 	ScopeStrategyProvider
+	MaxScopeCountProvider
+	MaxTokenRequestBytesProvider
+	MaxAudienceLengthProvider
 	RedirectSecureCheckerProvider
 	OmitRedirectScopeParamProvider
+	ReportRequestedScopeCasingProvider
 	SanitationAllowedProvider
 	JWTScopeFieldProvider
+	JWTAudienceClaimNameProvider
+	RequireJWTAccessTokenAudienceProvider
+	IncludeJWTAccessTokenAuthClaimsProvider
 	AccessTokenIssuerProvider
 	DisableRefreshTokenValidationProvider
+	IntrospectionRespondInactiveOnErrorProvider
+	RejectUnknownTokenTypeHintProvider
+	RejectTokensWithEmptySubjectProvider
+	IntrospectionCacheProvider
 	RefreshTokenScopesProvider
+	RefreshTokenScopeStrategyProvider
+	UnknownScopePolicyProvider
+	EnforceOfflineAccessConsentProvider
+	AuthorizeParameterReuseWindowProvider
+	RequestObjectConfigProvider
+	JWTSecuredAuthorizeResponseConfigProvider
+	DeviceAuthorizeConfigProvider
 	AccessTokenLifespanProvider
 	RefreshTokenLifespanProvider
 	VerifiableCredentialsNonceLifespanProvider
@@ -116,6 +176,10 @@ type Configurator interface {
 	ScopeStrategyProvider
 	AudienceStrategyProvider
 	MinParameterEntropyProvider
+	MinNonceEntropyProvider
+	EnforceEssentialClaimsProvider
+	SubjectIdentifierAlgorithmProvider
+	PairwiseSubjectSaltProvider
 	HMACHashingProvider
 	ClientAuthenticationStrategyProvider
 	ResponseModeHandlerExtensionProvider
@@ -129,9 +193,35 @@ type Configurator interface {
 	GetSecretsHashingProvider
 	AuthorizeEndpointHandlersProvider
 	TokenEndpointHandlersProvider
+	TokenEndpointDecoratorsProvider
 	TokenIntrospectionHandlersProvider
 	RevocationHandlersProvider
+	DeviceEndpointHandlersProvider
 	UseLegacyErrorFormatProvider
+	ErrorStatusMapperProvider
+	WWWAuthenticateSchemesProvider
+	LoggerProvider
+	ClientAuthenticatedHookProvider
+	ExpiresInReporterProvider
+	IntrospectionResponseModifierProvider
+	RequireTLSProvider
+	TLSForwardedProtoHeaderProvider
+	AuthorizationDetailValidatorsProvider
+	EmptyClientGrantTypesPolicyProvider
+	IDTokenMaxAudiencesProvider
+	IDTokenDropExcessAudiencesProvider
+	GrantTypeEnabledProvider
+	AuditSinkProvider
+	StrictAuditProvider
+	IntrospectionIssuerProvider
+	BodyClientIDMatchProvider
+	CallerNetworkExtractorProvider
+	TemporarilyUnavailableRetryAfterProvider
+	TokenExchangeRequestedTokenTypesProvider
+	RedirectURIMatchingStrategyProvider
+	RefreshTokenLineageRetentionProvider
+	RandomSourceProvider
+	RejectRedirectURIFragmentsProvider
 }
 
 func NewOAuth2Provider(s Storage, c Configurator) *Fosite {
@@ -143,6 +233,11 @@ type Fosite struct {
 	Store Storage
 
 	Config Configurator
+
+	tokenEndpointHandlerIndex atomic.Pointer[TokenEndpointHandlerIndex]
+
+	// dummySecretHash is compared against the secrets sent for unknown clients, see compareDummySecret.
+	dummySecretHash atomic.Pointer[[]byte]
 }
 
 // GetMinParameterEntropy returns MinParameterEntropy if set. Defaults to fosite.MinParameterEntropy.
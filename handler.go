@@ -22,6 +22,16 @@ type AuthorizeEndpointHandler interface {
 	HandleAuthorizeEndpointRequest(ctx context.Context, requester AuthorizeRequester, responder AuthorizeResponder) error
 }
 
+// AuthorizeEndpointRequestValidator is implemented by authorize endpoint handlers which validate the authorize request
+// before handling it. It is used by Fosite.ValidateAuthorizeRequest and must not have side effects, other than on the
+// requester, which is discarded after the validation. Validators responsible for a response type mark it using
+// SetResponseTypeHandled, just like HandleAuthorizeEndpointRequest does.
+type AuthorizeEndpointRequestValidator interface {
+	// ValidateAuthorizeEndpointRequest returns the error HandleAuthorizeEndpointRequest would return because the
+	// request is invalid, or nil if the request is valid or the handler is not responsible for it.
+	ValidateAuthorizeEndpointRequest(ctx context.Context, requester AuthorizeRequester) error
+}
+
 type TokenEndpointHandler interface {
 	// PopulateTokenEndpointResponse is responsible for setting return values and should only be executed if
 	// the handler's HandleTokenEndpointRequest did not return ErrUnknownRequest.
@@ -41,6 +51,28 @@ type TokenEndpointHandler interface {
 	CanHandleTokenEndpointRequest(ctx context.Context, requester AccessRequester) bool
 }
 
+// TokenEndpointDecorator extends the token requests handled by the TokenEndpointHandlers, for example by binding the
+// issued tokens to a key. Unlike a TokenEndpointHandler, a decorator never handles a grant itself and has no say on
+// whether client authentication is required, so requests of unknown grant types are still rejected. Decorators are
+// called after the grant handlers, in the order they are registered.
+type TokenEndpointDecorator interface {
+	// DecorateTokenEndpointRequest is called after a grant handler handled the request. It returns nil if the
+	// decorator does not apply to the request.
+	DecorateTokenEndpointRequest(ctx context.Context, requester AccessRequester) error
+
+	// DecorateTokenEndpointResponse is called after the grant handlers populated the response.
+	DecorateTokenEndpointResponse(ctx context.Context, requester AccessRequester, responder AccessResponder) error
+}
+
+// TokenEndpointGrantTypesHandler is an optional interface for TokenEndpointHandler. The token endpoint only asks
+// handlers which implement it about requests of the grant types they declare, instead of calling
+// CanHandleTokenEndpointRequest on every handler. Handlers which do not implement it are asked about every request.
+type TokenEndpointGrantTypesHandler interface {
+	// HandledGrantTypes returns the grant types the handler is responsible for. Handlers which may handle requests
+	// of any grant type return GrantTypeAny.
+	HandledGrantTypes() []GrantType
+}
+
 // RevocationHandler is the interface that allows token revocation for an OAuth2.0 provider.
 // https://tools.ietf.org/html/rfc7009
 //
@@ -66,3 +98,12 @@ type PushedAuthorizeEndpointHandler interface {
 	// the pushed authorize request, he must return nil and NOT modify session nor responder neither requester.
 	HandlePushedAuthorizeEndpointRequest(ctx context.Context, requester AuthorizeRequester, responder PushedAuthorizeResponder) error
 }
+
+// DeviceEndpointHandler is the interface that handles device authorization requests
+// (https://datatracker.ietf.org/doc/html/rfc8628#section-3.1)
+type DeviceEndpointHandler interface {
+	// HandleDeviceEndpointRequest handles a device authorization endpoint request. If the handler feels that he is
+	// not responsible for the device authorization request, he must return nil and NOT modify session nor responder
+	// neither requester.
+	HandleDeviceEndpointRequest(ctx context.Context, requester DeviceRequester, responder DeviceResponder) error
+}
@@ -0,0 +1,271 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package dpop
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/ory/x/errorsx"
+	"github.com/pkg/errors"
+
+	"github.com/ory/fosite"
+)
+
+const (
+	// HeaderName is the HTTP header carrying the DPoP proof.
+	HeaderName = "DPoP"
+
+	// TokenType is the token type of access tokens bound to a DPoP key.
+	TokenType = "DPoP"
+
+	proofType = "dpop+jwt"
+)
+
+// supportedAlgorithms lists the asymmetric algorithms a DPoP proof may be signed with. Symmetric algorithms and
+// "none" must not be used (https://datatracker.ietf.org/doc/html/rfc9449#section-4.2).
+var supportedAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+type proofClaims struct {
+	jwt.Claims
+	Method          string `json:"htm"`
+	URI             string `json:"htu"`
+	AccessTokenHash string `json:"ath"`
+}
+
+// Handler validates the DPoP proof sent to the token endpoint and binds the issued access token to the key
+// the proof was signed with, as described in https://datatracker.ietf.org/doc/html/rfc9449.
+//
+// The handler is a token endpoint decorator: it only binds the tokens of grants handled by the grant handlers, and
+// does not affect client authentication.
+type Handler struct {
+	Storage ProofStorage
+
+	Config interface {
+		fosite.TokenURLProvider
+		fosite.DPoPProofMaxAgeProvider
+	}
+}
+
+var _ fosite.TokenEndpointDecorator = (*Handler)(nil)
+
+func (c *Handler) DecorateTokenEndpointRequest(ctx context.Context, request fosite.AccessRequester) error {
+	r := httpRequestFromContext(ctx)
+	if r == nil || len(r.Header.Values(HeaderName)) == 0 {
+		// Tokens of a grant bound to a DPoP key, for example refreshed tokens, must be bound to the same key.
+		if GetJWKThumbprint(request.GetSession()) != "" {
+			return errorsx.WithStack(fosite.ErrInvalidDPoPProof.WithHintf("The grant is bound to a DPoP key, so a '%s' HTTP header must be sent.", HeaderName))
+		}
+		return nil
+	}
+
+	proofs := r.Header.Values(HeaderName)
+	if len(proofs) != 1 {
+		return errorsx.WithStack(fosite.ErrInvalidDPoPProof.WithHintf("Exactly one '%s' HTTP header must be sent.", HeaderName))
+	}
+
+	jkt, err := c.validateProof(ctx, r, proofs[0], c.Config.GetTokenURLs(ctx), "")
+	if err != nil {
+		return err
+	}
+
+	if bound := GetJWKThumbprint(request.GetSession()); bound != "" && bound != jkt {
+		return errorsx.WithStack(fosite.ErrInvalidDPoPProof.WithHint("The DPoP proof was signed with a different key than the one the grant is bound to."))
+	}
+
+	return setJWKThumbprint(request.GetSession(), jkt)
+}
+
+func (c *Handler) DecorateTokenEndpointResponse(ctx context.Context, request fosite.AccessRequester, response fosite.AccessResponder) error {
+	if GetJWKThumbprint(request.GetSession()) != "" {
+		response.SetTokenType(TokenType)
+	}
+	return nil
+}
+
+// ValidateResourceRequest validates the DPoP proof sent to a protected resource together with an access token bound
+// to a DPoP key, see https://datatracker.ietf.org/doc/html/rfc9449#section-7. The "htu" claim of the proof must match
+// the URL of the request and the "ath" claim the hash of the access token. Access tokens which are not bound to a
+// DPoP key are accepted.
+func (c *Handler) ValidateResourceRequest(ctx context.Context, r *http.Request, session fosite.Session, accessToken string) error {
+	bound := GetJWKThumbprint(session)
+	if bound == "" {
+		return nil
+	}
+
+	proofs := r.Header.Values(HeaderName)
+	if len(proofs) != 1 {
+		return errorsx.WithStack(fosite.ErrInvalidDPoPProof.WithHintf("Exactly one '%s' HTTP header must be sent together with a DPoP-bound access token.", HeaderName))
+	}
+
+	jkt, err := c.validateProof(ctx, r, proofs[0], nil, accessToken)
+	if err != nil {
+		return err
+	}
+
+	if jkt != bound {
+		return errorsx.WithStack(fosite.ErrInvalidDPoPProof.WithHint("The DPoP proof was signed with a different key than the one the access token is bound to."))
+	}
+	return nil
+}
+
+// validateProof validates the DPoP proof and returns the thumbprint of its key. The "htu" claim must match one of the
+// expected URLs, or the URL of the request if none is given. If an access token is given, the "ath" claim must
+// contain its hash.
+func (c *Handler) validateProof(ctx context.Context, r *http.Request, proof string, expectedURIs []string, accessToken string) (string, error) {
+	token, err := jwt.ParseSigned(proof)
+	if err != nil {
+		return "", errorsx.WithStack(fosite.ErrInvalidDPoPProof.
+			WithHint("Unable to parse the DPoP proof.").
+			WithWrap(err).WithDebug(err.Error()),
+		)
+	}
+
+	if len(token.Headers) != 1 {
+		return "", errorsx.WithStack(fosite.ErrInvalidDPoPProof.WithHint("The DPoP proof must contain exactly one signature."))
+	}
+
+	header := token.Headers[0]
+	if typ, _ := header.ExtraHeaders[jose.HeaderType].(string); typ != proofType {
+		return "", errorsx.WithStack(fosite.ErrInvalidDPoPProof.WithHintf("The DPoP proof must contain a \"typ\" header with value \"%s\".", proofType))
+	}
+
+	if !isSupportedAlgorithm(header.Algorithm) {
+		return "", errorsx.WithStack(fosite.ErrInvalidDPoPProof.WithHintf("The DPoP proof is signed with unsupported algorithm \"%s\".", header.Algorithm))
+	}
+
+	key := header.JSONWebKey
+	if key == nil || !key.Valid() || !key.IsPublic() {
+		return "", errorsx.WithStack(fosite.ErrInvalidDPoPProof.WithHint("The DPoP proof must contain a public key in its \"jwk\" header."))
+	}
+
+	var claims proofClaims
+	if err := token.Claims(key, &claims); err != nil {
+		return "", errorsx.WithStack(fosite.ErrInvalidDPoPProof.
+			WithHint("Unable to verify the signature of the DPoP proof.").
+			WithWrap(err).WithDebug(err.Error()),
+		)
+	}
+
+	if claims.ID == "" {
+		return "", errorsx.WithStack(fosite.ErrInvalidDPoPProof.WithHint("The DPoP proof must contain a \"jti\" (JWT ID) claim."))
+	}
+
+	if claims.Method != r.Method {
+		return "", errorsx.WithStack(fosite.ErrInvalidDPoPProof.WithHintf("The \"htm\" claim of the DPoP proof must be \"%s\" but got \"%s\".", r.Method, claims.Method))
+	}
+
+	if !uriMatches(r, claims.URI, expectedURIs) {
+		return "", errorsx.WithStack(fosite.ErrInvalidDPoPProof.WithHintf("The \"htu\" claim \"%s\" of the DPoP proof does not match the URL of the request.", claims.URI))
+	}
+
+	if accessToken != "" {
+		hash := sha256.Sum256([]byte(accessToken))
+		if claims.AccessTokenHash != base64.RawURLEncoding.EncodeToString(hash[:]) {
+			return "", errorsx.WithStack(fosite.ErrInvalidDPoPProof.WithHint("The \"ath\" claim of the DPoP proof does not match the access token."))
+		}
+	}
+
+	if claims.IssuedAt == nil {
+		return "", errorsx.WithStack(fosite.ErrInvalidDPoPProof.WithHint("The DPoP proof must contain an \"iat\" (issued at) claim."))
+	}
+
+	maxAge := c.Config.GetDPoPProofMaxAge(ctx)
+	issuedAt := claims.IssuedAt.Time()
+	if now := time.Now(); issuedAt.Before(now.Add(-maxAge)) || issuedAt.After(now.Add(maxAge)) {
+		return "", errorsx.WithStack(fosite.ErrInvalidDPoPProof.WithHintf("The DPoP proof was issued at '%s', which is outside of the accepted window.", issuedAt.Format(time.RFC3339)))
+	}
+
+	used, err := c.Storage.IsDPoPProofUsed(ctx, claims.ID)
+	if err != nil {
+		return "", errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+	if used {
+		return "", errorsx.WithStack(fosite.ErrInvalidDPoPProof.WithHint("The \"jti\" of the DPoP proof was already used."))
+	}
+
+	if err := c.Storage.MarkDPoPProofUsedForTime(ctx, claims.ID, issuedAt.Add(maxAge)); errors.Is(err, fosite.ErrJTIKnown) {
+		return "", errorsx.WithStack(fosite.ErrInvalidDPoPProof.WithHint("The \"jti\" of the DPoP proof was already used."))
+	} else if err != nil {
+		return "", errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	thumbprint, err := key.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return "", errorsx.WithStack(fosite.ErrInvalidDPoPProof.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	return base64.RawURLEncoding.EncodeToString(thumbprint), nil
+}
+
+// uriMatches compares the "htu" claim with the expected URLs, ignoring query and fragment components
+// (https://datatracker.ietf.org/doc/html/rfc9449#section-4.3). If no URL is expected, the URL of the request is
+// used instead.
+func uriMatches(r *http.Request, htu string, expectedURIs []string) bool {
+	var expected []string
+	for _, uri := range expectedURIs {
+		if uri != "" {
+			expected = append(expected, uri)
+		}
+	}
+
+	if len(expected) == 0 {
+		scheme := r.URL.Scheme
+		if scheme == "" && r.TLS != nil {
+			scheme = "https"
+		} else if scheme == "" {
+			scheme = "http"
+		}
+		expected = append(expected, (&url.URL{Scheme: scheme, Host: r.Host, Path: r.URL.Path}).String())
+	}
+
+	actual, err := normalizeURI(htu)
+	if err != nil {
+		return false
+	}
+
+	for _, e := range expected {
+		if e, err := normalizeURI(e); err == nil && e == actual {
+			return true
+		}
+	}
+
+	return false
+}
+
+func normalizeURI(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.ToLower(u.Scheme) + "://" + strings.ToLower(u.Host) + u.Path, nil
+}
+
+func isSupportedAlgorithm(alg string) bool {
+	for _, supported := range supportedAlgorithms {
+		if string(supported) == alg {
+			return true
+		}
+	}
+	return false
+}
+
+func httpRequestFromContext(ctx context.Context) *http.Request {
+	r, _ := ctx.Value(fosite.RequestContextKey).(*http.Request)
+	return r
+}
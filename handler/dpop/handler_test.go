@@ -0,0 +1,345 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package dpop
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/oauth2"
+	"github.com/ory/fosite/storage"
+	fjwt "github.com/ory/fosite/token/jwt"
+)
+
+const testTokenURL = "https://auth.example.com/oauth2/token"
+
+func newProof(t *testing.T, key *ecdsa.PrivateKey, typ string, claims proofClaims) string {
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.ES256, Key: key},
+		(&jose.SignerOptions{EmbedJWK: true}).WithType(jose.ContentType(typ)),
+	)
+	require.NoError(t, err)
+
+	proof, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+	require.NoError(t, err)
+	return proof
+}
+
+func validClaims() proofClaims {
+	return proofClaims{
+		Claims: jwt.Claims{
+			ID:       uuid.New().String(),
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+		},
+		Method: http.MethodPost,
+		URI:    testTokenURL,
+	}
+}
+
+func TestHandler(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwk := jose.JSONWebKey{Key: key.Public()}
+	sum, err := jwk.Thumbprint(crypto.SHA256)
+	require.NoError(t, err)
+	thumbprint := base64.RawURLEncoding.EncodeToString(sum)
+
+	replayedClaims := validClaims()
+	replayed := newProof(t, key, proofType, replayedClaims)
+
+	for _, c := range []struct {
+		description string
+		proofs      func() []string
+		session     fosite.Session
+		prepare     func(store *storage.MemoryStore)
+		expectErr   error
+		expectJKT   string
+	}{
+		{
+			description: "should pass with a valid proof",
+			proofs:      func() []string { return []string{newProof(t, key, proofType, validClaims())} },
+			expectJKT:   thumbprint,
+		},
+		{
+			description: "should pass with a valid proof on a default session",
+			proofs:      func() []string { return []string{newProof(t, key, proofType, validClaims())} },
+			session:     new(fosite.DefaultSession),
+			expectJKT:   thumbprint,
+		},
+		{
+			description: "should fail because multiple proofs were sent",
+			proofs: func() []string {
+				return []string{newProof(t, key, proofType, validClaims()), newProof(t, key, proofType, validClaims())}
+			},
+			expectErr: fosite.ErrInvalidDPoPProof,
+		},
+		{
+			description: "should fail because the proof is not a JWT",
+			proofs:      func() []string { return []string{"foo.bar.baz"} },
+			expectErr:   fosite.ErrInvalidDPoPProof,
+		},
+		{
+			description: "should fail because typ is wrong",
+			proofs:      func() []string { return []string{newProof(t, key, "JWT", validClaims())} },
+			expectErr:   fosite.ErrInvalidDPoPProof,
+		},
+		{
+			description: "should fail because htm does not match",
+			proofs: func() []string {
+				claims := validClaims()
+				claims.Method = http.MethodGet
+				return []string{newProof(t, key, proofType, claims)}
+			},
+			expectErr: fosite.ErrInvalidDPoPProof,
+		},
+		{
+			description: "should fail because htu does not match",
+			proofs: func() []string {
+				claims := validClaims()
+				claims.URI = "https://auth.example.com/oauth2/auth"
+				return []string{newProof(t, key, proofType, claims)}
+			},
+			expectErr: fosite.ErrInvalidDPoPProof,
+		},
+		{
+			description: "should pass because query and fragment of htu are ignored",
+			proofs: func() []string {
+				claims := validClaims()
+				claims.URI = testTokenURL + "?foo=bar#baz"
+				return []string{newProof(t, key, proofType, claims)}
+			},
+			expectJKT: thumbprint,
+		},
+		{
+			description: "should fail because jti is missing",
+			proofs: func() []string {
+				claims := validClaims()
+				claims.ID = ""
+				return []string{newProof(t, key, proofType, claims)}
+			},
+			expectErr: fosite.ErrInvalidDPoPProof,
+		},
+		{
+			description: "should fail because iat is missing",
+			proofs: func() []string {
+				claims := validClaims()
+				claims.IssuedAt = nil
+				return []string{newProof(t, key, proofType, claims)}
+			},
+			expectErr: fosite.ErrInvalidDPoPProof,
+		},
+		{
+			description: "should fail because the proof is too old",
+			proofs: func() []string {
+				claims := validClaims()
+				claims.IssuedAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+				return []string{newProof(t, key, proofType, claims)}
+			},
+			expectErr: fosite.ErrInvalidDPoPProof,
+		},
+		{
+			description: "should fail because the proof is replayed",
+			proofs:      func() []string { return []string{replayed} },
+			prepare: func(store *storage.MemoryStore) {
+				require.NoError(t, store.MarkDPoPProofUsedForTime(context.Background(), replayedClaims.ID, time.Now().Add(time.Minute)))
+			},
+			expectErr: fosite.ErrInvalidDPoPProof,
+		},
+		{
+			description: "should fail because the session is bound to another key",
+			proofs:      func() []string { return []string{newProof(t, otherKey, proofType, validClaims())} },
+			session: &oauth2.JWTSession{JWTClaims: &fjwt.JWTClaims{Extra: map[string]interface{}{
+				"cnf": map[string]interface{}{"jkt": thumbprint},
+			}}},
+			expectErr: fosite.ErrInvalidDPoPProof,
+		},
+	} {
+		t.Run("case="+c.description, func(t *testing.T) {
+			store := storage.NewMemoryStore()
+			if c.prepare != nil {
+				c.prepare(store)
+			}
+
+			h := &Handler{
+				Storage: store,
+				Config:  &fosite.Config{TokenURL: testTokenURL},
+			}
+
+			r, err := http.NewRequest(http.MethodPost, testTokenURL, nil)
+			require.NoError(t, err)
+			for _, proof := range c.proofs() {
+				r.Header.Add(HeaderName, proof)
+			}
+			ctx := context.WithValue(context.Background(), fosite.RequestContextKey, r)
+
+			session := c.session
+			if session == nil {
+				session = new(oauth2.JWTSession)
+			}
+			ar := fosite.NewAccessRequest(session)
+
+			err = h.DecorateTokenEndpointRequest(ctx, ar)
+			if c.expectErr != nil {
+				require.ErrorIs(t, err, c.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.expectJKT, GetJWKThumbprint(ar.GetSession()))
+
+			resp := fosite.NewAccessResponse()
+			require.NoError(t, h.DecorateTokenEndpointResponse(ctx, ar, resp))
+			assert.Equal(t, TokenType, resp.GetTokenType())
+		})
+	}
+
+	t.Run("case=should not bind requests without proof", func(t *testing.T) {
+		h := &Handler{Storage: storage.NewMemoryStore(), Config: new(fosite.Config)}
+		r, err := http.NewRequest(http.MethodPost, testTokenURL, nil)
+		require.NoError(t, err)
+		ctx := context.WithValue(context.Background(), fosite.RequestContextKey, r)
+		ar := fosite.NewAccessRequest(new(oauth2.JWTSession))
+
+		require.NoError(t, h.DecorateTokenEndpointRequest(ctx, ar))
+		assert.Empty(t, GetJWKThumbprint(ar.GetSession()))
+
+		resp := fosite.NewAccessResponse()
+		resp.SetTokenType("bearer")
+		require.NoError(t, h.DecorateTokenEndpointResponse(ctx, ar, resp))
+		assert.Equal(t, "bearer", resp.GetTokenType())
+	})
+
+	t.Run("case=should not share used jtis with client assertions", func(t *testing.T) {
+		store := storage.NewMemoryStore()
+		h := &Handler{Storage: store, Config: &fosite.Config{TokenURL: testTokenURL}}
+
+		claims := validClaims()
+		require.NoError(t, store.SetClientAssertionJWT(context.Background(), claims.ID, time.Now().Add(time.Hour)))
+
+		r, err := http.NewRequest(http.MethodPost, testTokenURL, nil)
+		require.NoError(t, err)
+		r.Header.Add(HeaderName, newProof(t, key, proofType, claims))
+		ctx := context.WithValue(context.Background(), fosite.RequestContextKey, r)
+
+		require.NoError(t, h.DecorateTokenEndpointRequest(ctx, fosite.NewAccessRequest(new(oauth2.JWTSession))))
+		require.NoError(t, store.ClientAssertionJWTValid(context.Background(), claims.ID+"-other"))
+	})
+}
+
+func TestValidateResourceRequest(t *testing.T) {
+	const resourceURL = "https://rs.example.com/userinfo"
+	const accessToken = "some-access-token"
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwk := jose.JSONWebKey{Key: key.Public()}
+	sum, err := jwk.Thumbprint(crypto.SHA256)
+	require.NoError(t, err)
+	thumbprint := base64.RawURLEncoding.EncodeToString(sum)
+
+	hash := sha256.Sum256([]byte(accessToken))
+	resourceClaims := func() proofClaims {
+		claims := validClaims()
+		claims.Method = http.MethodGet
+		claims.URI = resourceURL
+		claims.AccessTokenHash = base64.RawURLEncoding.EncodeToString(hash[:])
+		return claims
+	}
+
+	bound := func() fosite.Session {
+		return &oauth2.JWTSession{JWTClaims: &fjwt.JWTClaims{Extra: map[string]interface{}{
+			"cnf": map[string]interface{}{"jkt": thumbprint},
+		}}}
+	}
+
+	for _, c := range []struct {
+		description string
+		proofs      func() []string
+		session     fosite.Session
+		expectErr   error
+	}{
+		{
+			description: "should pass with a valid proof",
+			proofs:      func() []string { return []string{newProof(t, key, proofType, resourceClaims())} },
+			session:     bound(),
+		},
+		{
+			description: "should pass without proof because the token is not bound",
+			proofs:      func() []string { return nil },
+			session:     new(oauth2.JWTSession),
+		},
+		{
+			description: "should fail because the proof is missing",
+			proofs:      func() []string { return nil },
+			session:     bound(),
+			expectErr:   fosite.ErrInvalidDPoPProof,
+		},
+		{
+			description: "should fail because htu is the token endpoint",
+			proofs: func() []string {
+				claims := resourceClaims()
+				claims.URI = testTokenURL
+				return []string{newProof(t, key, proofType, claims)}
+			},
+			session:   bound(),
+			expectErr: fosite.ErrInvalidDPoPProof,
+		},
+		{
+			description: "should fail because ath does not match the access token",
+			proofs: func() []string {
+				claims := resourceClaims()
+				claims.AccessTokenHash = "foo"
+				return []string{newProof(t, key, proofType, claims)}
+			},
+			session:   bound(),
+			expectErr: fosite.ErrInvalidDPoPProof,
+		},
+		{
+			description: "should fail because the token is bound to another key",
+			proofs:      func() []string { return []string{newProof(t, otherKey, proofType, resourceClaims())} },
+			session:     bound(),
+			expectErr:   fosite.ErrInvalidDPoPProof,
+		},
+	} {
+		t.Run("case="+c.description, func(t *testing.T) {
+			h := &Handler{
+				Storage: storage.NewMemoryStore(),
+				Config:  &fosite.Config{TokenURL: testTokenURL},
+			}
+
+			r, err := http.NewRequest(http.MethodGet, resourceURL, nil)
+			require.NoError(t, err)
+			for _, proof := range c.proofs() {
+				r.Header.Add(HeaderName, proof)
+			}
+
+			err = h.ValidateResourceRequest(context.Background(), r, c.session, accessToken)
+			if c.expectErr != nil {
+				require.ErrorIs(t, err, c.expectErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package dpop
+
+import (
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/oauth2"
+)
+
+const (
+	confirmationClaim = "cnf"
+	thumbprintMember  = "jkt"
+)
+
+// GetJWKThumbprint returns the JWK SHA-256 thumbprint ("cnf.jkt") the session is bound to, or an empty string
+// if the session is not bound to a DPoP key.
+func GetJWKThumbprint(session fosite.Session) string {
+	claims, err := oauth2.ExtraClaims(session)
+	if err != nil {
+		return ""
+	}
+
+	cnf, ok := claims[confirmationClaim].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	jkt, _ := cnf[thumbprintMember].(string)
+	return jkt
+}
+
+func setJWKThumbprint(session fosite.Session, jkt string) error {
+	claims, err := oauth2.ExtraClaims(session)
+	if err != nil {
+		return err
+	}
+
+	cnf, ok := claims[confirmationClaim].(map[string]interface{})
+	if !ok {
+		cnf = make(map[string]interface{})
+	}
+	cnf[thumbprintMember] = jkt
+	claims[confirmationClaim] = cnf
+	return nil
+}
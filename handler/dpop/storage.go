@@ -0,0 +1,22 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package dpop
+
+import (
+	"context"
+	"time"
+)
+
+// ProofStorage holds the information needed to detect replayed DPoP proofs.
+type ProofStorage interface {
+	// IsDPoPProofUsed returns true, if a DPoP proof with the given "jti" was already used and can still be
+	// considered valid.
+	IsDPoPProofUsed(ctx context.Context, jti string) (bool, error)
+
+	// MarkDPoPProofUsedForTime marks the DPoP proof with the given "jti" as used until exp. This ensures that a
+	// proof can not be replayed within the window in which its "iat" claim is accepted.
+	// (https://datatracker.ietf.org/doc/html/rfc9449#section-11.1) It returns fosite.ErrJTIKnown if the proof is
+	// already marked as used. The "jti" values of DPoP proofs must be kept apart from those of client assertions.
+	MarkDPoPProofUsedForTime(ctx context.Context, jti string, exp time.Time) error
+}
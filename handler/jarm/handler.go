@@ -0,0 +1,141 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package jarm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/token/jwt"
+)
+
+// ResponseModeHandler implements JWT Secured Authorization Response Mode for OAuth 2.0 (JARM), see
+// https://openid.net/specs/oauth-v2-jarm.html. The authorization response parameters are wrapped into a signed
+// JWT which is passed to the client in the "response" parameter.
+//
+// Use it by setting it as the fosite.Config.ResponseModeHandlerExtension.
+type ResponseModeHandler struct {
+	// Signer signs the authorization response JWT.
+	Signer jwt.Signer
+
+	Config interface {
+		fosite.JWTSecuredAuthorizeResponseConfigProvider
+		fosite.FormPostHTMLTemplateProvider
+		fosite.UseLegacyErrorFormatProvider
+		fosite.SendDebugMessagesToClientsProvider
+		fosite.RedirectURIMatchingStrategyProvider
+	}
+}
+
+var _ fosite.ResponseModeHandler = (*ResponseModeHandler)(nil)
+
+func (h *ResponseModeHandler) ResponseModes() fosite.ResponseModeTypes {
+	return fosite.ResponseModeTypes{
+		fosite.ResponseModeJWT,
+		fosite.ResponseModeQueryJWT,
+		fosite.ResponseModeFragmentJWT,
+		fosite.ResponseModeFormPostJWT,
+	}
+}
+
+func (h *ResponseModeHandler) WriteAuthorizeResponse(ctx context.Context, rw http.ResponseWriter, ar fosite.AuthorizeRequester, resp fosite.AuthorizeResponder) {
+	h.write(ctx, rw, ar, resp.GetParameters())
+}
+
+func (h *ResponseModeHandler) WriteAuthorizeError(ctx context.Context, rw http.ResponseWriter, ar fosite.AuthorizeRequester, err error) {
+	rfcerr := fosite.ErrorToRFC6749Error(err).
+		WithLegacyFormat(h.Config.GetUseLegacyErrorFormat(ctx)).
+		WithExposeDebug(h.Config.GetSendDebugMessagesToClients(ctx))
+
+	if !fosite.IsRedirectURIValid(ctx, h.Config, ar) {
+		rw.Header().Set("Content-Type", "application/json;charset=UTF-8")
+
+		js, err := json.Marshal(rfcerr)
+		if err != nil {
+			http.Error(rw, `{"error":"server_error"}`, http.StatusInternalServerError)
+			return
+		}
+
+		rw.WriteHeader(rfcerr.CodeField)
+		_, _ = rw.Write(js)
+		return
+	}
+
+	parameters := rfcerr.ToValues()
+	parameters.Set("state", ar.GetState())
+	h.write(ctx, rw, ar, parameters)
+}
+
+func (h *ResponseModeHandler) write(ctx context.Context, rw http.ResponseWriter, ar fosite.AuthorizeRequester, parameters url.Values) {
+	token, err := h.generate(ctx, ar, parameters)
+	if err != nil {
+		http.Error(rw, "Unable to sign the authorization response.", http.StatusInternalServerError)
+		return
+	}
+
+	redir := *ar.GetRedirectURI()
+	response := url.Values{"response": {token}}
+
+	switch ResponseMode(ar) {
+	case fosite.ResponseModeFormPostJWT:
+		template := h.Config.GetFormPostHTMLTemplate(ctx)
+		if template == nil {
+			template = fosite.DefaultFormPostTemplate
+		}
+
+		rw.Header().Add("Content-Type", "text/html;charset=UTF-8")
+		fosite.WriteAuthorizeFormPostResponse(redir.String(), response, template, rw)
+		return
+	case fosite.ResponseModeFragmentJWT:
+		// The endpoint URI MUST NOT include a fragment component.
+		redir.Fragment = ""
+		sendRedirect(redir.String()+"#"+response.Encode(), rw)
+		return
+	default:
+		q := redir.Query()
+		q.Set("response", token)
+		redir.RawQuery = q.Encode()
+		sendRedirect(redir.String(), rw)
+		return
+	}
+}
+
+// generate wraps the authorization response parameters into a signed JWT, see
+// https://openid.net/specs/oauth-v2-jarm.html#section-2.1
+func (h *ResponseModeHandler) generate(ctx context.Context, ar fosite.AuthorizeRequester, parameters url.Values) (string, error) {
+	claims := jwt.MapClaims{}
+	for k := range parameters {
+		claims[k] = parameters.Get(k)
+	}
+
+	claims["iss"] = h.Config.GetJWTSecuredAuthorizeResponseIssuer(ctx)
+	claims["aud"] = ar.GetClient().GetID()
+	claims["exp"] = time.Now().UTC().Add(h.Config.GetJWTSecuredAuthorizeResponseLifespan(ctx)).Unix()
+
+	token, _, err := h.Signer.Generate(ctx, claims, jwt.NewHeaders())
+	return token, err
+}
+
+// ResponseMode returns the response mode used to deliver the authorization response. The "jwt" response mode
+// resolves to "query.jwt" for the "code" response type and to "fragment.jwt" otherwise, see
+// https://openid.net/specs/oauth-v2-jarm.html#section-2.3.4
+func ResponseMode(ar fosite.AuthorizeRequester) fosite.ResponseModeType {
+	if rm := ar.GetResponseMode(); rm != fosite.ResponseModeJWT {
+		return rm
+	}
+
+	if ar.GetResponseTypes().ExactOne("code") {
+		return fosite.ResponseModeQueryJWT
+	}
+	return fosite.ResponseModeFragmentJWT
+}
+
+func sendRedirect(location string, rw http.ResponseWriter) {
+	rw.Header().Set("Location", location)
+	rw.WriteHeader(http.StatusSeeOther)
+}
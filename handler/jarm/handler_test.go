@@ -0,0 +1,139 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package jarm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/internal/gen"
+	"github.com/ory/fosite/token/jwt"
+)
+
+var formPostResponse = regexp.MustCompile(`name="response" value="([^"]+)"`)
+
+func TestResponseModeHandler(t *testing.T) {
+	key := gen.MustRSAKey()
+	h := &ResponseModeHandler{
+		Signer: &jwt.DefaultSigner{GetPrivateKey: func(_ context.Context) (interface{}, error) {
+			return key, nil
+		}},
+		Config: &fosite.Config{JWTSecuredAuthorizeResponseIssuer: "https://auth.example.com"},
+	}
+
+	newRequest := func(responseMode fosite.ResponseModeType, responseTypes ...string) *fosite.AuthorizeRequest {
+		ar := fosite.NewAuthorizeRequest()
+		ar.Client = &fosite.DefaultClient{ID: "foo", RedirectURIs: []string{"https://client.example.com/callback?foo=bar"}}
+		ar.RedirectURI, _ = url.Parse("https://client.example.com/callback?foo=bar")
+		ar.ResponseMode = responseMode
+		ar.ResponseTypes = responseTypes
+		ar.State = "some-state"
+		return ar
+	}
+
+	extract := func(t *testing.T, responseMode fosite.ResponseModeType, rw *httptest.ResponseRecorder) string {
+		switch responseMode {
+		case fosite.ResponseModeFormPostJWT:
+			require.Equal(t, http.StatusOK, rw.Code)
+			matches := formPostResponse.FindStringSubmatch(rw.Body.String())
+			require.Len(t, matches, 2, "%s", rw.Body.String())
+			return matches[1]
+		case fosite.ResponseModeFragmentJWT:
+			require.Equal(t, http.StatusSeeOther, rw.Code)
+			location, err := url.Parse(rw.Header().Get("Location"))
+			require.NoError(t, err)
+			assert.Equal(t, "foo=bar", location.RawQuery)
+			fragment, err := url.ParseQuery(location.Fragment)
+			require.NoError(t, err)
+			return fragment.Get("response")
+		default:
+			require.Equal(t, http.StatusSeeOther, rw.Code)
+			location, err := url.Parse(rw.Header().Get("Location"))
+			require.NoError(t, err)
+			assert.Empty(t, location.Fragment)
+			assert.Equal(t, "bar", location.Query().Get("foo"))
+			return location.Query().Get("response")
+		}
+	}
+
+	verify := func(t *testing.T, token string) jwt.MapClaims {
+		require.NotEmpty(t, token)
+		parsed, err := jwt.Parse(token, func(*jwt.Token) (interface{}, error) {
+			return &key.PublicKey, nil
+		})
+		require.NoError(t, err)
+		require.True(t, parsed.Valid())
+
+		claims := parsed.Claims
+		assert.Equal(t, "https://auth.example.com", claims["iss"])
+		assert.Equal(t, "foo", claims["aud"])
+		exp, ok := claims["exp"].(int64)
+		require.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(10*time.Minute), time.Unix(exp, 0), time.Minute)
+		return claims
+	}
+
+	for _, c := range []struct {
+		responseMode fosite.ResponseModeType
+		responseType string
+		expectMode   fosite.ResponseModeType
+	}{
+		{responseMode: fosite.ResponseModeQueryJWT, responseType: "code", expectMode: fosite.ResponseModeQueryJWT},
+		{responseMode: fosite.ResponseModeFragmentJWT, responseType: "code", expectMode: fosite.ResponseModeFragmentJWT},
+		{responseMode: fosite.ResponseModeFormPostJWT, responseType: "code", expectMode: fosite.ResponseModeFormPostJWT},
+		{responseMode: fosite.ResponseModeJWT, responseType: "code", expectMode: fosite.ResponseModeQueryJWT},
+		{responseMode: fosite.ResponseModeJWT, responseType: "token", expectMode: fosite.ResponseModeFragmentJWT},
+	} {
+		t.Run("mode="+string(c.responseMode)+"/type="+c.responseType, func(t *testing.T) {
+			ar := newRequest(c.responseMode, c.responseType)
+			assert.Equal(t, c.expectMode, ResponseMode(ar))
+
+			t.Run("case=response", func(t *testing.T) {
+				resp := fosite.NewAuthorizeResponse()
+				resp.AddParameter("code", "some-code")
+				resp.AddParameter("state", "some-state")
+
+				rw := httptest.NewRecorder()
+				h.WriteAuthorizeResponse(context.Background(), rw, ar, resp)
+
+				claims := verify(t, extract(t, c.expectMode, rw))
+				assert.Equal(t, "some-code", claims["code"])
+				assert.Equal(t, "some-state", claims["state"])
+			})
+
+			t.Run("case=error", func(t *testing.T) {
+				rw := httptest.NewRecorder()
+				h.WriteAuthorizeError(context.Background(), rw, ar, errors.WithStack(fosite.ErrAccessDenied))
+
+				claims := verify(t, extract(t, c.expectMode, rw))
+				assert.Equal(t, "access_denied", claims["error"])
+				assert.NotEmpty(t, claims["error_description"])
+				assert.Equal(t, "some-state", claims["state"])
+				assert.Nil(t, claims["code"])
+			})
+		})
+	}
+
+	t.Run("case=should not redirect errors to an invalid redirect uri", func(t *testing.T) {
+		ar := newRequest(fosite.ResponseModeQueryJWT, "code")
+		ar.RedirectURI = nil
+
+		rw := httptest.NewRecorder()
+		h.WriteAuthorizeError(context.Background(), rw, ar, errors.WithStack(fosite.ErrInvalidRequest))
+
+		assert.Equal(t, http.StatusBadRequest, rw.Code)
+		assert.Empty(t, rw.Header().Get("Location"))
+		assert.Contains(t, rw.Body.String(), `"error":"invalid_request"`)
+	})
+}
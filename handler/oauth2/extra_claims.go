@@ -0,0 +1,33 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package oauth2
+
+import (
+	"github.com/ory/x/errorsx"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/token/jwt"
+)
+
+// ExtraClaims returns the extra claims of the session, which end up in JWT access tokens and in the token
+// introspection response. The returned map can be modified in-place.
+func ExtraClaims(session fosite.Session) (map[string]interface{}, error) {
+	switch s := session.(type) {
+	case JWTSessionContainer:
+		claims, ok := s.GetJWTClaims().(*jwt.JWTClaims)
+		if !ok {
+			break
+		}
+		if claims.Extra == nil {
+			claims.Extra = make(map[string]interface{})
+		}
+		return claims.Extra, nil
+	case fosite.ExtraClaimsSession:
+		if claims := s.GetExtraClaims(); claims != nil {
+			return claims, nil
+		}
+	}
+
+	return nil, errorsx.WithStack(fosite.ErrServerError.WithHintf("Session must be able to carry extra claims but got type: %T", session))
+}
@@ -15,6 +15,7 @@ import (
 )
 
 var _ fosite.AuthorizeEndpointHandler = (*AuthorizeExplicitGrantHandler)(nil)
+var _ fosite.AuthorizeEndpointRequestValidator = (*AuthorizeExplicitGrantHandler)(nil)
 var _ fosite.TokenEndpointHandler = (*AuthorizeExplicitGrantHandler)(nil)
 
 // AuthorizeExplicitGrantHandler is a response handler for the Authorize Code grant using the explicit grant type
@@ -33,8 +34,12 @@ type AuthorizeExplicitGrantHandler struct {
 		fosite.AudienceStrategyProvider
 		fosite.RedirectSecureCheckerProvider
 		fosite.RefreshTokenScopesProvider
+		fosite.EnforceOfflineAccessConsentProvider
 		fosite.OmitRedirectScopeParamProvider
 		fosite.SanitationAllowedProvider
+		PublicClientRefreshTokenConfigProvider
+		fosite.EmptyClientGrantTypesPolicyProvider
+		fosite.IntrospectionCacheProvider
 	}
 }
 
@@ -52,7 +57,30 @@ func (c *AuthorizeExplicitGrantHandler) HandleAuthorizeEndpointRequest(ctx conte
 	}
 
 	ar.SetDefaultResponseMode(fosite.ResponseModeQuery)
+	if err := c.validateAuthorizeEndpointRequest(ctx, ar); err != nil {
+		return err
+	}
+
+	return c.IssueAuthorizeCode(ctx, ar, resp)
+}
+
+// ValidateAuthorizeEndpointRequest validates authorize requests using the authorize code flow and marks the "code"
+// response type as handled.
+func (c *AuthorizeExplicitGrantHandler) ValidateAuthorizeEndpointRequest(ctx context.Context, ar fosite.AuthorizeRequester) error {
+	if !ar.GetResponseTypes().ExactOne("code") {
+		return nil
+	}
 
+	ar.SetDefaultResponseMode(fosite.ResponseModeQuery)
+	if err := c.validateAuthorizeEndpointRequest(ctx, ar); err != nil {
+		return err
+	}
+
+	ar.SetResponseTypeHandled("code")
+	return nil
+}
+
+func (c *AuthorizeExplicitGrantHandler) validateAuthorizeEndpointRequest(ctx context.Context, ar fosite.AuthorizeRequester) error {
 	// Disabled because this is already handled at the authorize_request_handler
 	// if !ar.GetClient().GetResponseTypes().Has("code") {
 	// 	 return errorsx.WithStack(fosite.ErrInvalidGrant)
@@ -69,11 +97,7 @@ func (c *AuthorizeExplicitGrantHandler) HandleAuthorizeEndpointRequest(ctx conte
 		}
 	}
 
-	if err := c.Config.GetAudienceStrategy(ctx)(client.GetAudience(), ar.GetRequestedAudience()); err != nil {
-		return err
-	}
-
-	return c.IssueAuthorizeCode(ctx, ar, resp)
+	return c.Config.GetAudienceStrategy(ctx)(client.GetAudience(), ar.GetRequestedAudience())
 }
 
 func (c *AuthorizeExplicitGrantHandler) IssueAuthorizeCode(ctx context.Context, ar fosite.AuthorizeRequester, resp fosite.AuthorizeResponder) error {
@@ -82,8 +106,14 @@ func (c *AuthorizeExplicitGrantHandler) IssueAuthorizeCode(ctx context.Context,
 		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
 	}
 
+	allowedParameters := c.GetSanitationWhiteList(ctx)
+	if c.Config.GetEnforceOfflineAccessConsent(ctx) {
+		// The prompt is needed at the token endpoint to decide whether "offline_access" yields a refresh token.
+		allowedParameters = append(allowedParameters[:len(allowedParameters):len(allowedParameters)], "prompt")
+	}
+
 	ar.GetSession().SetExpiresAt(fosite.AuthorizeCode, time.Now().UTC().Add(c.Config.GetAuthorizeCodeLifespan(ctx)))
-	if err := c.CoreStorage.CreateAuthorizeCodeSession(ctx, signature, ar.Sanitize(c.GetSanitationWhiteList(ctx))); err != nil {
+	if err := c.CoreStorage.CreateAuthorizeCodeSession(ctx, signature, ar.Sanitize(allowedParameters)); err != nil {
 		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
 	}
 
@@ -5,6 +5,7 @@ package oauth2
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/ory/x/errorsx"
@@ -23,7 +24,7 @@ func (c *AuthorizeExplicitGrantHandler) HandleTokenEndpointRequest(ctx context.C
 		return errorsx.WithStack(errorsx.WithStack(fosite.ErrUnknownRequest))
 	}
 
-	if !request.GetClient().GetGrantTypes().Has("authorization_code") {
+	if !fosite.ClientHasGrantType(ctx, c.Config, request.GetClient(), "authorization_code") {
 		return errorsx.WithStack(fosite.ErrUnauthorizedClient.WithHint("The OAuth 2.0 Client is not allowed to use authorization grant \"authorization_code\"."))
 	}
 
@@ -49,6 +50,7 @@ func (c *AuthorizeExplicitGrantHandler) HandleTokenEndpointRequest(ctx context.C
 			hint += " Additionally, an error occurred during processing the refresh token revocation."
 			debug += "Revocation of refresh_token lead to error " + revErr.Error() + "."
 		}
+		fosite.InvalidateIntrospectionCache(ctx, c.Config, reqID)
 		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint(hint).WithDebug(debug))
 	} else if err != nil && errors.Is(err, fosite.ErrNotFound) {
 		return errorsx.WithStack(fosite.ErrInvalidGrant.WithWrap(err).WithDebug(err.Error()))
@@ -103,17 +105,46 @@ func (c *AuthorizeExplicitGrantHandler) HandleTokenEndpointRequest(ctx context.C
 	return nil
 }
 
+// RememberedConsentSession can be implemented by sessions to signal that the end-user's consent was remembered from a
+// previous authorization, in which case "offline_access" does not require "prompt=consent".
+type RememberedConsentSession interface {
+	// IsConsentRemembered returns true if the consent was remembered.
+	IsConsentRemembered() bool
+}
+
+func hasOfflineAccessConsent(request fosite.Requester) bool {
+	if fosite.Arguments(fosite.RemoveEmpty(strings.Split(request.GetRequestForm().Get("prompt"), " "))).Has("consent") {
+		return true
+	}
+
+	session, ok := request.GetSession().(RememberedConsentSession)
+	return ok && session.IsConsentRemembered()
+}
+
 func canIssueRefreshToken(ctx context.Context, c *AuthorizeExplicitGrantHandler, request fosite.Requester) bool {
 	scope := c.Config.GetRefreshTokenScopes(ctx)
 	// Require one of the refresh token scopes, if set.
 	if len(scope) > 0 && !request.GetGrantedScopes().HasOneOf(scope...) {
 		return false
 	}
+	// OpenID Connect ignores "offline_access" unless the end-user consented to it explicitly or the consent
+	// was remembered. Other refresh token scopes are not affected.
+	if c.Config.GetEnforceOfflineAccessConsent(ctx) && request.GetGrantedScopes().Has("offline_access") && !hasOfflineAccessConsent(request) {
+		var others []string
+		for _, s := range scope {
+			if s != "offline_access" {
+				others = append(others, s)
+			}
+		}
+		if len(others) == 0 || !request.GetGrantedScopes().HasOneOf(others...) {
+			return false
+		}
+	}
 	// Do not issue a refresh token to clients that cannot use the refresh token grant type.
-	if !request.GetClient().GetGrantTypes().Has("refresh_token") {
+	if !fosite.ClientHasGrantType(ctx, c.Config, request.GetClient(), "refresh_token") {
 		return false
 	}
-	return true
+	return CanIssueRefreshTokenToClient(ctx, c.Config, request.GetClient())
 }
 
 func (c *AuthorizeExplicitGrantHandler) PopulateTokenEndpointResponse(ctx context.Context, requester fosite.AccessRequester, responder fosite.AccessResponder) (err error) {
@@ -139,9 +170,11 @@ func (c *AuthorizeExplicitGrantHandler) PopulateTokenEndpointResponse(ctx contex
 		requester.GrantAudience(audience)
 	}
 
+	fosite.GrantAuthorizationDetailsOf(requester, authorizeRequest)
+
 	access, accessSignature, err := c.AccessTokenStrategy.GenerateAccessToken(ctx, requester)
 	if err != nil {
-		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+		return accessTokenGenerationError(err)
 	}
 
 	var refresh, refreshSignature string
@@ -182,6 +215,8 @@ func (c *AuthorizeExplicitGrantHandler) PopulateTokenEndpointResponse(ctx contex
 	if refresh != "" {
 		responder.SetExtra("refresh_token", refresh)
 	}
+	fosite.SetTokenSignature(responder, fosite.AccessToken, accessSignature)
+	fosite.SetTokenSignature(responder, fosite.RefreshToken, refreshSignature)
 
 	if err = storage.MaybeCommitTx(ctx, c.CoreStorage); err != nil {
 		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
@@ -199,3 +234,7 @@ func (c *AuthorizeExplicitGrantHandler) CanHandleTokenEndpointRequest(ctx contex
 	// Value MUST be set to "authorization_code"
 	return requester.GetGrantTypes().ExactOne("authorization_code")
 }
+
+func (c *AuthorizeExplicitGrantHandler) HandledGrantTypes() []fosite.GrantType {
+	return []fosite.GrantType{fosite.GrantTypeAuthorizationCode}
+}
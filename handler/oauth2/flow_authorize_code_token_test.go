@@ -201,6 +201,178 @@ func TestAuthorizeCode_PopulateTokenEndpointResponse(t *testing.T) {
 						assert.Equal(t, "foo", aresp.GetExtra("scope"))
 					},
 				},
+				{
+					areq: &fosite.AccessRequest{
+						GrantTypes: fosite.Arguments{"authorization_code"},
+						Request: fosite.Request{
+							Form: url.Values{},
+							Client: &fosite.DefaultClient{
+								GrantTypes: fosite.Arguments{"authorization_code", "refresh_token"},
+							},
+							GrantedScope: fosite.Arguments{"openid", "offline_access"},
+							Session:      &fosite.DefaultSession{},
+							RequestedAt:  time.Now().UTC(),
+						},
+					},
+					setup: func(t *testing.T, areq *fosite.AccessRequest, config *fosite.Config) {
+						config.RefreshTokenScopes = []string{"offline", "offline_access"}
+						config.EnforceOfflineAccessConsent = true
+						code, sig, err := strategy.GenerateAuthorizeCode(context.Background(), nil)
+						require.NoError(t, err)
+						areq.Form.Add("code", code)
+
+						require.NoError(t, store.CreateAuthorizeCodeSession(context.Background(), sig, areq))
+					},
+					description: "should not have refresh token because offline_access was granted without prompt=consent in strict mode",
+					check: func(t *testing.T, aresp *fosite.AccessResponse) {
+						assert.NotEmpty(t, aresp.AccessToken)
+						assert.Empty(t, aresp.GetExtra("refresh_token"))
+						assert.Equal(t, "openid offline_access", aresp.GetExtra("scope"))
+					},
+				},
+				{
+					areq: &fosite.AccessRequest{
+						GrantTypes: fosite.Arguments{"authorization_code"},
+						Request: fosite.Request{
+							Form: url.Values{"prompt": {"login consent"}},
+							Client: &fosite.DefaultClient{
+								GrantTypes: fosite.Arguments{"authorization_code", "refresh_token"},
+							},
+							GrantedScope: fosite.Arguments{"openid", "offline_access"},
+							Session:      &fosite.DefaultSession{},
+							RequestedAt:  time.Now().UTC(),
+						},
+					},
+					setup: func(t *testing.T, areq *fosite.AccessRequest, config *fosite.Config) {
+						config.RefreshTokenScopes = []string{"offline", "offline_access"}
+						config.EnforceOfflineAccessConsent = true
+						code, sig, err := strategy.GenerateAuthorizeCode(context.Background(), nil)
+						require.NoError(t, err)
+						areq.Form.Add("code", code)
+
+						require.NoError(t, store.CreateAuthorizeCodeSession(context.Background(), sig, areq))
+					},
+					description: "should have refresh token because offline_access was granted with prompt=consent in strict mode",
+					check: func(t *testing.T, aresp *fosite.AccessResponse) {
+						assert.NotEmpty(t, aresp.AccessToken)
+						assert.NotEmpty(t, aresp.GetExtra("refresh_token"))
+					},
+				},
+				{
+					areq: &fosite.AccessRequest{
+						GrantTypes: fosite.Arguments{"authorization_code"},
+						Request: fosite.Request{
+							Form: url.Values{},
+							Client: &fosite.DefaultClient{
+								GrantTypes: fosite.Arguments{"authorization_code", "refresh_token"},
+							},
+							GrantedScope: fosite.Arguments{"openid", "offline_access"},
+							Session:      &fosite.DefaultSession{},
+							RequestedAt:  time.Now().UTC(),
+						},
+					},
+					setup: func(t *testing.T, areq *fosite.AccessRequest, config *fosite.Config) {
+						config.RefreshTokenScopes = []string{"offline", "offline_access"}
+						code, sig, err := strategy.GenerateAuthorizeCode(context.Background(), nil)
+						require.NoError(t, err)
+						areq.Form.Add("code", code)
+
+						require.NoError(t, store.CreateAuthorizeCodeSession(context.Background(), sig, areq))
+					},
+					description: "should have refresh token because offline_access consent is not enforced in lenient mode",
+					check: func(t *testing.T, aresp *fosite.AccessResponse) {
+						assert.NotEmpty(t, aresp.AccessToken)
+						assert.NotEmpty(t, aresp.GetExtra("refresh_token"))
+					},
+				},
+				{
+					areq: &fosite.AccessRequest{
+						GrantTypes: fosite.Arguments{"authorization_code"},
+						Request: fosite.Request{
+							Form: url.Values{},
+							Client: &fosite.DefaultClient{
+								GrantTypes: fosite.Arguments{"authorization_code", "refresh_token"},
+								Public:     true,
+							},
+							GrantedScope: fosite.Arguments{"foo", "offline"},
+							Session:      &fosite.DefaultSession{},
+							RequestedAt:  time.Now().UTC(),
+						},
+					},
+					setup: func(t *testing.T, areq *fosite.AccessRequest, config *fosite.Config) {
+						config.RestrictPublicClientRefreshTokens = true
+						code, sig, err := strategy.GenerateAuthorizeCode(context.Background(), nil)
+						require.NoError(t, err)
+						areq.Form.Add("code", code)
+
+						require.NoError(t, store.CreateAuthorizeCodeSession(context.Background(), sig, areq))
+					},
+					description: "should not have refresh token because the client is public and PKCE is not enforced",
+					check: func(t *testing.T, aresp *fosite.AccessResponse) {
+						assert.NotEmpty(t, aresp.AccessToken)
+						assert.Empty(t, aresp.GetExtra("refresh_token"))
+						assert.Equal(t, "foo offline", aresp.GetExtra("scope"))
+					},
+				},
+				{
+					areq: &fosite.AccessRequest{
+						GrantTypes: fosite.Arguments{"authorization_code"},
+						Request: fosite.Request{
+							Form: url.Values{},
+							Client: &fosite.DefaultClient{
+								GrantTypes: fosite.Arguments{"authorization_code", "refresh_token"},
+								Public:     true,
+							},
+							GrantedScope: fosite.Arguments{"foo", "offline"},
+							Session:      &fosite.DefaultSession{},
+							RequestedAt:  time.Now().UTC(),
+						},
+					},
+					setup: func(t *testing.T, areq *fosite.AccessRequest, config *fosite.Config) {
+						config.RestrictPublicClientRefreshTokens = true
+						config.EnforcePKCEForPublicClients = true
+						code, sig, err := strategy.GenerateAuthorizeCode(context.Background(), nil)
+						require.NoError(t, err)
+						areq.Form.Add("code", code)
+
+						require.NoError(t, store.CreateAuthorizeCodeSession(context.Background(), sig, areq))
+					},
+					description: "should have refresh token because the client is public and PKCE is enforced",
+					check: func(t *testing.T, aresp *fosite.AccessResponse) {
+						assert.NotEmpty(t, aresp.AccessToken)
+						assert.NotEmpty(t, aresp.GetExtra("refresh_token"))
+						assert.Equal(t, "foo offline", aresp.GetExtra("scope"))
+					},
+				},
+				{
+					areq: &fosite.AccessRequest{
+						GrantTypes: fosite.Arguments{"authorization_code"},
+						Request: fosite.Request{
+							Form: url.Values{},
+							Client: &fosite.DefaultClient{
+								GrantTypes: fosite.Arguments{"authorization_code", "refresh_token"},
+								Public:     false,
+							},
+							GrantedScope: fosite.Arguments{"foo", "offline"},
+							Session:      &fosite.DefaultSession{},
+							RequestedAt:  time.Now().UTC(),
+						},
+					},
+					setup: func(t *testing.T, areq *fosite.AccessRequest, config *fosite.Config) {
+						config.RestrictPublicClientRefreshTokens = true
+						code, sig, err := strategy.GenerateAuthorizeCode(context.Background(), nil)
+						require.NoError(t, err)
+						areq.Form.Add("code", code)
+
+						require.NoError(t, store.CreateAuthorizeCodeSession(context.Background(), sig, areq))
+					},
+					description: "should have refresh token because the client is confidential",
+					check: func(t *testing.T, aresp *fosite.AccessResponse) {
+						assert.NotEmpty(t, aresp.AccessToken)
+						assert.NotEmpty(t, aresp.GetExtra("refresh_token"))
+						assert.Equal(t, "foo offline", aresp.GetExtra("scope"))
+					},
+				},
 			} {
 				t.Run("case="+c.description, func(t *testing.T) {
 					config := &fosite.Config{
@@ -15,6 +15,7 @@ import (
 )
 
 var _ fosite.AuthorizeEndpointHandler = (*AuthorizeImplicitGrantTypeHandler)(nil)
+var _ fosite.AuthorizeEndpointRequestValidator = (*AuthorizeImplicitGrantTypeHandler)(nil)
 
 // AuthorizeImplicitGrantTypeHandler is a response handler for the Authorize Code grant using the implicit grant type
 // as defined in https://tools.ietf.org/html/rfc6749#section-4.2
@@ -27,6 +28,7 @@ type AuthorizeImplicitGrantTypeHandler struct {
 		fosite.AccessTokenLifespanProvider
 		fosite.ScopeStrategyProvider
 		fosite.AudienceStrategyProvider
+		fosite.EmptyClientGrantTypesPolicyProvider
 	}
 }
 
@@ -37,13 +39,39 @@ func (c *AuthorizeImplicitGrantTypeHandler) HandleAuthorizeEndpointRequest(ctx c
 	}
 
 	ar.SetDefaultResponseMode(fosite.ResponseModeFragment)
+	if err := c.validateAuthorizeEndpointRequest(ctx, ar); err != nil {
+		return err
+	}
+
+	// there is no need to check for https, because implicit flow does not require https
+	// https://tools.ietf.org/html/rfc6819#section-4.4.2
+
+	return c.IssueImplicitAccessToken(ctx, ar, resp)
+}
+
+// ValidateAuthorizeEndpointRequest validates authorize requests using the implicit flow and marks the "token"
+// response type as handled.
+func (c *AuthorizeImplicitGrantTypeHandler) ValidateAuthorizeEndpointRequest(ctx context.Context, ar fosite.AuthorizeRequester) error {
+	if !ar.GetResponseTypes().ExactOne("token") {
+		return nil
+	}
+
+	ar.SetDefaultResponseMode(fosite.ResponseModeFragment)
+	if err := c.validateAuthorizeEndpointRequest(ctx, ar); err != nil {
+		return err
+	}
 
+	ar.SetResponseTypeHandled("token")
+	return nil
+}
+
+func (c *AuthorizeImplicitGrantTypeHandler) validateAuthorizeEndpointRequest(ctx context.Context, ar fosite.AuthorizeRequester) error {
 	// Disabled because this is already handled at the authorize_request_handler
 	// if !ar.GetClient().GetResponseTypes().Has("token") {
 	// 	 return errorsx.WithStack(fosite.ErrInvalidGrant.WithDebug("The client is not allowed to use response type token"))
 	// }
 
-	if !ar.GetClient().GetGrantTypes().Has("implicit") {
+	if !fosite.ClientHasGrantType(ctx, c.Config, ar.GetClient(), "implicit") {
 		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The OAuth 2.0 Client is not allowed to use the authorization grant 'implicit'."))
 	}
 
@@ -54,14 +82,7 @@ func (c *AuthorizeImplicitGrantTypeHandler) HandleAuthorizeEndpointRequest(ctx c
 		}
 	}
 
-	if err := c.Config.GetAudienceStrategy(ctx)(client.GetAudience(), ar.GetRequestedAudience()); err != nil {
-		return err
-	}
-
-	// there is no need to check for https, because implicit flow does not require https
-	// https://tools.ietf.org/html/rfc6819#section-4.4.2
-
-	return c.IssueImplicitAccessToken(ctx, ar, resp)
+	return c.Config.GetAudienceStrategy(ctx)(client.GetAudience(), ar.GetRequestedAudience())
 }
 
 func (c *AuthorizeImplicitGrantTypeHandler) IssueImplicitAccessToken(ctx context.Context, ar fosite.AuthorizeRequester, resp fosite.AuthorizeResponder) error {
@@ -74,7 +95,7 @@ func (c *AuthorizeImplicitGrantTypeHandler) IssueImplicitAccessToken(ctx context
 	// Generate the code
 	token, signature, err := c.AccessTokenStrategy.GenerateAccessToken(ctx, ar)
 	if err != nil {
-		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+		return accessTokenGenerationError(err)
 	}
 
 	if err := c.AccessTokenStorage.CreateAccessTokenSession(ctx, signature, ar.Sanitize([]string{})); err != nil {
@@ -50,6 +50,13 @@ func TestAuthorizeImplicit_EndpointHandler(t *testing.T) {
 			},
 			expectErr: fosite.ErrServerError,
 		},
+		{
+			description: "should pass through OAuth2 errors of the access token generation",
+			setup: func() {
+				chgen.EXPECT().GenerateAccessToken(gomock.Any(), areq).Return("", "", errors.WithStack(fosite.ErrInvalidRequest.WithHint("No audience was granted.")))
+			},
+			expectErr: fosite.ErrInvalidRequest,
+		},
 		{
 			description: "should fail because scope invalid",
 			setup: func() {
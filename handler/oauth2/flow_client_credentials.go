@@ -20,6 +20,7 @@ type ClientCredentialsGrantHandler struct {
 		fosite.ScopeStrategyProvider
 		fosite.AudienceStrategyProvider
 		fosite.AccessTokenLifespanProvider
+		fosite.EmptyClientGrantTypesPolicyProvider
 	}
 }
 
@@ -59,7 +60,7 @@ func (c *ClientCredentialsGrantHandler) PopulateTokenEndpointResponse(ctx contex
 		return errorsx.WithStack(fosite.ErrUnknownRequest)
 	}
 
-	if !request.GetClient().GetGrantTypes().Has("client_credentials") {
+	if !fosite.ClientHasGrantType(ctx, c.Config, request.GetClient(), "client_credentials") {
 		return errorsx.WithStack(fosite.ErrUnauthorizedClient.WithHint("The OAuth 2.0 Client is not allowed to use authorization grant 'client_credentials'."))
 	}
 
@@ -76,3 +77,7 @@ func (c *ClientCredentialsGrantHandler) CanHandleTokenEndpointRequest(ctx contex
 	// Value MUST be set to "client_credentials".
 	return requester.GetGrantTypes().ExactOne("client_credentials")
 }
+
+func (c *ClientCredentialsGrantHandler) HandledGrantTypes() []fosite.GrantType {
+	return []fosite.GrantType{fosite.GrantTypeClientCredentials}
+}
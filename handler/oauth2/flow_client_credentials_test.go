@@ -152,6 +152,25 @@ func TestClientCredentials_PopulateTokenEndpointResponse(t *testing.T) {
 				store.EXPECT().CreateAccessTokenSession(gomock.Any(), "bar", gomock.Eq(areq.Sanitize([]string{}))).Return(nil)
 			},
 		},
+		{
+			description: "should fail because client without grant types may only use authorization_code by default",
+			expectErr:   fosite.ErrUnauthorizedClient,
+			mock: func() {
+				areq.GrantTypes = fosite.Arguments{"client_credentials"}
+				areq.Client = &fosite.DefaultClient{}
+			},
+		},
+		{
+			description: "should pass because client without grant types may use the standard grants",
+			mock: func() {
+				h.Config.(*fosite.Config).EmptyClientGrantTypesPolicy = fosite.EmptyClientGrantTypesAllowStandard
+				areq.GrantTypes = fosite.Arguments{"client_credentials"}
+				areq.Session = &fosite.DefaultSession{}
+				areq.Client = &fosite.DefaultClient{}
+				chgen.EXPECT().GenerateAccessToken(gomock.Any(), areq).Return("tokenfoo.bar", "bar", nil)
+				store.EXPECT().CreateAccessTokenSession(gomock.Any(), "bar", gomock.Eq(areq.Sanitize([]string{}))).Return(nil)
+			},
+		},
 	} {
 		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
 			c.mock()
@@ -29,7 +29,14 @@ type RefreshTokenGrantHandler struct {
 		fosite.ScopeStrategyProvider
 		fosite.AudienceStrategyProvider
 		fosite.RefreshTokenScopesProvider
+		fosite.RefreshTokenScopeStrategyProvider
+		fosite.EmptyClientGrantTypesPolicyProvider
+		fosite.IntrospectionCacheProvider
+		fosite.AuditSinkProvider
+		fosite.StrictAuditProvider
 	}
+
+	locks signatureLocks
 }
 
 // HandleTokenEndpointRequest implements https://tools.ietf.org/html/rfc6749#section-6
@@ -38,7 +45,7 @@ func (c *RefreshTokenGrantHandler) HandleTokenEndpointRequest(ctx context.Contex
 		return errorsx.WithStack(fosite.ErrUnknownRequest)
 	}
 
-	if !request.GetClient().GetGrantTypes().Has("refresh_token") {
+	if !fosite.ClientHasGrantType(ctx, c.Config, request.GetClient(), "refresh_token") {
 		return errorsx.WithStack(fosite.ErrUnauthorizedClient.WithHint("The OAuth 2.0 Client is not allowed to use authorization grant 'refresh_token'."))
 	}
 
@@ -77,26 +84,45 @@ func (c *RefreshTokenGrantHandler) HandleTokenEndpointRequest(ctx context.Contex
 		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The OAuth 2.0 Client ID from this request does not match the ID during the initial token issuance."))
 	}
 
+	// The client may request a narrower scope, which is then granted to the new access token.
+	scopes, err := c.Config.GetRefreshTokenScopeStrategy(ctx)(ctx, originalRequest.GetGrantedScopes(), request.GetRequestedScopes())
+	if err != nil {
+		return err
+	}
+
+	// Likewise, the client may request a subset of the originally granted audience.
+	audience := originalRequest.GetGrantedAudience()
+	if requested := request.GetRequestedAudience(); len(requested) > 0 {
+		for _, aud := range requested {
+			if !audience.Has(aud) {
+				return errorsx.WithStack(fosite.ErrInvalidTarget.WithHintf("The requested audience '%s' was not originally granted by the resource owner.", aud))
+			}
+		}
+		audience = requested
+	}
+
 	request.SetID(originalRequest.GetID())
 	request.SetSession(originalRequest.GetSession().Clone())
 	request.SetRequestedScopes(originalRequest.GetRequestedScopes())
 	request.SetRequestedAudience(originalRequest.GetRequestedAudience())
 
-	for _, scope := range originalRequest.GetGrantedScopes() {
+	for _, scope := range scopes {
 		if !c.Config.GetScopeStrategy(ctx)(request.GetClient().GetScopes(), scope) {
 			return errorsx.WithStack(fosite.ErrInvalidScope.WithHintf("The OAuth 2.0 Client is not allowed to request scope '%s'.", scope))
 		}
 		request.GrantScope(scope)
 	}
 
-	if err := c.Config.GetAudienceStrategy(ctx)(request.GetClient().GetAudience(), originalRequest.GetGrantedAudience()); err != nil {
+	if err := c.Config.GetAudienceStrategy(ctx)(request.GetClient().GetAudience(), audience); err != nil {
 		return err
 	}
 
-	for _, audience := range originalRequest.GetGrantedAudience() {
-		request.GrantAudience(audience)
+	for _, aud := range audience {
+		request.GrantAudience(aud)
 	}
 
+	fosite.GrantAuthorizationDetailsOf(request, originalRequest)
+
 	atLifespan := fosite.GetEffectiveLifespan(request.GetClient(), fosite.GrantTypeRefreshToken, fosite.AccessToken, c.Config.GetAccessTokenLifespan(ctx))
 	request.GetSession().SetExpiresAt(fosite.AccessToken, time.Now().UTC().Add(atLifespan).Round(time.Second))
 
@@ -116,7 +142,7 @@ func (c *RefreshTokenGrantHandler) PopulateTokenEndpointResponse(ctx context.Con
 
 	accessToken, accessSignature, err := c.AccessTokenStrategy.GenerateAccessToken(ctx, requester)
 	if err != nil {
-		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+		return accessTokenGenerationError(err)
 	}
 
 	refreshToken, refreshSignature, err := c.RefreshTokenStrategy.GenerateRefreshToken(ctx, requester)
@@ -126,6 +152,11 @@ func (c *RefreshTokenGrantHandler) PopulateTokenEndpointResponse(ctx context.Con
 
 	signature := c.RefreshTokenStrategy.RefreshTokenSignature(ctx, requester.GetRequestForm().Get("refresh_token"))
 
+	// Concurrent refreshes of the same refresh token are serialized, so that only the first one rotates the token and
+	// the others find it inactive.
+	unlock := c.locks.lock(signature)
+	defer unlock()
+
 	ctx, err = storage.MaybeBeginTx(ctx, c.TokenRevocationStorage)
 	if err != nil {
 		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
@@ -135,7 +166,9 @@ func (c *RefreshTokenGrantHandler) PopulateTokenEndpointResponse(ctx context.Con
 	}()
 
 	ts, err := c.TokenRevocationStorage.GetRefreshTokenSession(ctx, signature, nil)
-	if err != nil {
+	if errors.Is(err, fosite.ErrInactiveToken) {
+		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The refresh token was already used by a concurrent request.").WithWrap(err).WithDebug(err.Error()))
+	} else if err != nil {
 		return err
 	} else if err := c.TokenRevocationStorage.RevokeAccessToken(ctx, ts.GetID()); err != nil {
 		return err
@@ -152,7 +185,19 @@ func (c *RefreshTokenGrantHandler) PopulateTokenEndpointResponse(ctx context.Con
 		return err
 	}
 
-	if err = c.TokenRevocationStorage.CreateRefreshTokenSession(ctx, refreshSignature, storeReq); err != nil {
+	// If a new refresh token is issued, the refresh token scope MUST be identical to that of the refresh token
+	// included by the client in the request, see https://tools.ietf.org/html/rfc6749#section-6. The scopes and
+	// audience granted to this request are a subset of them, so granting them all restores those of the refresh token.
+	refreshReq := requester.Sanitize([]string{})
+	refreshReq.SetID(ts.GetID())
+	for _, scope := range ts.GetGrantedScopes() {
+		refreshReq.GrantScope(scope)
+	}
+	for _, audience := range ts.GetGrantedAudience() {
+		refreshReq.GrantAudience(audience)
+	}
+
+	if err = c.TokenRevocationStorage.CreateRefreshTokenSession(ctx, refreshSignature, refreshReq); err != nil {
 		return err
 	}
 
@@ -162,12 +207,20 @@ func (c *RefreshTokenGrantHandler) PopulateTokenEndpointResponse(ctx context.Con
 	responder.SetExpiresIn(getExpiresIn(requester, fosite.AccessToken, atLifespan, time.Now().UTC()))
 	responder.SetScopes(requester.GetGrantedScopes())
 	responder.SetExtra("refresh_token", refreshToken)
+	fosite.SetTokenSignature(responder, fosite.AccessToken, accessSignature)
+	fosite.SetTokenSignature(responder, fosite.RefreshToken, refreshSignature)
 
 	if err = storage.MaybeCommitTx(ctx, c.TokenRevocationStorage); err != nil {
 		return err
 	}
 
-	return nil
+	// The new access token was not introspected yet, so only the revoked access token is removed from the cache.
+	fosite.InvalidateIntrospectionCache(ctx, c.Config, ts.GetID())
+
+	// The tokens issued in exchange are recorded by the token endpoint.
+	event := fosite.NewAuditEvent(fosite.AuditEventTokenRevoked, ts, fosite.AccessToken, fosite.RefreshToken)
+	event.RefreshTokenSignature = signature
+	return fosite.RecordAuditEvent(ctx, c.Config, event)
 }
 
 // Reference: https://tools.ietf.org/html/rfc6819#section-5.2.2.3
@@ -204,7 +257,11 @@ func (c *RefreshTokenGrantHandler) handleRefreshTokenReuse(ctx context.Context,
 		return err
 	}
 
-	return nil
+	fosite.InvalidateIntrospectionCache(ctx, c.Config, req.GetID())
+
+	event := fosite.NewAuditEvent(fosite.AuditEventTokenRevoked, req, fosite.AccessToken, fosite.RefreshToken)
+	event.RefreshTokenSignature = signature
+	return fosite.RecordAuditEvent(ctx, c.Config, event)
 }
 
 func (c *RefreshTokenGrantHandler) handleRefreshTokenEndpointStorageError(ctx context.Context, storageErr error) (err error) {
@@ -218,6 +275,10 @@ func (c *RefreshTokenGrantHandler) handleRefreshTokenEndpointStorageError(ctx co
 		}
 	}()
 
+	if errors.Is(storageErr, fosite.ErrInvalidGrant) {
+		return storageErr
+	}
+
 	if errors.Is(storageErr, fosite.ErrSerializationFailure) {
 		return errorsx.WithStack(fosite.ErrInvalidRequest.
 			WithDebugf(storageErr.Error()).
@@ -242,3 +303,7 @@ func (c *RefreshTokenGrantHandler) CanHandleTokenEndpointRequest(ctx context.Con
 	// Value MUST be set to "refresh_token".
 	return requester.GetGrantTypes().ExactOne("refresh_token")
 }
+
+func (c *RefreshTokenGrantHandler) HandledGrantTypes() []fosite.GrantType {
+	return []fosite.GrantType{fosite.GrantTypeRefreshToken}
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"sync"
 	"testing"
 	"time"
 
@@ -184,6 +185,163 @@ func TestRefreshFlow_HandleTokenEndpointRequest(t *testing.T) {
 						assert.EqualValues(t, areq.Form.Get("or_request_id"), areq.GetID(), "Requester ID should be replaced based on the refresh token session")
 					},
 				},
+				{
+					description: "should pass and narrow the granted scopes to the requested ones",
+					setup: func(config *fosite.Config) {
+						areq.GrantTypes = fosite.Arguments{"refresh_token"}
+						areq.RequestedScope = fosite.Arguments{"offline"}
+						areq.Client = &fosite.DefaultClient{
+							ID:         "foo",
+							GrantTypes: fosite.Arguments{"refresh_token"},
+							Scopes:     []string{"foo", "bar", "offline"},
+						}
+
+						token, sig, err := strategy.GenerateRefreshToken(context.Background(), nil)
+						require.NoError(t, err)
+
+						areq.Form.Add("refresh_token", token)
+						err = store.CreateRefreshTokenSession(context.Background(), sig, &fosite.Request{
+							Client:         areq.Client,
+							GrantedScope:   fosite.Arguments{"foo", "offline"},
+							RequestedScope: fosite.Arguments{"foo", "bar", "offline"},
+							Session:        sess,
+							Form:           url.Values{"foo": []string{"bar"}},
+							RequestedAt:    time.Now().UTC().Add(-time.Hour).Round(time.Hour),
+						})
+						require.NoError(t, err)
+					},
+					expect: func(t *testing.T) {
+						assert.Equal(t, fosite.Arguments{"offline"}, areq.GrantedScope)
+						assert.Equal(t, fosite.Arguments{"foo", "bar", "offline"}, areq.RequestedScope)
+					},
+				},
+				{
+					description: "should fail because the requested scopes are wider than the granted ones",
+					setup: func(config *fosite.Config) {
+						areq.GrantTypes = fosite.Arguments{"refresh_token"}
+						areq.RequestedScope = fosite.Arguments{"foo", "bar", "offline"}
+						areq.Client = &fosite.DefaultClient{
+							ID:         "foo",
+							GrantTypes: fosite.Arguments{"refresh_token"},
+							Scopes:     []string{"foo", "bar", "offline"},
+						}
+
+						token, sig, err := strategy.GenerateRefreshToken(context.Background(), nil)
+						require.NoError(t, err)
+
+						areq.Form.Add("refresh_token", token)
+						err = store.CreateRefreshTokenSession(context.Background(), sig, &fosite.Request{
+							Client:         areq.Client,
+							GrantedScope:   fosite.Arguments{"foo", "offline"},
+							RequestedScope: fosite.Arguments{"foo", "bar", "offline"},
+							Session:        sess,
+							Form:           url.Values{"foo": []string{"bar"}},
+							RequestedAt:    time.Now().UTC().Add(-time.Hour).Round(time.Hour),
+						})
+						require.NoError(t, err)
+					},
+					expectErr: fosite.ErrInvalidScope,
+				},
+				{
+					description: "should pass and narrow the granted audience to the requested one",
+					setup: func(config *fosite.Config) {
+						areq.GrantTypes = fosite.Arguments{"refresh_token"}
+						areq.RequestedAudience = fosite.Arguments{"https://www.ory.sh/api"}
+						areq.Client = &fosite.DefaultClient{
+							ID:         "foo",
+							GrantTypes: fosite.Arguments{"refresh_token"},
+							Scopes:     []string{"foo", "offline"},
+							Audience:   []string{"https://www.ory.sh/api", "https://www.ory.sh/admin"},
+						}
+
+						token, sig, err := strategy.GenerateRefreshToken(context.Background(), nil)
+						require.NoError(t, err)
+
+						areq.Form.Add("refresh_token", token)
+						err = store.CreateRefreshTokenSession(context.Background(), sig, &fosite.Request{
+							Client:            areq.Client,
+							GrantedScope:      fosite.Arguments{"foo", "offline"},
+							RequestedScope:    fosite.Arguments{"foo", "offline"},
+							GrantedAudience:   fosite.Arguments{"https://www.ory.sh/api", "https://www.ory.sh/admin"},
+							RequestedAudience: fosite.Arguments{"https://www.ory.sh/api", "https://www.ory.sh/admin"},
+							Session:           sess,
+							Form:              url.Values{"foo": []string{"bar"}},
+							RequestedAt:       time.Now().UTC().Add(-time.Hour).Round(time.Hour),
+						})
+						require.NoError(t, err)
+					},
+					expect: func(t *testing.T) {
+						assert.Equal(t, fosite.Arguments{"https://www.ory.sh/api"}, areq.GrantedAudience)
+						assert.Equal(t, fosite.Arguments{"https://www.ory.sh/api", "https://www.ory.sh/admin"}, areq.RequestedAudience)
+					},
+				},
+				{
+					description: "should fail because the requested audience was not originally granted",
+					setup: func(config *fosite.Config) {
+						areq.GrantTypes = fosite.Arguments{"refresh_token"}
+						areq.RequestedAudience = fosite.Arguments{"https://www.ory.sh/api", "https://www.ory.sh/admin"}
+						areq.Client = &fosite.DefaultClient{
+							ID:         "foo",
+							GrantTypes: fosite.Arguments{"refresh_token"},
+							Scopes:     []string{"foo", "offline"},
+							Audience:   []string{"https://www.ory.sh/api", "https://www.ory.sh/admin"},
+						}
+
+						token, sig, err := strategy.GenerateRefreshToken(context.Background(), nil)
+						require.NoError(t, err)
+
+						areq.Form.Add("refresh_token", token)
+						err = store.CreateRefreshTokenSession(context.Background(), sig, &fosite.Request{
+							Client:            areq.Client,
+							GrantedScope:      fosite.Arguments{"foo", "offline"},
+							RequestedScope:    fosite.Arguments{"foo", "offline"},
+							GrantedAudience:   fosite.Arguments{"https://www.ory.sh/api"},
+							RequestedAudience: fosite.Arguments{"https://www.ory.sh/api"},
+							Session:           sess,
+							Form:              url.Values{"foo": []string{"bar"}},
+							RequestedAt:       time.Now().UTC().Add(-time.Hour).Round(time.Hour),
+						})
+						require.NoError(t, err)
+					},
+					expectErr: fosite.ErrInvalidTarget,
+				},
+				{
+					description: "should pass and drop a scope with a custom refresh token scope strategy",
+					setup: func(config *fosite.Config) {
+						config.RefreshTokenScopeStrategy = func(_ context.Context, granted fosite.Arguments, _ fosite.Arguments) (fosite.Arguments, error) {
+							var scopes fosite.Arguments
+							for _, scope := range granted {
+								if scope != "offline" {
+									scopes = append(scopes, scope)
+								}
+							}
+							return scopes, nil
+						}
+						areq.GrantTypes = fosite.Arguments{"refresh_token"}
+						areq.Client = &fosite.DefaultClient{
+							ID:         "foo",
+							GrantTypes: fosite.Arguments{"refresh_token"},
+							Scopes:     []string{"foo", "bar", "offline"},
+						}
+
+						token, sig, err := strategy.GenerateRefreshToken(context.Background(), nil)
+						require.NoError(t, err)
+
+						areq.Form.Add("refresh_token", token)
+						err = store.CreateRefreshTokenSession(context.Background(), sig, &fosite.Request{
+							Client:         areq.Client,
+							GrantedScope:   fosite.Arguments{"foo", "offline"},
+							RequestedScope: fosite.Arguments{"foo", "bar", "offline"},
+							Session:        sess,
+							Form:           url.Values{"foo": []string{"bar"}},
+							RequestedAt:    time.Now().UTC().Add(-time.Hour).Round(time.Hour),
+						})
+						require.NoError(t, err)
+					},
+					expect: func(t *testing.T) {
+						assert.Equal(t, fosite.Arguments{"foo"}, areq.GrantedScope)
+					},
+				},
 				{
 					description: "should pass with custom client lifespans",
 					setup: func(config *fosite.Config) {
@@ -486,6 +644,37 @@ func TestRefreshFlow_PopulateTokenEndpointResponse(t *testing.T) {
 						assert.Equal(t, "foo bar", aresp.ToMap()["scope"])
 					},
 				},
+				{
+					description: "should pass and keep the scopes of the refresh token when the access token was narrowed",
+					setup: func(config *fosite.Config) {
+						areq.ID = "req-id"
+						areq.GrantTypes = fosite.Arguments{"refresh_token"}
+						areq.GrantedScope = fosite.Arguments{"foo"}
+
+						token, signature, err := strategy.GenerateRefreshToken(context.Background(), nil)
+						require.NoError(t, err)
+						require.NoError(t, store.CreateRefreshTokenSession(context.Background(), signature, &fosite.Request{
+							ID:           "req-id",
+							Client:       areq.Client,
+							GrantedScope: fosite.Arguments{"foo", "bar"},
+							Session:      areq.Session,
+						}))
+						areq.Form.Add("refresh_token", token)
+					},
+					check: func(t *testing.T) {
+						assert.Equal(t, "foo", aresp.ToMap()["scope"])
+
+						accessSignature := strategy.AccessTokenSignature(context.Background(), aresp.GetAccessToken())
+						at, err := store.GetAccessTokenSession(context.Background(), accessSignature, nil)
+						require.NoError(t, err)
+						assert.Equal(t, fosite.Arguments{"foo"}, at.GetGrantedScopes())
+
+						refreshSignature := strategy.RefreshTokenSignature(context.Background(), aresp.ToMap()["refresh_token"].(string))
+						rt, err := store.GetRefreshTokenSession(context.Background(), refreshSignature, nil)
+						require.NoError(t, err)
+						assert.Equal(t, fosite.Arguments{"foo", "bar"}, rt.GetGrantedScopes())
+					},
+				},
 			} {
 				t.Run("case="+c.description, func(t *testing.T) {
 					config := &fosite.Config{
@@ -522,6 +711,174 @@ func TestRefreshFlow_PopulateTokenEndpointResponse(t *testing.T) {
 	}
 }
 
+func TestRefreshFlow_NarrowedAudience(t *testing.T) {
+	store := storage.NewMemoryStore()
+	h := &RefreshTokenGrantHandler{
+		TokenRevocationStorage: store,
+		RefreshTokenStrategy:   hmacshaStrategy,
+		AccessTokenStrategy:    hmacshaStrategy,
+		Config: &fosite.Config{
+			AccessTokenLifespan:      time.Hour,
+			RefreshTokenLifespan:     time.Hour,
+			ScopeStrategy:            fosite.HierarchicScopeStrategy,
+			AudienceMatchingStrategy: fosite.DefaultAudienceMatchingStrategy,
+		},
+	}
+
+	client := &fosite.DefaultClient{
+		ID:         "foo",
+		GrantTypes: fosite.Arguments{"refresh_token"},
+		Scopes:     []string{"offline"},
+		Audience:   []string{"https://www.ory.sh/api", "https://www.ory.sh/admin"},
+	}
+	token, signature, err := hmacshaStrategy.GenerateRefreshToken(context.Background(), nil)
+	require.NoError(t, err)
+	require.NoError(t, store.CreateRefreshTokenSession(context.Background(), signature, &fosite.Request{
+		ID:              "req-id",
+		Client:          client,
+		GrantedScope:    fosite.Arguments{"offline"},
+		GrantedAudience: fosite.Arguments{"https://www.ory.sh/api", "https://www.ory.sh/admin"},
+		Session:         &fosite.DefaultSession{ExpiresAt: map[fosite.TokenType]time.Time{fosite.RefreshToken: time.Now().UTC().Add(time.Hour)}},
+	}))
+
+	refresh := func(t *testing.T, token string, audience ...string) *fosite.AccessResponse {
+		areq := fosite.NewAccessRequest(&fosite.DefaultSession{})
+		areq.GrantTypes = fosite.Arguments{"refresh_token"}
+		areq.Client = client
+		areq.Form = url.Values{"refresh_token": {token}}
+		areq.RequestedAudience = audience
+		require.NoError(t, h.HandleTokenEndpointRequest(context.Background(), areq))
+		assert.Equal(t, fosite.Arguments(audience), areq.GetGrantedAudience())
+
+		aresp := fosite.NewAccessResponse()
+		require.NoError(t, h.PopulateTokenEndpointResponse(context.Background(), areq, aresp))
+		return aresp
+	}
+
+	aresp := refresh(t, token, "https://www.ory.sh/api")
+	refreshSignature := hmacshaStrategy.RefreshTokenSignature(context.Background(), aresp.ToMap()["refresh_token"].(string))
+	rt, err := store.GetRefreshTokenSession(context.Background(), refreshSignature, nil)
+	require.NoError(t, err)
+	assert.Equal(t, fosite.Arguments{"https://www.ory.sh/api", "https://www.ory.sh/admin"}, rt.GetGrantedAudience())
+
+	refresh(t, aresp.ToMap()["refresh_token"].(string), "https://www.ory.sh/api", "https://www.ory.sh/admin")
+}
+
+// slowRefreshTokenStore widens the window between reading and rotating a refresh token, so that concurrent
+// refreshes overlap.
+type slowRefreshTokenStore struct {
+	*storage.MemoryStore
+}
+
+func (s slowRefreshTokenStore) GetRefreshTokenSession(ctx context.Context, signature string, session fosite.Session) (fosite.Requester, error) {
+	req, err := s.MemoryStore.GetRefreshTokenSession(ctx, signature, session)
+	time.Sleep(10 * time.Millisecond)
+	return req, err
+}
+
+func TestRefreshFlow_ConcurrentRefresh(t *testing.T) {
+	const n = 10
+
+	store := storage.NewMemoryStore()
+	h := &RefreshTokenGrantHandler{
+		TokenRevocationStorage: slowRefreshTokenStore{store},
+		RefreshTokenStrategy:   hmacshaStrategy,
+		AccessTokenStrategy:    hmacshaStrategy,
+		Config: &fosite.Config{
+			AccessTokenLifespan:      time.Hour,
+			RefreshTokenLifespan:     time.Hour,
+			ScopeStrategy:            fosite.HierarchicScopeStrategy,
+			AudienceMatchingStrategy: fosite.DefaultAudienceMatchingStrategy,
+		},
+	}
+
+	client := &fosite.DefaultClient{ID: "foo", GrantTypes: fosite.Arguments{"refresh_token"}, Scopes: []string{"offline"}}
+	token, signature, err := hmacshaStrategy.GenerateRefreshToken(context.Background(), nil)
+	require.NoError(t, err)
+	require.NoError(t, store.CreateRefreshTokenSession(context.Background(), signature, &fosite.Request{
+		ID:           "req-id",
+		Client:       client,
+		GrantedScope: fosite.Arguments{"offline"},
+		Session:      &fosite.DefaultSession{ExpiresAt: map[fosite.TokenType]time.Time{fosite.RefreshToken: time.Now().UTC().Add(time.Hour)}},
+	}))
+
+	// All requests pass validation before any of them rotates the refresh token, as racing requests would.
+	requests := make([]*fosite.AccessRequest, n)
+	for i := range requests {
+		requests[i] = fosite.NewAccessRequest(&fosite.DefaultSession{})
+		requests[i].GrantTypes = fosite.Arguments{"refresh_token"}
+		requests[i].Client = client
+		requests[i].Form = url.Values{"refresh_token": {token}}
+		require.NoError(t, h.HandleTokenEndpointRequest(context.Background(), requests[i]))
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	responses := make([]*fosite.AccessResponse, n)
+	for i := range requests {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i] = fosite.NewAccessResponse()
+			errs[i] = h.PopulateTokenEndpointResponse(context.Background(), requests[i], responses[i])
+		}(i)
+	}
+	wg.Wait()
+
+	var succeeded int
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+		} else {
+			assert.ErrorIs(t, err, fosite.ErrInvalidGrant)
+		}
+	}
+	assert.Equal(t, 1, succeeded)
+
+	var active int
+	for signature := range store.RefreshTokens {
+		if _, err := store.GetRefreshTokenSession(context.Background(), signature, nil); err == nil {
+			active++
+		}
+	}
+	assert.Equal(t, 1, active, "exactly one new refresh token must be active")
+	assert.Len(t, store.AccessTokens, 1)
+}
+
+func TestSignatureLocks(t *testing.T) {
+	var locks signatureLocks
+
+	unlock := locks.lock("foo")
+
+	done := make(chan struct{})
+	go func() {
+		locks.lock("bar")()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("locking an unrelated signature must not block")
+	}
+
+	blocked := make(chan struct{})
+	go func() {
+		locks.lock("foo")()
+		close(blocked)
+	}()
+
+	select {
+	case <-blocked:
+		t.Fatal("locking the same signature must block")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+	<-blocked
+	assert.Empty(t, locks.locks)
+}
+
 func TestRefreshFlowTransactional_PopulateTokenEndpointResponse(t *testing.T) {
 	var mockTransactional *internal.MockTransactional
 	var mockRevocationStore *internal.MockTokenRevocationStorage
@@ -682,8 +1039,8 @@ func TestRefreshFlowTransactional_PopulateTokenEndpointResponse(t *testing.T) {
 			expectError: fosite.ErrInvalidRequest,
 		},
 		{
-			description: "should result in a fosite.ErrInactiveToken if call to `RevokeAccessToken` results in a " +
-				"fosite.ErrInvalidRequest error",
+			description: "should result in a fosite.ErrInvalidGrant if call to `GetRefreshTokenSession` results in a " +
+				"fosite.ErrInactiveToken error",
 			setup: func() {
 				request.GrantTypes = fosite.Arguments{"refresh_token"}
 				mockTransactional.
@@ -702,7 +1059,7 @@ func TestRefreshFlowTransactional_PopulateTokenEndpointResponse(t *testing.T) {
 					Return(nil).
 					Times(1)
 			},
-			expectError: fosite.ErrInvalidRequest,
+			expectError: fosite.ErrInvalidGrant,
 		},
 		{
 			description: "transaction should be rolled back if call to `RevokeRefreshTokenMaybeGracePeriod` results in an error",
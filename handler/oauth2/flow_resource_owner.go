@@ -30,6 +30,9 @@ type ResourceOwnerPasswordCredentialsGrantHandler struct {
 		fosite.RefreshTokenScopesProvider
 		fosite.RefreshTokenLifespanProvider
 		fosite.AccessTokenLifespanProvider
+		fosite.SubjectValidatorProvider
+		PublicClientRefreshTokenConfigProvider
+		fosite.EmptyClientGrantTypesPolicyProvider
 	}
 }
 
@@ -44,7 +47,7 @@ func (c *ResourceOwnerPasswordCredentialsGrantHandler) HandleTokenEndpointReques
 		return errorsx.WithStack(fosite.ErrUnknownRequest)
 	}
 
-	if !request.GetClient().GetGrantTypes().Has("password") {
+	if !fosite.ClientHasGrantType(ctx, c.Config, request.GetClient(), "password") {
 		return errorsx.WithStack(fosite.ErrUnauthorizedClient.WithHint("The client is not allowed to use authorization grant 'password'."))
 	}
 
@@ -67,6 +70,8 @@ func (c *ResourceOwnerPasswordCredentialsGrantHandler) HandleTokenEndpointReques
 		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("Unable to authenticate the provided username and password credentials.").WithWrap(err).WithDebug(err.Error()))
 	} else if err != nil {
 		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	} else if err := c.Config.GetSubjectValidator(ctx)(sub); err != nil {
+		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The resource owner's subject is invalid.").WithWrap(err).WithDebug(err.Error()))
 	} else {
 		if sess, ok := request.GetSession().(Session); ok {
 			sess.SetSubject(sub)
@@ -94,7 +99,8 @@ func (c *ResourceOwnerPasswordCredentialsGrantHandler) PopulateTokenEndpointResp
 	}
 
 	var refresh, refreshSignature string
-	if len(c.Config.GetRefreshTokenScopes(ctx)) == 0 || requester.GetGrantedScopes().HasOneOf(c.Config.GetRefreshTokenScopes(ctx)...) {
+	if (len(c.Config.GetRefreshTokenScopes(ctx)) == 0 || requester.GetGrantedScopes().HasOneOf(c.Config.GetRefreshTokenScopes(ctx)...)) &&
+		CanIssueRefreshTokenToClient(ctx, c.Config, requester.GetClient()) {
 		var err error
 		refresh, refreshSignature, err = c.RefreshTokenStrategy.GenerateRefreshToken(ctx, requester)
 		if err != nil {
@@ -111,6 +117,7 @@ func (c *ResourceOwnerPasswordCredentialsGrantHandler) PopulateTokenEndpointResp
 
 	if refresh != "" {
 		responder.SetExtra("refresh_token", refresh)
+		fosite.SetTokenSignature(responder, fosite.RefreshToken, refreshSignature)
 	}
 
 	return nil
@@ -125,3 +132,7 @@ func (c *ResourceOwnerPasswordCredentialsGrantHandler) CanHandleTokenEndpointReq
 	// Value MUST be set to "password".
 	return requester.GetGrantTypes().ExactOne("password")
 }
+
+func (c *ResourceOwnerPasswordCredentialsGrantHandler) HandledGrantTypes() []fosite.GrantType {
+	return []fosite.GrantType{fosite.GrantTypePassword}
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -82,6 +83,14 @@ func TestResourceOwnerFlow_HandleTokenEndpointRequest(t *testing.T) {
 			},
 			expectErr: fosite.ErrServerError,
 		},
+		{
+			description: "should fail because the subject is too long",
+			setup: func(config *fosite.Config) {
+				config.SubjectValidator = fosite.DefaultSubjectValidator
+				store.EXPECT().Authenticate(gomock.Any(), "peter", "pan").Return(strings.Repeat("a", fosite.MaxSubjectLength+1), nil)
+			},
+			expectErr: fosite.ErrInvalidGrant,
+		},
 		{
 			description: "should pass",
 			setup: func(config *fosite.Config) {
@@ -177,6 +186,21 @@ func TestResourceOwnerFlow_PopulateTokenEndpointResponse(t *testing.T) {
 				assert.NotNil(t, aresp.GetExtra("refresh_token"), "expected refresh token")
 			},
 		},
+		{
+			description: "should pass - no refresh token for public clients when restricted",
+			setup: func(config *fosite.Config) {
+				config.RestrictPublicClientRefreshTokens = true
+				areq.GrantTypes = fosite.Arguments{"password"}
+				areq.Client = &fosite.DefaultClient{Public: true}
+				areq.GrantScope("offline")
+				chgen.EXPECT().GenerateAccessToken(gomock.Any(), areq).Return(mockAT, "bar", nil)
+				store.EXPECT().CreateAccessTokenSession(gomock.Any(), "bar", gomock.Eq(areq.Sanitize([]string{}))).Return(nil)
+			},
+			expect: func() {
+				assert.Nil(t, aresp.GetExtra("refresh_token"), "unexpected refresh token")
+				assert.Equal(t, mockAT, aresp.GetAccessToken())
+			},
+		},
 		{
 			description: "should pass - refresh token without offline scope",
 			setup: func(config *fosite.Config) {
@@ -7,6 +7,9 @@ import (
 	"context"
 	"time"
 
+	"github.com/ory/x/errorsx"
+	"github.com/pkg/errors"
+
 	"github.com/ory/fosite"
 )
 
@@ -33,9 +36,29 @@ func (h *HandleHelper) IssueAccessToken(ctx context.Context, defaultLifespan tim
 	responder.SetTokenType("bearer")
 	responder.SetExpiresIn(getExpiresIn(requester, fosite.AccessToken, defaultLifespan, time.Now().UTC()))
 	responder.SetScopes(requester.GetGrantedScopes())
+	fosite.SetTokenSignature(responder, fosite.AccessToken, signature)
 	return nil
 }
 
+// CanIssueRefreshTokenToClient returns false if the client is public and refresh tokens for public clients are
+// restricted, unless PKCE is enforced for public clients.
+func CanIssueRefreshTokenToClient(ctx context.Context, config PublicClientRefreshTokenConfigProvider, client fosite.Client) bool {
+	if !client.IsPublic() || !config.GetRestrictPublicClientRefreshTokens(ctx) {
+		return true
+	}
+	return config.GetEnforcePKCE(ctx) || config.GetEnforcePKCEForPublicClients(ctx)
+}
+
+// accessTokenGenerationError returns the error of generating an access token. Errors of the strategy which are
+// already OAuth2 errors, such as a missing audience, are returned unchanged, all others become server errors.
+func accessTokenGenerationError(err error) error {
+	var rfcErr *fosite.RFC6749Error
+	if errors.As(err, &rfcErr) {
+		return err
+	}
+	return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+}
+
 func getExpiresIn(r fosite.Requester, key fosite.TokenType, defaultLifespan time.Duration, now time.Time) time.Duration {
 	if r.GetSession().GetExpiresAt(key).IsZero() {
 		return defaultLifespan
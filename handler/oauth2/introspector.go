@@ -14,6 +14,7 @@ import (
 type coreValidatorConfigProvider interface {
 	fosite.ScopeStrategyProvider
 	fosite.DisableRefreshTokenValidationProvider
+	fosite.IntrospectionCacheProvider
 }
 
 var _ fosite.TokenIntrospector = (*CoreValidator)(nil)
@@ -68,7 +69,7 @@ func matchScopes(ss fosite.ScopeStrategy, granted, scopes []string) error {
 
 func (c *CoreValidator) introspectAccessToken(ctx context.Context, token string, accessRequest fosite.AccessRequester, scopes []string) error {
 	sig := c.CoreStrategy.AccessTokenSignature(ctx, token)
-	or, err := c.CoreStorage.GetAccessTokenSession(ctx, sig, accessRequest.GetSession())
+	or, err := c.getAccessTokenSession(ctx, sig, accessRequest.GetSession())
 	if err != nil {
 		return errorsx.WithStack(fosite.ErrRequestUnauthorized.WithWrap(err).WithDebug(err.Error()))
 	} else if err := c.CoreStrategy.ValidateAccessToken(ctx, or, token); err != nil {
@@ -83,6 +84,27 @@ func (c *CoreValidator) introspectAccessToken(ctx context.Context, token string,
 	return nil
 }
 
+// getAccessTokenSession returns the request of the access token from the introspection cache, if configured, or
+// from the storage otherwise. The token still has to be validated, as the cached request may have expired.
+func (c *CoreValidator) getAccessTokenSession(ctx context.Context, signature string, session fosite.Session) (fosite.Requester, error) {
+	cache := c.Config.GetIntrospectionCache(ctx)
+	if cache != nil {
+		if or, ok := cache.Get(ctx, signature, session); ok {
+			return or, nil
+		}
+	}
+
+	or, err := c.CoreStorage.GetAccessTokenSession(ctx, signature, session)
+	if err != nil {
+		return nil, err
+	}
+
+	if cache != nil {
+		cache.Set(ctx, signature, or)
+	}
+	return or, nil
+}
+
 func (c *CoreValidator) introspectRefreshToken(ctx context.Context, token string, accessRequest fosite.AccessRequester, scopes []string) error {
 	sig := c.CoreStrategy.RefreshTokenSignature(ctx, token)
 	or, err := c.CoreStorage.GetRefreshTokenSession(ctx, sig, accessRequest.GetSession())
@@ -7,6 +7,8 @@ import (
 	"context"
 	"time"
 
+	"github.com/ory/x/errorsx"
+
 	"github.com/ory/fosite"
 	"github.com/ory/fosite/token/jwt"
 )
@@ -16,6 +18,9 @@ type StatelessJWTValidator struct {
 	Config interface {
 		fosite.ScopeStrategyProvider
 	}
+
+	// JWTDenylistStorage, if set, is consulted to reject revoked access tokens.
+	JWTDenylistStorage JWTDenylistStorage
 }
 
 // AccessTokenJWTToRequest tries to reconstruct fosite.Request from a JWT.
@@ -74,6 +79,10 @@ func (v *StatelessJWTValidator) IntrospectToken(ctx context.Context, token strin
 		return "", err
 	}
 
+	if err := checkJWTDenylist(ctx, v.JWTDenylistStorage, t); err != nil {
+		return "", err
+	}
+
 	// TODO: From here we assume it is an access token, but how do we know it is really and that is not an ID token?
 
 	requester := AccessTokenJWTToRequest(t)
@@ -86,3 +95,19 @@ func (v *StatelessJWTValidator) IntrospectToken(ctx context.Context, token strin
 
 	return fosite.AccessToken, nil
 }
+
+// checkJWTDenylist returns fosite.ErrInactiveToken if the "jti" of the token was revoked.
+func checkJWTDenylist(ctx context.Context, storage JWTDenylistStorage, t *jwt.Token) error {
+	jti, _ := t.Claims["jti"].(string)
+	if storage == nil || jti == "" {
+		return nil
+	}
+
+	revoked, err := storage.IsJTIBlacklisted(ctx, jti)
+	if err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	} else if revoked {
+		return errorsx.WithStack(fosite.ErrInactiveToken.WithHint("The token has been revoked."))
+	}
+	return nil
+}
@@ -6,9 +6,12 @@ package oauth2
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ory/fosite/internal/gen"
 
@@ -16,6 +19,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/ory/fosite"
+	"github.com/ory/fosite/storage"
 	"github.com/ory/fosite/token/jwt"
 )
 
@@ -118,6 +122,82 @@ func TestIntrospectJWT(t *testing.T) {
 	}
 }
 
+func TestIntrospectJWTDenylist(t *testing.T) {
+	ctx := context.Background()
+	strat := &DefaultJWTStrategy{
+		Signer:          j.Signer,
+		HMACSHAStrategy: hmacshaStrategy,
+		Config:          &fosite.Config{},
+	}
+
+	setup := func(t *testing.T) (*storage.MemoryStore, *StatelessJWTValidator, *TokenRevocationHandler) {
+		store := storage.NewMemoryStore()
+		validator := &StatelessJWTValidator{
+			Signer:             strat,
+			Config:             &fosite.Config{ScopeStrategy: fosite.HierarchicScopeStrategy},
+			JWTDenylistStorage: store,
+		}
+		revoker := &TokenRevocationHandler{
+			TokenRevocationStorage: store,
+			AccessTokenStrategy:    strat,
+			RefreshTokenStrategy:   strat,
+			JWTDenylistStorage:     store,
+		}
+		return store, validator, revoker
+	}
+
+	newToken := func(t *testing.T, extra map[string]interface{}) (string, fosite.Requester) {
+		req := jwtValidCase(fosite.AccessToken)
+		req.ID = "request-id"
+		req.Client = &fosite.DefaultClient{ID: "foo"}
+		req.Session.(*JWTSession).JWTClaims.Extra = extra
+		token, _, err := strat.GenerateAccessToken(ctx, req)
+		require.NoError(t, err)
+		return token, req
+	}
+
+	introspect := func(v *StatelessJWTValidator, token string) error {
+		_, err := v.IntrospectToken(ctx, token, fosite.AccessToken, fosite.NewAccessRequest(nil), []string{})
+		return err
+	}
+
+	t.Run("case=should report a revoked stored token as inactive", func(t *testing.T) {
+		store, validator, revoker := setup(t)
+		token, req := newToken(t, nil)
+		require.NoError(t, store.CreateAccessTokenSession(ctx, strat.AccessTokenSignature(ctx, token), req))
+		require.NoError(t, introspect(validator, token))
+
+		require.NoError(t, revoker.RevokeToken(ctx, token, fosite.AccessToken, req.GetClient()))
+		assert.ErrorIs(t, introspect(validator, token), fosite.ErrInactiveToken)
+	})
+
+	t.Run("case=should report a revoked token naming the client as inactive", func(t *testing.T) {
+		_, validator, revoker := setup(t)
+		token, req := newToken(t, map[string]interface{}{"client_id": "foo"})
+
+		require.NoError(t, revoker.RevokeToken(ctx, token, fosite.AccessToken, req.GetClient()))
+		assert.ErrorIs(t, introspect(validator, token), fosite.ErrInactiveToken)
+	})
+
+	t.Run("case=should not revoke a token of another client", func(t *testing.T) {
+		_, validator, revoker := setup(t)
+		token, _ := newToken(t, map[string]interface{}{"client_id": "foo"})
+
+		require.NoError(t, revoker.RevokeToken(ctx, token, fosite.AccessToken, &fosite.DefaultClient{ID: "bar"}))
+		assert.NoError(t, introspect(validator, token))
+	})
+
+	t.Run("case=should ignore expired denylist entries", func(t *testing.T) {
+		store, validator, _ := setup(t)
+		token, _ := newToken(t, nil)
+		decoded, err := strat.Decode(ctx, token)
+		require.NoError(t, err)
+
+		require.NoError(t, store.BlacklistJTI(ctx, decoded.Claims["jti"].(string), time.Now().Add(-time.Minute)))
+		assert.NoError(t, introspect(validator, token))
+	})
+}
+
 func BenchmarkIntrospectJWT(b *testing.B) {
 	strat := &DefaultJWTStrategy{
 		Signer: &jwt.DefaultSigner{GetPrivateKey: func(_ context.Context) (interface{}, error) {
@@ -142,3 +222,60 @@ func BenchmarkIntrospectJWT(b *testing.B) {
 
 	assert.NoError(b, err)
 }
+
+func TestIntrospectJWTScopeClaim(t *testing.T) {
+	rsaKey := gen.MustRSAKey()
+	signer := &jwt.DefaultSigner{
+		GetPrivateKey: func(_ context.Context) (interface{}, error) {
+			return rsaKey, nil
+		},
+	}
+
+	v := &StatelessJWTValidator{
+		Signer: signer,
+		Config: &fosite.Config{ScopeStrategy: fosite.HierarchicScopeStrategy},
+	}
+	provider := fosite.NewOAuth2Provider(nil, &fosite.Config{})
+
+	for k, c := range []struct {
+		description string
+		claims      jwt.MapClaims
+	}{
+		{
+			description: "scp as array",
+			claims:      jwt.MapClaims{"scp": []string{"foo", "bar"}},
+		},
+		{
+			description: "scp as string",
+			claims:      jwt.MapClaims{"scp": "foo bar"},
+		},
+		{
+			description: "scope as string",
+			claims:      jwt.MapClaims{"scope": "foo bar"},
+		},
+		{
+			description: "scope as array",
+			claims:      jwt.MapClaims{"scope": []string{"foo", "bar"}},
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.description), func(t *testing.T) {
+			c.claims["sub"] = "peter"
+			c.claims["exp"] = time.Now().Add(time.Hour).Unix()
+			token, _, err := signer.Generate(context.Background(), c.claims, &jwt.Headers{})
+			require.NoError(t, err)
+
+			ar := fosite.NewAccessRequest(new(JWTSession))
+			_, err = v.IntrospectToken(context.Background(), token, fosite.AccessToken, ar, []string{"foo"})
+			require.NoError(t, err)
+			assert.Equal(t, fosite.Arguments{"foo", "bar"}, ar.GetGrantedScopes())
+
+			rw := httptest.NewRecorder()
+			provider.WriteIntrospectionResponse(context.Background(), rw, &fosite.IntrospectionResponse{Active: true, AccessRequester: ar})
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &response))
+			assert.Equal(t, "foo bar", response["scope"])
+			assert.NotContains(t, response, "scp")
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/ory/x/errorsx"
 
@@ -102,3 +103,42 @@ func TestIntrospectToken(t *testing.T) {
 		})
 	}
 }
+
+func TestIntrospectTokenWithCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := internal.NewMockCoreStorage(ctrl)
+	chgen := internal.NewMockCoreStrategy(ctrl)
+	defer ctrl.Finish()
+
+	cache := fosite.NewMemoryIntrospectionCache(time.Minute, 0)
+	v := &CoreValidator{
+		CoreStrategy: chgen,
+		CoreStorage:  store,
+		Config:       &fosite.Config{IntrospectionCache: cache},
+	}
+
+	or := fosite.NewAccessRequest(&fosite.DefaultSession{})
+	chgen.EXPECT().AccessTokenSignature(gomock.Any(), "1234").AnyTimes().Return("asdf")
+	chgen.EXPECT().ValidateAccessToken(gomock.Any(), or, "1234").AnyTimes().Return(nil)
+
+	t.Run("case=should cache the result of the storage lookup", func(t *testing.T) {
+		store.EXPECT().GetAccessTokenSession(gomock.Any(), "asdf", nil).Times(1).Return(or, nil)
+
+		for i := 0; i < 2; i++ {
+			tu, err := v.IntrospectToken(context.Background(), "1234", fosite.AccessToken, fosite.NewAccessRequest(nil), []string{})
+			require.NoError(t, err)
+			assert.Equal(t, fosite.AccessToken, tu)
+		}
+	})
+
+	t.Run("case=should look up the token again once the entry was invalidated", func(t *testing.T) {
+		cache.Invalidate(context.Background(), or.GetID())
+
+		store.EXPECT().GetAccessTokenSession(gomock.Any(), "asdf", nil).Return(nil, fosite.ErrNotFound)
+		chgen.EXPECT().RefreshTokenSignature(gomock.Any(), "1234").Return("asdf")
+		store.EXPECT().GetRefreshTokenSession(gomock.Any(), "asdf", nil).Return(nil, fosite.ErrNotFound)
+
+		_, err := v.IntrospectToken(context.Background(), "1234", fosite.AccessToken, fosite.NewAccessRequest(nil), []string{})
+		assert.ErrorIs(t, err, fosite.ErrRequestUnauthorized)
+	})
+}
@@ -0,0 +1,27 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package oauth2
+
+import (
+	"net"
+
+	"github.com/ory/fosite"
+)
+
+// BindToNetwork binds the tokens issued for the session to the network ("cnf.net"). The tokens are only active if
+// they are used by a caller from within the network, as determined by the fosite.CallerNetworkExtractor.
+func BindToNetwork(session fosite.Session, network *net.IPNet) error {
+	claims, err := ExtraClaims(session)
+	if err != nil {
+		return err
+	}
+
+	cnf, ok := claims["cnf"].(map[string]interface{})
+	if !ok {
+		cnf = make(map[string]interface{})
+	}
+	cnf[fosite.NetworkBindingMember] = network.String()
+	claims["cnf"] = cnf
+	return nil
+}
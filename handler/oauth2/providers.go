@@ -10,3 +10,9 @@ type LifespanConfigProvider interface {
 	fosite.RefreshTokenLifespanProvider
 	fosite.AuthorizeCodeLifespanProvider
 }
+
+type PublicClientRefreshTokenConfigProvider interface {
+	fosite.RestrictPublicClientRefreshTokensProvider
+	fosite.EnforcePKCEProvider
+	fosite.EnforcePKCEForPublicClientsProvider
+}
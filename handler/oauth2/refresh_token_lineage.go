@@ -0,0 +1,33 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package oauth2
+
+import (
+	"context"
+	"time"
+
+	"github.com/ory/x/errorsx"
+
+	"github.com/ory/fosite"
+)
+
+// PruneRefreshTokenLineage deletes inactive refresh tokens which are no longer retained according to the
+// configured retention policy. It is meant to be run periodically. Reuse of refresh tokens which are still retained
+// continues to be detected, reuse of pruned refresh tokens is rejected as an invalid grant.
+func PruneRefreshTokenLineage(ctx context.Context, config fosite.RefreshTokenLineageRetentionProvider, storage RefreshTokenLineageStorage) error {
+	var notAfter time.Time
+	if maxAge := config.GetRefreshTokenLineageMaxAge(ctx); maxAge > 0 {
+		notAfter = time.Now().UTC().Add(-maxAge)
+	}
+
+	maxCount := config.GetRefreshTokenLineageMaxCount(ctx)
+	if notAfter.IsZero() && maxCount <= 0 {
+		return nil
+	}
+
+	if err := storage.PruneInactiveRefreshTokens(ctx, notAfter, maxCount); err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+	return nil
+}
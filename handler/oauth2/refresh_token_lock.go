@@ -0,0 +1,45 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package oauth2
+
+import "sync"
+
+// signatureLocks serializes concurrent refreshes of the same refresh token within this process, while refreshes of
+// different refresh tokens proceed in parallel. Deployments with multiple instances additionally rely on the storage
+// to reject concurrent refreshes, for example with fosite.ErrSerializationFailure.
+type signatureLocks struct {
+	mu    sync.Mutex
+	locks map[string]*signatureLock
+}
+
+type signatureLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock blocks until no other refresh of the signature is in progress and returns the function releasing the lock.
+func (l *signatureLocks) lock(signature string) (unlock func()) {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*signatureLock)
+	}
+	sl, ok := l.locks[signature]
+	if !ok {
+		sl = new(signatureLock)
+		l.locks[signature] = sl
+	}
+	sl.refs++
+	l.mu.Unlock()
+
+	sl.Lock()
+	return func() {
+		sl.Unlock()
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if sl.refs--; sl.refs == 0 {
+			delete(l.locks, signature)
+		}
+	}
+}
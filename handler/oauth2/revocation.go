@@ -11,27 +11,45 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/ory/fosite"
+	"github.com/ory/fosite/token/jwt"
 )
 
 type TokenRevocationHandler struct {
 	TokenRevocationStorage TokenRevocationStorage
 	RefreshTokenStrategy   RefreshTokenStrategy
 	AccessTokenStrategy    AccessTokenStrategy
+	Config                 interface {
+		fosite.IntrospectionCacheProvider
+		fosite.AuditSinkProvider
+		fosite.StrictAuditProvider
+	}
+
+	// JWTDenylistStorage, if set, records the "jti" of revoked JWT access tokens so that they are rejected by the
+	// StatelessJWTValidator. It requires the AccessTokenStrategy to issue JWTs.
+	JWTDenylistStorage JWTDenylistStorage
 }
 
 // RevokeToken implements https://tools.ietf.org/html/rfc7009#section-2.1
 // The token type hint indicates which token type check should be performed first.
 func (r *TokenRevocationHandler) RevokeToken(ctx context.Context, token string, tokenType fosite.TokenType, client fosite.Client) error {
+	// The signature of the token is recorded in the audit event.
+	var refreshSignature, accessSignature string
 	discoveryFuncs := []func() (request fosite.Requester, err error){
 		func() (request fosite.Requester, err error) {
 			// Refresh token
 			signature := r.RefreshTokenStrategy.RefreshTokenSignature(ctx, token)
-			return r.TokenRevocationStorage.GetRefreshTokenSession(ctx, signature, nil)
+			if request, err = r.TokenRevocationStorage.GetRefreshTokenSession(ctx, signature, nil); err == nil {
+				refreshSignature = signature
+			}
+			return request, err
 		},
 		func() (request fosite.Requester, err error) {
 			// Access token
 			signature := r.AccessTokenStrategy.AccessTokenSignature(ctx, token)
-			return r.TokenRevocationStorage.GetAccessTokenSession(ctx, signature, nil)
+			if request, err = r.TokenRevocationStorage.GetAccessTokenSession(ctx, signature, nil); err == nil {
+				accessSignature = signature
+			}
+			return request, err
 		},
 	}
 
@@ -47,6 +65,29 @@ func (r *TokenRevocationHandler) RevokeToken(ctx context.Context, token string,
 	}
 	// err2 can only be not nil if first err1 was not nil
 	if err2 != nil {
+		// JWT access tokens which are not stored can still be revoked if they name the client.
+		if t := r.decodeJWT(ctx, token); t != nil {
+			if clientID, _ := t.Claims["client_id"].(string); clientID == client.GetID() {
+				if err := r.denylistJWT(ctx, t); err != nil {
+					return err
+				}
+
+				if err := r.audit(ctx, func() fosite.AuditEvent {
+					subject, _ := t.Claims["sub"].(string)
+					jti, _ := t.Claims["jti"].(string)
+					return fosite.AuditEvent{
+						Type:                 fosite.AuditEventTokenRevoked,
+						ClientID:             clientID,
+						Subject:              subject,
+						TokenTypes:           []fosite.TokenType{fosite.AccessToken},
+						AccessTokenSignature: r.AccessTokenStrategy.AccessTokenSignature(ctx, token),
+						AccessTokenJTI:       jti,
+					}
+				}); err != nil {
+					return err
+				}
+			}
+		}
 		return storeErrorsToRevocationError(err1, err2)
 	}
 
@@ -58,7 +99,67 @@ func (r *TokenRevocationHandler) RevokeToken(ctx context.Context, token string,
 	err1 = r.TokenRevocationStorage.RevokeRefreshToken(ctx, requestID)
 	err2 = r.TokenRevocationStorage.RevokeAccessToken(ctx, requestID)
 
-	return storeErrorsToRevocationError(err1, err2)
+	// Cached introspection results must not outlive the revocation, even if revoking failed partially.
+	fosite.InvalidateIntrospectionCache(ctx, r.Config, requestID)
+
+	var jti string
+	if t := r.decodeJWT(ctx, token); t != nil {
+		if err := r.denylistJWT(ctx, t); err != nil {
+			return err
+		}
+		jti, _ = t.Claims["jti"].(string)
+	}
+
+	if err := storeErrorsToRevocationError(err1, err2); err != nil {
+		return err
+	}
+
+	return r.audit(ctx, func() fosite.AuditEvent {
+		event := fosite.NewAuditEvent(fosite.AuditEventTokenRevoked, ar, fosite.AccessToken, fosite.RefreshToken)
+		event.AccessTokenSignature = accessSignature
+		event.RefreshTokenSignature = refreshSignature
+		event.AccessTokenJTI = jti
+		return event
+	})
+}
+
+// audit records the revocation if an audit sink is configured. The event is only created if it is recorded.
+func (r *TokenRevocationHandler) audit(ctx context.Context, event func() fosite.AuditEvent) error {
+	if r.Config == nil || r.Config.GetAuditSink(ctx) == nil {
+		return nil
+	}
+	return fosite.RecordAuditEvent(ctx, r.Config, event())
+}
+
+// decodeJWT returns the token if JWT revocation is enabled and the token is a valid JWT issued by the
+// AccessTokenStrategy, or nil otherwise.
+func (r *TokenRevocationHandler) decodeJWT(ctx context.Context, token string) *jwt.Token {
+	signer, ok := r.AccessTokenStrategy.(jwt.Signer)
+	if r.JWTDenylistStorage == nil || !ok {
+		return nil
+	}
+
+	t, err := validate(ctx, signer, token)
+	if err != nil {
+		// Not a JWT, or already expired.
+		return nil
+	}
+	return t
+}
+
+// denylistJWT records the "jti" of the token as revoked until the token expires.
+func (r *TokenRevocationHandler) denylistJWT(ctx context.Context, t *jwt.Token) error {
+	jti, _ := t.Claims["jti"].(string)
+	if jti == "" {
+		return nil
+	}
+
+	claims := jwt.JWTClaims{}
+	claims.FromMapClaims(t.Claims)
+	if err := r.JWTDenylistStorage.BlacklistJTI(ctx, jti, claims.ExpiresAt); err != nil {
+		return errorsx.WithStack(fosite.ErrTemporarilyUnavailable.WithWrap(err).WithDebug(err.Error()))
+	}
+	return nil
 }
 
 func storeErrorsToRevocationError(err1, err2 error) error {
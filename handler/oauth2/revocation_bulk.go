@@ -0,0 +1,79 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package oauth2
+
+import (
+	"context"
+
+	"github.com/ory/x/errorsx"
+
+	"github.com/ory/fosite"
+)
+
+// BulkTokenRevoker revokes all access and refresh tokens of a subject or a client at once, for example when an
+// account is deleted or a client is disabled. Unlike the TokenRevocationHandler it is not exposed as an endpoint.
+type BulkTokenRevoker struct {
+	Storage BulkTokenRevocationStorage
+
+	// Config, if set, provides the introspection cache whose entries of the revoked tokens are removed, and the audit
+	// sink which records the revocations.
+	Config interface {
+		fosite.IntrospectionCacheProvider
+		fosite.AuditSinkProvider
+		fosite.StrictAuditProvider
+	}
+}
+
+// RevokeAllForSubject revokes all access and refresh tokens issued to the subject. It is safe to call repeatedly.
+func (r *BulkTokenRevoker) RevokeAllForSubject(ctx context.Context, subject string) error {
+	if subject == "" {
+		// Tokens issued without a resource owner, e.g. using the client credentials grant, have no subject.
+		return errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("The subject must not be empty."))
+	}
+
+	if err := r.Storage.RevokeRefreshTokensForSubject(ctx, subject); err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+	err := r.Storage.RevokeAccessTokensForSubject(ctx, subject)
+	if cache := r.introspectionCache(ctx); cache != nil {
+		cache.InvalidateSubject(ctx, subject)
+	}
+	if err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+	return r.audit(ctx, fosite.AuditEvent{Subject: subject})
+}
+
+// RevokeAllForClient revokes all access and refresh tokens issued to the client. It is safe to call repeatedly.
+func (r *BulkTokenRevoker) RevokeAllForClient(ctx context.Context, clientID string) error {
+	if clientID == "" {
+		return errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("The client ID must not be empty."))
+	}
+
+	if err := r.Storage.RevokeRefreshTokensForClient(ctx, clientID); err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+	err := r.Storage.RevokeAccessTokensForClient(ctx, clientID)
+	if cache := r.introspectionCache(ctx); cache != nil {
+		cache.InvalidateClient(ctx, clientID)
+	}
+	if err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+	return r.audit(ctx, fosite.AuditEvent{ClientID: clientID})
+}
+
+// audit records the revocation of all access and refresh tokens matching the event.
+func (r *BulkTokenRevoker) audit(ctx context.Context, event fosite.AuditEvent) error {
+	event.Type = fosite.AuditEventTokenRevoked
+	event.TokenTypes = []fosite.TokenType{fosite.AccessToken, fosite.RefreshToken}
+	return fosite.RecordAuditEvent(ctx, r.Config, event)
+}
+
+func (r *BulkTokenRevoker) introspectionCache(ctx context.Context) fosite.IntrospectionCache {
+	if r.Config == nil {
+		return nil
+	}
+	return r.Config.GetIntrospectionCache(ctx)
+}
@@ -5,6 +5,7 @@ package oauth2
 
 import (
 	"context"
+	"time"
 )
 
 // TokenRevocationStorage provides the storage implementation
@@ -41,3 +42,39 @@ type TokenRevocationStorage interface {
 	// token as well.
 	RevokeAccessToken(ctx context.Context, requestID string) error
 }
+
+// BulkTokenRevocationStorage provides the storage implementation for revoking all tokens of a subject or a client
+// at once. Revoking tokens which are already revoked, or revoking tokens of a subject or a client without any tokens,
+// must not return an error.
+type BulkTokenRevocationStorage interface {
+	// RevokeAccessTokensForSubject revokes all access tokens issued to the subject.
+	RevokeAccessTokensForSubject(ctx context.Context, subject string) error
+
+	// RevokeRefreshTokensForSubject revokes all refresh tokens issued to the subject.
+	RevokeRefreshTokensForSubject(ctx context.Context, subject string) error
+
+	// RevokeAccessTokensForClient revokes all access tokens issued to the client.
+	RevokeAccessTokensForClient(ctx context.Context, clientID string) error
+
+	// RevokeRefreshTokensForClient revokes all refresh tokens issued to the client.
+	RevokeRefreshTokensForClient(ctx context.Context, clientID string) error
+}
+
+// RefreshTokenLineageStorage provides the storage implementation for pruning inactive refresh tokens. Inactive
+// refresh tokens are retained to detect their reuse, see RefreshTokenGrantHandler.
+type RefreshTokenLineageStorage interface {
+	// PruneInactiveRefreshTokens deletes the refresh tokens which became inactive before notAfter, unless notAfter
+	// is zero. Additionally, it deletes all but the maxCount most recently deactivated refresh tokens of each
+	// request ID, unless maxCount is zero.
+	PruneInactiveRefreshTokens(ctx context.Context, notAfter time.Time, maxCount int) error
+}
+
+// JWTDenylistStorage provides the storage implementation for revoking JWT access tokens, which are validated without
+// a storage lookup otherwise. Revoked tokens are identified by their "jti" claim.
+type JWTDenylistStorage interface {
+	// BlacklistJTI marks the jti as revoked until the given expiry time of the token.
+	BlacklistJTI(ctx context.Context, jti string, exp time.Time) error
+
+	// IsJTIBlacklisted returns true if the jti was revoked and its expiry time has not passed yet.
+	IsJTIBlacklisted(ctx context.Context, jti string) (bool, error)
+}
@@ -116,6 +116,24 @@ func TestRevokeToken(t *testing.T) {
 				store.EXPECT().RevokeAccessToken(gomock.Any(), gomock.Any())
 			},
 		},
+		{
+			description: "should pass - unknown token type hint is ignored; both stores are probed",
+			expectErr:   nil,
+			client:      &fosite.DefaultClient{ID: "bar"},
+			mock: func() {
+				token = "foo"
+				tokenType = fosite.TokenType("unknown_token")
+				rtStrat.EXPECT().RefreshTokenSignature(gomock.Any(), token)
+				store.EXPECT().GetRefreshTokenSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, fosite.ErrNotFound)
+
+				atStrat.EXPECT().AccessTokenSignature(gomock.Any(), token)
+				store.EXPECT().GetAccessTokenSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(ar, nil)
+				ar.EXPECT().GetID()
+				ar.EXPECT().GetClient().Return(&fosite.DefaultClient{ID: "bar"})
+				store.EXPECT().RevokeRefreshToken(gomock.Any(), gomock.Any())
+				store.EXPECT().RevokeAccessToken(gomock.Any(), gomock.Any())
+			},
+		},
 		{
 			description: "should pass - refresh token discovery first; both tokens not found",
 			expectErr:   nil,
@@ -246,3 +264,70 @@ func TestRevokeToken(t *testing.T) {
 		})
 	}
 }
+
+type auditRecorder struct {
+	events []fosite.AuditEvent
+	err    error
+}
+
+func (a *auditRecorder) Audit(_ context.Context, event fosite.AuditEvent) error {
+	a.events = append(a.events, event)
+	return a.err
+}
+
+func TestRevokeTokenRecordsAuditEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := internal.NewMockTokenRevocationStorage(ctrl)
+	atStrat := internal.NewMockAccessTokenStrategy(ctrl)
+	rtStrat := internal.NewMockRefreshTokenStrategy(ctrl)
+	defer ctrl.Finish()
+
+	for k, c := range []struct {
+		d         string
+		sinkErr   error
+		strict    bool
+		expectErr error
+	}{
+		{d: "should record the revoked tokens"},
+		{d: "should ignore errors of the sink by default", sinkErr: fmt.Errorf("sink is down")},
+		{d: "should fail in strict mode if the sink fails", sinkErr: fmt.Errorf("sink is down"), strict: true, expectErr: fosite.ErrServerError},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.d), func(t *testing.T) {
+			sink := &auditRecorder{err: c.sinkErr}
+			h := TokenRevocationHandler{
+				TokenRevocationStorage: store,
+				RefreshTokenStrategy:   rtStrat,
+				AccessTokenStrategy:    atStrat,
+				Config:                 &fosite.Config{AuditSink: sink, StrictAudit: c.strict},
+			}
+
+			ar := fosite.NewAccessRequest(&fosite.DefaultSession{Subject: "peter"})
+			ar.ID = "request-id"
+			ar.Client = &fosite.DefaultClient{ID: "bar"}
+			ar.GrantScope("offline")
+
+			rtStrat.EXPECT().RefreshTokenSignature(gomock.Any(), "foo")
+			store.EXPECT().GetRefreshTokenSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(ar, nil)
+			store.EXPECT().RevokeRefreshToken(gomock.Any(), "request-id")
+			store.EXPECT().RevokeAccessToken(gomock.Any(), "request-id")
+
+			err := h.RevokeToken(context.Background(), "foo", fosite.RefreshToken, &fosite.DefaultClient{ID: "bar"})
+			if c.expectErr != nil {
+				require.ErrorIs(t, err, c.expectErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Len(t, sink.events, 1)
+			require.Equal(t, fosite.AuditEvent{
+				Type:       fosite.AuditEventTokenRevoked,
+				RequestID:  "request-id",
+				ClientID:   "bar",
+				Subject:    "peter",
+				Scopes:     fosite.Arguments{"offline"},
+				Audience:   fosite.Arguments{},
+				TokenTypes: []fosite.TokenType{fosite.AccessToken, fosite.RefreshToken},
+			}, sink.events[0])
+		})
+	}
+}
@@ -5,6 +5,7 @@ package oauth2
 
 import (
 	"context"
+	"time"
 
 	"github.com/ory/fosite"
 )
@@ -48,3 +49,11 @@ type RefreshTokenStorage interface {
 
 	DeleteRefreshTokenSession(ctx context.Context, signature string) (err error)
 }
+
+// JTIStorage records the "jti" of issued JWT access tokens to guarantee their uniqueness. The jtis are kept apart
+// from those of client assertions, so that neither can be used to block the other.
+type JTIStorage interface {
+	// MarkAccessTokenJTIUsedForTime marks the jti as used until the given expiry time, or returns fosite.ErrJTIKnown
+	// if it is already in use.
+	MarkAccessTokenJTIUsedForTime(ctx context.Context, jti string, exp time.Time) error
+}
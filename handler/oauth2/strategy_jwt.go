@@ -5,9 +5,12 @@ package oauth2
 
 import (
 	"context"
+	"crypto/rand"
+	"io"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 
 	"github.com/ory/fosite"
@@ -22,7 +25,26 @@ type DefaultJWTStrategy struct {
 	Config          interface {
 		fosite.AccessTokenIssuerProvider
 		fosite.JWTScopeFieldProvider
+		fosite.JWTAudienceClaimNameProvider
+		fosite.RequireJWTAccessTokenAudienceProvider
+		fosite.IncludeJWTAccessTokenAuthClaimsProvider
+		fosite.EnforceUniqueJTIProvider
 	}
+
+	// JTIStorage records the "jti" of issued access tokens if unique JWT IDs are enforced.
+	JTIStorage JTIStorage
+
+	// JTIGenerator returns the "jti" of access tokens whose session does not set one. Defaults to random UUIDs read
+	// from the random source of the configuration if it implements fosite.RandomSourceProvider, or crypto/rand.Reader.
+	JTIGenerator func() string
+}
+
+// maxJTIAttempts is the number of times a "jti" is generated before issuing the access token fails.
+const maxJTIAttempts = 3
+
+// authClaimsSession is implemented by sessions carrying ID token claims, for example openid.Session.
+type authClaimsSession interface {
+	IDTokenClaims() *jwt.IDTokenClaims
 }
 
 func (h DefaultJWTStrategy) signature(token string) string {
@@ -39,6 +61,9 @@ func (h DefaultJWTStrategy) AccessTokenSignature(ctx context.Context, token stri
 }
 
 func (h *DefaultJWTStrategy) GenerateAccessToken(ctx context.Context, requester fosite.Requester) (token string, signature string, err error) {
+	if h.Config.GetRequireJWTAccessTokenAudience(ctx) && len(requester.GetGrantedAudience()) == 0 {
+		return "", "", errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("The JWT access token can not be issued because no audience was granted."))
+	}
 	return h.generate(ctx, fosite.AccessToken, requester)
 }
 
@@ -128,6 +153,91 @@ func (h *DefaultJWTStrategy) generate(ctx context.Context, tokenType fosite.Toke
 				h.Config.GetJWTScopeField(ctx),
 			)
 
-		return h.Signer.Generate(ctx, claims.ToMapClaims(), jwtSession.GetJWTHeader())
+		mapClaims := claims.ToMapClaims()
+		if h.Config.GetIncludeJWTAccessTokenAuthClaims(ctx) {
+			if sess, ok := requester.GetSession().(authClaimsSession); ok && sess.IDTokenClaims() != nil {
+				if authTime := sess.IDTokenClaims().AuthTime; !authTime.IsZero() {
+					mapClaims["auth_time"] = authTime.Unix()
+				}
+				if acr := sess.IDTokenClaims().AuthenticationContextClassReference; acr != "" {
+					mapClaims["acr"] = acr
+				}
+			}
+		}
+
+		if name := h.Config.GetJWTAudienceClaimName(ctx); name != "" && name != "aud" {
+			mapClaims[name] = mapClaims["aud"]
+		}
+
+		// The map claims of JWTClaims always carry a jti, so only its JTI field tells whether the session sets one.
+		jti, _ := mapClaims["jti"].(string)
+		if c, ok := claims.(*jwt.JWTClaims); ok {
+			jti = c.JTI
+		}
+		if jti == "" {
+			generated, err := h.generateJTI(ctx)
+			if err != nil {
+				return "", "", err
+			}
+			mapClaims["jti"] = generated
+		}
+
+		if h.Config.GetEnforceUniqueJTI(ctx) {
+			if err := h.recordJTI(ctx, mapClaims, jwtSession.GetExpiresAt(tokenType)); err != nil {
+				return "", "", err
+			}
+		}
+
+		return h.Signer.Generate(ctx, mapClaims, jwtSession.GetJWTHeader())
 	}
 }
+
+func (h *DefaultJWTStrategy) generateJTI(ctx context.Context) (string, error) {
+	if h.JTIGenerator != nil {
+		return h.JTIGenerator(), nil
+	}
+
+	var r io.Reader = rand.Reader
+	if p, ok := h.Config.(fosite.RandomSourceProvider); ok {
+		if source := p.GetRandomSource(ctx); source != nil {
+			r = source
+		}
+	}
+
+	id, err := uuid.NewRandomFromReader(r)
+	if err != nil {
+		return "", errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+	return id.String(), nil
+}
+
+// recordJTI records the "jti" of the claims as used until the token expires, and generates a new one if it is
+// already in use.
+func (h *DefaultJWTStrategy) recordJTI(ctx context.Context, mapClaims jwt.MapClaims, expiresAt time.Time) error {
+	if h.JTIStorage == nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithDebug("Unique JWT IDs are enforced, but no JTI storage is configured."))
+	} else if expiresAt.IsZero() {
+		return errorsx.WithStack(fosite.ErrServerError.WithDebug("Unique JWT IDs are enforced, but the access token does not expire."))
+	}
+
+	for attempt := 0; attempt < maxJTIAttempts; attempt++ {
+		jti, _ := mapClaims["jti"].(string)
+		if jti == "" {
+			// An empty jti is not recorded, so that it cannot block all other access tokens without a jti.
+			return nil
+		}
+
+		err := h.JTIStorage.MarkAccessTokenJTIUsedForTime(ctx, jti, expiresAt)
+		if err == nil {
+			return nil
+		} else if !errors.Is(err, fosite.ErrJTIKnown) {
+			return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+		}
+
+		if mapClaims["jti"], err = h.generateJTI(ctx); err != nil {
+			return err
+		}
+	}
+
+	return errorsx.WithStack(fosite.ErrServerError.WithDebugf("Unable to generate a unique JWT ID after %d attempts.", maxJTIAttempts))
+}
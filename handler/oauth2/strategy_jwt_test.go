@@ -4,20 +4,26 @@
 package oauth2
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
+	"testing/iotest"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/ory/fosite/internal/gen"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/ory/fosite"
+	"github.com/ory/fosite/storage"
 	"github.com/ory/fosite/token/jwt"
 )
 
@@ -232,3 +238,257 @@ func TestAccessToken(t *testing.T) {
 		}
 	}
 }
+
+func TestAccessTokenRequiresAudience(t *testing.T) {
+	strategy := &DefaultJWTStrategy{
+		Signer: j.Signer,
+		Config: &fosite.Config{RequireJWTAccessTokenAudience: true},
+	}
+
+	t.Run("case=should issue the token if an audience was granted", func(t *testing.T) {
+		token, _, err := strategy.GenerateAccessToken(context.Background(), jwtValidCase(fosite.AccessToken))
+		require.NoError(t, err)
+		assert.Len(t, strings.Split(token, "."), 3)
+	})
+
+	t.Run("case=should fail if no audience was granted", func(t *testing.T) {
+		r := jwtValidCase(fosite.AccessToken)
+		r.GrantedAudience = fosite.Arguments{}
+		_, _, err := strategy.GenerateAccessToken(context.Background(), r)
+		assert.ErrorIs(t, err, fosite.ErrInvalidRequest)
+	})
+
+	t.Run("case=should issue the token without audience if not required", func(t *testing.T) {
+		r := jwtValidCase(fosite.AccessToken)
+		r.GrantedAudience = fosite.Arguments{}
+		_, _, err := (&DefaultJWTStrategy{Signer: j.Signer, Config: &fosite.Config{}}).GenerateAccessToken(context.Background(), r)
+		assert.NoError(t, err)
+	})
+}
+
+type authClaimsJWTSession struct {
+	*JWTSession
+	Claims *jwt.IDTokenClaims
+}
+
+func (s *authClaimsJWTSession) IDTokenClaims() *jwt.IDTokenClaims {
+	return s.Claims
+}
+
+func TestAccessTokenIncludesAuthClaims(t *testing.T) {
+	authTime := time.Now().UTC().Add(-time.Minute).Truncate(time.Second)
+	newRequest := func() *fosite.Request {
+		r := jwtValidCase(fosite.AccessToken)
+		r.Session = &authClaimsJWTSession{
+			JWTSession: r.Session.(*JWTSession),
+			Claims:     &jwt.IDTokenClaims{AuthTime: authTime, AuthenticationContextClassReference: "urn:mace:incommon:iap:silver"},
+		}
+		return r
+	}
+
+	decode := func(t *testing.T, config *fosite.Config) jwt.MapClaims {
+		strategy := &DefaultJWTStrategy{Signer: j.Signer, Config: config}
+		token, _, err := strategy.GenerateAccessToken(context.Background(), newRequest())
+		require.NoError(t, err)
+
+		decoded, err := j.Signer.Decode(context.Background(), token)
+		require.NoError(t, err)
+		return decoded.Claims
+	}
+
+	t.Run("case=should include auth_time and acr if enabled", func(t *testing.T) {
+		claims := decode(t, &fosite.Config{IncludeJWTAccessTokenAuthClaims: true})
+		assert.EqualValues(t, authTime.Unix(), claims["auth_time"])
+		assert.Equal(t, "urn:mace:incommon:iap:silver", claims["acr"])
+	})
+
+	t.Run("case=should not include auth_time and acr by default", func(t *testing.T) {
+		claims := decode(t, &fosite.Config{})
+		assert.NotContains(t, claims, "auth_time")
+		assert.NotContains(t, claims, "acr")
+	})
+}
+
+func TestAccessTokenAudienceClaimName(t *testing.T) {
+	decode := func(t *testing.T, config *fosite.Config) jwt.MapClaims {
+		strategy := &DefaultJWTStrategy{Signer: j.Signer, Config: config}
+		token, _, err := strategy.GenerateAccessToken(context.Background(), jwtValidCase(fosite.AccessToken))
+		require.NoError(t, err)
+
+		decoded, err := j.Signer.Decode(context.Background(), token)
+		require.NoError(t, err)
+		return decoded.Claims
+	}
+
+	t.Run("case=should set the audience in the configured claim and aud", func(t *testing.T) {
+		claims := decode(t, &fosite.Config{JWTAudienceClaimKey: "resource"})
+		assert.Equal(t, []interface{}{"group0"}, claims["resource"])
+		assert.Equal(t, []interface{}{"group0"}, claims["aud"])
+	})
+
+	t.Run("case=should only set aud by default", func(t *testing.T) {
+		claims := decode(t, &fosite.Config{})
+		assert.Equal(t, []interface{}{"group0"}, claims["aud"])
+		assert.NotContains(t, claims, "resource")
+	})
+}
+
+func TestAccessTokenUniqueJTI(t *testing.T) {
+	newStrategy := func(store JTIStorage, jtis ...string) *DefaultJWTStrategy {
+		return &DefaultJWTStrategy{
+			Signer:     j.Signer,
+			Config:     &fosite.Config{EnforceUniqueJTI: true},
+			JTIStorage: store,
+			JTIGenerator: func() string {
+				jti := jtis[0]
+				if len(jtis) > 1 {
+					jtis = jtis[1:]
+				}
+				return jti
+			},
+		}
+	}
+
+	decodeJTI := func(t *testing.T, token string) string {
+		decoded, err := j.Signer.Decode(context.Background(), token)
+		require.NoError(t, err)
+		return decoded.Claims["jti"].(string)
+	}
+
+	t.Run("case=should record the jti", func(t *testing.T) {
+		store := storage.NewMemoryStore()
+		token, _, err := newStrategy(store, "first").GenerateAccessToken(context.Background(), jwtValidCase(fosite.AccessToken))
+		require.NoError(t, err)
+		assert.Equal(t, "first", decodeJTI(t, token))
+		assert.ErrorIs(t, store.MarkAccessTokenJTIUsedForTime(context.Background(), "first", time.Now().Add(time.Hour)), fosite.ErrJTIKnown)
+		assert.NoError(t, store.ClientAssertionJWTValid(context.Background(), "first"))
+	})
+
+	t.Run("case=should regenerate the jti on a collision", func(t *testing.T) {
+		store := storage.NewMemoryStore()
+		require.NoError(t, store.MarkAccessTokenJTIUsedForTime(context.Background(), "taken", time.Now().Add(time.Hour)))
+
+		token, _, err := newStrategy(store, "taken", "unique").GenerateAccessToken(context.Background(), jwtValidCase(fosite.AccessToken))
+		require.NoError(t, err)
+		assert.Equal(t, "unique", decodeJTI(t, token))
+	})
+
+	t.Run("case=should regenerate the jti if the session sets a taken one", func(t *testing.T) {
+		store := storage.NewMemoryStore()
+		require.NoError(t, store.MarkAccessTokenJTIUsedForTime(context.Background(), "taken", time.Now().Add(time.Hour)))
+
+		req := jwtValidCase(fosite.AccessToken)
+		req.Session.(*JWTSession).JWTClaims.JTI = "taken"
+		token, _, err := newStrategy(store, "unique").GenerateAccessToken(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, "unique", decodeJTI(t, token))
+	})
+
+	t.Run("case=should fail if the generator keeps colliding", func(t *testing.T) {
+		store := storage.NewMemoryStore()
+		require.NoError(t, store.MarkAccessTokenJTIUsedForTime(context.Background(), "taken", time.Now().Add(time.Hour)))
+
+		_, _, err := newStrategy(store, "taken").GenerateAccessToken(context.Background(), jwtValidCase(fosite.AccessToken))
+		assert.ErrorIs(t, err, fosite.ErrServerError)
+	})
+
+	t.Run("case=should fail without storage", func(t *testing.T) {
+		_, _, err := newStrategy(nil, "first").GenerateAccessToken(context.Background(), jwtValidCase(fosite.AccessToken))
+		assert.ErrorIs(t, err, fosite.ErrServerError)
+	})
+
+	t.Run("case=should not record the jti unless enforced", func(t *testing.T) {
+		store := storage.NewMemoryStore()
+		s := newStrategy(store, "first")
+		s.Config = &fosite.Config{}
+
+		_, _, err := s.GenerateAccessToken(context.Background(), jwtValidCase(fosite.AccessToken))
+		require.NoError(t, err)
+		assert.Empty(t, store.AccessTokenJTIs)
+	})
+
+	t.Run("case=should not be blocked by client assertions", func(t *testing.T) {
+		store := storage.NewMemoryStore()
+		require.NoError(t, store.SetClientAssertionJWT(context.Background(), "first", time.Now().Add(time.Hour)))
+
+		token, _, err := newStrategy(store, "first").GenerateAccessToken(context.Background(), jwtValidCase(fosite.AccessToken))
+		require.NoError(t, err)
+		assert.Equal(t, "first", decodeJTI(t, token))
+	})
+
+	t.Run("case=should not record an empty jti", func(t *testing.T) {
+		store := storage.NewMemoryStore()
+		_, _, err := newStrategy(store, "").GenerateAccessToken(context.Background(), jwtValidCase(fosite.AccessToken))
+		require.NoError(t, err)
+		assert.Empty(t, store.AccessTokenJTIs)
+	})
+
+	t.Run("case=should fail if the access token does not expire", func(t *testing.T) {
+		req := jwtValidCase(fosite.AccessToken)
+		req.Session.(*JWTSession).ExpiresAt = nil
+		_, _, err := newStrategy(storage.NewMemoryStore(), "first").GenerateAccessToken(context.Background(), req)
+		assert.ErrorIs(t, err, fosite.ErrServerError)
+	})
+
+	t.Run("case=should keep the jti of other claims", func(t *testing.T) {
+		req := jwtValidCase(fosite.AccessToken)
+		req.Session = &mapClaimsJWTSession{JWTSession: req.Session.(*JWTSession), claims: mapClaimsContainer{"sub": "peter", "jti": "from-session"}}
+		s := newStrategy(storage.NewMemoryStore(), "generated")
+		s.Config = &fosite.Config{}
+
+		token, _, err := s.GenerateAccessToken(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, "from-session", decodeJTI(t, token))
+	})
+}
+
+// mapClaimsContainer is a claims container other than jwt.JWTClaims.
+type mapClaimsContainer jwt.MapClaims
+
+func (c mapClaimsContainer) With(expiry time.Time, _, _ []string) jwt.JWTClaimsContainer {
+	c["exp"] = expiry.Unix()
+	return c
+}
+
+func (c mapClaimsContainer) WithDefaults(time.Time, string) jwt.JWTClaimsContainer {
+	return c
+}
+
+func (c mapClaimsContainer) WithScopeField(jwt.JWTScopeFieldEnum) jwt.JWTClaimsContainer {
+	return c
+}
+
+func (c mapClaimsContainer) ToMapClaims() jwt.MapClaims {
+	claims := jwt.MapClaims{}
+	for k, v := range c {
+		claims[k] = v
+	}
+	return claims
+}
+
+type mapClaimsJWTSession struct {
+	*JWTSession
+	claims mapClaimsContainer
+}
+
+func (s *mapClaimsJWTSession) GetJWTClaims() jwt.JWTClaimsContainer {
+	return s.claims
+}
+
+func TestAccessTokenJTIFromRandomSource(t *testing.T) {
+	source := []byte("0123456789abcdef")
+	expected, err := uuid.NewRandomFromReader(bytes.NewReader(source))
+	require.NoError(t, err)
+
+	s := &DefaultJWTStrategy{Signer: j.Signer, Config: &fosite.Config{RandomSource: bytes.NewReader(source)}}
+	token, _, err := s.GenerateAccessToken(context.Background(), jwtValidCase(fosite.AccessToken))
+	require.NoError(t, err)
+
+	decoded, err := j.Signer.Decode(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, expected.String(), decoded.Claims["jti"])
+
+	s.Config = &fosite.Config{RandomSource: iotest.ErrReader(errors.New("the random number generator failed"))}
+	_, _, err = s.GenerateAccessToken(context.Background(), jwtValidCase(fosite.AccessToken))
+	assert.ErrorIs(t, err, fosite.ErrServerError)
+}
@@ -0,0 +1,95 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package oauth2
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ory/fosite"
+)
+
+// PerClientAccessTokenStrategy issues opaque or JWT access tokens depending on the strategy selected by the
+// client, see fosite.AccessTokenStrategyClient. This allows running both strategies side by side, for example while
+// migrating from opaque to JWT access tokens. Access tokens are routed to the matching strategy by their format.
+// Refresh tokens and authorize codes are always handled by the HMACSHAStrategy.
+type PerClientAccessTokenStrategy struct {
+	HMACSHAStrategy CoreStrategy
+	JWTStrategy     AccessTokenStrategy
+
+	// DefaultAccessTokenStrategy is the strategy of clients which do not select one. Defaults to
+	// fosite.AccessTokenStrategyOpaque.
+	DefaultAccessTokenStrategy string
+}
+
+var _ CoreStrategy = (*PerClientAccessTokenStrategy)(nil)
+
+// isJWT returns true if the token consists of the three parts of a compact serialized JWT.
+func isJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+func (h *PerClientAccessTokenStrategy) forToken(token string) AccessTokenStrategy {
+	if isJWT(token) {
+		return h.JWTStrategy
+	}
+	return h.HMACSHAStrategy
+}
+
+func (h *PerClientAccessTokenStrategy) forClient(client fosite.Client) (AccessTokenStrategy, error) {
+	strategy := h.DefaultAccessTokenStrategy
+	if c, ok := client.(fosite.AccessTokenStrategyClient); ok && c.GetAccessTokenStrategy() != "" {
+		strategy = c.GetAccessTokenStrategy()
+	}
+
+	switch strategy {
+	case "", fosite.AccessTokenStrategyOpaque:
+		return h.HMACSHAStrategy, nil
+	case fosite.AccessTokenStrategyJWT:
+		return h.JWTStrategy, nil
+	}
+	return nil, errors.Errorf("Access token strategy '%s' is not supported", strategy)
+}
+
+func (h *PerClientAccessTokenStrategy) AccessTokenSignature(ctx context.Context, token string) string {
+	return h.forToken(token).AccessTokenSignature(ctx, token)
+}
+
+func (h *PerClientAccessTokenStrategy) GenerateAccessToken(ctx context.Context, requester fosite.Requester) (token string, signature string, err error) {
+	strategy, err := h.forClient(requester.GetClient())
+	if err != nil {
+		return "", "", err
+	}
+	return strategy.GenerateAccessToken(ctx, requester)
+}
+
+func (h *PerClientAccessTokenStrategy) ValidateAccessToken(ctx context.Context, requester fosite.Requester, token string) error {
+	return h.forToken(token).ValidateAccessToken(ctx, requester, token)
+}
+
+func (h *PerClientAccessTokenStrategy) RefreshTokenSignature(ctx context.Context, token string) string {
+	return h.HMACSHAStrategy.RefreshTokenSignature(ctx, token)
+}
+
+func (h *PerClientAccessTokenStrategy) AuthorizeCodeSignature(ctx context.Context, token string) string {
+	return h.HMACSHAStrategy.AuthorizeCodeSignature(ctx, token)
+}
+
+func (h *PerClientAccessTokenStrategy) GenerateRefreshToken(ctx context.Context, req fosite.Requester) (token string, signature string, err error) {
+	return h.HMACSHAStrategy.GenerateRefreshToken(ctx, req)
+}
+
+func (h *PerClientAccessTokenStrategy) ValidateRefreshToken(ctx context.Context, req fosite.Requester, token string) error {
+	return h.HMACSHAStrategy.ValidateRefreshToken(ctx, req, token)
+}
+
+func (h *PerClientAccessTokenStrategy) GenerateAuthorizeCode(ctx context.Context, req fosite.Requester) (token string, signature string, err error) {
+	return h.HMACSHAStrategy.GenerateAuthorizeCode(ctx, req)
+}
+
+func (h *PerClientAccessTokenStrategy) ValidateAuthorizeCode(ctx context.Context, req fosite.Requester, token string) error {
+	return h.HMACSHAStrategy.ValidateAuthorizeCode(ctx, req, token)
+}
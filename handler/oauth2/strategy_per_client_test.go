@@ -0,0 +1,58 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package oauth2
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/fosite"
+)
+
+func TestPerClientAccessTokenStrategy(t *testing.T) {
+	for k, c := range []struct {
+		d         string
+		strategy  string
+		fallback  string
+		expectJWT bool
+		expectErr bool
+	}{
+		{d: "should issue opaque tokens by default"},
+		{d: "should issue opaque tokens if the client selects them", strategy: fosite.AccessTokenStrategyOpaque, fallback: fosite.AccessTokenStrategyJWT},
+		{d: "should issue JWTs if the client selects them", strategy: fosite.AccessTokenStrategyJWT, expectJWT: true},
+		{d: "should fall back to the default strategy", fallback: fosite.AccessTokenStrategyJWT, expectJWT: true},
+		{d: "should fail on an unknown strategy", strategy: "foo", expectErr: true},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.d), func(t *testing.T) {
+			strategy := &PerClientAccessTokenStrategy{
+				HMACSHAStrategy:            hmacshaStrategy,
+				JWTStrategy:                j,
+				DefaultAccessTokenStrategy: c.fallback,
+			}
+
+			r := jwtValidCase(fosite.AccessToken)
+			r.Client = &fosite.DefaultAccessTokenStrategyClient{DefaultClient: &fosite.DefaultClient{}, AccessTokenStrategy: c.strategy}
+
+			token, signature, err := strategy.GenerateAccessToken(context.Background(), r)
+			if c.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			if c.expectJWT {
+				assert.Len(t, strings.Split(token, "."), 3)
+			} else {
+				assert.True(t, strings.HasPrefix(token, "ory_at_"), "%s", token)
+			}
+			assert.Equal(t, signature, strategy.AccessTokenSignature(context.Background(), token))
+			assert.NoError(t, strategy.ValidateAccessToken(context.Background(), r, token))
+		})
+	}
+}
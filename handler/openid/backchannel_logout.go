@@ -0,0 +1,170 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package openid
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ory/x/errorsx"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/token/jwt"
+)
+
+// BackChannelLogoutEvent is the member of the events claim identifying a logout token.
+const BackChannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+
+// SessionIDClaim is the claim of the ID token holding the session ID of the end-user at the OpenID Provider. It is
+// read from the extra ID token claims of the session.
+const SessionIDClaim = "sid"
+
+const defaultLogoutTokenLifespan = 2 * time.Minute
+
+// BackChannelLogoutToken is a logout token which must be delivered to the back-channel logout URI of the client by
+// sending it as the "logout_token" form parameter.
+type BackChannelLogoutToken struct {
+	Client      fosite.BackChannelLogoutClient
+	SessionID   string
+	LogoutToken string
+}
+
+// BackChannelLogoutHandler issues the logout tokens of OpenID Connect Back-Channel Logout 1.0. It enumerates the
+// clients to which tokens have been issued for an end-user and mints a signed logout token for each of them which
+// registered a back-channel logout URI. Delivering the logout tokens is left to the caller.
+type BackChannelLogoutHandler struct {
+	Storage BackChannelLogoutStorage
+	Signer  jwt.Signer
+	Config  interface {
+		fosite.IDTokenIssuerProvider
+	}
+}
+
+type backChannelLogoutSession struct {
+	client    fosite.BackChannelLogoutClient
+	subject   string
+	sessionID string
+}
+
+// GetBackChannelLogoutClients returns the clients which registered a back-channel logout URI and were issued tokens
+// for the given subject or session ID. At least one of them must be set.
+func (h *BackChannelLogoutHandler) GetBackChannelLogoutClients(ctx context.Context, subject, sessionID string) ([]fosite.BackChannelLogoutClient, error) {
+	sessions, err := h.getSessions(ctx, subject, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var clients []fosite.BackChannelLogoutClient
+	seen := map[string]bool{}
+	for _, s := range sessions {
+		if !seen[s.client.GetID()] {
+			seen[s.client.GetID()] = true
+			clients = append(clients, s.client)
+		}
+	}
+	return clients, nil
+}
+
+// GenerateBackChannelLogoutTokens returns a logout token for each session of the given subject or session ID at a
+// client which registered a back-channel logout URI. At least one of them must be set.
+func (h *BackChannelLogoutHandler) GenerateBackChannelLogoutTokens(ctx context.Context, subject, sessionID string) ([]BackChannelLogoutToken, error) {
+	sessions, err := h.getSessions(ctx, subject, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]BackChannelLogoutToken, 0, len(sessions))
+	for _, s := range sessions {
+		token, err := h.generateLogoutToken(ctx, s)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, BackChannelLogoutToken{Client: s.client, SessionID: s.sessionID, LogoutToken: token})
+	}
+	return tokens, nil
+}
+
+func (h *BackChannelLogoutHandler) generateLogoutToken(ctx context.Context, s backChannelLogoutSession) (string, error) {
+	now := time.Now().UTC()
+	claims := jwt.MapClaims{
+		"iss": h.Config.GetIDTokenIssuer(ctx),
+		"aud": []string{s.client.GetID()},
+		"iat": now.Unix(),
+		"exp": now.Add(defaultLogoutTokenLifespan).Unix(),
+		"jti": uuid.New().String(),
+		"events": map[string]interface{}{
+			BackChannelLogoutEvent: map[string]interface{}{},
+		},
+	}
+	if s.subject != "" {
+		claims["sub"] = s.subject
+	}
+	if s.sessionID != "" {
+		claims[SessionIDClaim] = s.sessionID
+	}
+
+	headers := &jwt.Headers{}
+	headers.Add("typ", "logout+jwt")
+
+	token, _, err := h.Signer.Generate(ctx, claims, headers)
+	if err != nil {
+		return "", errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+	return token, nil
+}
+
+// getSessions returns the distinct sessions of the given subject or session ID at clients which registered a
+// back-channel logout URI, ordered by client ID.
+func (h *BackChannelLogoutHandler) getSessions(ctx context.Context, subject, sessionID string) ([]backChannelLogoutSession, error) {
+	if subject == "" && sessionID == "" {
+		return nil, errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("A subject or a session ID is required to log out an end-user."))
+	}
+
+	requests, err := h.Storage.GetActiveRequestsBySubject(ctx, subject)
+	if err != nil {
+		return nil, errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	var sessions []backChannelLogoutSession
+	seen := map[string]bool{}
+	for _, r := range requests {
+		client, ok := r.GetClient().(fosite.BackChannelLogoutClient)
+		if !ok || client.GetBackChannelLogoutURI() == "" {
+			continue
+		}
+
+		s := backChannelLogoutSession{client: client, sessionID: getSessionID(r)}
+		if sessionID != "" && s.sessionID != sessionID {
+			continue
+		}
+		if r.GetSession() != nil {
+			s.subject = r.GetSession().GetSubject()
+		}
+
+		key := client.GetID() + "\x00" + s.subject + "\x00" + s.sessionID
+		if !seen[key] {
+			seen[key] = true
+			sessions = append(sessions, s)
+		}
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		if sessions[i].client.GetID() != sessions[j].client.GetID() {
+			return sessions[i].client.GetID() < sessions[j].client.GetID()
+		}
+		return sessions[i].sessionID < sessions[j].sessionID
+	})
+	return sessions, nil
+}
+
+func getSessionID(r fosite.Requester) string {
+	sess, ok := r.GetSession().(Session)
+	if !ok {
+		return ""
+	}
+	sid, _ := sess.IDTokenClaims().Extra[SessionIDClaim].(string)
+	return sid
+}
@@ -0,0 +1,97 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package openid
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/storage"
+	"github.com/ory/fosite/token/jwt"
+)
+
+func TestBackChannelLogoutHandler(t *testing.T) {
+	store := storage.NewMemoryStore()
+	h := &BackChannelLogoutHandler{
+		Storage: store,
+		Signer: &jwt.DefaultSigner{
+			GetPrivateKey: func(_ context.Context) (interface{}, error) {
+				return key, nil
+			},
+		},
+		Config: &fosite.Config{IDTokenIssuer: "https://auth.example.com"},
+	}
+
+	newClient := func(id, uri string) fosite.Client {
+		return &fosite.DefaultBackChannelLogoutClient{
+			DefaultOpenIDConnectClient: &fosite.DefaultOpenIDConnectClient{DefaultClient: &fosite.DefaultClient{ID: id}},
+			BackChannelLogoutURI:       uri,
+		}
+	}
+
+	createSession := func(signature string, client fosite.Client, subject, sid string) {
+		req := fosite.NewAccessRequest(&DefaultSession{
+			Subject: subject,
+			Claims:  &jwt.IDTokenClaims{Subject: subject, Extra: map[string]interface{}{SessionIDClaim: sid}},
+		})
+		req.Client = client
+		require.NoError(t, store.CreateAccessTokenSession(context.Background(), signature, req))
+	}
+
+	createSession("a", newClient("client-a", "https://a.example.com/logout"), "peter", "session-1")
+	createSession("b", newClient("client-b", "https://b.example.com/logout"), "peter", "session-2")
+	createSession("c", newClient("client-c", ""), "peter", "session-1")
+	createSession("d", &fosite.DefaultClient{ID: "client-d"}, "peter", "session-1")
+	createSession("e", newClient("client-a", "https://a.example.com/logout"), "alice", "session-3")
+
+	t.Run("case=should require a subject or session ID", func(t *testing.T) {
+		_, err := h.GetBackChannelLogoutClients(context.Background(), "", "")
+		require.ErrorIs(t, err, fosite.ErrInvalidRequest)
+	})
+
+	t.Run("case=should enumerate the clients of a subject", func(t *testing.T) {
+		clients, err := h.GetBackChannelLogoutClients(context.Background(), "peter", "")
+		require.NoError(t, err)
+		require.Len(t, clients, 2)
+		assert.Equal(t, "client-a", clients[0].GetID())
+		assert.Equal(t, "client-b", clients[1].GetID())
+	})
+
+	t.Run("case=should enumerate the clients of a session", func(t *testing.T) {
+		clients, err := h.GetBackChannelLogoutClients(context.Background(), "", "session-3")
+		require.NoError(t, err)
+		require.Len(t, clients, 1)
+		assert.Equal(t, "client-a", clients[0].GetID())
+	})
+
+	t.Run("case=should generate signed logout tokens", func(t *testing.T) {
+		tokens, err := h.GenerateBackChannelLogoutTokens(context.Background(), "peter", "session-1")
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+		assert.Equal(t, "client-a", tokens[0].Client.GetID())
+		assert.Equal(t, "https://a.example.com/logout", tokens[0].Client.GetBackChannelLogoutURI())
+
+		token, err := jwt.Parse(tokens[0].LogoutToken, func(*jwt.Token) (interface{}, error) {
+			return &key.PublicKey, nil
+		})
+		require.NoError(t, err)
+		assert.True(t, token.Valid())
+		assert.Equal(t, "logout+jwt", token.Header["typ"])
+
+		claims := token.Claims
+		assert.Equal(t, "https://auth.example.com", claims["iss"])
+		assert.Equal(t, "peter", claims["sub"])
+		assert.Equal(t, "session-1", claims["sid"])
+		assert.Equal(t, []interface{}{"client-a"}, claims["aud"])
+		assert.NotEmpty(t, claims["jti"])
+		assert.NotContains(t, claims, "nonce")
+		assert.WithinDuration(t, time.Now(), time.Unix(claims["iat"].(int64), 0), time.Minute)
+		assert.Equal(t, map[string]interface{}{BackChannelLogoutEvent: map[string]interface{}{}}, claims["events"])
+	})
+}
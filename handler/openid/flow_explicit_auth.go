@@ -18,12 +18,15 @@ type OpenIDConnectExplicitHandler struct {
 
 	Config interface {
 		fosite.IDTokenLifespanProvider
+		fosite.EmptyClientGrantTypesPolicyProvider
+		idTokenAudienceConfigProvider
 	}
 
 	*IDTokenHandleHelper
 }
 
 var _ fosite.AuthorizeEndpointHandler = (*OpenIDConnectExplicitHandler)(nil)
+var _ fosite.AuthorizeEndpointRequestValidator = (*OpenIDConnectExplicitHandler)(nil)
 var _ fosite.TokenEndpointHandler = (*OpenIDConnectExplicitHandler)(nil)
 
 var oidcParameters = []string{"grant_type",
@@ -32,6 +35,7 @@ var oidcParameters = []string{"grant_type",
 	"acr_values",
 	"id_token_hint",
 	"nonce",
+	"claims",
 }
 
 func (c *OpenIDConnectExplicitHandler) HandleAuthorizeEndpointRequest(ctx context.Context, ar fosite.AuthorizeRequester, resp fosite.AuthorizeResponder) error {
@@ -71,3 +75,18 @@ func (c *OpenIDConnectExplicitHandler) HandleAuthorizeEndpointRequest(ctx contex
 
 	return nil
 }
+
+// ValidateAuthorizeEndpointRequest validates OpenID Connect authorize requests using the authorize code flow. As the
+// scopes have not been granted yet, the request is considered an OpenID Connect request if the "openid" scope was
+// requested. The "code" response type is left to the authorize code handler.
+func (c *OpenIDConnectExplicitHandler) ValidateAuthorizeEndpointRequest(ctx context.Context, ar fosite.AuthorizeRequester) error {
+	if !(ar.GetRequestedScopes().Has("openid") && ar.GetResponseTypes().ExactOne("code")) {
+		return nil
+	}
+
+	if len(ar.GetRequestForm().Get("redirect_uri")) == 0 {
+		return errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("The 'redirect_uri' parameter is required when using OpenID Connect 1.0."))
+	}
+
+	return c.OpenIDConnectRequestValidator.ValidatePromptParameters(ctx, ar)
+}
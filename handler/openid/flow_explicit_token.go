@@ -14,6 +14,24 @@ import (
 )
 
 func (c *OpenIDConnectExplicitHandler) HandleTokenEndpointRequest(ctx context.Context, request fosite.AccessRequester) error {
+	if !c.CanHandleTokenEndpointRequest(ctx, request) {
+		return errorsx.WithStack(fosite.ErrUnknownRequest)
+	}
+
+	// The ID token is issued after the authorization code has been invalidated, so an ID token which would be
+	// rejected because of its audience must be rejected before.
+	if c.Config.GetIDTokenMaxAudiences(ctx) <= 0 || c.Config.GetIDTokenDropExcessAudiences(ctx) {
+		return errorsx.WithStack(fosite.ErrUnknownRequest)
+	}
+
+	if authorize, err := c.OpenIDConnectRequestStorage.GetOpenIDConnectSession(ctx, request.GetRequestForm().Get("code"), request); err == nil {
+		if sess, ok := authorize.GetSession().(Session); ok && sess.IDTokenClaims() != nil {
+			if _, err := idTokenAudience(ctx, c.Config, sess.IDTokenClaims(), request.GetClient().GetID()); err != nil {
+				return err
+			}
+		}
+	}
+
 	return errorsx.WithStack(fosite.ErrUnknownRequest)
 }
 
@@ -35,7 +53,7 @@ func (c *OpenIDConnectExplicitHandler) PopulateTokenEndpointResponse(ctx context
 		return errorsx.WithStack(fosite.ErrMisconfiguration.WithDebug("An OpenID Connect session was found but the openid scope is missing, probably due to a broken code configuration."))
 	}
 
-	if !requester.GetClient().GetGrantTypes().Has("authorization_code") {
+	if !fosite.ClientHasGrantType(ctx, c.Config, requester.GetClient(), "authorization_code") {
 		return errorsx.WithStack(fosite.ErrUnauthorizedClient.WithHint("The OAuth 2.0 Client is not allowed to use the authorization grant \"authorization_code\"."))
 	}
 
@@ -74,3 +92,7 @@ func (c *OpenIDConnectExplicitHandler) CanSkipClientAuth(ctx context.Context, re
 func (c *OpenIDConnectExplicitHandler) CanHandleTokenEndpointRequest(ctx context.Context, requester fosite.AccessRequester) bool {
 	return requester.GetGrantTypes().ExactOne("authorization_code")
 }
+
+func (c *OpenIDConnectExplicitHandler) HandledGrantTypes() []fosite.GrantType {
+	return []fosite.GrantType{fosite.GrantTypeAuthorizationCode}
+}
@@ -26,6 +26,31 @@ func TestHandleTokenEndpointRequest(t *testing.T) {
 		//ResponseTypes: fosite.Arguments{"id_token"},
 	}
 	assert.EqualError(t, h.HandleTokenEndpointRequest(context.Background(), areq), fosite.ErrUnknownRequest.Error())
+
+	t.Run("case=should reject too many ID token audiences before the code is invalidated", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		store := internal.NewMockOpenIDConnectRequestStorage(ctrl)
+		defer ctrl.Finish()
+
+		storedReq := fosite.NewAuthorizeRequest()
+		storedReq.GrantedScope = fosite.Arguments{"openid"}
+		storedReq.Session = &DefaultSession{Claims: &jwt.IDTokenClaims{Subject: "peter", Audience: []string{"foo", "bar"}}}
+
+		req := fosite.NewAccessRequest(nil)
+		req.Client = &fosite.DefaultClient{ID: "client"}
+		req.GrantTypes = fosite.Arguments{"authorization_code"}
+		req.Form.Set("code", "foobar")
+		store.EXPECT().GetOpenIDConnectSession(gomock.Any(), "foobar", req).Return(storedReq, nil).Times(2)
+
+		h := &OpenIDConnectExplicitHandler{OpenIDConnectRequestStorage: store, Config: &fosite.Config{IDTokenMaxAudiences: 2}}
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(context.Background(), req), fosite.ErrInvalidRequest)
+
+		h.Config = &fosite.Config{IDTokenMaxAudiences: 2, IDTokenDropExcessAudiences: true}
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(context.Background(), req), fosite.ErrUnknownRequest)
+
+		h.Config = &fosite.Config{IDTokenMaxAudiences: 3}
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(context.Background(), req), fosite.ErrUnknownRequest)
+	})
 }
 
 func TestExplicit_PopulateTokenEndpointResponse(t *testing.T) {
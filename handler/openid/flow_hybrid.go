@@ -26,10 +26,14 @@ type OpenIDConnectHybridHandler struct {
 	Config interface {
 		fosite.IDTokenLifespanProvider
 		fosite.MinParameterEntropyProvider
+		fosite.MinNonceEntropyProvider
 		fosite.ScopeStrategyProvider
+		fosite.EmptyClientGrantTypesPolicyProvider
 	}
 }
 
+var _ fosite.AuthorizeEndpointRequestValidator = (*OpenIDConnectHybridHandler)(nil)
+
 func (c *OpenIDConnectHybridHandler) HandleAuthorizeEndpointRequest(ctx context.Context, ar fosite.AuthorizeRequester, resp fosite.AuthorizeResponder) error {
 	if len(ar.GetResponseTypes()) < 2 {
 		return nil
@@ -40,39 +44,8 @@ func (c *OpenIDConnectHybridHandler) HandleAuthorizeEndpointRequest(ctx context.
 	}
 
 	ar.SetDefaultResponseMode(fosite.ResponseModeFragment)
-
-	// Disabled because this is already handled at the authorize_request_handler
-	//if ar.GetResponseTypes().Matches("token") && !ar.GetClient().GetResponseTypes().Has("token") {
-	//	return errorsx.WithStack(fosite.ErrInvalidGrant.WithDebug("The client is not allowed to use the token response type"))
-	//} else if ar.GetResponseTypes().Matches("code") && !ar.GetClient().GetResponseTypes().Has("code") {
-	//	return errorsx.WithStack(fosite.ErrInvalidGrant.WithDebug("The client is not allowed to use the code response type"))
-	//} else if ar.GetResponseTypes().Matches("id_token") && !ar.GetClient().GetResponseTypes().Has("id_token") {
-	//	return errorsx.WithStack(fosite.ErrInvalidGrant.WithDebug("The client is not allowed to use the id_token response type"))
-	//}
-
-	// The nonce is actually not required for hybrid flows. It fails the OpenID Connect Conformity
-	// Test Module "oidcc-ensure-request-without-nonce-succeeds-for-code-flow" if enabled.
-	//
-	nonce := ar.GetRequestForm().Get("nonce")
-
-	if len(nonce) == 0 && ar.GetResponseTypes().Has("id_token") {
-		return errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("Parameter 'nonce' must be set when requesting an ID Token using the OpenID Connect Hybrid Flow."))
-	}
-
-	if len(nonce) > 0 && len(nonce) < c.Config.GetMinParameterEntropy(ctx) {
-		return errorsx.WithStack(fosite.ErrInsufficientEntropy.WithHintf("Parameter 'nonce' is set but does not satisfy the minimum entropy of %d characters.", c.Config.GetMinParameterEntropy(ctx)))
-	}
-
-	// This ensures that the 'redirect_uri' parameter is present for OpenID Connect 1.0 authorization requests as per:
-	//
-	// Authorization Code Flow - https://openid.net/specs/openid-connect-core-1_0.html#AuthRequest
-	// Implicit Flow - https://openid.net/specs/openid-connect-core-1_0.html#ImplicitAuthRequest
-	// Hybrid Flow - https://openid.net/specs/openid-connect-core-1_0.html#HybridAuthRequest
-	//
-	// Note: as per the Hybrid Flow documentation the Hybrid Flow has the same requirements as the Authorization Code Flow.
-	rawRedirectURI := ar.GetRequestForm().Get("redirect_uri")
-	if len(rawRedirectURI) == 0 {
-		return errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("The 'redirect_uri' parameter is required when using OpenID Connect 1.0."))
+	if err := c.validateAuthorizeEndpointRequest(ctx, ar); err != nil {
+		return err
 	}
 
 	sess, ok := ar.GetSession().(Session)
@@ -93,7 +66,7 @@ func (c *OpenIDConnectHybridHandler) HandleAuthorizeEndpointRequest(ctx context.
 
 	claims := sess.IDTokenClaims()
 	if ar.GetResponseTypes().Has("code") {
-		if !ar.GetClient().GetGrantTypes().Has("authorization_code") {
+		if !fosite.ClientHasGrantType(ctx, c.Config, ar.GetClient(), "authorization_code") {
 			return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The OAuth 2.0 Client is not allowed to use authorization grant 'authorization_code'."))
 		}
 
@@ -132,7 +105,7 @@ func (c *OpenIDConnectHybridHandler) HandleAuthorizeEndpointRequest(ctx context.
 	}
 
 	if ar.GetResponseTypes().Has("token") {
-		if !ar.GetClient().GetGrantTypes().Has("implicit") {
+		if !fosite.ClientHasGrantType(ctx, c.Config, ar.GetClient(), "implicit") {
 			return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The OAuth 2.0 Client is not allowed to use the authorization grant 'implicit'."))
 		} else if err := c.AuthorizeImplicitGrantTypeHandler.IssueImplicitAccessToken(ctx, ar, resp); err != nil {
 			return errorsx.WithStack(err)
@@ -166,3 +139,85 @@ func (c *OpenIDConnectHybridHandler) HandleAuthorizeEndpointRequest(ctx context.
 	// there is no need to check for https, because implicit flow does not require https
 	// https://tools.ietf.org/html/rfc6819#section-4.4.2
 }
+
+// ValidateAuthorizeEndpointRequest validates authorize requests using the OpenID Connect hybrid flow and marks their
+// response types as handled.
+func (c *OpenIDConnectHybridHandler) ValidateAuthorizeEndpointRequest(ctx context.Context, ar fosite.AuthorizeRequester) error {
+	if len(ar.GetResponseTypes()) < 2 {
+		return nil
+	}
+
+	if !(ar.GetResponseTypes().Matches("token", "id_token", "code") || ar.GetResponseTypes().Matches("token", "code") || ar.GetResponseTypes().Matches("id_token", "code")) {
+		return nil
+	}
+
+	ar.SetDefaultResponseMode(fosite.ResponseModeFragment)
+	if err := c.validateAuthorizeEndpointRequest(ctx, ar); err != nil {
+		return err
+	}
+
+	if err := c.OpenIDConnectRequestValidator.ValidatePromptParameters(ctx, ar); err != nil {
+		return err
+	}
+
+	client := ar.GetClient()
+	for _, scope := range ar.GetRequestedScopes() {
+		if !c.Config.GetScopeStrategy(ctx)(client.GetScopes(), scope) {
+			return errorsx.WithStack(fosite.ErrInvalidScope.WithHintf("The OAuth 2.0 Client is not allowed to request scope '%s'.", scope))
+		}
+	}
+
+	if ar.GetResponseTypes().Has("code") {
+		if !fosite.ClientHasGrantType(ctx, c.Config, ar.GetClient(), "authorization_code") {
+			return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The OAuth 2.0 Client is not allowed to use authorization grant 'authorization_code'."))
+		}
+		ar.SetResponseTypeHandled("code")
+	}
+
+	if ar.GetResponseTypes().Has("token") {
+		if !fosite.ClientHasGrantType(ctx, c.Config, ar.GetClient(), "implicit") {
+			return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The OAuth 2.0 Client is not allowed to use the authorization grant 'implicit'."))
+		}
+		ar.SetResponseTypeHandled("token")
+	}
+
+	ar.SetResponseTypeHandled("id_token")
+	return nil
+}
+
+func (c *OpenIDConnectHybridHandler) validateAuthorizeEndpointRequest(ctx context.Context, ar fosite.AuthorizeRequester) error {
+	// Disabled because this is already handled at the authorize_request_handler
+	//if ar.GetResponseTypes().Matches("token") && !ar.GetClient().GetResponseTypes().Has("token") {
+	//	return errorsx.WithStack(fosite.ErrInvalidGrant.WithDebug("The client is not allowed to use the token response type"))
+	//} else if ar.GetResponseTypes().Matches("code") && !ar.GetClient().GetResponseTypes().Has("code") {
+	//	return errorsx.WithStack(fosite.ErrInvalidGrant.WithDebug("The client is not allowed to use the code response type"))
+	//} else if ar.GetResponseTypes().Matches("id_token") && !ar.GetClient().GetResponseTypes().Has("id_token") {
+	//	return errorsx.WithStack(fosite.ErrInvalidGrant.WithDebug("The client is not allowed to use the id_token response type"))
+	//}
+
+	// The nonce is actually not required for hybrid flows. It fails the OpenID Connect Conformity
+	// Test Module "oidcc-ensure-request-without-nonce-succeeds-for-code-flow" if enabled.
+	//
+	nonce := ar.GetRequestForm().Get("nonce")
+
+	if len(nonce) == 0 && ar.GetResponseTypes().Has("id_token") {
+		return errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("Parameter 'nonce' must be set when requesting an ID Token using the OpenID Connect Hybrid Flow."))
+	}
+
+	if len(nonce) > 0 && len(nonce) < c.Config.GetMinNonceEntropy(ctx) {
+		return errorsx.WithStack(fosite.ErrInsufficientEntropy.WithHintf("Parameter 'nonce' is set but does not satisfy the minimum entropy of %d characters.", c.Config.GetMinNonceEntropy(ctx)))
+	}
+
+	// This ensures that the 'redirect_uri' parameter is present for OpenID Connect 1.0 authorization requests as per:
+	//
+	// Authorization Code Flow - https://openid.net/specs/openid-connect-core-1_0.html#AuthRequest
+	// Implicit Flow - https://openid.net/specs/openid-connect-core-1_0.html#ImplicitAuthRequest
+	// Hybrid Flow - https://openid.net/specs/openid-connect-core-1_0.html#HybridAuthRequest
+	//
+	// Note: as per the Hybrid Flow documentation the Hybrid Flow has the same requirements as the Authorization Code Flow.
+	rawRedirectURI := ar.GetRequestForm().Get("redirect_uri")
+	if len(rawRedirectURI) == 0 {
+		return errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("The 'redirect_uri' parameter is required when using OpenID Connect 1.0."))
+	}
+	return nil
+}
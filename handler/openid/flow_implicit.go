@@ -23,10 +23,14 @@ type OpenIDConnectImplicitHandler struct {
 	Config interface {
 		fosite.IDTokenLifespanProvider
 		fosite.MinParameterEntropyProvider
+		fosite.MinNonceEntropyProvider
 		fosite.ScopeStrategyProvider
+		fosite.EmptyClientGrantTypesPolicyProvider
 	}
 }
 
+var _ fosite.AuthorizeEndpointRequestValidator = (*OpenIDConnectImplicitHandler)(nil)
+
 func (c *OpenIDConnectImplicitHandler) HandleAuthorizeEndpointRequest(ctx context.Context, ar fosite.AuthorizeRequester, resp fosite.AuthorizeResponder) error {
 	if !(ar.GetGrantedScopes().Has("openid") && (ar.GetResponseTypes().Has("token", "id_token") || ar.GetResponseTypes().ExactOne("id_token"))) {
 		return nil
@@ -36,41 +40,8 @@ func (c *OpenIDConnectImplicitHandler) HandleAuthorizeEndpointRequest(ctx contex
 	}
 
 	ar.SetDefaultResponseMode(fosite.ResponseModeFragment)
-
-	if !ar.GetClient().GetGrantTypes().Has("implicit") {
-		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The OAuth 2.0 Client is not allowed to use the authorization grant 'implicit'."))
-	}
-
-	// Disabled because this is already handled at the authorize_request_handler
-	//if ar.GetResponseTypes().ExactOne("id_token") && !ar.GetClient().GetResponseTypes().Has("id_token") {
-	//	return errorsx.WithStack(fosite.ErrInvalidGrant.WithDebug("The client is not allowed to use response type id_token"))
-	//} else if ar.GetResponseTypes().Matches("token", "id_token") && !ar.GetClient().GetResponseTypes().Has("token", "id_token") {
-	//	return errorsx.WithStack(fosite.ErrInvalidGrant.WithDebug("The client is not allowed to use response type token and id_token"))
-	//}
-
-	// This ensures that the 'redirect_uri' parameter is present for OpenID Connect 1.0 authorization requests as per:
-	//
-	// Authorization Code Flow - https://openid.net/specs/openid-connect-core-1_0.html#AuthRequest
-	// Implicit Flow - https://openid.net/specs/openid-connect-core-1_0.html#ImplicitAuthRequest
-	// Hybrid Flow - https://openid.net/specs/openid-connect-core-1_0.html#HybridAuthRequest
-	//
-	// Note: as per the Hybrid Flow documentation the Hybrid Flow has the same requirements as the Authorization Code Flow.
-	rawRedirectURI := ar.GetRequestForm().Get("redirect_uri")
-	if len(rawRedirectURI) == 0 {
-		return errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("The 'redirect_uri' parameter is required when using OpenID Connect 1.0."))
-	}
-
-	if nonce := ar.GetRequestForm().Get("nonce"); len(nonce) == 0 {
-		return errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("Parameter 'nonce' must be set when using the OpenID Connect Implicit Flow."))
-	} else if len(nonce) < c.Config.GetMinParameterEntropy(ctx) {
-		return errorsx.WithStack(fosite.ErrInsufficientEntropy.WithHintf("Parameter 'nonce' is set but does not satisfy the minimum entropy of %d characters.", c.Config.GetMinParameterEntropy(ctx)))
-	}
-
-	client := ar.GetClient()
-	for _, scope := range ar.GetRequestedScopes() {
-		if !c.Config.GetScopeStrategy(ctx)(client.GetScopes(), scope) {
-			return errorsx.WithStack(fosite.ErrInvalidScope.WithHintf("The OAuth 2.0 Client is not allowed to request scope '%s'.", scope))
-		}
+	if err := c.validateAuthorizeEndpointRequest(ctx, ar); err != nil {
+		return err
 	}
 
 	sess, ok := ar.GetSession().(Session)
@@ -110,3 +81,70 @@ func (c *OpenIDConnectImplicitHandler) HandleAuthorizeEndpointRequest(ctx contex
 	ar.SetResponseTypeHandled("id_token")
 	return nil
 }
+
+// ValidateAuthorizeEndpointRequest validates OpenID Connect authorize requests using the implicit flow and marks the
+// "token" and "id_token" response types as handled. As the scopes have not been granted yet, the request is
+// considered an OpenID Connect request if the "openid" scope was requested.
+func (c *OpenIDConnectImplicitHandler) ValidateAuthorizeEndpointRequest(ctx context.Context, ar fosite.AuthorizeRequester) error {
+	if !(ar.GetRequestedScopes().Has("openid") && (ar.GetResponseTypes().Has("token", "id_token") || ar.GetResponseTypes().ExactOne("id_token"))) {
+		return nil
+	} else if ar.GetResponseTypes().Has("code") {
+		// hybrid flow
+		return nil
+	}
+
+	ar.SetDefaultResponseMode(fosite.ResponseModeFragment)
+	if err := c.validateAuthorizeEndpointRequest(ctx, ar); err != nil {
+		return err
+	}
+
+	if err := c.OpenIDConnectRequestValidator.ValidatePromptParameters(ctx, ar); err != nil {
+		return err
+	}
+
+	if ar.GetResponseTypes().Has("token") {
+		ar.SetResponseTypeHandled("token")
+	}
+	ar.SetResponseTypeHandled("id_token")
+	return nil
+}
+
+func (c *OpenIDConnectImplicitHandler) validateAuthorizeEndpointRequest(ctx context.Context, ar fosite.AuthorizeRequester) error {
+	if !fosite.ClientHasGrantType(ctx, c.Config, ar.GetClient(), "implicit") {
+		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The OAuth 2.0 Client is not allowed to use the authorization grant 'implicit'."))
+	}
+
+	// Disabled because this is already handled at the authorize_request_handler
+	//if ar.GetResponseTypes().ExactOne("id_token") && !ar.GetClient().GetResponseTypes().Has("id_token") {
+	//	return errorsx.WithStack(fosite.ErrInvalidGrant.WithDebug("The client is not allowed to use response type id_token"))
+	//} else if ar.GetResponseTypes().Matches("token", "id_token") && !ar.GetClient().GetResponseTypes().Has("token", "id_token") {
+	//	return errorsx.WithStack(fosite.ErrInvalidGrant.WithDebug("The client is not allowed to use response type token and id_token"))
+	//}
+
+	// This ensures that the 'redirect_uri' parameter is present for OpenID Connect 1.0 authorization requests as per:
+	//
+	// Authorization Code Flow - https://openid.net/specs/openid-connect-core-1_0.html#AuthRequest
+	// Implicit Flow - https://openid.net/specs/openid-connect-core-1_0.html#ImplicitAuthRequest
+	// Hybrid Flow - https://openid.net/specs/openid-connect-core-1_0.html#HybridAuthRequest
+	//
+	// Note: as per the Hybrid Flow documentation the Hybrid Flow has the same requirements as the Authorization Code Flow.
+	rawRedirectURI := ar.GetRequestForm().Get("redirect_uri")
+	if len(rawRedirectURI) == 0 {
+		return errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("The 'redirect_uri' parameter is required when using OpenID Connect 1.0."))
+	}
+
+	if nonce := ar.GetRequestForm().Get("nonce"); len(nonce) == 0 {
+		return errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("Parameter 'nonce' must be set when using the OpenID Connect Implicit Flow."))
+	} else if len(nonce) < c.Config.GetMinNonceEntropy(ctx) {
+		return errorsx.WithStack(fosite.ErrInsufficientEntropy.WithHintf("Parameter 'nonce' is set but does not satisfy the minimum entropy of %d characters.", c.Config.GetMinNonceEntropy(ctx)))
+	}
+
+	client := ar.GetClient()
+	for _, scope := range ar.GetRequestedScopes() {
+		if !c.Config.GetScopeStrategy(ctx)(client.GetScopes(), scope) {
+			return errorsx.WithStack(fosite.ErrInvalidScope.WithHintf("The OAuth 2.0 Client is not allowed to request scope '%s'.", scope))
+		}
+	}
+
+	return nil
+}
@@ -5,8 +5,11 @@ package openid
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -15,6 +18,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/ory/fosite"
 	"github.com/ory/fosite/handler/oauth2"
@@ -291,6 +295,36 @@ func TestImplicit_HandleAuthorizeEndpointRequest(t *testing.T) {
 				assert.NotEmpty(t, aresp.GetParameters().Get("access_token"))
 			},
 		},
+		{
+			description: "should fail because the nonce is shorter than the minimum nonce entropy",
+			setup: func() OpenIDConnectImplicitHandler {
+				areq.Form.Set("nonce", "long-enough")
+				h := makeOpenIDConnectImplicitHandler(fosite.MinParameterEntropy)
+				h.Config.(*fosite.Config).MinNonceEntropy = 16
+				return h
+			},
+			expectErr: fosite.ErrInsufficientEntropy,
+		},
+		{
+			description: "should pass and copy the nonce into the ID token",
+			setup: func() OpenIDConnectImplicitHandler {
+				areq.Form.Set("nonce", "a-nonce-which-is-long-enough")
+				aresp = fosite.NewAuthorizeResponse()
+				h := makeOpenIDConnectImplicitHandler(fosite.MinParameterEntropy)
+				h.Config.(*fosite.Config).MinNonceEntropy = 16
+				return h
+			},
+			check: func() {
+				idToken := aresp.GetParameters().Get("id_token")
+				require.NotEmpty(t, idToken)
+
+				payload, err := base64.RawURLEncoding.DecodeString(strings.Split(idToken, ".")[1])
+				require.NoError(t, err)
+				var claims map[string]interface{}
+				require.NoError(t, json.Unmarshal(payload, &claims))
+				assert.Equal(t, "a-nonce-which-is-long-enough", claims["nonce"])
+			},
+		},
 		{
 			description: "should fail without redirect_uri",
 			setup: func() OpenIDConnectImplicitHandler {
@@ -21,6 +21,7 @@ type OpenIDConnectRefreshHandler struct {
 
 	Config interface {
 		fosite.IDTokenLifespanProvider
+		fosite.EmptyClientGrantTypesPolicyProvider
 	}
 }
 
@@ -33,7 +34,7 @@ func (c *OpenIDConnectRefreshHandler) HandleTokenEndpointRequest(ctx context.Con
 		return errorsx.WithStack(fosite.ErrUnknownRequest)
 	}
 
-	if !request.GetClient().GetGrantTypes().Has("refresh_token") {
+	if !fosite.ClientHasGrantType(ctx, c.Config, request.GetClient(), "refresh_token") {
 		return errorsx.WithStack(fosite.ErrUnauthorizedClient.WithHint("The OAuth 2.0 Client is not allowed to use the authorization grant \"refresh_token\"."))
 	}
 
@@ -71,7 +72,7 @@ func (c *OpenIDConnectRefreshHandler) PopulateTokenEndpointResponse(ctx context.
 		return errorsx.WithStack(fosite.ErrUnknownRequest)
 	}
 
-	if !requester.GetClient().GetGrantTypes().Has("refresh_token") {
+	if !fosite.ClientHasGrantType(ctx, c.Config, requester.GetClient(), "refresh_token") {
 		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The OAuth 2.0 Client is not allowed to use the authorization grant \"refresh_token\"."))
 	}
 
@@ -108,3 +109,7 @@ func (c *OpenIDConnectRefreshHandler) CanHandleTokenEndpointRequest(ctx context.
 	// Value MUST be set to "refresh_token"
 	return requester.GetGrantTypes().ExactOne("refresh_token")
 }
+
+func (c *OpenIDConnectRefreshHandler) HandledGrantTypes() []fosite.GrantType {
+	return []fosite.GrantType{fosite.GrantTypeRefreshToken}
+}
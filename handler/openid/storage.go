@@ -25,3 +25,10 @@ type OpenIDConnectRequestStorage interface {
 	// DeleteOpenIDConnectSession removes an open id connect session from the store.
 	DeleteOpenIDConnectSession(ctx context.Context, authorizeCode string) error
 }
+
+// BackChannelLogoutStorage looks up the requests affected by the logout of an end-user.
+type BackChannelLogoutStorage interface {
+	// GetActiveRequestsBySubject returns the requests of all active access and refresh tokens issued for the given
+	// subject, or for all subjects if the subject is empty.
+	GetActiveRequestsBySubject(ctx context.Context, subject string) ([]fosite.Requester, error)
+}
@@ -108,13 +108,44 @@ func (s *DefaultSession) IDTokenClaims() *jwt.IDTokenClaims {
 	return s.Claims
 }
 
+// SetAuthTime sets the time when the end-user authenticated, which is issued as the "auth_time" claim of the ID token
+// and validated against the "max_age" parameter.
+func (s *DefaultSession) SetAuthTime(authTime time.Time) {
+	s.IDTokenClaims().AuthTime = authTime.UTC()
+}
+
+// SetACR sets the authentication context class the authentication satisfied, which is issued as the "acr" claim of
+// the ID token.
+func (s *DefaultSession) SetACR(acr string) {
+	s.IDTokenClaims().AuthenticationContextClassReference = acr
+}
+
+// SetAMR sets the authentication methods used in the authentication, for example "pwd" and "otp", which are issued
+// as the "amr" claim of the ID token.
+func (s *DefaultSession) SetAMR(amr []string) {
+	s.IDTokenClaims().AuthenticationMethodsReferences = amr
+}
+
 type DefaultStrategy struct {
 	jwt.Signer
 
+	// Signers, if set, sign the ID tokens of clients which registered an "id_token_signed_response_alg" with the
+	// signer of that algorithm, and clients which registered an algorithm without a signer are rejected. The ID tokens
+	// of all other clients are signed by Signer. If not set, clients which registered an algorithm other than the one
+	// of Signer are rejected.
+	Signers map[string]jwt.Signer
+
 	Config interface {
 		fosite.IDTokenIssuerProvider
 		fosite.IDTokenLifespanProvider
 		fosite.MinParameterEntropyProvider
+		fosite.MinNonceEntropyProvider
+		fosite.EnforceEssentialClaimsProvider
+		fosite.JWKSFetcherStrategyProvider
+		fosite.SubjectIdentifierAlgorithmProvider
+		fosite.PairwiseSubjectSaltProvider
+		fosite.IDTokenMaxAudiencesProvider
+		fosite.IDTokenDropExcessAudiencesProvider
 	}
 }
 
@@ -136,6 +167,11 @@ func (h DefaultStrategy) GenerateIDToken(ctx context.Context, lifespan time.Dura
 		return "", errorsx.WithStack(fosite.ErrServerError.WithDebug("Failed to generate id token because subject is an empty string."))
 	}
 
+	subject, err := GetSubjectIdentifier(ctx, h.Config, requester.GetClient(), claims.Subject)
+	if err != nil {
+		return "", err
+	}
+
 	if requester.GetRequestForm().Get("grant_type") != "refresh_token" {
 		maxAge, err := strconv.ParseInt(requester.GetRequestForm().Get("max_age"), 10, 64)
 		if err != nil {
@@ -153,7 +189,7 @@ func (h DefaultStrategy) GenerateIDToken(ctx context.Context, lifespan time.Dura
 			} else if claims.RequestedAt.IsZero() {
 				return "", errorsx.WithStack(fosite.ErrServerError.WithDebug("Failed to generate id token because requested at claim is required when max_age is set."))
 			} else if claims.AuthTime.Add(time.Second * time.Duration(maxAge)).Before(claims.RequestedAt) {
-				return "", errorsx.WithStack(fosite.ErrServerError.WithDebug("Failed to generate id token because authentication time does not satisfy max_age time."))
+				return "", errorsx.WithStack(fosite.ErrLoginRequired.WithDebug("Failed to generate id token because authentication time does not satisfy max_age time, the end-user must re-authenticate."))
 			}
 		}
 
@@ -194,7 +230,7 @@ func (h DefaultStrategy) GenerateIDToken(ctx context.Context, lifespan time.Dura
 
 			if hintSub, _ := tokenHint.Claims["sub"].(string); hintSub == "" {
 				return "", errorsx.WithStack(fosite.ErrServerError.WithDebug("Provided id token from 'id_token_hint' does not have a subject."))
-			} else if hintSub != claims.Subject {
+			} else if hintSub != subject {
 				return "", errorsx.WithStack(fosite.ErrServerError.WithDebug("Subject from authorization mismatches id token subject from 'id_token_hint'."))
 			}
 		}
@@ -218,16 +254,127 @@ func (h DefaultStrategy) GenerateIDToken(ctx context.Context, lifespan time.Dura
 
 	// OPTIONAL. String value used to associate a Client session with an ID Token, and to mitigate replay attacks.
 	if nonce := requester.GetRequestForm().Get("nonce"); len(nonce) == 0 {
-	} else if len(nonce) > 0 && len(nonce) < h.Config.GetMinParameterEntropy(ctx) {
+	} else if len(nonce) > 0 && len(nonce) < h.Config.GetMinNonceEntropy(ctx) {
 		// We're assuming that using less then, by default, 8 characters for the state can not be considered "unguessable"
-		return "", errorsx.WithStack(fosite.ErrInsufficientEntropy.WithHintf("Parameter 'nonce' is set but does not satisfy the minimum entropy of %d characters.", h.Config.GetMinParameterEntropy(ctx)))
+		return "", errorsx.WithStack(fosite.ErrInsufficientEntropy.WithHintf("Parameter 'nonce' is set but does not satisfy the minimum entropy of %d characters.", h.Config.GetMinNonceEntropy(ctx)))
 	} else if len(nonce) > 0 {
 		claims.Nonce = nonce
 	}
 
-	claims.Audience = stringslice.Unique(append(claims.Audience, requester.GetClient().GetID()))
+	audience, err := idTokenAudience(ctx, h.Config, claims, requester.GetClient().GetID())
+	if err != nil {
+		return "", err
+	}
+	claims.Audience = audience
 	claims.IssuedAt = time.Now().UTC()
 
-	token, _, err = h.Signer.Generate(ctx, claims.ToMapClaims(), sess.IDTokenHeaders())
-	return token, err
+	mapClaims := claims.ToMapClaims()
+	mapClaims["sub"] = subject
+	if h.Config.GetEnforceEssentialClaims(ctx) {
+		if err := validateEssentialClaims(requester, mapClaims); err != nil {
+			return "", err
+		}
+	}
+
+	signer, err := h.getSigner(ctx, requester)
+	if err != nil {
+		return "", err
+	}
+
+	token, _, err = signer.Generate(ctx, mapClaims, sess.IDTokenHeaders())
+	if err != nil {
+		return "", err
+	}
+
+	if client, ok := requester.GetClient().(fosite.IDTokenEncryptionClient); ok && client.GetIDTokenEncryptedResponseAlgorithm() != "" {
+		return h.encryptIDToken(ctx, client, token)
+	}
+	return token, nil
+}
+
+type idTokenAudienceConfigProvider interface {
+	fosite.IDTokenMaxAudiencesProvider
+	fosite.IDTokenDropExcessAudiencesProvider
+}
+
+// idTokenAudience returns the audience of the ID token with the given claims issued to the client. The configured
+// maximum number of ID token audiences is enforced: excess audiences are either dropped or rejected, but the audience
+// of the client is always kept as required by https://openid.net/specs/openid-connect-core-1_0.html#IDToken.
+func idTokenAudience(ctx context.Context, config idTokenAudienceConfigProvider, claims *jwt.IDTokenClaims, clientID string) ([]string, error) {
+	audience := stringslice.Unique(append(claims.Audience, clientID))
+	max := config.GetIDTokenMaxAudiences(ctx)
+	if max <= 0 || len(audience) <= max {
+		return audience, nil
+	}
+
+	if !config.GetIDTokenDropExcessAudiences(ctx) {
+		return nil, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("The ID token must not have more than %d audiences.", max))
+	}
+
+	capped := make([]string, 0, max)
+	for _, aud := range audience {
+		if len(capped) == max-1 {
+			break
+		} else if aud != clientID {
+			capped = append(capped, aud)
+		}
+	}
+	return append(capped, clientID), nil
+}
+
+// getSigner returns the signer for the "id_token_signed_response_alg" registered by the client, falling back to the
+// default signer if the client registered no algorithm. Without signers per algorithm, the algorithm must be the one
+// of the default signer.
+func (h DefaultStrategy) getSigner(ctx context.Context, requester fosite.Requester) (jwt.Signer, error) {
+	client, ok := requester.GetClient().(fosite.IDTokenSigningAlgorithmClient)
+	if !ok || client.GetIDTokenSignedResponseAlgorithm() == "" {
+		return h.Signer, nil
+	}
+
+	alg := client.GetIDTokenSignedResponseAlgorithm()
+	if len(h.Signers) == 0 {
+		// Signers which do not report their algorithm can not be checked and are used as they are.
+		signer, ok := h.Signer.(interface {
+			GetSigningAlgorithm(ctx context.Context) (string, error)
+		})
+		if !ok {
+			return h.Signer, nil
+		}
+
+		defaultAlg, err := signer.GetSigningAlgorithm(ctx)
+		if err != nil {
+			return nil, errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+		} else if defaultAlg != alg {
+			return nil, errorsx.WithStack(fosite.ErrInvalidClient.WithHintf("The OAuth 2.0 Client requires ID Tokens signed with algorithm '%s', but the provider has no key for this algorithm.", alg))
+		}
+		return h.Signer, nil
+	}
+
+	signer, ok := h.Signers[alg]
+	if !ok {
+		return nil, errorsx.WithStack(fosite.ErrInvalidClient.WithHintf("The OAuth 2.0 Client requires ID Tokens signed with algorithm '%s', but the provider has no key for this algorithm.", alg))
+	}
+	return signer, nil
+}
+
+// validateEssentialClaims returns ErrInvalidRequest if an essential ID token claim requested using the "claims"
+// parameter is missing or does not have the requested value.
+func validateEssentialClaims(requester fosite.Requester, claims jwt.MapClaims) error {
+	claimsRequest, err := fosite.GetClaimsRequest(requester)
+	if err != nil {
+		return err
+	} else if claimsRequest == nil {
+		return nil
+	}
+
+	for name, claim := range claimsRequest.IDToken {
+		if claim == nil || !claim.Essential {
+			continue
+		}
+
+		if value, ok := claims[name]; !ok || !claim.IsSatisfiedBy(value) {
+			return errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("The essential claim '%s' requested using the 'claims' parameter can not be fulfilled.", name))
+		}
+	}
+	return nil
 }
@@ -0,0 +1,72 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package openid
+
+import (
+	"context"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/ory/x/errorsx"
+
+	"github.com/ory/fosite"
+)
+
+// encryptIDToken nests the signed ID token in a JWE encrypted to the public encryption key registered by the client,
+// using the algorithms of its "id_token_encrypted_response_alg" and "id_token_encrypted_response_enc" metadata.
+func (h DefaultStrategy) encryptIDToken(ctx context.Context, client fosite.IDTokenEncryptionClient, token string) (string, error) {
+	alg := jose.KeyAlgorithm(client.GetIDTokenEncryptedResponseAlgorithm())
+	enc := jose.ContentEncryption(client.GetIDTokenEncryptedResponseEncryption())
+
+	key, err := h.findEncryptionKey(ctx, client, alg)
+	if err != nil {
+		return "", err
+	}
+
+	encrypter, err := jose.NewEncrypter(enc, jose.Recipient{Algorithm: alg, Key: key, KeyID: key.KeyID}, (&jose.EncrypterOptions{}).WithContentType("JWT"))
+	if err != nil {
+		return "", errorsx.WithStack(fosite.ErrServerError.WithHintf("Unable to encrypt the ID Token using algorithms '%s' and '%s'.", alg, enc).WithWrap(err).WithDebug(err.Error()))
+	}
+
+	object, err := encrypter.Encrypt([]byte(token))
+	if err != nil {
+		return "", errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	encrypted, err := object.CompactSerialize()
+	if err != nil {
+		return "", errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+	return encrypted, nil
+}
+
+// findEncryptionKey returns the public key of the client which is meant for encryption with the given algorithm. The
+// keys registered with the client take precedence over the keys at its "jwks_uri".
+func (h DefaultStrategy) findEncryptionKey(ctx context.Context, client fosite.IDTokenEncryptionClient, alg jose.KeyAlgorithm) (*jose.JSONWebKey, error) {
+	set := client.GetJSONWebKeys()
+	if set == nil && client.GetJSONWebKeysURI() != "" {
+		var err error
+		set, err = h.Config.GetJWKSFetcherStrategy(ctx).Resolve(ctx, client.GetJSONWebKeysURI(), false)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if set != nil {
+		for _, key := range set.Keys {
+			if key.Use != "" && key.Use != "enc" {
+				continue
+			}
+			if key.Algorithm != "" && key.Algorithm != string(alg) {
+				continue
+			}
+
+			public := key.Public()
+			if public.Key != nil {
+				return &public, nil
+			}
+		}
+	}
+
+	return nil, errorsx.WithStack(fosite.ErrInvalidClient.WithHintf("The OAuth 2.0 Client requires encrypted ID Tokens, but has no public key registered for algorithm '%s'.", alg))
+}
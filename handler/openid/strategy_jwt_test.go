@@ -9,9 +9,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-jose/go-jose/v3"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/ory/fosite"
+	"github.com/ory/fosite/internal/gen"
 	"github.com/ory/fosite/token/jwt"
 )
 
@@ -283,3 +286,314 @@ func TestJWTStrategy_GenerateIDToken(t *testing.T) {
 		})
 	}
 }
+
+func TestJWTStrategy_GenerateIDTokenWithEssentialClaims(t *testing.T) {
+	newRequest := func(claims string) *fosite.AccessRequest {
+		req := fosite.NewAccessRequest(&DefaultSession{
+			Claims: &jwt.IDTokenClaims{
+				Subject:                             "peter",
+				AuthenticationContextClassReference: "urn:mace:incommon:iap:silver",
+			},
+			Headers: &jwt.Headers{},
+		})
+		req.Form.Set("claims", claims)
+		return req
+	}
+
+	for k, c := range []struct {
+		d         string
+		claims    string
+		enforce   bool
+		expectErr bool
+	}{
+		{d: "should pass because the essential claim is present", claims: `{"id_token": {"acr": {"essential": true}}}`, enforce: true},
+		{d: "should pass because the essential claim has a requested value", claims: `{"id_token": {"acr": {"essential": true, "values": ["urn:mace:incommon:iap:silver"]}}}`, enforce: true},
+		{d: "should fail because the essential claim is missing", claims: `{"id_token": {"given_name": {"essential": true}}}`, enforce: true, expectErr: true},
+		{d: "should fail because the essential claim does not have the requested value", claims: `{"id_token": {"acr": {"essential": true, "value": "urn:mace:incommon:iap:gold"}}}`, enforce: true, expectErr: true},
+		{d: "should pass because a voluntary claim is missing", claims: `{"id_token": {"given_name": null}}`, enforce: true},
+		{d: "should pass because essential claims are not enforced", claims: `{"id_token": {"given_name": {"essential": true}}}`},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.d), func(t *testing.T) {
+			strategy := &DefaultStrategy{
+				Signer: &jwt.DefaultSigner{
+					GetPrivateKey: func(_ context.Context) (interface{}, error) {
+						return key, nil
+					}},
+				Config: &fosite.Config{EnforceEssentialClaims: c.enforce},
+			}
+
+			token, err := strategy.GenerateIDToken(context.Background(), time.Duration(0), newRequest(c.claims))
+			if c.expectErr {
+				assert.ErrorIs(t, err, fosite.ErrInvalidRequest)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotEmpty(t, token)
+		})
+	}
+}
+
+func TestJWTStrategy_GenerateIDTokenWithAuthenticationClaims(t *testing.T) {
+	var j = &DefaultStrategy{
+		Signer: &jwt.DefaultSigner{
+			GetPrivateKey: func(_ context.Context) (interface{}, error) {
+				return key, nil
+			}},
+		Config: &fosite.Config{},
+	}
+
+	newRequest := func(authTime time.Time, maxAge string) *fosite.AccessRequest {
+		sess := NewDefaultSession()
+		sess.Claims.Subject = "peter"
+		sess.Claims.RequestedAt = time.Now().UTC()
+		sess.SetAuthTime(authTime)
+		sess.SetACR("urn:mace:incommon:iap:silver")
+		sess.SetAMR([]string{"pwd", "otp"})
+
+		req := fosite.NewAccessRequest(sess)
+		req.Form.Set("max_age", maxAge)
+		return req
+	}
+
+	t.Run("case=should issue auth_time, acr and amr", func(t *testing.T) {
+		authTime := time.Now().Add(-time.Second * 30).Truncate(time.Second)
+		token, err := j.GenerateIDToken(context.Background(), time.Duration(0), newRequest(authTime, "60"))
+		require.NoError(t, err)
+
+		decoded, err := j.Signer.Decode(context.Background(), token)
+		require.NoError(t, err)
+		assert.EqualValues(t, authTime.Unix(), decoded.Claims["auth_time"])
+		assert.Equal(t, "urn:mace:incommon:iap:silver", decoded.Claims["acr"])
+		assert.EqualValues(t, []interface{}{"pwd", "otp"}, decoded.Claims["amr"])
+	})
+
+	t.Run("case=should require re-authentication if auth_time exceeds max_age", func(t *testing.T) {
+		_, err := j.GenerateIDToken(context.Background(), time.Duration(0), newRequest(time.Now().Add(-time.Hour), "60"))
+		assert.ErrorIs(t, err, fosite.ErrLoginRequired)
+	})
+}
+
+func TestJWTStrategy_GenerateIDTokenWithClientSigningAlgorithm(t *testing.T) {
+	ecKey := gen.MustES256Key()
+	var j = &DefaultStrategy{
+		Signer: &jwt.DefaultSigner{
+			GetPrivateKey: func(_ context.Context) (interface{}, error) {
+				return key, nil
+			}},
+		Signers: map[string]jwt.Signer{
+			"RS256": &jwt.DefaultSigner{
+				GetPrivateKey: func(_ context.Context) (interface{}, error) {
+					return key, nil
+				}},
+			"ES256": &jwt.DefaultSigner{
+				GetPrivateKey: func(_ context.Context) (interface{}, error) {
+					return ecKey, nil
+				}},
+		},
+		Config: &fosite.Config{},
+	}
+
+	newRequest := func(alg string) *fosite.AccessRequest {
+		req := fosite.NewAccessRequest(&DefaultSession{
+			Claims:  &jwt.IDTokenClaims{Subject: "peter"},
+			Headers: &jwt.Headers{},
+		})
+		req.Client = &fosite.DefaultOpenIDConnectClient{
+			DefaultClient:                  &fosite.DefaultClient{ID: "client-" + alg},
+			IDTokenSignedResponseAlgorithm: alg,
+		}
+		return req
+	}
+
+	for k, c := range []struct {
+		alg       string
+		publicKey interface{}
+	}{
+		{alg: "RS256", publicKey: &key.PublicKey},
+		{alg: "ES256", publicKey: &ecKey.PublicKey},
+	} {
+		t.Run(fmt.Sprintf("case=%d/alg=%s", k, c.alg), func(t *testing.T) {
+			token, err := j.GenerateIDToken(context.Background(), time.Duration(0), newRequest(c.alg))
+			require.NoError(t, err)
+
+			decoded, err := jwt.Parse(token, func(*jwt.Token) (interface{}, error) {
+				return c.publicKey, nil
+			})
+			require.NoError(t, err)
+			assert.True(t, decoded.Valid())
+			assert.Equal(t, c.alg, decoded.Header["alg"])
+			assert.Equal(t, []interface{}{"client-" + c.alg}, decoded.Claims["aud"])
+		})
+	}
+
+	t.Run("case=should fall back to the default signer", func(t *testing.T) {
+		token, err := j.GenerateIDToken(context.Background(), time.Duration(0), newRequest(""))
+		require.NoError(t, err)
+
+		_, err = j.Signer.Decode(context.Background(), token)
+		require.NoError(t, err)
+	})
+
+	t.Run("case=should reject an algorithm without a key", func(t *testing.T) {
+		_, err := j.GenerateIDToken(context.Background(), time.Duration(0), newRequest("PS256"))
+		assert.ErrorIs(t, err, fosite.ErrInvalidClient)
+	})
+
+	t.Run("case=should accept the algorithm of the default signer without signers", func(t *testing.T) {
+		withoutSigners := &DefaultStrategy{Signer: j.Signer, Config: j.Config}
+		_, err := withoutSigners.GenerateIDToken(context.Background(), time.Duration(0), newRequest("RS256"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("case=should reject another algorithm than the one of the default signer without signers", func(t *testing.T) {
+		withoutSigners := &DefaultStrategy{Signer: j.Signer, Config: j.Config}
+		_, err := withoutSigners.GenerateIDToken(context.Background(), time.Duration(0), newRequest("ES256"))
+		assert.ErrorIs(t, err, fosite.ErrInvalidClient)
+	})
+}
+
+func TestJWTStrategy_GenerateIDTokenWithEncryption(t *testing.T) {
+	encryptionKey := gen.MustRSAKey()
+	var j = &DefaultStrategy{
+		Signer: &jwt.DefaultSigner{
+			GetPrivateKey: func(_ context.Context) (interface{}, error) {
+				return key, nil
+			}},
+		Config: &fosite.Config{},
+	}
+
+	newRequest := func(client fosite.Client) *fosite.AccessRequest {
+		req := fosite.NewAccessRequest(&DefaultSession{
+			Claims:  &jwt.IDTokenClaims{Subject: "peter"},
+			Headers: &jwt.Headers{},
+		})
+		req.Client = client
+		return req
+	}
+
+	t.Run("case=should encrypt the ID token to the key of the client", func(t *testing.T) {
+		token, err := j.GenerateIDToken(context.Background(), time.Duration(0), newRequest(&fosite.DefaultOpenIDConnectClient{
+			DefaultClient: &fosite.DefaultClient{ID: "encrypting-client"},
+			JSONWebKeys: &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+				{Key: &gen.MustRSAKey().PublicKey, KeyID: "sig", Use: "sig"},
+				{Key: &encryptionKey.PublicKey, KeyID: "enc", Use: "enc", Algorithm: string(jose.RSA_OAEP_256)},
+			}},
+			IDTokenEncryptedResponseAlgorithm:  string(jose.RSA_OAEP_256),
+			IDTokenEncryptedResponseEncryption: string(jose.A256GCM),
+		}))
+		require.NoError(t, err)
+
+		object, err := jose.ParseEncrypted(token)
+		require.NoError(t, err)
+		assert.Equal(t, "enc", object.Header.KeyID)
+		assert.EqualValues(t, jose.RSA_OAEP_256, object.Header.Algorithm)
+		assert.Equal(t, "JWT", object.Header.ExtraHeaders[jose.HeaderContentType])
+
+		nested, err := object.Decrypt(encryptionKey)
+		require.NoError(t, err)
+
+		decoded, err := j.Signer.Decode(context.Background(), string(nested))
+		require.NoError(t, err)
+		assert.Equal(t, "peter", decoded.Claims["sub"])
+	})
+
+	t.Run("case=should not encrypt the ID token if the client registered no encryption", func(t *testing.T) {
+		token, err := j.GenerateIDToken(context.Background(), time.Duration(0), newRequest(&fosite.DefaultOpenIDConnectClient{
+			DefaultClient: &fosite.DefaultClient{ID: "plain-client"},
+			JSONWebKeys: &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+				{Key: &encryptionKey.PublicKey, KeyID: "enc", Use: "enc"},
+			}},
+		}))
+		require.NoError(t, err)
+
+		decoded, err := j.Signer.Decode(context.Background(), token)
+		require.NoError(t, err)
+		assert.Equal(t, "peter", decoded.Claims["sub"])
+	})
+
+	t.Run("case=should fail if the client registered no encryption key", func(t *testing.T) {
+		_, err := j.GenerateIDToken(context.Background(), time.Duration(0), newRequest(&fosite.DefaultOpenIDConnectClient{
+			DefaultClient: &fosite.DefaultClient{ID: "keyless-client"},
+			JSONWebKeys: &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+				{Key: &encryptionKey.PublicKey, KeyID: "sig", Use: "sig"},
+			}},
+			IDTokenEncryptedResponseAlgorithm: string(jose.RSA_OAEP_256),
+		}))
+		assert.ErrorIs(t, err, fosite.ErrInvalidClient)
+	})
+}
+
+func TestJWTStrategy_GenerateIDTokenWithPairwiseSubject(t *testing.T) {
+	var j = &DefaultStrategy{
+		Signer: &jwt.DefaultSigner{
+			GetPrivateKey: func(_ context.Context) (interface{}, error) {
+				return key, nil
+			}},
+		Config: &fosite.Config{PairwiseSubjectSalt: []byte("some-salt")},
+	}
+
+	session := &DefaultSession{
+		Claims:  &jwt.IDTokenClaims{Subject: "peter"},
+		Headers: &jwt.Headers{},
+	}
+	req := fosite.NewAccessRequest(session)
+	req.Client = newPairwiseClient("foo", "https://sector.example.com/sector.json")
+
+	token, err := j.GenerateIDToken(context.Background(), time.Duration(0), req)
+	require.NoError(t, err)
+
+	decoded, err := j.Signer.Decode(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, PairwiseSubjectIdentifier("sector.example.com", "peter", []byte("some-salt")), decoded.Claims["sub"])
+	assert.Equal(t, "peter", session.Claims.Subject, "the session must keep the local subject")
+}
+
+func TestJWTStrategy_GenerateIDTokenWithMaxAudiences(t *testing.T) {
+	config := &fosite.Config{IDTokenMaxAudiences: 2}
+	var j = &DefaultStrategy{
+		Signer: &jwt.DefaultSigner{
+			GetPrivateKey: func(_ context.Context) (interface{}, error) {
+				return key, nil
+			}},
+		Config: config,
+	}
+
+	newRequest := func(audience ...string) *fosite.AccessRequest {
+		req := fosite.NewAccessRequest(&DefaultSession{
+			Claims:  &jwt.IDTokenClaims{Subject: "peter", Audience: audience},
+			Headers: &jwt.Headers{},
+		})
+		req.Client = &fosite.DefaultClient{ID: "foo"}
+		return req
+	}
+
+	decodeAudience := func(t *testing.T, token string) []interface{} {
+		decoded, err := j.Signer.Decode(context.Background(), token)
+		require.NoError(t, err)
+		return decoded.Claims["aud"].([]interface{})
+	}
+
+	for k, c := range []struct {
+		d         string
+		drop      bool
+		audience  []string
+		expectErr bool
+		expect    []interface{}
+	}{
+		{d: "within the cap", audience: []string{"bar"}, expect: []interface{}{"bar", "foo"}},
+		{d: "within the cap if the client is one of the audiences", audience: []string{"foo", "bar"}, expect: []interface{}{"foo", "bar"}},
+		{d: "exceeding the cap is rejected", audience: []string{"bar", "baz"}, expectErr: true},
+		{d: "exceeding the cap is dropped", drop: true, audience: []string{"bar", "baz", "foo"}, expect: []interface{}{"bar", "foo"}},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.d), func(t *testing.T) {
+			config.IDTokenDropExcessAudiences = c.drop
+			token, err := j.GenerateIDToken(context.Background(), time.Duration(0), newRequest(c.audience...))
+			if c.expectErr {
+				require.ErrorIs(t, err, fosite.ErrInvalidRequest)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.expect, decodeAudience(t, token))
+		})
+	}
+}
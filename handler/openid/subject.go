@@ -0,0 +1,108 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package openid
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+
+	"github.com/ory/x/errorsx"
+
+	"github.com/ory/fosite"
+)
+
+// The subject identifier types of https://openid.net/specs/openid-connect-core-1_0.html#SubjectIDTypes.
+const (
+	// SubjectTypePublic provides the same "sub" value to all clients.
+	SubjectTypePublic = "public"
+
+	// SubjectTypePairwise provides a different "sub" value to each sector, so that clients of different sectors can
+	// not correlate the end-user's activities.
+	SubjectTypePairwise = "pairwise"
+)
+
+type subjectIdentifierConfigProvider interface {
+	fosite.SubjectIdentifierAlgorithmProvider
+	fosite.PairwiseSubjectSaltProvider
+}
+
+// GetSubjectIdentifier returns the "sub" value of the subject for the client. Clients which registered the
+// "pairwise" subject type, or all clients if pairwise is the configured default, receive the pairwise subject
+// identifier of their sector.
+//
+// Pairwise subject identifiers are used in ID tokens and userinfo responses. They are not applied to JWT access
+// tokens and introspection responses, which are meant for resource servers and contain the subject of the session.
+// Sessions which should expose the pairwise subject to resource servers must set it themselves.
+func GetSubjectIdentifier(ctx context.Context, config subjectIdentifierConfigProvider, client fosite.Client, subject string) (string, error) {
+	subjectType := config.GetSubjectIdentifierAlgorithm(ctx)
+	c, ok := client.(fosite.SubjectIdentifierClient)
+	if ok && c.GetSubjectType() != "" {
+		subjectType = c.GetSubjectType()
+	}
+
+	switch subjectType {
+	case SubjectTypePublic:
+		return subject, nil
+	case SubjectTypePairwise:
+	default:
+		return "", errorsx.WithStack(fosite.ErrInvalidClient.WithHintf("The subject type '%s' is not supported.", subjectType))
+	}
+
+	salt := config.GetPairwiseSubjectSalt(ctx)
+	if len(salt) == 0 {
+		return "", errorsx.WithStack(fosite.ErrMisconfiguration.WithDebug("Pairwise subject identifiers require a salt, but none is configured."))
+	}
+
+	sectorIdentifier, err := GetSectorIdentifier(client)
+	if err != nil {
+		return "", err
+	}
+
+	return PairwiseSubjectIdentifier(sectorIdentifier, subject, salt), nil
+}
+
+// GetSectorIdentifier returns the host of the "sector_identifier_uri" of the client, or the host of its redirect URIs
+// if it did not register one, see https://openid.net/specs/openid-connect-core-1_0.html#PairwiseAlg.
+func GetSectorIdentifier(client fosite.Client) (string, error) {
+	if c, ok := client.(fosite.SubjectIdentifierClient); ok && c.GetSectorIdentifierURI() != "" {
+		u, err := url.Parse(c.GetSectorIdentifierURI())
+		if err != nil || u.Host == "" {
+			return "", errorsx.WithStack(fosite.ErrInvalidClient.WithHint("The 'sector_identifier_uri' of the OAuth 2.0 Client is not a valid URL."))
+		}
+		return u.Host, nil
+	}
+
+	var host string
+	for _, redirectURI := range client.GetRedirectURIs() {
+		u, err := url.Parse(redirectURI)
+		if err != nil {
+			return "", errorsx.WithStack(fosite.ErrInvalidClient.WithHintf("The redirect URI '%s' of the OAuth 2.0 Client is not a valid URL.", redirectURI))
+		}
+
+		if host == "" {
+			host = u.Host
+		} else if host != u.Host {
+			return "", errorsx.WithStack(fosite.ErrInvalidClient.WithHint("The redirect URIs of the OAuth 2.0 Client use multiple hosts, so a 'sector_identifier_uri' is required for pairwise subject identifiers."))
+		}
+	}
+
+	if host == "" {
+		return "", errorsx.WithStack(fosite.ErrInvalidClient.WithHint("The OAuth 2.0 Client has neither a 'sector_identifier_uri' nor redirect URIs to derive pairwise subject identifiers from."))
+	}
+	return host, nil
+}
+
+// PairwiseSubjectIdentifier derives the pairwise subject identifier from the sector identifier, the local subject and
+// the salt using SHA-256. It is stable for the same inputs and differs between sectors.
+func PairwiseSubjectIdentifier(sectorIdentifier, subject string, salt []byte) string {
+	h := sha256.New()
+	h.Write([]byte(sectorIdentifier))
+	h.Write([]byte{0})
+	h.Write([]byte(subject))
+	h.Write([]byte{0})
+	h.Write(salt)
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
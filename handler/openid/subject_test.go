@@ -0,0 +1,119 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package openid
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/fosite"
+)
+
+func newPairwiseClient(id, sectorIdentifierURI string, redirectURIs ...string) *fosite.DefaultOpenIDConnectClient {
+	return &fosite.DefaultOpenIDConnectClient{
+		DefaultClient:       &fosite.DefaultClient{ID: id, RedirectURIs: redirectURIs},
+		SubjectType:         SubjectTypePairwise,
+		SectorIdentifierURI: sectorIdentifierURI,
+	}
+}
+
+func TestGetSubjectIdentifier(t *testing.T) {
+	ctx := context.Background()
+	config := &fosite.Config{PairwiseSubjectSalt: []byte("some-salt")}
+
+	t.Run("case=public subjects are not changed", func(t *testing.T) {
+		sub, err := GetSubjectIdentifier(ctx, config, &fosite.DefaultClient{ID: "foo"}, "peter")
+		require.NoError(t, err)
+		assert.Equal(t, "peter", sub)
+	})
+
+	t.Run("case=pairwise subjects are stable within a sector", func(t *testing.T) {
+		a, err := GetSubjectIdentifier(ctx, config, newPairwiseClient("a", "https://sector.example.com/sector.json"), "peter")
+		require.NoError(t, err)
+		b, err := GetSubjectIdentifier(ctx, config, newPairwiseClient("b", "https://sector.example.com/other.json"), "peter")
+		require.NoError(t, err)
+		c, err := GetSubjectIdentifier(ctx, config, newPairwiseClient("c", "", "https://sector.example.com/cb", "https://sector.example.com/cb2"), "peter")
+		require.NoError(t, err)
+
+		assert.NotEqual(t, "peter", a)
+		assert.Equal(t, a, b)
+		assert.Equal(t, a, c)
+	})
+
+	t.Run("case=pairwise subjects differ across sectors and subjects", func(t *testing.T) {
+		a, err := GetSubjectIdentifier(ctx, config, newPairwiseClient("a", "https://one.example.com/sector.json"), "peter")
+		require.NoError(t, err)
+		b, err := GetSubjectIdentifier(ctx, config, newPairwiseClient("b", "https://two.example.com/sector.json"), "peter")
+		require.NoError(t, err)
+		c, err := GetSubjectIdentifier(ctx, config, newPairwiseClient("a", "https://one.example.com/sector.json"), "alice")
+		require.NoError(t, err)
+
+		assert.NotEqual(t, a, b)
+		assert.NotEqual(t, a, c)
+	})
+
+	t.Run("case=pairwise subjects depend on the salt", func(t *testing.T) {
+		client := newPairwiseClient("a", "https://sector.example.com/sector.json")
+		a, err := GetSubjectIdentifier(ctx, config, client, "peter")
+		require.NoError(t, err)
+		b, err := GetSubjectIdentifier(ctx, &fosite.Config{PairwiseSubjectSalt: []byte("other-salt")}, client, "peter")
+		require.NoError(t, err)
+
+		assert.NotEqual(t, a, b)
+	})
+
+	t.Run("case=pairwise is used by default if configured", func(t *testing.T) {
+		config := &fosite.Config{PairwiseSubjectSalt: []byte("some-salt"), SubjectIdentifierAlgorithm: SubjectTypePairwise}
+		sub, err := GetSubjectIdentifier(ctx, config, &fosite.DefaultClient{ID: "foo", RedirectURIs: []string{"https://sector.example.com/cb"}}, "peter")
+		require.NoError(t, err)
+		assert.Equal(t, PairwiseSubjectIdentifier("sector.example.com", "peter", []byte("some-salt")), sub)
+
+		client := newPairwiseClient("foo", "")
+		client.SubjectType = SubjectTypePublic
+		sub, err = GetSubjectIdentifier(ctx, config, client, "peter")
+		require.NoError(t, err)
+		assert.Equal(t, "peter", sub)
+	})
+
+	for k, c := range []struct {
+		d         string
+		config    *fosite.Config
+		client    fosite.Client
+		expectErr error
+	}{
+		{
+			d:         "should fail because the salt is missing",
+			config:    &fosite.Config{},
+			client:    newPairwiseClient("foo", "https://sector.example.com/sector.json"),
+			expectErr: fosite.ErrMisconfiguration,
+		},
+		{
+			d:         "should fail because the redirect URIs use multiple hosts",
+			config:    config,
+			client:    newPairwiseClient("foo", "", "https://one.example.com/cb", "https://two.example.com/cb"),
+			expectErr: fosite.ErrInvalidClient,
+		},
+		{
+			d:         "should fail because there is nothing to derive the sector from",
+			config:    config,
+			client:    newPairwiseClient("foo", ""),
+			expectErr: fosite.ErrInvalidClient,
+		},
+		{
+			d:         "should fail because the subject type is unknown",
+			config:    &fosite.Config{SubjectIdentifierAlgorithm: "foo"},
+			client:    &fosite.DefaultClient{ID: "foo"},
+			expectErr: fosite.ErrInvalidClient,
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.d), func(t *testing.T) {
+			_, err := GetSubjectIdentifier(ctx, c.config, c.client, "peter")
+			require.ErrorIs(t, err, c.expectErr)
+		})
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package openid
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/ory/x/errorsx"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/dpop"
+	"github.com/ory/fosite/handler/rfc8705"
+)
+
+// UserInfoProvider returns the claims about the authenticated end-user, see
+// https://openid.net/specs/openid-connect-core-1_0.html#UserInfo.
+type UserInfoProvider interface {
+	// GetUserInfo returns the claims of the given subject. The claims are filtered by the scopes granted to the
+	// access token afterwards, so all claims known about the end-user may be returned.
+	GetUserInfo(ctx context.Context, subject string, requester fosite.AccessRequester) (map[string]interface{}, error)
+}
+
+// DefaultScopeClaims maps the scopes of https://openid.net/specs/openid-connect-core-1_0.html#ScopeClaims to the
+// claims they grant access to.
+var DefaultScopeClaims = map[string][]string{
+	"profile": {
+		"name", "family_name", "given_name", "middle_name", "nickname", "preferred_username", "profile",
+		"picture", "website", "gender", "birthdate", "zoneinfo", "locale", "updated_at",
+	},
+	"email":   {"email", "email_verified"},
+	"address": {"address"},
+	"phone":   {"phone_number", "phone_number_verified"},
+}
+
+// UserInfoHandler serves the OpenID Connect UserInfo endpoint. It validates the access token of the request,
+// including its binding to a client certificate or a DPoP key, and returns the claims of the UserInfoProvider
+// which the scopes granted to the access token grant access to.
+type UserInfoHandler struct {
+	// OAuth2 validates the access tokens and writes error responses.
+	OAuth2 fosite.OAuth2Provider
+
+	// Provider returns the claims about the end-user.
+	Provider UserInfoProvider
+
+	// DPoP validates the DPoP proofs sent together with DPoP-bound access tokens. If nil, DPoP-bound access
+	// tokens are rejected.
+	DPoP *dpop.Handler
+
+	// ScopeClaims maps scopes to the claims they grant access to. Defaults to DefaultScopeClaims.
+	ScopeClaims map[string][]string
+
+	Config interface {
+		fosite.ClientCertificateExtractorProvider
+		fosite.SubjectIdentifierAlgorithmProvider
+		fosite.PairwiseSubjectSaltProvider
+	}
+}
+
+// HandleUserInfoRequest validates the access token of the request and returns the claims about the end-user. The
+// session is used to decode the access token session.
+func (h *UserInfoHandler) HandleUserInfoRequest(ctx context.Context, r *http.Request, session fosite.Session) (map[string]interface{}, error) {
+	token := userInfoTokenFromRequest(r)
+	if token == "" {
+		return nil, errorsx.WithStack(fosite.ErrInvalidToken.WithHint("The request does not contain an access token."))
+	}
+
+	ctx = context.WithValue(ctx, fosite.RequestContextKey, r)
+	_, ar, err := h.OAuth2.IntrospectToken(ctx, token, fosite.AccessToken, session)
+	if err != nil {
+		return nil, errorsx.WithStack(fosite.ErrInvalidToken.WithHint("The access token is not active.").WithWrap(err).WithDebug(err.Error()))
+	}
+
+	if err := h.validateBinding(ctx, r, ar.GetSession(), token); err != nil {
+		return nil, errorsx.WithStack(fosite.ErrInvalidToken.WithHint("The access token is bound to a proof of possession which was not presented.").WithWrap(err).WithDebug(err.Error()))
+	}
+
+	if !ar.GetGrantedScopes().Has("openid") {
+		return nil, errorsx.WithStack(fosite.ErrInsufficientScope.WithHint("The access token was not granted the 'openid' scope."))
+	}
+
+	subject := ar.GetSession().GetSubject()
+	if subject == "" {
+		return nil, errorsx.WithStack(fosite.ErrInvalidToken.WithHint("The access token is not associated with a subject."))
+	}
+
+	claims, err := h.Provider.GetUserInfo(ctx, subject, ar)
+	if err != nil {
+		return nil, err
+	}
+
+	subject, err = GetSubjectIdentifier(ctx, h.Config, ar.GetClient(), subject)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.filterClaims(subject, ar.GetGrantedScopes(), claims), nil
+}
+
+// WriteUserInfoResponse writes the claims, or the error using the WWW-Authenticate challenges of
+// https://datatracker.ietf.org/doc/html/rfc6750#section-3.
+func (h *UserInfoHandler) WriteUserInfoResponse(ctx context.Context, rw http.ResponseWriter, claims map[string]interface{}, err error) {
+	if err != nil {
+		h.OAuth2.WriteAccessError(ctx, rw, nil, err)
+		return
+	}
+
+	js, err := json.Marshal(claims)
+	if err != nil {
+		h.OAuth2.WriteAccessError(ctx, rw, nil, errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error())))
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.Header().Set("Pragma", "no-cache")
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write(js)
+}
+
+func (h *UserInfoHandler) validateBinding(ctx context.Context, r *http.Request, session fosite.Session, token string) error {
+	if rfc8705.GetCertificateThumbprint(session) != "" {
+		cert, err := h.Config.GetClientCertificateExtractor(ctx)(r)
+		if err != nil {
+			return err
+		}
+		if err := rfc8705.ValidateCertificateBinding(session, cert); err != nil {
+			return err
+		}
+	}
+
+	if dpop.GetJWKThumbprint(session) != "" {
+		if h.DPoP == nil {
+			return errorsx.WithStack(fosite.ErrInvalidDPoPProof.WithHint("DPoP-bound access tokens are not supported."))
+		}
+		return h.DPoP.ValidateResourceRequest(ctx, r, session, token)
+	}
+
+	return nil
+}
+
+func (h *UserInfoHandler) filterClaims(subject string, scopes fosite.Arguments, claims map[string]interface{}) map[string]interface{} {
+	scopeClaims := h.ScopeClaims
+	if scopeClaims == nil {
+		scopeClaims = DefaultScopeClaims
+	}
+
+	filtered := map[string]interface{}{"sub": subject}
+	for _, scope := range scopes {
+		for _, claim := range scopeClaims[scope] {
+			if value, ok := claims[claim]; ok {
+				filtered[claim] = value
+			}
+		}
+	}
+	return filtered
+}
+
+// userInfoTokenFromRequest returns the access token of the request, accepting the "DPoP" authentication scheme
+// of https://datatracker.ietf.org/doc/html/rfc9449#section-7.1 in addition to the "Bearer" one.
+func userInfoTokenFromRequest(r *http.Request) string {
+	split := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+	if len(split) == 2 && strings.EqualFold(split[0], "dpop") {
+		return split[1]
+	}
+	return fosite.AccessTokenFromRequest(r)
+}
@@ -0,0 +1,138 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package openid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/oauth2"
+	"github.com/ory/fosite/storage"
+)
+
+type staticUserInfoProvider map[string]interface{}
+
+func (p staticUserInfoProvider) GetUserInfo(_ context.Context, _ string, _ fosite.AccessRequester) (map[string]interface{}, error) {
+	return p, nil
+}
+
+func TestUserInfoHandler(t *testing.T) {
+	store := storage.NewExampleStore()
+	config := &fosite.Config{}
+	config.TokenIntrospectionHandlers = fosite.TokenIntrospectionHandlers{
+		&oauth2.CoreValidator{CoreStrategy: hmacStrategy, CoreStorage: store, Config: config},
+	}
+	provider := fosite.NewOAuth2Provider(store, config)
+
+	h := &UserInfoHandler{
+		OAuth2: provider,
+		Provider: staticUserInfoProvider{
+			"name":         "Peter",
+			"email":        "peter@example.com",
+			"phone_number": "+1 555 0100",
+			"secret":       "not-a-standard-claim",
+		},
+		Config: config,
+	}
+
+	issue := func(t *testing.T, subject string, scopes ...string) string {
+		session := NewDefaultSession()
+		session.Subject = subject
+		session.SetExpiresAt(fosite.AccessToken, time.Now().UTC().Add(time.Hour))
+
+		req := fosite.NewAccessRequest(session)
+		req.Client = &fosite.DefaultClient{ID: "foo"}
+		req.GrantedScope = scopes
+
+		token, signature, err := hmacStrategy.GenerateAccessToken(context.Background(), req)
+		require.NoError(t, err)
+		require.NoError(t, store.CreateAccessTokenSession(context.Background(), signature, req))
+		return token
+	}
+
+	for k, c := range []struct {
+		d              string
+		token          func(t *testing.T) string
+		expectErr      error
+		expectClaims   map[string]interface{}
+		expectedStatus int
+	}{
+		{
+			d: "should only return the claims of the granted scopes",
+			token: func(t *testing.T) string {
+				return issue(t, "peter", "openid", "email")
+			},
+			expectClaims:   map[string]interface{}{"sub": "peter", "email": "peter@example.com"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			d: "should return the claims of all granted scopes",
+			token: func(t *testing.T) string {
+				return issue(t, "peter", "openid", "profile", "phone")
+			},
+			expectClaims:   map[string]interface{}{"sub": "peter", "name": "Peter", "phone_number": "+1 555 0100"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			d: "should fail with invalid_token because the token is unknown",
+			token: func(t *testing.T) string {
+				return "ory_at_foo.bar"
+			},
+			expectErr:      fosite.ErrInvalidToken,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			d: "should fail with invalid_token because no token was sent",
+			token: func(t *testing.T) string {
+				return ""
+			},
+			expectErr:      fosite.ErrInvalidToken,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			d: "should fail with insufficient_scope because openid was not granted",
+			token: func(t *testing.T) string {
+				return issue(t, "peter", "email")
+			},
+			expectErr:      fosite.ErrInsufficientScope,
+			expectedStatus: http.StatusForbidden,
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.d), func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "https://op.example.com/userinfo", nil)
+			if token := c.token(t); token != "" {
+				r.Header.Set("Authorization", "Bearer "+token)
+			}
+
+			claims, err := h.HandleUserInfoRequest(context.Background(), r, NewDefaultSession())
+			if c.expectErr != nil {
+				require.ErrorIs(t, err, c.expectErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, c.expectClaims, claims)
+			}
+
+			rw := httptest.NewRecorder()
+			h.WriteUserInfoResponse(context.Background(), rw, claims, err)
+			assert.Equal(t, c.expectedStatus, rw.Code)
+
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &body))
+			if c.expectErr != nil {
+				assert.Equal(t, fosite.ErrorToRFC6749Error(c.expectErr).ErrorField, body["error"])
+			} else {
+				assert.Equal(t, c.expectClaims, body)
+			}
+		})
+	}
+}
@@ -18,11 +18,13 @@ import (
 	"github.com/ory/go-convenience/stringslice"
 )
 
-var defaultPrompts = []string{"login", "none", "consent", "select_account"}
+var defaultPrompts = []string{"login", "none", "consent", "select_account", "create"}
 
 type openIDConnectRequestValidatorConfigProvider interface {
 	fosite.RedirectSecureCheckerProvider
 	fosite.AllowedPromptsProvider
+	fosite.SubjectIdentifierAlgorithmProvider
+	fosite.PairwiseSubjectSaltProvider
 }
 
 type OpenIDConnectRequestValidator struct {
@@ -38,48 +40,9 @@ func NewOpenIDConnectRequestValidator(strategy jwt.Signer, config openIDConnectR
 }
 
 func (v *OpenIDConnectRequestValidator) ValidatePrompt(ctx context.Context, req fosite.AuthorizeRequester) error {
-	// prompt is case sensitive!
-	requiredPrompt := fosite.RemoveEmpty(strings.Split(req.GetRequestForm().Get("prompt"), " "))
-
-	if req.GetClient().IsPublic() {
-		// Threat: Malicious Client Obtains Existing Authorization by Fraud
-		// https://tools.ietf.org/html/rfc6819#section-4.2.3
-		//
-		//  Authorization servers should not automatically process repeat
-		//  authorizations to public clients unless the client is validated
-		//  using a pre-registered redirect URI
-
-		// Client Impersonation
-		// https://tools.ietf.org/html/rfc8252#section-8.6#
-		//
-		//  As stated in Section 10.2 of OAuth 2.0 [RFC6749], the authorization
-		//  server SHOULD NOT process authorization requests automatically
-		//  without user consent or interaction, except when the identity of the
-		//  client can be assured.  This includes the case where the user has
-		//  previously approved an authorization request for a given client id --
-		//  unless the identity of the client can be proven, the request SHOULD
-		//  be processed as if no previous request had been approved.
-
-		checker := v.Config.GetRedirectSecureChecker(ctx)
-		if stringslice.Has(requiredPrompt, "none") {
-			if !checker(ctx, req.GetRedirectURI()) {
-				return errorsx.WithStack(fosite.ErrConsentRequired.WithHint("OAuth 2.0 Client is marked public and redirect uri is not considered secure (https missing), but \"prompt=none\" was requested."))
-			}
-		}
-	}
-
-	availablePrompts := v.Config.GetAllowedPrompts(ctx)
-	if len(availablePrompts) == 0 {
-		availablePrompts = defaultPrompts
-	}
-
-	if !isWhitelisted(requiredPrompt, availablePrompts) {
-		return errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("Used unknown value '%s' for prompt parameter", requiredPrompt))
-	}
-
-	if stringslice.Has(requiredPrompt, "none") && len(requiredPrompt) > 1 {
-		// If this parameter contains none with any other value, an error is returned.
-		return errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("Parameter 'prompt' was set to 'none', but contains other values as well which is not allowed."))
+	requiredPrompt, err := v.validatePromptValues(ctx, req)
+	if err != nil {
+		return err
 	}
 
 	maxAge, err := strconv.ParseInt(req.GetRequestForm().Get("max_age"), 10, 64)
@@ -133,21 +96,98 @@ func (v *OpenIDConnectRequestValidator) ValidatePrompt(ctx context.Context, req
 		return nil
 	}
 
+	hintSub, err := v.decodeIDTokenHint(ctx, idTokenHint)
+	if err != nil {
+		return err
+	} else if subject, err := GetSubjectIdentifier(ctx, v.Config, req.GetClient(), claims.Subject); err != nil {
+		return err
+	} else if hintSub != subject {
+		return errorsx.WithStack(fosite.ErrLoginRequired.WithHint("Failed to validate OpenID Connect request because the subject from provided id token from id_token_hint does not match the current session's subject."))
+	}
+
+	return nil
+}
+
+// ValidatePromptParameters validates the parameters ValidatePrompt validates, as far as this is possible without an
+// authenticated end-user: the prompt values and the id_token_hint, but not whether the session satisfies them.
+func (v *OpenIDConnectRequestValidator) ValidatePromptParameters(ctx context.Context, req fosite.AuthorizeRequester) error {
+	if _, err := v.validatePromptValues(ctx, req); err != nil {
+		return err
+	}
+
+	if idTokenHint := req.GetRequestForm().Get("id_token_hint"); idTokenHint != "" {
+		if _, err := v.decodeIDTokenHint(ctx, idTokenHint); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validatePromptValues validates and returns the values of the prompt parameter.
+func (v *OpenIDConnectRequestValidator) validatePromptValues(ctx context.Context, req fosite.AuthorizeRequester) ([]string, error) {
+	// prompt is case sensitive!
+	requiredPrompt := fosite.RemoveEmpty(strings.Split(req.GetRequestForm().Get("prompt"), " "))
+
+	if req.GetClient().IsPublic() {
+		// Threat: Malicious Client Obtains Existing Authorization by Fraud
+		// https://tools.ietf.org/html/rfc6819#section-4.2.3
+		//
+		//  Authorization servers should not automatically process repeat
+		//  authorizations to public clients unless the client is validated
+		//  using a pre-registered redirect URI
+
+		// Client Impersonation
+		// https://tools.ietf.org/html/rfc8252#section-8.6#
+		//
+		//  As stated in Section 10.2 of OAuth 2.0 [RFC6749], the authorization
+		//  server SHOULD NOT process authorization requests automatically
+		//  without user consent or interaction, except when the identity of the
+		//  client can be assured.  This includes the case where the user has
+		//  previously approved an authorization request for a given client id --
+		//  unless the identity of the client can be proven, the request SHOULD
+		//  be processed as if no previous request had been approved.
+
+		checker := v.Config.GetRedirectSecureChecker(ctx)
+		if stringslice.Has(requiredPrompt, "none") {
+			if !checker(ctx, req.GetRedirectURI()) {
+				return nil, errorsx.WithStack(fosite.ErrConsentRequired.WithHint("OAuth 2.0 Client is marked public and redirect uri is not considered secure (https missing), but \"prompt=none\" was requested."))
+			}
+		}
+	}
+
+	availablePrompts := v.Config.GetAllowedPrompts(ctx)
+	if len(availablePrompts) == 0 {
+		availablePrompts = defaultPrompts
+	}
+
+	if !isWhitelisted(requiredPrompt, availablePrompts) {
+		return nil, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("Used unknown value '%s' for prompt parameter", requiredPrompt))
+	}
+
+	if stringslice.Has(requiredPrompt, "none") && len(requiredPrompt) > 1 {
+		// If this parameter contains none with any other value, an error is returned.
+		return nil, errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("Parameter 'prompt' was set to 'none', but contains other values as well which is not allowed."))
+	}
+
+	return requiredPrompt, nil
+}
+
+// decodeIDTokenHint decodes the id_token_hint, which may be expired, and returns its subject.
+func (v *OpenIDConnectRequestValidator) decodeIDTokenHint(ctx context.Context, idTokenHint string) (string, error) {
 	tokenHint, err := v.Strategy.Decode(ctx, idTokenHint)
 	var ve *jwt.ValidationError
 	if errors.As(err, &ve) && ve.Has(jwt.ValidationErrorExpired) {
 		// Expired tokens are ok
 	} else if err != nil {
-		return errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("Failed to validate OpenID Connect request as decoding id token from id_token_hint parameter failed.").WithWrap(err).WithDebug(err.Error()))
+		return "", errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("Failed to validate OpenID Connect request as decoding id token from id_token_hint parameter failed.").WithWrap(err).WithDebug(err.Error()))
 	}
 
-	if hintSub, _ := tokenHint.Claims["sub"].(string); hintSub == "" {
-		return errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("Failed to validate OpenID Connect request because provided id token from id_token_hint does not have a subject."))
-	} else if hintSub != claims.Subject {
-		return errorsx.WithStack(fosite.ErrLoginRequired.WithHint("Failed to validate OpenID Connect request because the subject from provided id token from id_token_hint does not match the current session's subject."))
+	hintSub, _ := tokenHint.Claims["sub"].(string)
+	if hintSub == "" {
+		return "", errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("Failed to validate OpenID Connect request because provided id token from id_token_hint does not have a subject."))
 	}
-
-	return nil
+	return hintSub, nil
 }
 
 func isWhitelisted(items []string, whiteList []string) bool {
@@ -162,6 +162,20 @@ func TestValidatePrompt(t *testing.T) {
 				},
 			},
 		},
+		{
+			d:         "should pass because prompt=create is a known value",
+			prompt:    "create",
+			isPublic:  false,
+			expectErr: false,
+			s: &DefaultSession{
+				Subject: "foo",
+				Claims: &jwt.IDTokenClaims{
+					Subject:     "foo",
+					RequestedAt: time.Now().UTC(),
+					AuthTime:    time.Now().UTC(),
+				},
+			},
+		},
 		{
 			d:         "should pass because requesting consent and login works with public clients",
 			prompt:    "login consent",
@@ -268,6 +282,18 @@ func TestValidatePrompt(t *testing.T) {
 	}
 }
 
+func TestValidatePromptCreateNotAllowed(t *testing.T) {
+	v := NewOpenIDConnectRequestValidator(nil, &fosite.Config{AllowedPromptValues: []string{"login", "none", "consent"}})
+	err := v.ValidatePrompt(context.TODO(), &fosite.AuthorizeRequest{
+		Request: fosite.Request{
+			Form:    url.Values{"prompt": {"create"}},
+			Client:  &fosite.DefaultClient{},
+			Session: &DefaultSession{Subject: "foo", Claims: &jwt.IDTokenClaims{Subject: "foo"}},
+		},
+	})
+	assert.ErrorIs(t, err, fosite.ErrInvalidRequest)
+}
+
 func parse(u string) *url.URL {
 	o, _ := url.Parse(u)
 	return o
@@ -66,7 +66,7 @@ func (c *PushedAuthorizeHandler) HandlePushedAuthorizeEndpointRequest(ctx contex
 	}
 
 	// generate an ID
-	stateKey, err := hmac.RandomBytes(defaultPARKeyLength)
+	stateKey, err := hmac.RandomBytesFrom(c.Config.GetRandomSource(ctx), defaultPARKeyLength)
 	if err != nil {
 		return errorsx.WithStack(fosite.ErrInsufficientEntropy.WithHint("Unable to generate the random part of the request_uri.").WithWrap(err).WithDebug(err.Error()))
 	}
@@ -26,29 +26,42 @@ type Handler struct {
 		fosite.EnforcePKCEProvider
 		fosite.EnforcePKCEForPublicClientsProvider
 		fosite.EnablePKCEPlainChallengeMethodProvider
+		fosite.PKCEMinCodeChallengeLengthProvider
 	}
+
+	// EnforcePKCE, if set, requires PKCE for the authorize requests it returns true for, in addition to the enforcement
+	// configured using EnforcePKCEProvider and EnforcePKCEForPublicClientsProvider. This allows, for example, enforcing
+	// PKCE for all clients but a legacy client. It is only called at the authorization endpoint, as the code challenge
+	// is bound to the authorization code there.
+	EnforcePKCE func(ctx context.Context, ar fosite.AuthorizeRequester) bool
+
+	// EnforcePKCEForPublicClients, if set to true, requires PKCE for public clients regardless of EnforcePKCE and the
+	// configuration.
+	EnforcePKCEForPublicClients bool
 }
 
-var _ fosite.TokenEndpointHandler = (*Handler)(nil)
+var (
+	_ fosite.TokenEndpointHandler              = (*Handler)(nil)
+	_ fosite.AuthorizeEndpointRequestValidator = (*Handler)(nil)
+)
 
 var verifierWrongFormat = regexp.MustCompile("[^\\w\\.\\-~]")
 
+// s256ChallengeFormat matches the unpadded base64url encoding of a SHA-256 digest.
+var s256ChallengeFormat = regexp.MustCompile("^[\\w\\-]{43}$")
+
 func (c *Handler) HandleAuthorizeEndpointRequest(ctx context.Context, ar fosite.AuthorizeRequester, resp fosite.AuthorizeResponder) error {
+	if err := c.ValidateAuthorizeEndpointRequest(ctx, ar); err != nil {
+		return err
+	}
+
 	// This let's us define multiple response types, for example open id connect's id_token
 	if !ar.GetResponseTypes().Has("code") {
 		return nil
 	}
 
-	challenge := ar.GetRequestForm().Get("code_challenge")
-	method := ar.GetRequestForm().Get("code_challenge_method")
-	client := ar.GetClient()
-
-	if err := c.validate(ctx, challenge, method, client); err != nil {
-		return err
-	}
-
 	// We don't need a session if it's not enforced and the PKCE parameters are not provided by the client.
-	if challenge == "" && method == "" {
+	if ar.GetRequestForm().Get("code_challenge") == "" && ar.GetRequestForm().Get("code_challenge_method") == "" {
 		return nil
 	}
 
@@ -68,7 +81,26 @@ func (c *Handler) HandleAuthorizeEndpointRequest(ctx context.Context, ar fosite.
 	return nil
 }
 
-func (c *Handler) validate(ctx context.Context, challenge, method string, client fosite.Client) error {
+// ValidateAuthorizeEndpointRequest validates the PKCE parameters of authorize requests using the authorize code flow.
+func (c *Handler) ValidateAuthorizeEndpointRequest(ctx context.Context, ar fosite.AuthorizeRequester) error {
+	if !ar.GetResponseTypes().Has("code") {
+		return nil
+	}
+
+	challenge := ar.GetRequestForm().Get("code_challenge")
+	method := ar.GetRequestForm().Get("code_challenge_method")
+
+	if err := c.validate(ctx, challenge, method, ar); err != nil {
+		return err
+	}
+
+	if challenge != "" {
+		return c.validateChallenge(ctx, challenge, method)
+	}
+	return nil
+}
+
+func (c *Handler) validate(ctx context.Context, challenge, method string, requester fosite.Requester) error {
 	if len(challenge) == 0 {
 		// If the server requires Proof Key for Code Exchange (PKCE) by OAuth
 		// clients and the client does not send the "code_challenge" in
@@ -76,7 +108,7 @@ func (c *Handler) validate(ctx context.Context, challenge, method string, client
 		// error response with the "error" value set to "invalid_request".  The
 		// "error_description" or the response of "error_uri" SHOULD explain the
 		// nature of error, e.g., code challenge required.
-		return c.validateNoPKCE(ctx, client)
+		return c.validateNoPKCE(ctx, requester)
 	}
 
 	// If the server supporting PKCE does not support the requested
@@ -103,17 +135,42 @@ func (c *Handler) validate(ctx context.Context, challenge, method string, client
 	return nil
 }
 
-func (c *Handler) validateNoPKCE(ctx context.Context, client fosite.Client) error {
+// validateChallenge rejects malformed code challenges. A S256 challenge is the base64url encoded SHA-256 hash of
+// the code verifier and therefore always 43 characters long, while a plain challenge is the code verifier itself.
+func (c *Handler) validateChallenge(ctx context.Context, challenge, method string) error {
+	if method == "S256" {
+		if !s256ChallengeFormat.MatchString(challenge) {
+			return errorsx.WithStack(fosite.ErrInvalidRequest.
+				WithHint("The code_challenge must be a base64url encoded SHA-256 hash of 43 characters when using code_challenge_method=S256."))
+		}
+	} else if verifierWrongFormat.MatchString(challenge) {
+		return errorsx.WithStack(fosite.ErrInvalidRequest.
+			WithHint("The code_challenge must only contain [a-Z], [0-9], '-', '.', '_', '~'."))
+	}
+
+	if minLength := c.Config.GetPKCEMinCodeChallengeLength(ctx); minLength > 0 && len(challenge) < minLength {
+		return errorsx.WithStack(fosite.ErrInvalidRequest.
+			WithHintf("The code_challenge must be at least %d characters.", minLength))
+	}
+	return nil
+}
+
+func (c *Handler) validateNoPKCE(ctx context.Context, requester fosite.Requester) error {
 	if c.Config.GetEnforcePKCE(ctx) {
 		return errorsx.WithStack(fosite.ErrInvalidRequest.
 			WithHint("Clients must include a code_challenge when performing the authorize code flow, but it is missing.").
 			WithDebug("The server is configured in a way that enforces PKCE for clients."))
 	}
-	if c.Config.GetEnforcePKCEForPublicClients(ctx) && client.IsPublic() {
+	if (c.Config.GetEnforcePKCEForPublicClients(ctx) || c.EnforcePKCEForPublicClients) && requester.GetClient().IsPublic() {
 		return errorsx.WithStack(fosite.ErrInvalidRequest.
 			WithHint("This client must include a code_challenge when performing the authorize code flow, but it is missing.").
 			WithDebug("The server is configured in a way that enforces PKCE for this client."))
 	}
+	if ar, ok := requester.(fosite.AuthorizeRequester); ok && c.EnforcePKCE != nil && c.EnforcePKCE(ctx, ar) {
+		return errorsx.WithStack(fosite.ErrInvalidRequest.
+			WithHint("This client must include a code_challenge when performing the authorize code flow, but it is missing.").
+			WithDebug("The server is configured in a way that enforces PKCE for this request."))
+	}
 	return nil
 }
 
@@ -138,7 +195,7 @@ func (c *Handler) HandleTokenEndpointRequest(ctx context.Context, request fosite
 
 	if errors.Is(err, fosite.ErrNotFound) {
 		if nv == 0 {
-			return c.validateNoPKCE(ctx, request.GetClient())
+			return c.validateNoPKCE(ctx, request)
 		}
 
 		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("Unable to find initial PKCE data tied to this request").WithWrap(err).WithDebug(err.Error()))
@@ -152,14 +209,13 @@ func (c *Handler) HandleTokenEndpointRequest(ctx context.Context, request fosite
 
 	challenge := pkceRequest.GetRequestForm().Get("code_challenge")
 	method := pkceRequest.GetRequestForm().Get("code_challenge_method")
-	client := pkceRequest.GetClient()
-	if err := c.validate(ctx, challenge, method, client); err != nil {
+	if err := c.validate(ctx, challenge, method, pkceRequest); err != nil {
 		return err
 	}
 
+	// A missing challenge has been rejected above if PKCE is enforced.
 	nc := len(challenge)
-
-	if !c.Config.GetEnforcePKCE(ctx) && nc == 0 && nv == 0 {
+	if nc == 0 && nv == 0 {
 		return nil
 	}
 
@@ -242,3 +298,7 @@ func (c *Handler) CanHandleTokenEndpointRequest(ctx context.Context, requester f
 	// Value MUST be set to "authorization_code"
 	return requester.GetGrantTypes().ExactOne("authorization_code")
 }
+
+func (c *Handler) HandledGrantTypes() []fosite.GrantType {
+	return []fosite.GrantType{fosite.GrantTypeAuthorizationCode}
+}
@@ -8,6 +8,7 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/pkg/errors"
@@ -49,7 +50,11 @@ func TestPKCEHandleAuthorizeEndpointRequest(t *testing.T) {
 
 	w.AddParameter("code", "foo")
 
-	r.Form.Add("code_challenge", "challenge")
+	plainChallenge := "challengechallengechallengechallengechallenge"
+	s256Sum := sha256.Sum256([]byte(plainChallenge))
+	s256Challenge := base64.RawURLEncoding.EncodeToString(s256Sum[:])
+
+	r.Form.Add("code_challenge", plainChallenge)
 	r.Form.Add("code_challenge_method", "plain")
 
 	r.ResponseTypes = fosite.Arguments{}
@@ -77,7 +82,7 @@ func TestPKCEHandleAuthorizeEndpointRequest(t *testing.T) {
 	config.EnforcePKCE = true
 	require.Error(t, h.HandleAuthorizeEndpointRequest(context.Background(), r, w))
 
-	r.Form.Set("code_challenge", "challenge")
+	r.Form.Set("code_challenge", s256Challenge)
 	require.NoError(t, h.HandleAuthorizeEndpointRequest(context.Background(), r, w))
 }
 
@@ -382,10 +387,277 @@ func TestPKCEHandleTokenEndpointRequest(t *testing.T) {
 				},
 			}
 
+			r := &fosite.Request{Client: tc.client}
 			if tc.expectErr {
-				assert.Error(t, h.validate(context.Background(), tc.challenge, tc.method, tc.client))
+				assert.Error(t, h.validate(context.Background(), tc.challenge, tc.method, r))
+			} else {
+				assert.NoError(t, h.validate(context.Background(), tc.challenge, tc.method, r))
+			}
+		})
+	}
+}
+
+func TestPKCEEnforcementPolicy(t *testing.T) {
+	exemptLegacyClient := func(_ context.Context, ar fosite.AuthorizeRequester) bool {
+		return ar.GetClient().GetID() != "legacy-client"
+	}
+
+	for k, tc := range []struct {
+		d              string
+		config         *fosite.Config
+		forcePublic    bool
+		client         *fosite.DefaultClient
+		expectErr      bool
+		expectTokenErr bool
+	}{
+		{
+			d:         "should fail at the authorization endpoint because the policy enforces pkce for the client",
+			config:    &fosite.Config{},
+			client:    &fosite.DefaultClient{ID: "confidential-client"},
+			expectErr: true,
+		},
+		{
+			d:      "should pass because the policy exempts the client",
+			config: &fosite.Config{},
+			client: &fosite.DefaultClient{ID: "legacy-client"},
+		},
+		{
+			d:              "should fail because pkce is enforced by the configuration although the policy exempts the client",
+			config:         &fosite.Config{EnforcePKCE: true},
+			client:         &fosite.DefaultClient{ID: "legacy-client"},
+			expectErr:      true,
+			expectTokenErr: true,
+		},
+		{
+			d:              "should fail because pkce is enforced for public clients by the configuration although the policy exempts the client",
+			config:         &fosite.Config{EnforcePKCEForPublicClients: true},
+			client:         &fosite.DefaultClient{ID: "legacy-client", Public: true},
+			expectErr:      true,
+			expectTokenErr: true,
+		},
+		{
+			d:              "should fail because pkce is forced for public clients although the policy exempts the client",
+			config:         &fosite.Config{},
+			client:         &fosite.DefaultClient{ID: "legacy-client", Public: true},
+			forcePublic:    true,
+			expectErr:      true,
+			expectTokenErr: true,
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, tc.d), func(t *testing.T) {
+			s := storage.NewMemoryStore()
+			ms := &mockCodeStrategy{signature: "code"}
+			h := &Handler{
+				Storage:                     s,
+				AuthorizeCodeStrategy:       ms,
+				Config:                      tc.config,
+				EnforcePKCE:                 exemptLegacyClient,
+				EnforcePKCEForPublicClients: tc.forcePublic,
+			}
+
+			ar := fosite.NewAuthorizeRequest()
+			ar.ResponseTypes = fosite.Arguments{"code"}
+			ar.Client = tc.client
+			w := fosite.NewAuthorizeResponse()
+			w.AddParameter("code", "code")
+
+			r := fosite.NewAccessRequest(nil)
+			r.Client = tc.client
+			r.GrantTypes = fosite.Arguments{"authorization_code"}
+
+			if tc.expectErr {
+				require.ErrorIs(t, h.HandleAuthorizeEndpointRequest(context.Background(), ar, w), fosite.ErrInvalidRequest)
+			} else {
+				require.NoError(t, h.HandleAuthorizeEndpointRequest(context.Background(), ar, w))
+			}
+
+			if tc.expectTokenErr {
+				require.ErrorIs(t, h.HandleTokenEndpointRequest(context.Background(), r), fosite.ErrInvalidRequest)
+			} else {
+				require.NoError(t, h.HandleTokenEndpointRequest(context.Background(), r))
+			}
+		})
+	}
+}
+
+func TestPKCEHandleTokenEndpointRequestUsesStoredChallengeMethod(t *testing.T) {
+	verifier := "KGCt4m8AmjUvIR5ArTByrmehjtbxn1A49YpTZhsH8N7fhDr7LQayn9xx6mck"
+	hash := sha256.Sum256([]byte(verifier))
+	s256Challenge := base64.RawURLEncoding.EncodeToString(hash[:])
+
+	for k, tc := range []struct {
+		d           string
+		enablePlain bool
+		challenge   string
+		method      string
+		verifier    string
+		expectErr   error
+		expectHint  string
+	}{
+		{
+			d:           "should fail because a flow started with S256 can not be completed using the challenge as the verifier",
+			enablePlain: true,
+			challenge:   s256Challenge,
+			method:      "S256",
+			verifier:    s256Challenge,
+			expectErr:   fosite.ErrInvalidGrant,
+			expectHint:  "The PKCE code challenge did not match the code verifier.",
+		},
+		{
+			d:         "should pass because the verifier matches the S256 challenge",
+			challenge: s256Challenge,
+			method:    "S256",
+			verifier:  verifier,
+		},
+		{
+			d:         "should fail because the plain method is disabled",
+			challenge: verifier,
+			method:    "plain",
+			verifier:  verifier,
+			expectErr: fosite.ErrInvalidRequest,
+		},
+		{
+			d:           "should pass because the plain method is enabled",
+			enablePlain: true,
+			challenge:   verifier,
+			method:      "plain",
+			verifier:    verifier,
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, tc.d), func(t *testing.T) {
+			s := storage.NewMemoryStore()
+			h := &Handler{
+				Storage:               s,
+				AuthorizeCodeStrategy: &mockCodeStrategy{signature: "code"},
+				Config:                &fosite.Config{EnablePKCEPlainChallengeMethod: tc.enablePlain},
+			}
+			client := &fosite.DefaultClient{ID: "foo"}
+
+			ar := fosite.NewAuthorizeRequest()
+			ar.Client = client
+			ar.Form.Add("code_challenge", tc.challenge)
+			ar.Form.Add("code_challenge_method", tc.method)
+			require.NoError(t, s.CreatePKCERequestSession(context.Background(), "code", ar))
+
+			r := fosite.NewAccessRequest(nil)
+			r.Client = client
+			r.GrantTypes = fosite.Arguments{"authorization_code"}
+			r.Form.Add("code_verifier", tc.verifier)
+
+			err := h.HandleTokenEndpointRequest(context.Background(), r)
+			if tc.expectErr != nil {
+				require.ErrorIs(t, err, tc.expectErr)
+				assert.Contains(t, fosite.ErrorToRFC6749Error(err).HintField, tc.expectHint)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestPKCEHandleAuthorizeEndpointRequestValidatesChallenge(t *testing.T) {
+	verifier := "KGCt4m8AmjUvIR5ArTByrmehjtbxn1A49YpTZhsH8N7fhDr7LQayn9xx6mck"
+	sum := sha256.Sum256([]byte(verifier))
+	s256challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	for k, tc := range []struct {
+		d         string
+		challenge string
+		method    string
+		minLength int
+		expectErr error
+	}{
+		{
+			d:         "passes with a valid S256 challenge",
+			challenge: s256challenge,
+			method:    "S256",
+		},
+		{
+			d:         "fails because the S256 challenge is too short",
+			challenge: s256challenge[:42],
+			method:    "S256",
+			expectErr: fosite.ErrInvalidRequest,
+		},
+		{
+			d:         "fails because the S256 challenge is too long",
+			challenge: s256challenge + "A",
+			method:    "S256",
+			expectErr: fosite.ErrInvalidRequest,
+		},
+		{
+			d:         "fails because the S256 challenge is not base64url encoded",
+			challenge: strings.Replace(s256challenge, s256challenge[:1], "+", 1),
+			method:    "S256",
+			expectErr: fosite.ErrInvalidRequest,
+		},
+		{
+			d:         "fails because the S256 challenge is too short even if the minimum length is disabled",
+			challenge: "challenge",
+			method:    "S256",
+			minLength: -1,
+			expectErr: fosite.ErrInvalidRequest,
+		},
+		{
+			d:         "passes with a valid plain challenge",
+			challenge: verifier,
+			method:    "plain",
+		},
+		{
+			d:         "fails because the plain challenge is too short",
+			challenge: verifier[:42],
+			method:    "plain",
+			expectErr: fosite.ErrInvalidRequest,
+		},
+		{
+			d:         "fails because the plain challenge is malformed",
+			challenge: verifier + "!",
+			method:    "plain",
+			expectErr: fosite.ErrInvalidRequest,
+		},
+		{
+			d:         "fails because the plain challenge is shorter than the configured minimum length",
+			challenge: verifier,
+			method:    "plain",
+			minLength: 64,
+			expectErr: fosite.ErrInvalidRequest,
+		},
+		{
+			d:         "passes because the plain challenge is longer than the configured minimum length",
+			challenge: "challenge",
+			method:    "plain",
+			minLength: 8,
+		},
+		{
+			d:         "passes because the minimum length is disabled",
+			challenge: "foo",
+			method:    "plain",
+			minLength: -1,
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, tc.d), func(t *testing.T) {
+			h := &Handler{
+				Storage:               storage.NewMemoryStore(),
+				AuthorizeCodeStrategy: oauth2.NewHMACSHAStrategy(nil, nil),
+				Config: &fosite.Config{
+					EnablePKCEPlainChallengeMethod: true,
+					PKCEMinCodeChallengeLength:     tc.minLength,
+				},
+			}
+
+			ar := fosite.NewAuthorizeRequest()
+			ar.Client = &fosite.DefaultClient{}
+			ar.ResponseTypes = fosite.Arguments{"code"}
+			ar.Form.Set("code_challenge", tc.challenge)
+			ar.Form.Set("code_challenge_method", tc.method)
+
+			resp := fosite.NewAuthorizeResponse()
+			resp.AddParameter("code", "foo")
+
+			err := h.HandleAuthorizeEndpointRequest(context.Background(), ar, resp)
+			if tc.expectErr == nil {
+				assert.NoError(t, err)
 			} else {
-				assert.NoError(t, h.validate(context.Background(), tc.challenge, tc.method, tc.client))
+				assert.ErrorIs(t, err, tc.expectErr)
 			}
 		})
 	}
@@ -5,6 +5,13 @@ package rfc7523
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"strings"
 	"time"
 
@@ -23,15 +30,23 @@ const grantTypeJWTBearer = "urn:ietf:params:oauth:grant-type:jwt-bearer"
 type Handler struct {
 	Storage RFC7523KeyStorage
 
+	// AssertionStorage, if set, records every assertion which was validated successfully.
+	AssertionStorage RFC7523AssertionStorage
+
 	Config interface {
 		fosite.AccessTokenLifespanProvider
 		fosite.TokenURLProvider
 		fosite.GrantTypeJWTBearerCanSkipClientAuthProvider
 		fosite.GrantTypeJWTBearerIDOptionalProvider
 		fosite.GrantTypeJWTBearerIssuedDateOptionalProvider
+		fosite.GrantTypeJWTBearerExtraClaimsProvider
+		fosite.GrantTypeJWTBearerHashAssertionProvider
 		fosite.GetJWTMaxDurationProvider
+		fosite.MaxAssertionExpiryFromNowProvider
 		fosite.AudienceStrategyProvider
 		fosite.ScopeStrategyProvider
+		fosite.SubjectValidatorProvider
+		fosite.EmptyClientGrantTypesPolicyProvider
 	}
 
 	*oauth2.HandleHelper
@@ -70,7 +85,8 @@ func (c *Handler) HandleTokenEndpointRequest(ctx context.Context, request fosite
 	}
 
 	claims := jwt.Claims{}
-	if err := token.Claims(key, &claims); err != nil {
+	privateClaims := map[string]interface{}{}
+	if err := token.Claims(key, &claims, &privateClaims); err != nil {
 		return errorsx.WithStack(fosite.ErrInvalidGrant.
 			WithHint("Unable to verify the integrity of the 'assertion' value.").
 			WithWrap(err).WithDebug(err.Error()),
@@ -81,6 +97,14 @@ func (c *Handler) HandleTokenEndpointRequest(ctx context.Context, request fosite
 		return err
 	}
 
+	if err := c.validateClientAssertionIssuer(ctx, request, claims.Issuer); err != nil {
+		return err
+	}
+
+	if err := c.Config.GetSubjectValidator(ctx)(claims.Subject); err != nil {
+		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The JWT in \"assertion\" request parameter contains an invalid \"sub\" (subject) claim.").WithWrap(err).WithDebug(err.Error()))
+	}
+
 	scopes, err := c.Storage.GetPublicKeyScopes(ctx, claims.Issuer, claims.Subject, key.KeyID)
 	if err != nil {
 		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
@@ -98,6 +122,10 @@ func (c *Handler) HandleTokenEndpointRequest(ctx context.Context, request fosite
 		}
 	}
 
+	if err := c.recordAssertion(ctx, claims, assertion); err != nil {
+		return err
+	}
+
 	for _, scope := range request.GetRequestedScopes() {
 		request.GrantScope(scope)
 	}
@@ -114,10 +142,53 @@ func (c *Handler) HandleTokenEndpointRequest(ctx context.Context, request fosite
 	atLifespan := fosite.GetEffectiveLifespan(request.GetClient(), fosite.GrantTypeJWTBearer, fosite.AccessToken, c.HandleHelper.Config.GetAccessTokenLifespan(ctx))
 	session.SetExpiresAt(fosite.AccessToken, time.Now().UTC().Add(atLifespan).Round(time.Second))
 	session.SetSubject(claims.Subject)
+	c.setExtraClaims(ctx, session, privateClaims)
 
 	return nil
 }
 
+// recordAssertion passes the validated assertion, or its hash, to the assertion storage if one is configured.
+func (c *Handler) recordAssertion(ctx context.Context, claims jwt.Claims, assertion string) error {
+	if c.AssertionStorage == nil {
+		return nil
+	}
+
+	if c.Config.GetGrantTypeJWTBearerHashAssertion(ctx) {
+		hash := sha256.Sum256([]byte(assertion))
+		assertion = hex.EncodeToString(hash[:])
+	}
+
+	if err := c.AssertionStorage.RecordAssertion(ctx, claims.ID, claims.Issuer, claims.Subject, assertion); err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+	return nil
+}
+
+// setExtraClaims copies the private claims of the assertion which are allowed by the configuration into the extra
+// claims of the session.
+func (c *Handler) setExtraClaims(ctx context.Context, session fosite.Session, privateClaims map[string]interface{}) {
+	mapping := c.Config.GetGrantTypeJWTBearerExtraClaims(ctx)
+	if len(mapping) == 0 {
+		return
+	}
+
+	extraClaimsSession, ok := session.(fosite.ExtraClaimsSession)
+	if !ok {
+		return
+	}
+
+	extra := extraClaimsSession.GetExtraClaims()
+	if extra == nil {
+		return
+	}
+
+	for claim, name := range mapping {
+		if value, ok := privateClaims[claim]; ok {
+			extra[name] = value
+		}
+	}
+}
+
 func (c *Handler) PopulateTokenEndpointResponse(ctx context.Context, request fosite.AccessRequester, response fosite.AccessResponder) error {
 	if err := c.CheckRequest(ctx, request); err != nil {
 		return err
@@ -137,6 +208,10 @@ func (c *Handler) CanHandleTokenEndpointRequest(ctx context.Context, requester f
 	return requester.GetGrantTypes().ExactOne(grantTypeJWTBearer)
 }
 
+func (c *Handler) HandledGrantTypes() []fosite.GrantType {
+	return []fosite.GrantType{fosite.GrantTypeJWTBearer}
+}
+
 func (c *Handler) CheckRequest(ctx context.Context, request fosite.AccessRequester) error {
 	if !c.CanHandleTokenEndpointRequest(ctx, request) {
 		return errorsx.WithStack(fosite.ErrUnknownRequest)
@@ -150,7 +225,7 @@ func (c *Handler) CheckRequest(ctx context.Context, request fosite.AccessRequest
 	//   relies on the parameter is used.
 
 	// if client is authenticated, check grant types
-	if !c.CanSkipClientAuth(ctx, request) && !request.GetClient().GetGrantTypes().Has(grantTypeJWTBearer) {
+	if !c.CanSkipClientAuth(ctx, request) && !fosite.ClientHasGrantType(ctx, c.Config, request.GetClient(), grantTypeJWTBearer) {
 		return errorsx.WithStack(fosite.ErrUnauthorizedClient.WithHintf("The OAuth 2.0 Client is not allowed to use authorization grant \"%s\".", grantTypeJWTBearer))
 	}
 
@@ -211,8 +286,18 @@ func (c *Handler) findPublicKeyForToken(ctx context.Context, token *jwt.JSONWebT
 		return nil, errorsx.WithStack(keyNotFoundErr.WithWrap(err).WithDebug(err.Error()))
 	}
 
+	var alg string
+	if len(token.Headers) > 0 {
+		alg = token.Headers[0].Algorithm
+	}
+
 	claims := jwt.Claims{}
 	for _, key := range keys.Keys {
+		// Verifying the signature is expensive, so keys which can not have signed the token are skipped.
+		if !keyMatchesAlgorithm(key, alg) {
+			continue
+		}
+
 		err := token.Claims(key, &claims)
 		if err == nil {
 			return &key, nil
@@ -222,6 +307,81 @@ func (c *Handler) findPublicKeyForToken(ctx context.Context, token *jwt.JSONWebT
 	return nil, errorsx.WithStack(keyNotFoundErr)
 }
 
+// keyMatchesAlgorithm returns false if the key can not be used to verify a signature created with the given
+// algorithm, based on the "use" and "alg" parameters and the type of the key.
+func keyMatchesAlgorithm(key jose.JSONWebKey, alg string) bool {
+	if key.Use != "" && key.Use != "sig" {
+		return false
+	}
+
+	if key.Algorithm != "" && key.Algorithm != alg {
+		return false
+	}
+
+	switch k := key.Key.(type) {
+	case *rsa.PublicKey, *rsa.PrivateKey:
+		return strings.HasPrefix(alg, "RS") || strings.HasPrefix(alg, "PS")
+	case *ecdsa.PublicKey:
+		return ecdsaAlgorithm(k.Curve) == alg
+	case *ecdsa.PrivateKey:
+		return ecdsaAlgorithm(k.Curve) == alg
+	case ed25519.PublicKey, ed25519.PrivateKey:
+		return alg == string(jose.EdDSA)
+	case []byte:
+		return strings.HasPrefix(alg, "HS")
+	default:
+		// Unknown key types are left to the signature verification.
+		return true
+	}
+}
+
+func ecdsaAlgorithm(curve elliptic.Curve) string {
+	switch curve {
+	case elliptic.P256():
+		return string(jose.ES256)
+	case elliptic.P384():
+		return string(jose.ES384)
+	case elliptic.P521():
+		return string(jose.ES512)
+	default:
+		return ""
+	}
+}
+
+// validateClientAssertionIssuer checks that the authenticated client may present assertions of the issuer. Clients
+// which are not bound to any issuer, or storages which do not implement RFC7523ClientAssertionIssuerStorage, allow
+// assertions of all issuers.
+func (c *Handler) validateClientAssertionIssuer(ctx context.Context, request fosite.AccessRequester, issuer string) error {
+	client := request.GetClient()
+	if client == nil {
+		return nil
+	}
+
+	issuerStorage, ok := c.Storage.(RFC7523ClientAssertionIssuerStorage)
+	if !ok {
+		return nil
+	}
+
+	issuers, err := issuerStorage.GetClientAssertionIssuers(ctx, client.GetID())
+	if errors.Is(err, fosite.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	if len(issuers) == 0 {
+		return nil
+	}
+
+	for _, permitted := range issuers {
+		if permitted == issuer {
+			return nil
+		}
+	}
+
+	return errorsx.WithStack(fosite.ErrInvalidGrant.WithHintf("The OAuth 2.0 Client is not allowed to present assertions issued by \"%s\".", issuer))
+}
+
 func (c *Handler) validateTokenClaims(ctx context.Context, claims jwt.Claims, key *jose.JSONWebKey) error {
 	if len(claims.Audience) == 0 {
 		return errorsx.WithStack(fosite.ErrInvalidGrant.
@@ -279,6 +439,16 @@ func (c *Handler) validateTokenClaims(ctx context.Context, claims jwt.Claims, ke
 		)
 	}
 
+	if maxExpiry := c.Config.GetMaxAssertionExpiryFromNow(ctx); maxExpiry > 0 && claims.Expiry.Time().After(time.Now().Add(maxExpiry)) {
+		return errorsx.WithStack(fosite.ErrInvalidGrant.
+			WithHintf(
+				"The JWT in \"assertion\" request parameter contains an \"exp\" (expiration time) claim with value \"%s\" that is more than \"%s\" in the future.",
+				claims.Expiry.Time().Format(time.RFC3339),
+				maxExpiry,
+			),
+		)
+	}
+
 	if !c.Config.GetGrantTypeJWTBearerIDOptional(ctx) && claims.ID == "" {
 		return errorsx.WithStack(fosite.ErrInvalidGrant.
 			WithHint("The JWT in \"assertion\" request parameter MUST contain an \"jti\" (JWT ID) claim."),
@@ -5,8 +5,13 @@ package rfc7523
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	mrand "math/rand"
@@ -21,6 +26,8 @@ import (
 	"github.com/go-jose/go-jose/v3"
 	"github.com/go-jose/go-jose/v3/jwt"
 	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/ory/fosite"
@@ -339,6 +346,30 @@ func (s *AuthorizeJWTGrantRequestHandlerTestSuite) TestNotValidAudienceInAsserti
 	)
 }
 
+func (s *AuthorizeJWTGrantRequestHandlerTestSuite) TestTooLongSubjectInAssertion() {
+	// arrange
+	ctx := context.Background()
+	s.handler.Config.(*fosite.Config).SubjectValidator = fosite.DefaultSubjectValidator
+	s.accessRequest.GrantTypes = []string{grantTypeJWTBearer}
+	keyID := "my_key"
+	pubKey := s.createJWK(s.privateKey.Public(), keyID)
+	cl := s.createStandardClaim()
+	cl.Subject = strings.Repeat("a", fosite.MaxSubjectLength+1)
+	s.accessRequest.Form.Add("assertion", s.createTestAssertion(cl, keyID))
+	s.mockStore.EXPECT().GetPublicKey(ctx, cl.Issuer, cl.Subject, keyID).Return(&pubKey, nil)
+	s.mockStore.EXPECT().IsJWTUsed(ctx, cl.ID).Return(false, nil)
+
+	// act
+	err := s.handler.HandleTokenEndpointRequest(ctx, s.accessRequest)
+
+	// assert
+	s.True(errors.Is(err, fosite.ErrInvalidGrant))
+	s.Equal(
+		`The JWT in "assertion" request parameter contains an invalid "sub" (subject) claim.`,
+		fosite.ErrorToRFC6749Error(err).HintField,
+	)
+}
+
 func (s *AuthorizeJWTGrantRequestHandlerTestSuite) TestNoExpirationInAssertion() {
 	// arrange
 	ctx := context.Background()
@@ -518,6 +549,59 @@ func (s *AuthorizeJWTGrantRequestHandlerTestSuite) TestAssertionWithExpirationDa
 	s.EqualError(err, fosite.ErrInvalidGrant.Error(), "expected error, because assertion will expire unreasonably far in the future.")
 }
 
+func (s *AuthorizeJWTGrantRequestHandlerTestSuite) TestAssertionWithExpirationDateWithinMaxExpiryFromNow() {
+	// arrange
+	ctx := context.Background()
+	s.accessRequest.GrantTypes = []string{grantTypeJWTBearer}
+	keyID := "my_key"
+	pubKey := s.createJWK(s.privateKey.Public(), keyID)
+	cl := s.createStandardClaim()
+	cl.IssuedAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	cl.Expiry = jwt.NewNumericDate(time.Now().Add(time.Hour))
+	s.handler.Config.(*fosite.Config).MaxAssertionExpiryFromNow = time.Hour * 2
+	s.accessRequest.Form.Add("assertion", s.createTestAssertion(cl, keyID))
+	s.accessRequest.RequestedScope = []string{"valid_scope"}
+	s.mockStore.EXPECT().GetPublicKey(ctx, cl.Issuer, cl.Subject, keyID).Return(&pubKey, nil)
+	s.mockStore.EXPECT().GetPublicKeyScopes(ctx, cl.Issuer, cl.Subject, keyID).Return([]string{"valid_scope"}, nil)
+	s.mockStore.EXPECT().IsJWTUsed(ctx, cl.ID).Return(false, nil)
+	s.mockStore.EXPECT().MarkJWTUsedForTime(ctx, cl.ID, cl.Expiry.Time()).Return(nil)
+
+	// act
+	err := s.handler.HandleTokenEndpointRequest(ctx, s.accessRequest)
+
+	// assert
+	s.NoError(err, "no error expected, because assertion expires within the allowed time from now")
+}
+
+func (s *AuthorizeJWTGrantRequestHandlerTestSuite) TestAssertionWithExpirationDateBeyondMaxExpiryFromNow() {
+	// arrange
+	ctx := context.Background()
+	s.accessRequest.GrantTypes = []string{grantTypeJWTBearer}
+	keyID := "my_key"
+	pubKey := s.createJWK(s.privateKey.Public(), keyID)
+	cl := s.createStandardClaim()
+	cl.IssuedAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	cl.Expiry = jwt.NewNumericDate(time.Now().Add(time.Hour * 3))
+	s.handler.Config.(*fosite.Config).MaxAssertionExpiryFromNow = time.Hour * 2
+	s.accessRequest.Form.Add("assertion", s.createTestAssertion(cl, keyID))
+	s.mockStore.EXPECT().GetPublicKey(ctx, cl.Issuer, cl.Subject, keyID).Return(&pubKey, nil)
+
+	// act
+	err := s.handler.HandleTokenEndpointRequest(ctx, s.accessRequest)
+
+	// assert
+	s.True(errors.Is(err, fosite.ErrInvalidGrant))
+	s.EqualError(err, fosite.ErrInvalidGrant.Error(), "expected error, because assertion expires further from now than allowed.")
+	s.Equal(
+		fmt.Sprintf(
+			"The JWT in \"assertion\" request parameter contains an \"exp\" (expiration time) claim with value \"%s\" that is more than \"%s\" in the future.",
+			cl.Expiry.Time().Format(time.RFC3339),
+			time.Hour*2,
+		),
+		fosite.ErrorToRFC6749Error(err).HintField,
+	)
+}
+
 func (s *AuthorizeJWTGrantRequestHandlerTestSuite) TestAssertionWithoutRequiredTokenID() {
 	// arrange
 	ctx := context.Background()
@@ -669,6 +753,188 @@ func (s *AuthorizeJWTGrantRequestHandlerTestSuite) TestValidAssertion() {
 	s.NoError(err, "no error expected, because assertion must be valid")
 }
 
+type recordedAssertion struct {
+	jti, issuer, subject, assertion string
+}
+
+type assertionRecorder struct {
+	recorded []recordedAssertion
+	err      error
+}
+
+func (r *assertionRecorder) RecordAssertion(_ context.Context, jti, issuer, subject string, assertion string) error {
+	r.recorded = append(r.recorded, recordedAssertion{jti: jti, issuer: issuer, subject: subject, assertion: assertion})
+	return r.err
+}
+
+func (s *AuthorizeJWTGrantRequestHandlerTestSuite) TestValidAssertionIsRecorded() {
+	for _, hashAssertion := range []bool{false, true} {
+		s.Run(fmt.Sprintf("hash=%v", hashAssertion), func() {
+			// arrange
+			ctx := context.Background()
+			s.SetupTest()
+			recorder := new(assertionRecorder)
+			s.handler.AssertionStorage = recorder
+			s.handler.Config.(*fosite.Config).GrantTypeJWTBearerHashAssertion = hashAssertion
+			s.accessRequest.GrantTypes = []string{grantTypeJWTBearer}
+			keyID := "my_key"
+			pubKey := s.createJWK(s.privateKey.Public(), keyID)
+			cl := s.createStandardClaim()
+
+			assertion := s.createTestAssertion(cl, keyID)
+			s.accessRequest.Form.Add("assertion", assertion)
+			s.mockStore.EXPECT().GetPublicKey(ctx, cl.Issuer, cl.Subject, keyID).Return(&pubKey, nil)
+			s.mockStore.EXPECT().GetPublicKeyScopes(ctx, cl.Issuer, cl.Subject, keyID).Return([]string{}, nil)
+			s.mockStore.EXPECT().IsJWTUsed(ctx, cl.ID).Return(false, nil)
+			s.mockStore.EXPECT().MarkJWTUsedForTime(ctx, cl.ID, cl.Expiry.Time()).Return(nil)
+
+			// act
+			err := s.handler.HandleTokenEndpointRequest(ctx, s.accessRequest)
+
+			// assert
+			s.Require().NoError(err, "no error expected, because assertion must be valid")
+			expected := assertion
+			if hashAssertion {
+				hash := sha256.Sum256([]byte(assertion))
+				expected = hex.EncodeToString(hash[:])
+			}
+			s.Equal([]recordedAssertion{{jti: cl.ID, issuer: cl.Issuer, subject: cl.Subject, assertion: expected}}, recorder.recorded)
+		})
+	}
+}
+
+func (s *AuthorizeJWTGrantRequestHandlerTestSuite) TestInvalidAssertionIsNotRecorded() {
+	// arrange
+	ctx := context.Background()
+	recorder := new(assertionRecorder)
+	s.handler.AssertionStorage = recorder
+	s.accessRequest.GrantTypes = []string{grantTypeJWTBearer}
+	keyID := "my_key"
+	pubKey := s.createJWK(s.privateKey.Public(), keyID)
+	cl := s.createStandardClaim()
+
+	s.accessRequest.Form.Add("assertion", s.createTestAssertion(cl, keyID))
+	s.mockStore.EXPECT().GetPublicKey(ctx, cl.Issuer, cl.Subject, keyID).Return(&pubKey, nil)
+	s.mockStore.EXPECT().IsJWTUsed(ctx, cl.ID).Return(true, nil)
+
+	// act
+	err := s.handler.HandleTokenEndpointRequest(ctx, s.accessRequest)
+
+	// assert
+	s.True(errors.Is(err, fosite.ErrJTIKnown))
+	s.Empty(recorder.recorded, "assertion must not be recorded, because it was rejected")
+}
+
+func (s *AuthorizeJWTGrantRequestHandlerTestSuite) TestErrWhenRecordingAssertion() {
+	// arrange
+	ctx := context.Background()
+	s.handler.AssertionStorage = &assertionRecorder{err: errors.New("some error")}
+	s.accessRequest.GrantTypes = []string{grantTypeJWTBearer}
+	keyID := "my_key"
+	pubKey := s.createJWK(s.privateKey.Public(), keyID)
+	cl := s.createStandardClaim()
+
+	s.accessRequest.Form.Add("assertion", s.createTestAssertion(cl, keyID))
+	s.mockStore.EXPECT().GetPublicKey(ctx, cl.Issuer, cl.Subject, keyID).Return(&pubKey, nil)
+	s.mockStore.EXPECT().GetPublicKeyScopes(ctx, cl.Issuer, cl.Subject, keyID).Return([]string{}, nil)
+	s.mockStore.EXPECT().IsJWTUsed(ctx, cl.ID).Return(false, nil)
+	s.mockStore.EXPECT().MarkJWTUsedForTime(ctx, cl.ID, cl.Expiry.Time()).Return(nil)
+
+	// act
+	err := s.handler.HandleTokenEndpointRequest(ctx, s.accessRequest)
+
+	// assert
+	s.True(errors.Is(err, fosite.ErrServerError))
+}
+
+func (s *AuthorizeJWTGrantRequestHandlerTestSuite) TestAllowedPrivateClaimsAreCopiedIntoSession() {
+	// arrange
+	ctx := context.Background()
+	s.accessRequest.GrantTypes = []string{grantTypeJWTBearer}
+	keyID := "my_key"
+	pubKey := s.createJWK(s.privateKey.Public(), keyID)
+	cl := s.createStandardClaim()
+	s.handler.Config.(*fosite.Config).GrantTypeJWTBearerExtraClaims = map[string]string{"partner_id": "ext_partner_id"}
+
+	s.accessRequest.Form.Add("assertion", s.createTestAssertionWithPrivateClaims(cl, map[string]interface{}{
+		"partner_id":     "partner-42",
+		"partner_secret": "do-not-expose",
+	}, keyID))
+	s.mockStore.EXPECT().GetPublicKey(ctx, cl.Issuer, cl.Subject, keyID).Return(&pubKey, nil)
+	s.mockStore.EXPECT().GetPublicKeyScopes(ctx, cl.Issuer, cl.Subject, keyID).Return([]string{}, nil)
+	s.mockStore.EXPECT().IsJWTUsed(ctx, cl.ID).Return(false, nil)
+	s.mockStore.EXPECT().MarkJWTUsedForTime(ctx, cl.ID, cl.Expiry.Time()).Return(nil)
+
+	// act
+	err := s.handler.HandleTokenEndpointRequest(ctx, s.accessRequest)
+
+	// assert
+	s.NoError(err, "no error expected, because assertion must be valid")
+	extra := s.accessRequest.GetSession().(fosite.ExtraClaimsSession).GetExtraClaims()
+	s.Equal("partner-42", extra["ext_partner_id"], "allowed private claim must be copied into the session")
+	s.NotContains(extra, "partner_secret", "private claim which is not allowed must not be copied into the session")
+	s.NotContains(extra, "ext_partner_secret")
+}
+
+func (s *AuthorizeJWTGrantRequestHandlerTestSuite) TestAssertionFromPermittedIssuer() {
+	// arrange
+	ctx := context.Background()
+	issuerStore := internal.NewMockRFC7523ClientAssertionIssuerStorage(s.mockCtrl)
+	s.handler.Storage = struct {
+		*internal.MockRFC7523KeyStorage
+		*internal.MockRFC7523ClientAssertionIssuerStorage
+	}{s.mockStore, issuerStore}
+	s.accessRequest.GrantTypes = []string{grantTypeJWTBearer}
+	s.accessRequest.Client = &fosite.DefaultClient{ID: "bound-client", GrantTypes: []string{grantTypeJWTBearer}}
+	keyID := "my_key"
+	pubKey := s.createJWK(s.privateKey.Public(), keyID)
+	cl := s.createStandardClaim()
+
+	s.accessRequest.Form.Add("assertion", s.createTestAssertion(cl, keyID))
+	s.mockStore.EXPECT().GetPublicKey(ctx, cl.Issuer, cl.Subject, keyID).Return(&pubKey, nil)
+	issuerStore.EXPECT().GetClientAssertionIssuers(ctx, "bound-client").Return([]string{"other_issuer", cl.Issuer}, nil)
+	s.mockStore.EXPECT().GetPublicKeyScopes(ctx, cl.Issuer, cl.Subject, keyID).Return([]string{}, nil)
+	s.mockStore.EXPECT().IsJWTUsed(ctx, cl.ID).Return(false, nil)
+	s.mockStore.EXPECT().MarkJWTUsedForTime(ctx, cl.ID, cl.Expiry.Time()).Return(nil)
+
+	// act
+	err := s.handler.HandleTokenEndpointRequest(ctx, s.accessRequest)
+
+	// assert
+	s.NoError(err, "no error expected, because the client may present assertions of the issuer")
+}
+
+func (s *AuthorizeJWTGrantRequestHandlerTestSuite) TestAssertionFromForbiddenIssuer() {
+	// arrange
+	ctx := context.Background()
+	issuerStore := internal.NewMockRFC7523ClientAssertionIssuerStorage(s.mockCtrl)
+	s.handler.Storage = struct {
+		*internal.MockRFC7523KeyStorage
+		*internal.MockRFC7523ClientAssertionIssuerStorage
+	}{s.mockStore, issuerStore}
+	s.accessRequest.GrantTypes = []string{grantTypeJWTBearer}
+	s.accessRequest.Client = &fosite.DefaultClient{ID: "bound-client", GrantTypes: []string{grantTypeJWTBearer}}
+	keyID := "my_key"
+	pubKey := s.createJWK(s.privateKey.Public(), keyID)
+	cl := s.createStandardClaim()
+
+	s.accessRequest.Form.Add("assertion", s.createTestAssertion(cl, keyID))
+	s.mockStore.EXPECT().GetPublicKey(ctx, cl.Issuer, cl.Subject, keyID).Return(&pubKey, nil)
+	issuerStore.EXPECT().GetClientAssertionIssuers(ctx, "bound-client").Return([]string{"other_issuer"}, nil)
+	s.mockStore.EXPECT().IsJWTUsed(ctx, cl.ID).Return(false, nil)
+
+	// act
+	err := s.handler.HandleTokenEndpointRequest(ctx, s.accessRequest)
+
+	// assert
+	s.True(errors.Is(err, fosite.ErrInvalidGrant))
+	s.EqualError(err, fosite.ErrInvalidGrant.Error(), "expected error, because the client may not present assertions of the issuer")
+	s.Equal(
+		"The OAuth 2.0 Client is not allowed to present assertions issued by \"trusted_issuer\".",
+		fosite.ErrorToRFC6749Error(err).HintField,
+	)
+}
+
 func (s *AuthorizeJWTGrantRequestHandlerTestSuite) TestAssertionIsValidWhenNoScopesPassed() {
 	// arrange
 	ctx := context.Background()
@@ -754,13 +1020,17 @@ func (s *AuthorizeJWTGrantRequestHandlerTestSuite) TestRequestIsValidWhenClientA
 }
 
 func (s *AuthorizeJWTGrantRequestHandlerTestSuite) createTestAssertion(cl jwt.Claims, keyID string) string {
+	return s.createTestAssertionWithPrivateClaims(cl, map[string]interface{}{}, keyID)
+}
+
+func (s *AuthorizeJWTGrantRequestHandlerTestSuite) createTestAssertionWithPrivateClaims(cl jwt.Claims, privateClaims map[string]interface{}, keyID string) string {
 	jwk := jose.JSONWebKey{Key: s.privateKey, KeyID: keyID, Algorithm: string(jose.RS256)}
 	sig, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: jwk}, (&jose.SignerOptions{}).WithType("JWT"))
 	if err != nil {
 		s.FailNowf("failed to create test assertion", "failed to create signer: %s", err.Error())
 	}
 
-	raw, err := jwt.Signed(sig).Claims(cl).CompactSerialize()
+	raw, err := jwt.Signed(sig).Claims(cl).Claims(privateClaims).CompactSerialize()
 	if err != nil {
 		s.FailNowf("failed to create test assertion", "failed to sign assertion: %s", err.Error())
 	}
@@ -956,3 +1226,99 @@ func (s *AuthorizeJWTGrantPopulateTokenEndpointTestSuite) TestAccessTokenIssuedS
 	s.Equal(s.accessResponse.GetExtra("scope"), "", "no scopes expected in response")
 	s.Nil(s.accessResponse.GetExtra("refresh_token"), "refresh token not expected in response")
 }
+
+func TestKeyMatchesAlgorithm(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	edKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	for k, tc := range []struct {
+		key      jose.JSONWebKey
+		alg      jose.SignatureAlgorithm
+		expected bool
+	}{
+		{key: jose.JSONWebKey{Key: rsaKey.Public()}, alg: jose.RS256, expected: true},
+		{key: jose.JSONWebKey{Key: rsaKey.Public()}, alg: jose.PS384, expected: true},
+		{key: jose.JSONWebKey{Key: rsaKey.Public()}, alg: jose.ES256, expected: false},
+		{key: jose.JSONWebKey{Key: rsaKey.Public(), Algorithm: string(jose.RS512)}, alg: jose.RS256, expected: false},
+		{key: jose.JSONWebKey{Key: rsaKey.Public(), Use: "enc"}, alg: jose.RS256, expected: false},
+		{key: jose.JSONWebKey{Key: rsaKey.Public(), Use: "sig", Algorithm: string(jose.RS256)}, alg: jose.RS256, expected: true},
+		{key: jose.JSONWebKey{Key: &p256Key.PublicKey}, alg: jose.ES256, expected: true},
+		{key: jose.JSONWebKey{Key: &p256Key.PublicKey}, alg: jose.ES384, expected: false},
+		{key: jose.JSONWebKey{Key: &p384Key.PublicKey}, alg: jose.ES384, expected: true},
+		{key: jose.JSONWebKey{Key: &p384Key.PublicKey}, alg: jose.RS256, expected: false},
+		{key: jose.JSONWebKey{Key: edKey}, alg: jose.EdDSA, expected: true},
+		{key: jose.JSONWebKey{Key: edKey}, alg: jose.ES256, expected: false},
+		{key: jose.JSONWebKey{Key: []byte("secret")}, alg: jose.HS256, expected: true},
+		{key: jose.JSONWebKey{Key: []byte("secret")}, alg: jose.RS256, expected: false},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			assert.Equal(t, tc.expected, keyMatchesAlgorithm(tc.key, string(tc.alg)))
+		})
+	}
+}
+
+func BenchmarkFindPublicKeyForToken(b *testing.B) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(b, err)
+
+	// A mixed key set in which the key that signed the assertion comes last.
+	keys := &jose.JSONWebKeySet{}
+	for i := 0; i < 4; i++ {
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(b, err)
+		p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(b, err)
+		p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		require.NoError(b, err)
+		edKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(b, err)
+
+		keys.Keys = append(keys.Keys,
+			jose.JSONWebKey{Key: rsaKey.Public(), Use: "enc"},
+			jose.JSONWebKey{Key: &p256Key.PublicKey, Use: "sig"},
+			jose.JSONWebKey{Key: &p384Key.PublicKey, Use: "sig"},
+			jose.JSONWebKey{Key: edKey, Use: "sig"},
+		)
+	}
+	keys.Keys = append(keys.Keys, jose.JSONWebKey{Key: privateKey.Public(), Use: "sig"})
+
+	sig, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: privateKey}, (&jose.SignerOptions{}).WithType("JWT"))
+	require.NoError(b, err)
+	raw, err := jwt.Signed(sig).Claims(jwt.Claims{Issuer: "trusted_issuer", Subject: "some_ro"}).CompactSerialize()
+	require.NoError(b, err)
+	token, err := jwt.ParseSigned(raw)
+	require.NoError(b, err)
+
+	ctrl := gomock.NewController(b)
+	defer ctrl.Finish()
+	store := internal.NewMockRFC7523KeyStorage(ctrl)
+	store.EXPECT().GetPublicKeys(gomock.Any(), "trusted_issuer", "some_ro").Return(keys, nil).AnyTimes()
+	h := &Handler{Storage: store}
+
+	b.Run("case=unfiltered", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var found bool
+			claims := jwt.Claims{}
+			for _, key := range keys.Keys {
+				if err := token.Claims(key, &claims); err == nil {
+					found = true
+					break
+				}
+			}
+			require.True(b, found)
+		}
+	})
+
+	b.Run("case=filtered", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, err := h.findPublicKeyForToken(context.Background(), token)
+			require.NoError(b, err)
+		}
+	})
+}
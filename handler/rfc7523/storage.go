@@ -31,3 +31,20 @@ type RFC7523KeyStorage interface {
 	// considered valid based on the applicable "exp" instant. (https://tools.ietf.org/html/rfc7523#section-3)
 	MarkJWTUsedForTime(ctx context.Context, jti string, exp time.Time) error
 }
+
+// RFC7523AssertionStorage records the assertions which were exchanged for tokens, for example to resolve disputes
+// about issued tokens.
+type RFC7523AssertionStorage interface {
+	// RecordAssertion is called after the assertion identified by 'jti' (which may be empty if the claim is optional),
+	// issued by 'issuer' for 'subject', has been validated successfully. Depending on the configuration, 'assertion'
+	// is either the raw assertion or its hex-encoded SHA-256 hash.
+	RecordAssertion(ctx context.Context, jti, issuer, subject string, assertion string) error
+}
+
+// RFC7523ClientAssertionIssuerStorage binds OAuth 2.0 Clients to the issuers whose assertions they may present. It is
+// optional: if the storage does not implement it, clients may present assertions of any issuer.
+type RFC7523ClientAssertionIssuerStorage interface {
+	// GetClientAssertionIssuers returns the issuers whose assertions the OAuth 2.0 Client 'clientID' may present.
+	// The client may present assertions of any issuer if no issuers are returned or fosite.ErrNotFound is returned.
+	GetClientAssertionIssuers(ctx context.Context, clientID string) ([]string, error)
+}
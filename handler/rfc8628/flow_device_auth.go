@@ -0,0 +1,121 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package rfc8628
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/ory/x/errorsx"
+
+	"github.com/ory/fosite"
+)
+
+// DeviceAuthHandler implements the device authorization endpoint and lets the authorization server record the
+// end user's decision at the verification URI, see https://datatracker.ietf.org/doc/html/rfc8628#section-3
+type DeviceAuthHandler struct {
+	Strategy RFC8628CodeStrategy
+	Storage  RFC8628CodeStorage
+	Config   fosite.DeviceAuthorizeConfigProvider
+}
+
+var _ fosite.DeviceEndpointHandler = (*DeviceAuthHandler)(nil)
+
+// HandleDeviceEndpointRequest implements https://datatracker.ietf.org/doc/html/rfc8628#section-3.2
+func (d *DeviceAuthHandler) HandleDeviceEndpointRequest(ctx context.Context, dar fosite.DeviceRequester, resp fosite.DeviceResponder) error {
+	deviceCode, deviceCodeSignature, err := d.Strategy.GenerateDeviceCode(ctx)
+	if err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	userCode, userCodeSignature, err := d.Strategy.GenerateUserCode(ctx)
+	if err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	lifespan := d.Config.GetDeviceAndUserCodeLifespan(ctx)
+	expiresAt := time.Now().UTC().Add(lifespan).Round(time.Second)
+	dar.GetSession().SetExpiresAt(fosite.DeviceCode, expiresAt)
+	dar.GetSession().SetExpiresAt(fosite.UserCode, expiresAt)
+
+	if err := d.Storage.CreateDeviceCodeSession(ctx, deviceCodeSignature, dar); err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	if err := d.Storage.CreateUserCodeSession(ctx, userCodeSignature, dar); err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	verificationURI := d.Config.GetDeviceVerificationURL(ctx)
+	resp.SetDeviceCode(deviceCode)
+	resp.SetUserCode(userCode)
+	resp.SetVerificationURI(verificationURI)
+	if u, err := url.Parse(verificationURI); err == nil && verificationURI != "" {
+		q := u.Query()
+		q.Set("user_code", userCode)
+		u.RawQuery = q.Encode()
+		resp.SetVerificationURIComplete(u.String())
+	}
+	resp.SetExpiresIn(int64(lifespan / time.Second))
+	resp.SetInterval(int(d.Config.GetDeviceAuthTokenPollingInterval(ctx) / time.Second))
+	return nil
+}
+
+// GetUserCodeRequest returns the device authorization request of the user code the end user entered at the
+// verification URI, so that it can be reviewed and then accepted or rejected.
+func (d *DeviceAuthHandler) GetUserCodeRequest(ctx context.Context, userCode string, session fosite.Session) (fosite.DeviceRequester, error) {
+	signature, err := d.Strategy.UserCodeSignature(ctx, userCode)
+	if err != nil {
+		return nil, errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	request, err := d.Storage.GetUserCodeSession(ctx, signature, session)
+	if errors.Is(err, fosite.ErrNotFound) {
+		return nil, errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The user code is unknown or has already been used.").WithWrap(err).WithDebug(err.Error()))
+	} else if err != nil {
+		return nil, errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	if err := d.Strategy.ValidateUserCode(ctx, request, userCode); err != nil {
+		return nil, err
+	}
+
+	if request.GetUserCodeState() != fosite.UserCodeStateUnused {
+		return nil, errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The user code has already been used."))
+	}
+
+	return request, nil
+}
+
+// AcceptUserCode approves the device authorization request returned by GetUserCodeRequest. The session as well as
+// the granted scopes and audience of the request are used when issuing tokens to the device.
+func (d *DeviceAuthHandler) AcceptUserCode(ctx context.Context, userCode string, request fosite.DeviceRequester) error {
+	return d.decide(ctx, userCode, request, fosite.UserCodeStateAccepted)
+}
+
+// RejectUserCode denies the device authorization request returned by GetUserCodeRequest.
+func (d *DeviceAuthHandler) RejectUserCode(ctx context.Context, userCode string, request fosite.DeviceRequester) error {
+	return d.decide(ctx, userCode, request, fosite.UserCodeStateRejected)
+}
+
+func (d *DeviceAuthHandler) decide(ctx context.Context, userCode string, request fosite.DeviceRequester, state fosite.UserCodeState) error {
+	signature, err := d.Strategy.UserCodeSignature(ctx, userCode)
+	if err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	request.SetUserCodeState(state)
+	if err := d.Storage.UpdateDeviceCodeSessionByRequestID(ctx, request.GetID(), request); err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	// The user code is single use, no matter whether the request was accepted or rejected.
+	if err := d.Storage.InvalidateUserCodeSession(ctx, signature); err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	return nil
+}
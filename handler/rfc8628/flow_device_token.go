@@ -0,0 +1,226 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package rfc8628
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ory/x/errorsx"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/oauth2"
+	"github.com/ory/fosite/storage"
+)
+
+// DeviceCodeTokenEndpointHandler exchanges an approved device code for tokens, see
+// https://datatracker.ietf.org/doc/html/rfc8628#section-3.4
+type DeviceCodeTokenEndpointHandler struct {
+	DeviceCodeStrategy   DeviceCodeStrategy
+	AccessTokenStrategy  oauth2.AccessTokenStrategy
+	RefreshTokenStrategy oauth2.RefreshTokenStrategy
+
+	Storage interface {
+		DeviceCodeStorage
+		oauth2.AccessTokenStorage
+		oauth2.RefreshTokenStorage
+	}
+
+	Config interface {
+		fosite.AccessTokenLifespanProvider
+		fosite.RefreshTokenLifespanProvider
+		fosite.RefreshTokenScopesProvider
+		fosite.DeviceAuthorizeConfigProvider
+		oauth2.PublicClientRefreshTokenConfigProvider
+		fosite.EmptyClientGrantTypesPolicyProvider
+	}
+}
+
+var _ fosite.TokenEndpointHandler = (*DeviceCodeTokenEndpointHandler)(nil)
+
+// HandleTokenEndpointRequest implements https://datatracker.ietf.org/doc/html/rfc8628#section-3.5
+func (c *DeviceCodeTokenEndpointHandler) HandleTokenEndpointRequest(ctx context.Context, request fosite.AccessRequester) error {
+	if !c.CanHandleTokenEndpointRequest(ctx, request) {
+		return errorsx.WithStack(fosite.ErrUnknownRequest)
+	}
+
+	if !fosite.ClientHasGrantType(ctx, c.Config, request.GetClient(), string(fosite.GrantTypeDeviceCode)) {
+		return errorsx.WithStack(fosite.ErrUnauthorizedClient.WithHintf("The OAuth 2.0 Client is not allowed to use authorization grant '%s'.", fosite.GrantTypeDeviceCode))
+	}
+
+	code := request.GetRequestForm().Get("device_code")
+	if code == "" {
+		return errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("The 'device_code' request parameter must be set."))
+	}
+
+	signature, deviceRequest, err := c.getDeviceCodeSession(ctx, request, code)
+	if err != nil {
+		return err
+	}
+
+	if deviceRequest.GetClient().GetID() != request.GetClient().GetID() {
+		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The OAuth 2.0 Client ID from this request does not match the one from the device authorization request."))
+	}
+
+	// A client polling faster than the interval is told to slow down. The poll is recorded either way, so that the
+	// client has to wait for a full interval after each request.
+	now := time.Now().UTC()
+	lastPolledAt := deviceRequest.GetLastPolledAt()
+	if err := c.Storage.UpdateDeviceCodeSessionLastPolledAt(ctx, signature, now); err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	if !lastPolledAt.IsZero() && now.Before(lastPolledAt.Add(c.Config.GetDeviceAuthTokenPollingInterval(ctx))) {
+		return errorsx.WithStack(fosite.ErrSlowDown)
+	}
+
+	switch deviceRequest.GetUserCodeState() {
+	case fosite.UserCodeStateAccepted:
+	case fosite.UserCodeStateRejected:
+		// The denial is final, so the device code is invalidated and subsequent polls fail with invalid_grant.
+		if err := c.Storage.InvalidateDeviceCodeSession(ctx, signature); err != nil {
+			return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+		}
+		return errorsx.WithStack(fosite.ErrAccessDenied.WithHint("The end user denied the device authorization request."))
+	default:
+		return errorsx.WithStack(fosite.ErrAuthorizationPending)
+	}
+
+	request.SetID(deviceRequest.GetID())
+	request.SetSession(deviceRequest.GetSession())
+	request.SetRequestedScopes(deviceRequest.GetRequestedScopes())
+	request.SetRequestedAudience(deviceRequest.GetRequestedAudience())
+	for _, scope := range deviceRequest.GetGrantedScopes() {
+		request.GrantScope(scope)
+	}
+
+	for _, audience := range deviceRequest.GetGrantedAudience() {
+		request.GrantAudience(audience)
+	}
+
+	fosite.GrantAuthorizationDetailsOf(request, deviceRequest)
+
+	// The device code must not be exchanged twice, so it is invalidated before any token is issued.
+	if err := c.Storage.InvalidateDeviceCodeSession(ctx, signature); err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	atLifespan := fosite.GetEffectiveLifespan(request.GetClient(), fosite.GrantTypeDeviceCode, fosite.AccessToken, c.Config.GetAccessTokenLifespan(ctx))
+	request.GetSession().SetExpiresAt(fosite.AccessToken, now.Add(atLifespan).Round(time.Second))
+
+	rtLifespan := fosite.GetEffectiveLifespan(request.GetClient(), fosite.GrantTypeDeviceCode, fosite.RefreshToken, c.Config.GetRefreshTokenLifespan(ctx))
+	if rtLifespan > -1 {
+		request.GetSession().SetExpiresAt(fosite.RefreshToken, now.Add(rtLifespan).Round(time.Second))
+	}
+
+	return nil
+}
+
+// PopulateTokenEndpointResponse implements https://datatracker.ietf.org/doc/html/rfc8628#section-3.5
+func (c *DeviceCodeTokenEndpointHandler) PopulateTokenEndpointResponse(ctx context.Context, requester fosite.AccessRequester, responder fosite.AccessResponder) (err error) {
+	if !c.CanHandleTokenEndpointRequest(ctx, requester) {
+		return errorsx.WithStack(fosite.ErrUnknownRequest)
+	}
+
+	access, accessSignature, err := c.AccessTokenStrategy.GenerateAccessToken(ctx, requester)
+	if rfcErr := new(fosite.RFC6749Error); errors.As(err, &rfcErr) {
+		return err
+	} else if err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	var refresh, refreshSignature string
+	if c.canIssueRefreshToken(ctx, requester) {
+		refresh, refreshSignature, err = c.RefreshTokenStrategy.GenerateRefreshToken(ctx, requester)
+		if err != nil {
+			return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+		}
+	}
+
+	ctx, err = storage.MaybeBeginTx(ctx, c.Storage)
+	if err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+	defer func() {
+		if err != nil {
+			if rollBackTxnErr := storage.MaybeRollbackTx(ctx, c.Storage); rollBackTxnErr != nil {
+				err = errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebugf("error: %s; rollback error: %s", err, rollBackTxnErr))
+			}
+		}
+	}()
+
+	if err = c.Storage.CreateAccessTokenSession(ctx, accessSignature, requester.Sanitize([]string{})); err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	} else if refreshSignature != "" {
+		if err = c.Storage.CreateRefreshTokenSession(ctx, refreshSignature, requester.Sanitize([]string{})); err != nil {
+			return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+		}
+	}
+
+	responder.SetAccessToken(access)
+	responder.SetTokenType("bearer")
+	responder.SetExpiresIn(time.Until(requester.GetSession().GetExpiresAt(fosite.AccessToken)))
+	responder.SetScopes(requester.GetGrantedScopes())
+	if refresh != "" {
+		responder.SetExtra("refresh_token", refresh)
+	}
+	fosite.SetTokenSignature(responder, fosite.AccessToken, accessSignature)
+	fosite.SetTokenSignature(responder, fosite.RefreshToken, refreshSignature)
+
+	if err = storage.MaybeCommitTx(ctx, c.Storage); err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	return nil
+}
+
+func (c *DeviceCodeTokenEndpointHandler) CanSkipClientAuth(ctx context.Context, requester fosite.AccessRequester) bool {
+	return false
+}
+
+func (c *DeviceCodeTokenEndpointHandler) CanHandleTokenEndpointRequest(ctx context.Context, requester fosite.AccessRequester) bool {
+	// grant_type REQUIRED.
+	// Value MUST be set to "urn:ietf:params:oauth:grant-type:device_code"
+	return requester.GetGrantTypes().ExactOne(string(fosite.GrantTypeDeviceCode))
+}
+
+func (c *DeviceCodeTokenEndpointHandler) HandledGrantTypes() []fosite.GrantType {
+	return []fosite.GrantType{fosite.GrantTypeDeviceCode}
+}
+
+func (c *DeviceCodeTokenEndpointHandler) getDeviceCodeSession(ctx context.Context, request fosite.AccessRequester, code string) (string, fosite.DeviceRequester, error) {
+	signature, err := c.DeviceCodeStrategy.DeviceCodeSignature(ctx, code)
+	if err != nil {
+		return "", nil, errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	deviceRequest, err := c.Storage.GetDeviceCodeSession(ctx, signature, request.GetSession())
+	if errors.Is(err, fosite.ErrNotFound) {
+		return "", nil, errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The device code is unknown or has already been used.").WithWrap(err).WithDebug(err.Error()))
+	} else if err != nil {
+		return "", nil, errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	// This needs to happen after store retrieval for the session to be hydrated properly
+	if err := c.DeviceCodeStrategy.ValidateDeviceCode(ctx, deviceRequest, code); errors.Is(err, fosite.ErrDeviceExpiredToken) {
+		return "", nil, err
+	} else if err != nil {
+		return "", nil, errorsx.WithStack(fosite.ErrInvalidGrant.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	return signature, deviceRequest, nil
+}
+
+func (c *DeviceCodeTokenEndpointHandler) canIssueRefreshToken(ctx context.Context, request fosite.Requester) bool {
+	// Require one of the refresh token scopes, if set.
+	if scope := c.Config.GetRefreshTokenScopes(ctx); len(scope) > 0 && !request.GetGrantedScopes().HasOneOf(scope...) {
+		return false
+	}
+	// Do not issue a refresh token to clients that cannot use the refresh token grant type.
+	if !fosite.ClientHasGrantType(ctx, c.Config, request.GetClient(), "refresh_token") {
+		return false
+	}
+	return oauth2.CanIssueRefreshTokenToClient(ctx, c.Config, request.GetClient())
+}
@@ -0,0 +1,188 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package rfc8628
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/oauth2"
+	"github.com/ory/fosite/storage"
+	"github.com/ory/fosite/token/hmac"
+)
+
+func TestDeviceCodeTokenEndpointHandler(t *testing.T) {
+	ctx := context.Background()
+	config := &fosite.Config{
+		DeviceAuthTokenPollingInterval: time.Hour,
+		RefreshTokenScopes:             []string{"offline"},
+		AccessTokenLifespan:            time.Hour,
+	}
+	store := storage.NewMemoryStore()
+	coreStrategy := oauth2.NewHMACSHAStrategy(&hmac.HMACStrategy{Config: &fosite.Config{GlobalSecret: []byte("foobarfoobarfoobarfoobarfoobarfoobarfoobarfoobar")}}, nil)
+
+	auth := &DeviceAuthHandler{Strategy: hmacDeviceStrategy, Storage: store, Config: config}
+	h := &DeviceCodeTokenEndpointHandler{
+		DeviceCodeStrategy:   hmacDeviceStrategy,
+		AccessTokenStrategy:  coreStrategy,
+		RefreshTokenStrategy: coreStrategy,
+		Storage:              store,
+		Config:               config,
+	}
+
+	client := &fosite.DefaultClient{ID: "foo", GrantTypes: fosite.Arguments{string(fosite.GrantTypeDeviceCode), "refresh_token"}}
+
+	authorize := func(t *testing.T) *fosite.DeviceResponse {
+		dar := fosite.NewDeviceRequest()
+		dar.Client = client
+		dar.Session = &fosite.DefaultSession{}
+		dar.RequestedScope = fosite.Arguments{"foo", "offline"}
+
+		resp := &fosite.DeviceResponse{}
+		require.NoError(t, auth.HandleDeviceEndpointRequest(ctx, dar, resp))
+		return resp
+	}
+
+	newAccessRequest := func(deviceCode string) *fosite.AccessRequest {
+		ar := fosite.NewAccessRequest(&fosite.DefaultSession{})
+		ar.GrantTypes = fosite.Arguments{string(fosite.GrantTypeDeviceCode)}
+		ar.Client = client
+		ar.Form = url.Values{"device_code": {deviceCode}}
+		return ar
+	}
+
+	accept := func(t *testing.T, userCode string) {
+		r, err := auth.GetUserCodeRequest(ctx, userCode, &fosite.DefaultSession{})
+		require.NoError(t, err)
+		r.SetSession(&fosite.DefaultSession{Subject: "peter"})
+		r.GrantScope("foo")
+		r.GrantScope("offline")
+		require.NoError(t, auth.AcceptUserCode(ctx, userCode, r))
+	}
+
+	t.Run("case=should not handle other grant types", func(t *testing.T) {
+		ar := newAccessRequest("")
+		ar.GrantTypes = fosite.Arguments{"authorization_code"}
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, ar), fosite.ErrUnknownRequest)
+		assert.ErrorIs(t, h.PopulateTokenEndpointResponse(ctx, ar, fosite.NewAccessResponse()), fosite.ErrUnknownRequest)
+	})
+
+	t.Run("case=should fail because the client may not use the grant", func(t *testing.T) {
+		ar := newAccessRequest("foo")
+		ar.Client = &fosite.DefaultClient{ID: "foo", GrantTypes: fosite.Arguments{"authorization_code"}}
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, ar), fosite.ErrUnauthorizedClient)
+	})
+
+	t.Run("case=should fail because the device code is missing", func(t *testing.T) {
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest("")), fosite.ErrInvalidRequest)
+	})
+
+	t.Run("case=should fail because the device code is unknown", func(t *testing.T) {
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest("foo.bar")), fosite.ErrInvalidGrant)
+	})
+
+	t.Run("case=should fail because the device code belongs to another client", func(t *testing.T) {
+		resp := authorize(t)
+		ar := newAccessRequest(resp.GetDeviceCode())
+		ar.Client = &fosite.DefaultClient{ID: "bar", GrantTypes: client.GrantTypes}
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, ar), fosite.ErrInvalidGrant)
+	})
+
+	t.Run("case=should be pending and then ask the client to slow down", func(t *testing.T) {
+		resp := authorize(t)
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest(resp.GetDeviceCode())), fosite.ErrAuthorizationPending)
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest(resp.GetDeviceCode())), fosite.ErrSlowDown)
+	})
+
+	t.Run("case=should not overwrite a decision made while polling", func(t *testing.T) {
+		resp := authorize(t)
+		racing := &racingDeviceCodeStore{MemoryStore: store, decide: func() { accept(t, resp.GetUserCode()) }}
+		h := *h
+		h.Storage = racing
+
+		// The poll still sees the pending request it read before the decision.
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest(resp.GetDeviceCode())), fosite.ErrAuthorizationPending)
+
+		signature, err := hmacDeviceStrategy.DeviceCodeSignature(ctx, resp.GetDeviceCode())
+		require.NoError(t, err)
+		r, err := store.GetDeviceCodeSession(ctx, signature, nil)
+		require.NoError(t, err)
+		assert.Equal(t, fosite.UserCodeStateAccepted, r.GetUserCodeState())
+		assert.False(t, r.GetLastPolledAt().IsZero())
+	})
+
+	t.Run("case=should deny access because the user code was rejected", func(t *testing.T) {
+		resp := authorize(t)
+		r, err := auth.GetUserCodeRequest(ctx, resp.GetUserCode(), &fosite.DefaultSession{})
+		require.NoError(t, err)
+		require.NoError(t, auth.RejectUserCode(ctx, resp.GetUserCode(), r))
+
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest(resp.GetDeviceCode())), fosite.ErrAccessDenied)
+
+		// The device code is invalidated by the denial.
+		signature, err := hmacDeviceStrategy.DeviceCodeSignature(ctx, resp.GetDeviceCode())
+		require.NoError(t, err)
+		_, err = store.GetDeviceCodeSession(ctx, signature, nil)
+		assert.ErrorIs(t, err, fosite.ErrNotFound)
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest(resp.GetDeviceCode())), fosite.ErrInvalidGrant)
+	})
+
+	t.Run("case=should fail because the device code expired", func(t *testing.T) {
+		resp := authorize(t)
+		accept(t, resp.GetUserCode())
+
+		signature, err := hmacDeviceStrategy.DeviceCodeSignature(ctx, resp.GetDeviceCode())
+		require.NoError(t, err)
+		r, err := store.GetDeviceCodeSession(ctx, signature, nil)
+		require.NoError(t, err)
+		r.GetSession().SetExpiresAt(fosite.DeviceCode, time.Now().UTC().Add(-time.Minute))
+
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest(resp.GetDeviceCode())), fosite.ErrDeviceExpiredToken)
+	})
+
+	t.Run("case=should issue tokens because the user code was accepted", func(t *testing.T) {
+		resp := authorize(t)
+		accept(t, resp.GetUserCode())
+
+		_, err := auth.GetUserCodeRequest(ctx, resp.GetUserCode(), &fosite.DefaultSession{})
+		assert.ErrorIs(t, err, fosite.ErrInvalidGrant)
+
+		ar := newAccessRequest(resp.GetDeviceCode())
+		require.NoError(t, h.HandleTokenEndpointRequest(ctx, ar))
+		assert.Equal(t, "peter", ar.GetSession().GetSubject())
+		assert.Equal(t, fosite.Arguments{"foo", "offline"}, ar.GetGrantedScopes())
+
+		aresp := fosite.NewAccessResponse()
+		require.NoError(t, h.PopulateTokenEndpointResponse(ctx, ar, aresp))
+		assert.NotEmpty(t, aresp.GetAccessToken())
+		assert.NotEmpty(t, aresp.GetExtra("refresh_token"))
+		assert.Equal(t, "bearer", aresp.GetTokenType())
+
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest(resp.GetDeviceCode())), fosite.ErrInvalidGrant)
+	})
+}
+
+// racingDeviceCodeStore returns a copy of the device authorization request and decides it right after, as if the end
+// user decided while the client was polling.
+type racingDeviceCodeStore struct {
+	*storage.MemoryStore
+	decide func()
+}
+
+func (s *racingDeviceCodeStore) GetDeviceCodeSession(ctx context.Context, signature string, session fosite.Session) (fosite.DeviceRequester, error) {
+	r, err := s.MemoryStore.GetDeviceCodeSession(ctx, signature, session)
+	if err != nil {
+		return nil, err
+	}
+
+	stale := *r.(*fosite.DeviceRequest)
+	s.decide()
+	return &stale, nil
+}
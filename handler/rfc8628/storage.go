@@ -0,0 +1,50 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package rfc8628
+
+import (
+	"context"
+	"time"
+
+	"github.com/ory/fosite"
+)
+
+// DeviceCodeStorage stores the device authorization requests, keyed by the device code signature.
+type DeviceCodeStorage interface {
+	// CreateDeviceCodeSession stores the device authorization request.
+	CreateDeviceCodeSession(ctx context.Context, signature string, request fosite.DeviceRequester) (err error)
+
+	// GetDeviceCodeSession returns the device authorization request or fosite.ErrNotFound.
+	GetDeviceCodeSession(ctx context.Context, signature string, session fosite.Session) (request fosite.DeviceRequester, err error)
+
+	// UpdateDeviceCodeSessionByRequestID replaces the device authorization request with the given request ID, for
+	// example once the end user accepted or rejected it.
+	UpdateDeviceCodeSessionByRequestID(ctx context.Context, requestID string, request fosite.DeviceRequester) (err error)
+
+	// UpdateDeviceCodeSessionLastPolledAt records when the client last polled the token endpoint with the device
+	// code. Only the poll time is updated, so that a concurrent decision of the end user is not overwritten.
+	UpdateDeviceCodeSessionLastPolledAt(ctx context.Context, signature string, lastPolledAt time.Time) (err error)
+
+	// InvalidateDeviceCodeSession invalidates the device code once it has been exchanged for tokens or the denial of
+	// the end user has been reported to the client.
+	InvalidateDeviceCodeSession(ctx context.Context, signature string) (err error)
+}
+
+// UserCodeStorage stores the device authorization requests, keyed by the user code signature.
+type UserCodeStorage interface {
+	// CreateUserCodeSession stores the device authorization request.
+	CreateUserCodeSession(ctx context.Context, signature string, request fosite.DeviceRequester) (err error)
+
+	// GetUserCodeSession returns the device authorization request or fosite.ErrNotFound.
+	GetUserCodeSession(ctx context.Context, signature string, session fosite.Session) (request fosite.DeviceRequester, err error)
+
+	// InvalidateUserCodeSession invalidates the user code once the end user accepted or rejected the request.
+	InvalidateUserCodeSession(ctx context.Context, signature string) (err error)
+}
+
+// RFC8628CodeStorage stores the state of device authorization requests.
+type RFC8628CodeStorage interface {
+	DeviceCodeStorage
+	UserCodeStorage
+}
@@ -0,0 +1,88 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package rfc8628
+
+import (
+	"context"
+	"time"
+
+	"github.com/ory/x/errorsx"
+
+	"github.com/ory/fosite"
+	enigma "github.com/ory/fosite/token/hmac"
+)
+
+var _ RFC8628CodeStrategy = (*DefaultDeviceStrategy)(nil)
+
+// DefaultDeviceStrategy generates HMAC-SHA based device codes and short, human readable user codes.
+type DefaultDeviceStrategy struct {
+	Enigma *enigma.HMACStrategy
+	Config fosite.DeviceAuthorizeConfigProvider
+
+	// UserCodeFormatter generates and normalizes the user codes. Defaults to eight base-20 consonants without
+	// separators, generated using the random source of the configuration.
+	UserCodeFormatter UserCodeFormatter
+}
+
+func (h *DefaultDeviceStrategy) DeviceCodeSignature(ctx context.Context, code string) (string, error) {
+	return h.Enigma.Signature(code), nil
+}
+
+func (h *DefaultDeviceStrategy) GenerateDeviceCode(ctx context.Context) (string, string, error) {
+	return h.Enigma.Generate(ctx)
+}
+
+func (h *DefaultDeviceStrategy) ValidateDeviceCode(ctx context.Context, r fosite.Requester, code string) error {
+	if err := h.validateExpiry(ctx, r, fosite.DeviceCode); err != nil {
+		return err
+	}
+
+	return h.Enigma.Validate(ctx, code)
+}
+
+func (h *DefaultDeviceStrategy) UserCodeSignature(ctx context.Context, code string) (string, error) {
+	return h.Enigma.GenerateHMACForString(ctx, h.getUserCodeFormatter(ctx).NormalizeUserCode(ctx, code))
+}
+
+func (h *DefaultDeviceStrategy) GenerateUserCode(ctx context.Context) (string, string, error) {
+	code, err := h.getUserCodeFormatter(ctx).GenerateUserCode(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	signature, err := h.UserCodeSignature(ctx, code)
+	if err != nil {
+		return "", "", err
+	}
+
+	return code, signature, nil
+}
+
+func (h *DefaultDeviceStrategy) ValidateUserCode(ctx context.Context, r fosite.Requester, code string) error {
+	return h.validateExpiry(ctx, r, fosite.UserCode)
+}
+
+func (h *DefaultDeviceStrategy) validateExpiry(ctx context.Context, r fosite.Requester, key fosite.TokenType) error {
+	exp := r.GetSession().GetExpiresAt(key)
+	if exp.IsZero() {
+		exp = r.GetRequestedAt().Add(h.Config.GetDeviceAndUserCodeLifespan(ctx))
+	}
+
+	if exp.Before(time.Now().UTC()) {
+		return errorsx.WithStack(fosite.ErrDeviceExpiredToken.WithHintf("The %s expired at '%s'.", key, exp))
+	}
+
+	return nil
+}
+
+func (h *DefaultDeviceStrategy) getUserCodeFormatter(ctx context.Context) UserCodeFormatter {
+	if h.UserCodeFormatter == nil {
+		formatter := &DefaultUserCodeFormatter{}
+		if p, ok := h.Config.(fosite.RandomSourceProvider); ok {
+			formatter.Rand = p.GetRandomSource(ctx)
+		}
+		return formatter
+	}
+	return h.UserCodeFormatter
+}
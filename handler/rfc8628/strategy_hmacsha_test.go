@@ -0,0 +1,104 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package rfc8628
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/token/hmac"
+)
+
+var hmacDeviceStrategy = &DefaultDeviceStrategy{
+	Enigma: &hmac.HMACStrategy{Config: &fosite.Config{GlobalSecret: []byte("foobarfoobarfoobarfoobarfoobarfoobarfoobarfoobar")}},
+	Config: &fosite.Config{DeviceAndUserCodeLifespan: time.Minute},
+}
+
+func TestDefaultDeviceStrategy_UserCode(t *testing.T) {
+	ctx := context.Background()
+
+	code, signature, err := hmacDeviceStrategy.GenerateUserCode(ctx)
+	require.NoError(t, err)
+	require.Len(t, code, userCodeLength)
+	for _, c := range code {
+		assert.True(t, strings.ContainsRune(userCodeCharset, c), "unexpected character %q", c)
+	}
+
+	for _, typed := range []string{code, strings.ToLower(code), code[:4] + "-" + code[4:], " " + code[:4] + " " + code[4:]} {
+		s, err := hmacDeviceStrategy.UserCodeSignature(ctx, typed)
+		require.NoError(t, err)
+		assert.Equal(t, signature, s, "%s", typed)
+	}
+
+	other, _, err := hmacDeviceStrategy.GenerateUserCode(ctx)
+	require.NoError(t, err)
+	assert.NotEqual(t, code, other)
+}
+
+func TestDefaultDeviceStrategy_ValidateExpiry(t *testing.T) {
+	ctx := context.Background()
+	deviceCode, signature, err := hmacDeviceStrategy.GenerateDeviceCode(ctx)
+	require.NoError(t, err)
+
+	s, err := hmacDeviceStrategy.DeviceCodeSignature(ctx, deviceCode)
+	require.NoError(t, err)
+	assert.Equal(t, signature, s)
+
+	for _, c := range []struct {
+		description string
+		expiresAt   time.Time
+		requestedAt time.Time
+		expectErr   error
+	}{
+		{
+			description: "should pass because the session has not expired",
+			expiresAt:   time.Now().UTC().Add(time.Minute),
+			requestedAt: time.Now().UTC(),
+		},
+		{
+			description: "should fail because the session has expired",
+			expiresAt:   time.Now().UTC().Add(-time.Minute),
+			requestedAt: time.Now().UTC(),
+			expectErr:   fosite.ErrDeviceExpiredToken,
+		},
+		{
+			description: "should pass because the lifespan has not passed since the request",
+			requestedAt: time.Now().UTC(),
+		},
+		{
+			description: "should fail because the lifespan has passed since the request",
+			requestedAt: time.Now().UTC().Add(-time.Hour),
+			expectErr:   fosite.ErrDeviceExpiredToken,
+		},
+	} {
+		t.Run("case="+c.description, func(t *testing.T) {
+			r := fosite.NewDeviceRequest()
+			r.RequestedAt = c.requestedAt
+			r.Session = &fosite.DefaultSession{ExpiresAt: map[fosite.TokenType]time.Time{
+				fosite.DeviceCode: c.expiresAt,
+				fosite.UserCode:   c.expiresAt,
+			}}
+
+			err := hmacDeviceStrategy.ValidateDeviceCode(ctx, r, deviceCode)
+			if c.expectErr != nil {
+				assert.ErrorIs(t, err, c.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			err = hmacDeviceStrategy.ValidateUserCode(ctx, r, "")
+			if c.expectErr != nil {
+				assert.ErrorIs(t, err, c.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
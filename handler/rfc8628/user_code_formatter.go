@@ -0,0 +1,111 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package rfc8628
+
+import (
+	"context"
+	"crypto/rand"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/ory/x/errorsx"
+)
+
+// userCodeCharset consists of base-20 consonants which are easy to type and can not form words, see
+// https://datatracker.ietf.org/doc/html/rfc8628#section-6.1
+const userCodeCharset = "BCDFGHJKLMNPQRSTVWXZ"
+
+const userCodeLength = 8
+
+// UserCodeFormatter generates the user codes shown to end users and normalizes the user codes they enter, so that a
+// code typed with different separators or case still matches the generated one.
+type UserCodeFormatter interface {
+	// GenerateUserCode returns a new random user code, formatted for display to the end user.
+	GenerateUserCode(ctx context.Context) (string, error)
+
+	// NormalizeUserCode returns the canonical form of a user code entered by the end user. The generated user code
+	// and every way of entering it must normalize to the same value.
+	NormalizeUserCode(ctx context.Context, code string) string
+}
+
+var _ UserCodeFormatter = (*DefaultUserCodeFormatter)(nil)
+
+// DefaultUserCodeFormatter generates user codes of random characters of a charset, optionally split into groups, for
+// example "WDJB-MJHT" or "123-456-789".
+type DefaultUserCodeFormatter struct {
+	// Charset is the set of characters user codes consist of. Defaults to base-20 consonants.
+	Charset string
+
+	// Length is the number of characters of a user code, not counting separators. Defaults to eight.
+	Length int
+
+	// GroupSize splits the user code into groups of this many characters. Defaults to zero, which does not split it.
+	GroupSize int
+
+	// Separator is placed between the groups of a user code. Defaults to "-".
+	Separator string
+
+	// Rand is the source of randomness of the user codes. It must be safe for concurrent use. Defaults to
+	// crypto/rand.Reader.
+	Rand io.Reader
+}
+
+func (f *DefaultUserCodeFormatter) GenerateUserCode(_ context.Context) (string, error) {
+	charset := f.getCharset()
+	max := big.NewInt(int64(len(charset)))
+
+	var code strings.Builder
+	for i := 0; i < f.getLength(); i++ {
+		if i > 0 && f.GroupSize > 0 && i%f.GroupSize == 0 {
+			code.WriteString(f.getSeparator())
+		}
+
+		n, err := rand.Int(f.getRand(), max)
+		if err != nil {
+			return "", errorsx.WithStack(err)
+		}
+		code.WriteByte(charset[n.Int64()])
+	}
+
+	return code.String(), nil
+}
+
+// NormalizeUserCode removes the separators and spaces, and unless the charset contains lower case characters, the
+// case differences end users are likely to introduce when typing the user code.
+func (f *DefaultUserCodeFormatter) NormalizeUserCode(_ context.Context, code string) string {
+	code = strings.NewReplacer(f.getSeparator(), "", "-", "", " ", "").Replace(code)
+	if charset := f.getCharset(); strings.ToUpper(charset) == charset {
+		code = strings.ToUpper(code)
+	}
+	return code
+}
+
+func (f *DefaultUserCodeFormatter) getRand() io.Reader {
+	if f.Rand == nil {
+		return rand.Reader
+	}
+	return f.Rand
+}
+
+func (f *DefaultUserCodeFormatter) getCharset() string {
+	if f.Charset == "" {
+		return userCodeCharset
+	}
+	return f.Charset
+}
+
+func (f *DefaultUserCodeFormatter) getLength() int {
+	if f.Length <= 0 {
+		return userCodeLength
+	}
+	return f.Length
+}
+
+func (f *DefaultUserCodeFormatter) getSeparator() string {
+	if f.Separator == "" {
+		return "-"
+	}
+	return f.Separator
+}
@@ -0,0 +1,97 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package rfc8628
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/token/hmac"
+)
+
+func TestDefaultUserCodeFormatterRandomSource(t *testing.T) {
+	ctx := context.Background()
+
+	a, err := (&DefaultUserCodeFormatter{Rand: bytes.NewReader(make([]byte, 64))}).GenerateUserCode(ctx)
+	require.NoError(t, err)
+	b, err := (&DefaultUserCodeFormatter{Rand: bytes.NewReader(make([]byte, 64))}).GenerateUserCode(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, a, b)
+
+	strategy := &DefaultDeviceStrategy{
+		Enigma: &hmac.HMACStrategy{Config: &fosite.Config{GlobalSecret: []byte("foobarfoobarfoobarfoobarfoobarfoobarfoobarfoobar")}},
+		Config: &fosite.Config{RandomSource: iotest.ErrReader(errors.New("the random number generator failed"))},
+	}
+	_, _, err = strategy.GenerateUserCode(ctx)
+	assert.Error(t, err)
+}
+
+func TestDefaultUserCodeFormatter(t *testing.T) {
+	ctx := context.Background()
+
+	for k, c := range []struct {
+		d         string
+		formatter *DefaultUserCodeFormatter
+		pattern   string
+		entered   func(code string) []string
+	}{
+		{
+			d:         "grouped consonants",
+			formatter: &DefaultUserCodeFormatter{GroupSize: 4},
+			pattern:   "^[BCDFGHJKLMNPQRSTVWXZ]{4}-[BCDFGHJKLMNPQRSTVWXZ]{4}$",
+			entered: func(code string) []string {
+				return []string{code, strings.ToLower(code), strings.ReplaceAll(code, "-", ""), strings.ReplaceAll(code, "-", " ")}
+			},
+		},
+		{
+			d:         "grouped digits",
+			formatter: &DefaultUserCodeFormatter{Charset: "0123456789", Length: 9, GroupSize: 3},
+			pattern:   "^[0-9]{3}-[0-9]{3}-[0-9]{3}$",
+			entered: func(code string) []string {
+				return []string{code, strings.ReplaceAll(code, "-", ""), strings.ReplaceAll(code, "-", " ")}
+			},
+		},
+		{
+			d:         "custom separator",
+			formatter: &DefaultUserCodeFormatter{Length: 6, GroupSize: 2, Separator: "."},
+			pattern:   `^[BCDFGHJKLMNPQRSTVWXZ]{2}\.[BCDFGHJKLMNPQRSTVWXZ]{2}\.[BCDFGHJKLMNPQRSTVWXZ]{2}$`,
+			entered: func(code string) []string {
+				return []string{code, strings.ReplaceAll(code, ".", ""), strings.ReplaceAll(code, ".", "-")}
+			},
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.d), func(t *testing.T) {
+			strategy := &DefaultDeviceStrategy{
+				Enigma:            &hmac.HMACStrategy{Config: &fosite.Config{GlobalSecret: []byte("foobarfoobarfoobarfoobarfoobarfoobarfoobarfoobar")}},
+				Config:            &fosite.Config{DeviceAndUserCodeLifespan: time.Minute},
+				UserCodeFormatter: c.formatter,
+			}
+
+			code, signature, err := strategy.GenerateUserCode(ctx)
+			require.NoError(t, err)
+			assert.Regexp(t, regexp.MustCompile(c.pattern), code)
+
+			for _, typed := range c.entered(code) {
+				s, err := strategy.UserCodeSignature(ctx, typed)
+				require.NoError(t, err)
+				assert.Equal(t, signature, s, "%s", typed)
+			}
+
+			other, err := strategy.UserCodeSignature(ctx, "WRONG-CODE")
+			require.NoError(t, err)
+			assert.NotEqual(t, signature, other)
+		})
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package rfc8693
+
+import (
+	"context"
+	"time"
+
+	"github.com/ory/x/errorsx"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/oauth2"
+)
+
+// Token type identifiers as defined in https://datatracker.ietf.org/doc/html/rfc8693#section-3
+const (
+	AccessTokenType  = "urn:ietf:params:oauth:token-type:access_token"  //nolint:gosec // this is not a hardcoded credential
+	RefreshTokenType = "urn:ietf:params:oauth:token-type:refresh_token" //nolint:gosec // this is not a hardcoded credential
+	IDTokenType      = "urn:ietf:params:oauth:token-type:id_token"      //nolint:gosec // this is not a hardcoded credential
+	JWTTokenType     = "urn:ietf:params:oauth:token-type:jwt"           //nolint:gosec // this is not a hardcoded credential
+)
+
+const actorClaim = "act"
+
+type Handler struct {
+	TokenValidator TokenValidator
+
+	Config interface {
+		fosite.AccessTokenLifespanProvider
+		fosite.ScopeStrategyProvider
+		fosite.AudienceStrategyProvider
+		fosite.SubjectValidatorProvider
+		fosite.EmptyClientGrantTypesPolicyProvider
+		fosite.TokenExchangeRequestedTokenTypesProvider
+	}
+
+	*oauth2.HandleHelper
+}
+
+var _ fosite.TokenEndpointHandler = (*Handler)(nil)
+
+// HandleTokenEndpointRequest implements https://datatracker.ietf.org/doc/html/rfc8693#section-2.1
+func (c *Handler) HandleTokenEndpointRequest(ctx context.Context, request fosite.AccessRequester) error {
+	if !c.CanHandleTokenEndpointRequest(ctx, request) {
+		return errorsx.WithStack(fosite.ErrUnknownRequest)
+	}
+
+	client := request.GetClient()
+	if !fosite.ClientHasGrantType(ctx, c.Config, client, string(fosite.GrantTypeTokenExchange)) {
+		return errorsx.WithStack(fosite.ErrUnauthorizedClient.WithHintf("The OAuth 2.0 Client is not allowed to use authorization grant '%s'.", fosite.GrantTypeTokenExchange))
+	}
+
+	form := request.GetRequestForm()
+	subjectToken, subjectTokenType := form.Get("subject_token"), form.Get("subject_token_type")
+	if subjectToken == "" || subjectTokenType == "" {
+		return errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("The 'subject_token' and 'subject_token_type' request parameters must be set when using grant_type of '%s'.", fosite.GrantTypeTokenExchange))
+	}
+
+	if tokenType := form.Get("requested_token_type"); tokenType != "" && !c.isRequestedTokenTypeAllowed(ctx, tokenType) {
+		return errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("Requested token type '%s' is not supported.", tokenType))
+	}
+
+	subject, err := c.validateToken(ctx, request, subjectToken, subjectTokenType, "subject_token")
+	if err != nil {
+		return err
+	}
+
+	claims, err := oauth2.ExtraClaims(request.GetSession())
+	if err != nil {
+		return err
+	}
+
+	actorToken, actorTokenType := form.Get("actor_token"), form.Get("actor_token_type")
+	if actorToken != "" || actorTokenType != "" {
+		if actorToken == "" || actorTokenType == "" {
+			return errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("The 'actor_token' and 'actor_token_type' request parameters must be set together."))
+		}
+
+		actor, err := c.validateToken(ctx, request, actorToken, actorTokenType, "actor_token")
+		if err != nil {
+			return err
+		}
+
+		// Prior actors of the subject token are retained as nested "act" claims, see
+		// https://datatracker.ietf.org/doc/html/rfc8693#section-4.1
+		act := map[string]interface{}{"sub": actor.GetSession().GetSubject()}
+		if subjectClaims, err := oauth2.ExtraClaims(subject.GetSession()); err == nil && subjectClaims[actorClaim] != nil {
+			act[actorClaim] = subjectClaims[actorClaim]
+		}
+		claims[actorClaim] = act
+	}
+
+	scopes := request.GetRequestedScopes()
+	if len(scopes) == 0 {
+		// Without an explicit scope, the issued token carries all scopes of the subject token which the client is
+		// allowed to request.
+		for _, scope := range subject.GetGrantedScopes() {
+			if c.Config.GetScopeStrategy(ctx)(client.GetScopes(), scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+
+	for _, scope := range scopes {
+		if !c.Config.GetScopeStrategy(ctx)(subject.GetGrantedScopes(), scope) {
+			return errorsx.WithStack(fosite.ErrInvalidScope.WithHintf("The subject token has not been granted scope '%s'.", scope))
+		}
+		if !c.Config.GetScopeStrategy(ctx)(client.GetScopes(), scope) {
+			return errorsx.WithStack(fosite.ErrInvalidScope.WithHintf("The OAuth 2.0 Client is not allowed to request scope '%s'.", scope))
+		}
+	}
+
+	audience := request.GetRequestedAudience()
+	if len(audience) == 0 {
+		audience = subject.GetGrantedAudience()
+	} else if err := c.Config.GetAudienceStrategy(ctx)(client.GetAudience(), audience); err != nil {
+		return err
+	}
+
+	for _, scope := range scopes {
+		request.GrantScope(scope)
+	}
+
+	for _, aud := range audience {
+		request.GrantAudience(aud)
+	}
+
+	session, ok := request.GetSession().(Session)
+	if !ok {
+		return errorsx.WithStack(fosite.ErrServerError.WithHintf("Session must be of type rfc8693.Session but got type: %T", request.GetSession()))
+	}
+
+	sub := subject.GetSession().GetSubject()
+	if err := c.Config.GetSubjectValidator(ctx)(sub); err != nil {
+		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The subject of the 'subject_token' is invalid.").WithWrap(err).WithDebug(err.Error()))
+	}
+	session.SetSubject(sub)
+
+	atLifespan := fosite.GetEffectiveLifespan(client, fosite.GrantTypeTokenExchange, fosite.AccessToken, c.Config.GetAccessTokenLifespan(ctx))
+	request.GetSession().SetExpiresAt(fosite.AccessToken, time.Now().UTC().Add(atLifespan).Round(time.Second))
+	return nil
+}
+
+// PopulateTokenEndpointResponse implements https://datatracker.ietf.org/doc/html/rfc8693#section-2.2.1
+func (c *Handler) PopulateTokenEndpointResponse(ctx context.Context, request fosite.AccessRequester, response fosite.AccessResponder) error {
+	if !c.CanHandleTokenEndpointRequest(ctx, request) {
+		return errorsx.WithStack(fosite.ErrUnknownRequest)
+	}
+
+	atLifespan := fosite.GetEffectiveLifespan(request.GetClient(), fosite.GrantTypeTokenExchange, fosite.AccessToken, c.Config.GetAccessTokenLifespan(ctx))
+	if err := c.IssueAccessToken(ctx, atLifespan, request, response); err != nil {
+		return err
+	}
+
+	issuedTokenType := AccessTokenType
+	if request.GetRequestForm().Get("requested_token_type") == JWTTokenType && c.issuesJWTs() {
+		issuedTokenType = JWTTokenType
+	}
+	response.SetExtra("issued_token_type", issuedTokenType)
+	return nil
+}
+
+func (c *Handler) CanSkipClientAuth(ctx context.Context, requester fosite.AccessRequester) bool {
+	return false
+}
+
+func (c *Handler) CanHandleTokenEndpointRequest(ctx context.Context, requester fosite.AccessRequester) bool {
+	// grant_type REQUIRED.
+	// Value MUST be set to "urn:ietf:params:oauth:grant-type:token-exchange"
+	return requester.GetGrantTypes().ExactOne(string(fosite.GrantTypeTokenExchange))
+}
+
+func (c *Handler) HandledGrantTypes() []fosite.GrantType {
+	return []fosite.GrantType{fosite.GrantTypeTokenExchange}
+}
+
+// isRequestedTokenTypeAllowed returns true if the token type can be issued and is allowed by the configuration.
+func (c *Handler) isRequestedTokenTypeAllowed(ctx context.Context, tokenType string) bool {
+	if tokenType != AccessTokenType && (tokenType != JWTTokenType || !c.issuesJWTs()) {
+		return false
+	}
+
+	allowed := c.Config.GetTokenExchangeRequestedTokenTypes(ctx)
+	if allowed == nil {
+		return tokenType == AccessTokenType
+	}
+	return fosite.Arguments(allowed).Has(tokenType)
+}
+
+// issuesJWTs returns true if the access token strategy issues JWT access tokens.
+func (c *Handler) issuesJWTs() bool {
+	if c.HandleHelper == nil {
+		return false
+	}
+	_, ok := c.AccessTokenStrategy.(*oauth2.DefaultJWTStrategy)
+	return ok
+}
+
+func (c *Handler) validateToken(ctx context.Context, request fosite.AccessRequester, token, tokenType, parameter string) (fosite.Requester, error) {
+	requester, err := c.TokenValidator.ValidateToken(ctx, token, tokenType, request.GetSession().Clone())
+	if err != nil {
+		rfcerr := fosite.ErrorToRFC6749Error(err)
+		return nil, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("The '%s' request parameter is invalid.", parameter).WithWrap(err).WithDebug(rfcerr.GetDescription()))
+	}
+	return requester, nil
+}
@@ -0,0 +1,277 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package rfc8693
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/oauth2"
+	"github.com/ory/fosite/token/jwt"
+)
+
+type staticTokenValidator map[string]fosite.Requester
+
+func (v staticTokenValidator) ValidateToken(_ context.Context, token string, _ string, _ fosite.Session) (fosite.Requester, error) {
+	if r, ok := v[token]; ok {
+		return r, nil
+	}
+	return nil, fosite.ErrTokenExpired
+}
+
+func newSubject(subject string, scopes []string, extra map[string]interface{}) fosite.Requester {
+	r := fosite.NewRequest()
+	r.Session = &oauth2.JWTSession{Subject: subject, JWTClaims: &jwt.JWTClaims{Extra: extra}}
+	r.GrantedScope = scopes
+	r.GrantedAudience = []string{"https://api.example.com"}
+	return r
+}
+
+func TestHandleTokenEndpointRequest(t *testing.T) {
+	h := &Handler{
+		TokenValidator: staticTokenValidator{
+			"subject":         newSubject("peter", []string{"foo", "bar"}, nil),
+			"delegated":       newSubject("peter", []string{"foo"}, map[string]interface{}{"act": map[string]interface{}{"sub": "first"}}),
+			"actor":           newSubject("service", []string{"foo"}, nil),
+			"subject-noscope": newSubject("peter", nil, nil),
+		},
+		Config: &fosite.Config{
+			ScopeStrategy:            fosite.HierarchicScopeStrategy,
+			AudienceMatchingStrategy: fosite.DefaultAudienceMatchingStrategy,
+		},
+	}
+
+	for _, c := range []struct {
+		description    string
+		form           url.Values
+		scopes         fosite.Arguments
+		audience       fosite.Arguments
+		grantTypes     fosite.Arguments
+		expectErr      error
+		expectScopes   fosite.Arguments
+		expectAudience fosite.Arguments
+		expectAct      interface{}
+	}{
+		{
+			description: "should fail because the client may not use the grant",
+			form:        url.Values{"subject_token": {"subject"}, "subject_token_type": {AccessTokenType}},
+			grantTypes:  fosite.Arguments{"client_credentials"},
+			expectErr:   fosite.ErrUnauthorizedClient,
+		},
+		{
+			description: "should fail because subject_token is missing",
+			form:        url.Values{"subject_token_type": {AccessTokenType}},
+			expectErr:   fosite.ErrInvalidRequest,
+		},
+		{
+			description: "should fail because subject_token_type is missing",
+			form:        url.Values{"subject_token": {"subject"}},
+			expectErr:   fosite.ErrInvalidRequest,
+		},
+		{
+			description: "should fail because the requested token type is not supported",
+			form:        url.Values{"subject_token": {"subject"}, "subject_token_type": {AccessTokenType}, "requested_token_type": {IDTokenType}},
+			expectErr:   fosite.ErrInvalidRequest,
+		},
+		{
+			description: "should fail because the subject token is invalid",
+			form:        url.Values{"subject_token": {"unknown"}, "subject_token_type": {AccessTokenType}},
+			expectErr:   fosite.ErrInvalidRequest,
+		},
+		{
+			description: "should fail because actor_token_type is missing",
+			form:        url.Values{"subject_token": {"subject"}, "subject_token_type": {AccessTokenType}, "actor_token": {"actor"}},
+			expectErr:   fosite.ErrInvalidRequest,
+		},
+		{
+			description: "should fail because the actor token is invalid",
+			form:        url.Values{"subject_token": {"subject"}, "subject_token_type": {AccessTokenType}, "actor_token": {"unknown"}, "actor_token_type": {AccessTokenType}},
+			expectErr:   fosite.ErrInvalidRequest,
+		},
+		{
+			description: "should fail because the scope was not granted to the subject token",
+			form:        url.Values{"subject_token": {"subject-noscope"}, "subject_token_type": {AccessTokenType}},
+			scopes:      fosite.Arguments{"foo"},
+			expectErr:   fosite.ErrInvalidScope,
+		},
+		{
+			description: "should fail because the client may not request the scope",
+			form:        url.Values{"subject_token": {"subject"}, "subject_token_type": {AccessTokenType}},
+			scopes:      fosite.Arguments{"bar"},
+			expectErr:   fosite.ErrInvalidScope,
+		},
+		{
+			description: "should fail because the client may not request the audience",
+			form:        url.Values{"subject_token": {"subject"}, "subject_token_type": {AccessTokenType}},
+			audience:    fosite.Arguments{"https://other.example.com"},
+			expectErr:   fosite.ErrInvalidRequest,
+		},
+		{
+			description:    "should pass and inherit the allowed scopes and audience of the subject token",
+			form:           url.Values{"subject_token": {"subject"}, "subject_token_type": {AccessTokenType}},
+			expectScopes:   fosite.Arguments{"foo"},
+			expectAudience: fosite.Arguments{"https://api.example.com"},
+		},
+		{
+			description:    "should pass and narrow scopes and audience",
+			form:           url.Values{"subject_token": {"subject"}, "subject_token_type": {AccessTokenType}},
+			scopes:         fosite.Arguments{"foo"},
+			audience:       fosite.Arguments{"https://api.example.com/users"},
+			expectScopes:   fosite.Arguments{"foo"},
+			expectAudience: fosite.Arguments{"https://api.example.com/users"},
+		},
+		{
+			description:    "should pass and set the actor",
+			form:           url.Values{"subject_token": {"subject"}, "subject_token_type": {AccessTokenType}, "actor_token": {"actor"}, "actor_token_type": {AccessTokenType}},
+			scopes:         fosite.Arguments{"foo"},
+			expectScopes:   fosite.Arguments{"foo"},
+			expectAudience: fosite.Arguments{"https://api.example.com"},
+			expectAct:      map[string]interface{}{"sub": "service"},
+		},
+		{
+			description:    "should pass and retain prior actors",
+			form:           url.Values{"subject_token": {"delegated"}, "subject_token_type": {AccessTokenType}, "actor_token": {"actor"}, "actor_token_type": {AccessTokenType}},
+			expectScopes:   fosite.Arguments{"foo"},
+			expectAudience: fosite.Arguments{"https://api.example.com"},
+			expectAct:      map[string]interface{}{"sub": "service", "act": map[string]interface{}{"sub": "first"}},
+		},
+	} {
+		t.Run("case="+c.description, func(t *testing.T) {
+			grantTypes := c.grantTypes
+			if grantTypes == nil {
+				grantTypes = fosite.Arguments{string(fosite.GrantTypeTokenExchange)}
+			}
+
+			session := new(oauth2.JWTSession)
+			ar := fosite.NewAccessRequest(session)
+			ar.GrantTypes = fosite.Arguments{string(fosite.GrantTypeTokenExchange)}
+			ar.Form = c.form
+			ar.RequestedScope = c.scopes
+			ar.RequestedAudience = c.audience
+			ar.Client = &fosite.DefaultClient{
+				GrantTypes: grantTypes,
+				Scopes:     []string{"foo"},
+				Audience:   []string{"https://api.example.com"},
+			}
+
+			err := h.HandleTokenEndpointRequest(context.Background(), ar)
+			if c.expectErr != nil {
+				require.ErrorIs(t, err, c.expectErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, c.expectScopes, ar.GetGrantedScopes())
+			assert.Equal(t, c.expectAudience, ar.GetGrantedAudience())
+			assert.Equal(t, "peter", session.GetSubject())
+			assert.False(t, session.GetExpiresAt(fosite.AccessToken).IsZero())
+			assert.Equal(t, c.expectAct, session.JWTClaims.Extra["act"])
+		})
+	}
+
+	t.Run("case=should not handle other grant types", func(t *testing.T) {
+		ar := fosite.NewAccessRequest(new(oauth2.JWTSession))
+		ar.GrantTypes = fosite.Arguments{"client_credentials"}
+
+		assert.False(t, h.CanHandleTokenEndpointRequest(context.Background(), ar))
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(context.Background(), ar), fosite.ErrUnknownRequest)
+		assert.ErrorIs(t, h.PopulateTokenEndpointResponse(context.Background(), ar, fosite.NewAccessResponse()), fosite.ErrUnknownRequest)
+	})
+}
+
+func TestHandleTokenEndpointRequestWithRequestedTokenTypes(t *testing.T) {
+	for _, c := range []struct {
+		description string
+		allowed     []string
+		strategy    oauth2.AccessTokenStrategy
+		tokenType   string
+		expectErr   error
+	}{
+		{description: "should allow access tokens by default", tokenType: AccessTokenType},
+		{description: "should reject JWTs by default", strategy: new(oauth2.DefaultJWTStrategy), tokenType: JWTTokenType, expectErr: fosite.ErrInvalidRequest},
+		{description: "should allow a configured token type", allowed: []string{AccessTokenType, JWTTokenType}, strategy: new(oauth2.DefaultJWTStrategy), tokenType: JWTTokenType},
+		{description: "should reject JWTs if the access tokens are not JWTs", allowed: []string{AccessTokenType, JWTTokenType}, strategy: new(oauth2.HMACSHAStrategy), tokenType: JWTTokenType, expectErr: fosite.ErrInvalidRequest},
+		{description: "should reject a token type which is not configured", allowed: []string{JWTTokenType}, tokenType: AccessTokenType, expectErr: fosite.ErrInvalidRequest},
+		{description: "should reject a configured token type which can not be issued", allowed: []string{IDTokenType}, tokenType: IDTokenType, expectErr: fosite.ErrInvalidRequest},
+	} {
+		t.Run("case="+c.description, func(t *testing.T) {
+			h := &Handler{
+				TokenValidator: staticTokenValidator{"subject": newSubject("peter", []string{"foo"}, nil)},
+				Config: &fosite.Config{
+					ScopeStrategy:                    fosite.HierarchicScopeStrategy,
+					AudienceMatchingStrategy:         fosite.DefaultAudienceMatchingStrategy,
+					TokenExchangeRequestedTokenTypes: c.allowed,
+				},
+				HandleHelper: &oauth2.HandleHelper{AccessTokenStrategy: c.strategy},
+			}
+
+			ar := fosite.NewAccessRequest(new(oauth2.JWTSession))
+			ar.GrantTypes = fosite.Arguments{string(fosite.GrantTypeTokenExchange)}
+			ar.Form = url.Values{"subject_token": {"subject"}, "subject_token_type": {AccessTokenType}, "requested_token_type": {c.tokenType}}
+			ar.Client = &fosite.DefaultClient{GrantTypes: fosite.Arguments{string(fosite.GrantTypeTokenExchange)}, Scopes: []string{"foo"}}
+
+			err := h.HandleTokenEndpointRequest(context.Background(), ar)
+			if c.expectErr != nil {
+				require.ErrorIs(t, err, c.expectErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestHandleTokenEndpointRequestWithTokenValidators(t *testing.T) {
+	h := &Handler{
+		TokenValidator: TokenValidators{
+			JWTTokenType: staticTokenValidator{"jwt-subject": newSubject("peter", []string{"foo"}, nil)},
+		},
+		Config: &fosite.Config{
+			ScopeStrategy:            fosite.HierarchicScopeStrategy,
+			AudienceMatchingStrategy: fosite.DefaultAudienceMatchingStrategy,
+		},
+	}
+
+	for _, c := range []struct {
+		description string
+		form        url.Values
+		expectErr   error
+	}{
+		{
+			description: "should pass with a subject token of a registered type",
+			form:        url.Values{"subject_token": {"jwt-subject"}, "subject_token_type": {JWTTokenType}},
+		},
+		{
+			description: "should fail with a subject token of an unregistered type",
+			form:        url.Values{"subject_token": {"jwt-subject"}, "subject_token_type": {AccessTokenType}},
+			expectErr:   fosite.ErrInvalidRequest,
+		},
+		{
+			description: "should fail with an actor token of an unregistered type",
+			form:        url.Values{"subject_token": {"jwt-subject"}, "subject_token_type": {JWTTokenType}, "actor_token": {"jwt-subject"}, "actor_token_type": {"urn:ietf:params:oauth:token-type:saml2"}},
+			expectErr:   fosite.ErrInvalidRequest,
+		},
+	} {
+		t.Run("case="+c.description, func(t *testing.T) {
+			session := new(oauth2.JWTSession)
+			ar := fosite.NewAccessRequest(session)
+			ar.GrantTypes = fosite.Arguments{string(fosite.GrantTypeTokenExchange)}
+			ar.Form = c.form
+			ar.Client = &fosite.DefaultClient{GrantTypes: fosite.Arguments{string(fosite.GrantTypeTokenExchange)}, Scopes: []string{"foo"}}
+
+			err := h.HandleTokenEndpointRequest(context.Background(), ar)
+			if c.expectErr != nil {
+				require.ErrorIs(t, err, c.expectErr)
+				assert.Contains(t, fosite.ErrorToRFC6749Error(err).DebugField, "is not supported")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "peter", session.GetSubject())
+		})
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package rfc8693
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ory/x/errorsx"
+
+	"github.com/ory/fosite"
+)
+
+// TokenValidator validates the subject and actor tokens of a token exchange request.
+type TokenValidator interface {
+	// ValidateToken validates the token of the given token type identifier (e.g.
+	// "urn:ietf:params:oauth:token-type:access_token") and returns the request the token was issued for. The
+	// returned request carries the session of the token, which is loaded into the given session.
+	ValidateToken(ctx context.Context, token string, tokenType string, session fosite.Session) (fosite.Requester, error)
+}
+
+// TokenValidators is a TokenValidator which validates every token using the validator registered for its token type
+// identifier, so that operators can declare which token types they accept, for example
+//
+//	TokenValidators{
+//		AccessTokenType: &IntrospectionTokenValidator{Config: config},
+//		JWTTokenType:    myJWTValidator,
+//	}
+//
+// Tokens of unregistered types are rejected.
+type TokenValidators map[string]TokenValidator
+
+var _ TokenValidator = TokenValidators(nil)
+
+func (v TokenValidators) ValidateToken(ctx context.Context, token string, tokenType string, session fosite.Session) (fosite.Requester, error) {
+	validator, ok := v[tokenType]
+	if !ok || validator == nil {
+		return nil, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("Token type '%s' is not supported.", tokenType))
+	}
+	return validator.ValidateToken(ctx, token, tokenType, session)
+}
+
+// IntrospectionTokenValidator validates access and refresh tokens using the token introspection handlers
+// which are registered with the provider.
+type IntrospectionTokenValidator struct {
+	Config fosite.TokenIntrospectionHandlersProvider
+}
+
+var _ TokenValidator = (*IntrospectionTokenValidator)(nil)
+
+func (v *IntrospectionTokenValidator) ValidateToken(ctx context.Context, token string, tokenType string, session fosite.Session) (fosite.Requester, error) {
+	var tokenUse fosite.TokenUse
+	switch tokenType {
+	case AccessTokenType:
+		tokenUse = fosite.AccessToken
+	case RefreshTokenType:
+		tokenUse = fosite.RefreshToken
+	default:
+		return nil, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("Token type '%s' is not supported.", tokenType))
+	}
+
+	found := false
+	ar := fosite.NewAccessRequest(session)
+	for _, validator := range v.Config.GetTokenIntrospectionHandlers(ctx) {
+		tu, err := validator.IntrospectToken(ctx, token, tokenUse, ar, []string{})
+		if errors.Is(err, fosite.ErrUnknownRequest) {
+			continue
+		} else if err != nil {
+			return nil, err
+		} else if tu != tokenUse {
+			return nil, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("The token is not of type '%s'.", tokenType))
+		}
+		found = true
+	}
+
+	if !found {
+		return nil, errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("Unable to find a suitable validation strategy for the token, thus it is invalid."))
+	}
+
+	return ar, nil
+}
@@ -0,0 +1,54 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package rfc8705
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ory/x/errorsx"
+
+	"github.com/ory/fosite"
+)
+
+// Handler binds the tokens issued to clients which authenticated using mutual TLS to the client certificate, as
+// described in https://datatracker.ietf.org/doc/html/rfc8705#section-3. The SHA-256 thumbprint of the certificate
+// is added as the "cnf.x5t#S256" claim to the session, and therefore ends up in JWT access tokens and in the
+// token introspection response.
+//
+// The handler is a token endpoint decorator: it only binds the tokens of grants handled by the grant handlers, and
+// does not affect client authentication.
+type Handler struct {
+	Config interface {
+		fosite.ClientCertificateExtractorProvider
+	}
+}
+
+var _ fosite.TokenEndpointDecorator = (*Handler)(nil)
+
+func (c *Handler) DecorateTokenEndpointRequest(ctx context.Context, request fosite.AccessRequester) error {
+	client, ok := request.GetClient().(fosite.OpenIDConnectClient)
+	if !ok || !fosite.IsMutualTLSClientAuthMethod(client.GetTokenEndpointAuthMethod()) {
+		return nil
+	}
+
+	r, _ := ctx.Value(fosite.RequestContextKey).(*http.Request)
+	if r == nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithDebug("The HTTP request is missing in the context."))
+	}
+
+	cert, err := c.Config.GetClientCertificateExtractor(ctx)(r)
+	if err != nil {
+		return err
+	} else if cert == nil {
+		return errorsx.WithStack(fosite.ErrInvalidClient.WithHint("The OAuth 2.0 Client authenticates using mutual TLS, but no client certificate was presented."))
+	}
+
+	return setCertificateThumbprint(request.GetSession(), fosite.CertificateThumbprint(cert))
+}
+
+func (c *Handler) DecorateTokenEndpointResponse(ctx context.Context, request fosite.AccessRequester, response fosite.AccessResponder) error {
+	// Certificate-bound access tokens keep the "Bearer" token type.
+	return nil
+}
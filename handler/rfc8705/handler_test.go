@@ -0,0 +1,76 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package rfc8705
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/internal/gen"
+)
+
+func TestHandler(t *testing.T) {
+	cert := gen.MustSelfSignedCertificate(gen.MustES256Key(), "foo")
+	otherCert := gen.MustSelfSignedCertificate(gen.MustES256Key(), "bar")
+	h := &Handler{Config: &fosite.Config{}}
+
+	newRequest := func(method string) *fosite.AccessRequest {
+		ar := fosite.NewAccessRequest(&fosite.DefaultSession{})
+		ar.GrantTypes = fosite.Arguments{"client_credentials"}
+		ar.Client = &fosite.DefaultOpenIDConnectClient{
+			DefaultClient:           &fosite.DefaultClient{ID: "foo"},
+			TokenEndpointAuthMethod: method,
+		}
+		return ar
+	}
+
+	withCert := func(cert *x509.Certificate) context.Context {
+		r := new(http.Request)
+		if cert != nil {
+			r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+		}
+		return context.WithValue(context.Background(), fosite.RequestContextKey, r)
+	}
+
+	t.Run("case=should bind the session to the client certificate", func(t *testing.T) {
+		for _, method := range []string{fosite.ClientAuthMethodTLS, fosite.ClientAuthMethodSelfSignedTLS} {
+			ar := newRequest(method)
+			ctx := withCert(cert)
+			require.NoError(t, h.DecorateTokenEndpointRequest(ctx, ar))
+
+			assert.Equal(t, fosite.CertificateThumbprint(cert), GetCertificateThumbprint(ar.GetSession()))
+			assert.Equal(t, map[string]interface{}{"x5t#S256": fosite.CertificateThumbprint(cert)}, ar.GetSession().(*fosite.DefaultSession).Extra["cnf"])
+
+			response := fosite.NewAccessResponse()
+			response.SetTokenType("bearer")
+			require.NoError(t, h.DecorateTokenEndpointResponse(ctx, ar, response))
+			assert.Equal(t, "bearer", response.GetTokenType())
+
+			assert.NoError(t, ValidateCertificateBinding(ar.GetSession(), cert))
+			assert.ErrorIs(t, ValidateCertificateBinding(ar.GetSession(), otherCert), fosite.ErrTokenClaim)
+			assert.ErrorIs(t, ValidateCertificateBinding(ar.GetSession(), nil), fosite.ErrTokenClaim)
+		}
+	})
+
+	t.Run("case=should fail without a client certificate", func(t *testing.T) {
+		ar := newRequest(fosite.ClientAuthMethodTLS)
+		assert.ErrorIs(t, h.DecorateTokenEndpointRequest(withCert(nil), ar), fosite.ErrInvalidClient)
+		assert.Empty(t, GetCertificateThumbprint(ar.GetSession()))
+	})
+
+	t.Run("case=should not bind tokens of other clients", func(t *testing.T) {
+		ar := newRequest("client_secret_basic")
+		ctx := withCert(cert)
+		assert.NoError(t, h.DecorateTokenEndpointRequest(ctx, ar))
+		assert.Empty(t, GetCertificateThumbprint(ar.GetSession()))
+		assert.NoError(t, ValidateCertificateBinding(ar.GetSession(), nil))
+	})
+}
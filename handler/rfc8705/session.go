@@ -0,0 +1,65 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package rfc8705
+
+import (
+	"crypto/x509"
+
+	"github.com/ory/x/errorsx"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/oauth2"
+)
+
+const (
+	confirmationClaim = "cnf"
+	thumbprintMember  = "x5t#S256"
+)
+
+// GetCertificateThumbprint returns the certificate SHA-256 thumbprint ("cnf.x5t#S256") the session is bound to,
+// or an empty string if the session is not bound to a client certificate.
+func GetCertificateThumbprint(session fosite.Session) string {
+	claims, err := oauth2.ExtraClaims(session)
+	if err != nil {
+		return ""
+	}
+
+	cnf, ok := claims[confirmationClaim].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	x5t, _ := cnf[thumbprintMember].(string)
+	return x5t
+}
+
+// ValidateCertificateBinding checks that a token bound to a client certificate is presented together with that
+// certificate, see https://datatracker.ietf.org/doc/html/rfc8705#section-3. Tokens which are not bound are
+// accepted.
+func ValidateCertificateBinding(session fosite.Session, cert *x509.Certificate) error {
+	x5t := GetCertificateThumbprint(session)
+	if x5t == "" {
+		return nil
+	}
+
+	if cert == nil || fosite.CertificateThumbprint(cert) != x5t {
+		return errorsx.WithStack(fosite.ErrTokenClaim.WithHint("The token is bound to a different client certificate than the one presented."))
+	}
+	return nil
+}
+
+func setCertificateThumbprint(session fosite.Session, x5t string) error {
+	claims, err := oauth2.ExtraClaims(session)
+	if err != nil {
+		return err
+	}
+
+	cnf, ok := claims[confirmationClaim].(map[string]interface{})
+	if !ok {
+		cnf = make(map[string]interface{})
+	}
+	cnf[thumbprintMember] = x5t
+	claims[confirmationClaim] = cnf
+	return nil
+}
@@ -63,3 +63,7 @@ func (c *Handler) CanSkipClientAuth(context.Context, fosite.AccessRequester) boo
 func (c *Handler) CanHandleTokenEndpointRequest(_ context.Context, requester fosite.AccessRequester) bool {
 	return requester.GetGrantedScopes().Has("openid", draftScope)
 }
+
+func (c *Handler) HandledGrantTypes() []fosite.GrantType {
+	return []fosite.GrantType{fosite.GrantTypeAny}
+}
@@ -0,0 +1,131 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"github.com/ory/x/errorsx"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/argon2"
+)
+
+// The default argon2id parameters, see https://datatracker.ietf.org/doc/html/rfc9106#section-4.
+const (
+	DefaultArgon2idMemory      uint32 = 64 * 1024
+	DefaultArgon2idIterations  uint32 = 3
+	DefaultArgon2idParallelism uint8  = 4
+	DefaultArgon2idSaltLength  uint32 = 16
+	DefaultArgon2idKeyLength   uint32 = 32
+)
+
+var argon2idPrefix = []byte("$argon2id$")
+
+// ErrArgon2idMismatch is returned by Argon2id.Compare if the data does not match the hash.
+var ErrArgon2idMismatch = errors.New("argon2id: hash does not match data")
+
+// Argon2id implements the Hasher interface by using argon2id. Hashes are encoded in the PHC string format, for
+// example "$argon2id$v=19$m=65536,t=3,p=4$<salt>$<key>", and carry their parameters, so that changing the parameters
+// does not invalidate existing hashes.
+type Argon2id struct {
+	// Memory is the amount of memory in KiB used to create new hashes. Defaults to 64 MiB.
+	Memory uint32
+
+	// Iterations is the number of passes over the memory used to create new hashes. Defaults to 3.
+	Iterations uint32
+
+	// Parallelism is the number of threads used to create new hashes. Defaults to 4.
+	Parallelism uint8
+
+	// SaltLength is the length of the random salt in bytes. Defaults to 16.
+	SaltLength uint32
+
+	// KeyLength is the length of the derived key in bytes. Defaults to 32.
+	KeyLength uint32
+
+	// Rand is the source of randomness of the salts. It must be safe for concurrent use. Defaults to
+	// crypto/rand.Reader.
+	Rand io.Reader
+}
+
+func (a *Argon2id) Hash(ctx context.Context, data []byte) ([]byte, error) {
+	salt := make([]byte, orDefault(a.SaltLength, DefaultArgon2idSaltLength))
+	if _, err := io.ReadFull(a.getRand(), salt); err != nil {
+		return nil, errorsx.WithStack(err)
+	}
+
+	memory := orDefault(a.Memory, DefaultArgon2idMemory)
+	iterations := orDefault(a.Iterations, DefaultArgon2idIterations)
+	parallelism := a.Parallelism
+	if parallelism == 0 {
+		parallelism = DefaultArgon2idParallelism
+	}
+
+	key := argon2.IDKey(data, salt, iterations, memory, parallelism, orDefault(a.KeyLength, DefaultArgon2idKeyLength))
+	return []byte(fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, memory, iterations, parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)), nil
+}
+
+func (a *Argon2id) Compare(ctx context.Context, hash, data []byte) error {
+	parts := bytes.Split(hash, []byte("$"))
+	if len(parts) != 6 || !bytes.HasPrefix(hash, argon2idPrefix) {
+		return errorsx.WithStack(errors.New("argon2id: hash is not in the PHC string format"))
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(string(parts[2]), "v=%d", &version); err != nil {
+		return errorsx.WithStack(err)
+	} else if version != argon2.Version {
+		return errorsx.WithStack(errors.Errorf("argon2id: version %d is not supported", version))
+	}
+
+	var memory, iterations uint32
+	var parallelism uint8
+	if _, err := fmt.Sscanf(string(parts[3]), "m=%d,t=%d,p=%d", &memory, &iterations, &parallelism); err != nil {
+		return errorsx.WithStack(err)
+	} else if memory == 0 || iterations < 1 || parallelism < 1 {
+		return errorsx.WithStack(errors.Errorf("argon2id: parameters m=%d,t=%d,p=%d are invalid", memory, iterations, parallelism))
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(string(parts[4]))
+	if err != nil {
+		return errorsx.WithStack(err)
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(string(parts[5]))
+	if err != nil {
+		return errorsx.WithStack(err)
+	} else if len(key) == 0 {
+		// An empty key would match any data.
+		return errorsx.WithStack(errors.New("argon2id: hash does not contain a key"))
+	}
+
+	if subtle.ConstantTimeCompare(key, argon2.IDKey(data, salt, iterations, memory, parallelism, uint32(len(key)))) != 1 {
+		return errorsx.WithStack(ErrArgon2idMismatch)
+	}
+	return nil
+}
+
+func (a *Argon2id) getRand() io.Reader {
+	if a.Rand == nil {
+		return rand.Reader
+	}
+	return a.Rand
+}
+
+func orDefault(value, fallback uint32) uint32 {
+	if value == 0 {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,75 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArgon2id(t *testing.T) {
+	ctx := context.Background()
+	hasher := &Argon2id{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+	hash, err := hasher.Hash(ctx, []byte("hello world"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(hash), "$argon2id$v=19$m=1024,t=1,p=1$"), "%s", hash)
+
+	t.Run("case=should match the hashed data", func(t *testing.T) {
+		assert.NoError(t, hasher.Compare(ctx, hash, []byte("hello world")))
+	})
+
+	t.Run("case=should not match other data", func(t *testing.T) {
+		assert.ErrorIs(t, hasher.Compare(ctx, hash, []byte("some invalid password")), ErrArgon2idMismatch)
+	})
+
+	t.Run("case=should use the parameters of the hash", func(t *testing.T) {
+		assert.NoError(t, (&Argon2id{}).Compare(ctx, hash, []byte("hello world")))
+	})
+
+	t.Run("case=should use random salts", func(t *testing.T) {
+		other, err := hasher.Hash(ctx, []byte("hello world"))
+		require.NoError(t, err)
+		assert.NotEqual(t, hash, other)
+	})
+
+	t.Run("case=should read the salt from the random source", func(t *testing.T) {
+		a, err := (&Argon2id{Memory: 1024, Iterations: 1, Parallelism: 1, Rand: bytes.NewReader(make([]byte, 16))}).Hash(ctx, []byte("hello world"))
+		require.NoError(t, err)
+		b, err := (&Argon2id{Memory: 1024, Iterations: 1, Parallelism: 1, Rand: bytes.NewReader(make([]byte, 16))}).Hash(ctx, []byte("hello world"))
+		require.NoError(t, err)
+		assert.Equal(t, a, b)
+
+		_, err = (&Argon2id{Memory: 1024, Iterations: 1, Parallelism: 1, Rand: bytes.NewReader(make([]byte, 15))}).Hash(ctx, []byte("hello world"))
+		assert.Error(t, err)
+	})
+
+	t.Run("case=should use the default parameters", func(t *testing.T) {
+		hash, err := (&Argon2id{}).Hash(ctx, []byte("hello world"))
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(hash), "$argon2id$v=19$m=65536,t=3,p=4$"), "%s", hash)
+	})
+
+	for _, invalid := range []string{
+		"",
+		"$2a$10$abc",
+		"$argon2id$v=19$m=1024,t=1,p=1$c2FsdA",
+		"$argon2id$v=18$m=1024,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=foo$c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=1$!$a2V5",
+		"$argon2id$v=19$m=1024,t=0,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=0$c2FsdA$a2V5",
+		"$argon2id$v=19$m=0,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=1$c2FsdA$",
+	} {
+		t.Run("case=should fail for malformed hash "+invalid, func(t *testing.T) {
+			assert.Error(t, hasher.Compare(ctx, []byte(invalid), []byte("hello world")))
+		})
+	}
+}
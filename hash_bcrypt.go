@@ -5,6 +5,7 @@ package fosite
 
 import (
 	"context"
+	"time"
 
 	"github.com/ory/x/errorsx"
 
@@ -13,6 +14,25 @@ import (
 
 const DefaultBCryptWorkFactor = 12
 
+// TuneBCryptCost returns the lowest bcrypt cost at which hashing a secret takes at least the target duration on this
+// machine, but not less than DefaultBCryptWorkFactor. The duration is extrapolated from a hash of the minimum cost, as
+// every increment of the cost doubles the work. The result can be used as Config.HashCost.
+func TuneBCryptCost(target time.Duration) int {
+	start := time.Now()
+	_, _ = bcrypt.GenerateFromPassword([]byte("tune"), bcrypt.MinCost)
+	elapsed := time.Since(start)
+
+	cost := bcrypt.MinCost
+	for ; elapsed < target && cost < bcrypt.MaxCost; cost++ {
+		elapsed *= 2
+	}
+
+	if cost < DefaultBCryptWorkFactor {
+		return DefaultBCryptWorkFactor
+	}
+	return cost
+}
+
 // BCrypt implements the Hasher interface by using BCrypt.
 type BCrypt struct {
 	Config interface {
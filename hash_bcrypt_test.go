@@ -5,7 +5,9 @@ package fosite
 
 import (
 	"context"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/crypto/bcrypt"
@@ -102,3 +104,8 @@ func TestDefaultWorkFactor(t *testing.T) {
 		t.Errorf("got cost factor %d", cost)
 	}
 }
+
+func TestTuneBCryptCost(t *testing.T) {
+	assert.Equal(t, DefaultBCryptWorkFactor, TuneBCryptCost(0))
+	assert.Equal(t, bcrypt.MaxCost, TuneBCryptCost(time.Duration(math.MaxInt64)))
+}
@@ -0,0 +1,42 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"bytes"
+	"context"
+)
+
+// MultiHasher creates new hashes with Hasher, but compares hashes with the hasher of their algorithm, which is
+// detected from the prefix of the hash. This allows hashes of different algorithms to coexist while secrets are
+// migrated from one algorithm to another.
+type MultiHasher struct {
+	// Hasher creates new hashes, and compares hashes of unknown algorithms.
+	Hasher Hasher
+
+	// BCrypt compares bcrypt hashes, which start with "$2".
+	BCrypt Hasher
+
+	// Argon2id compares argon2id hashes, which start with "$argon2id$".
+	Argon2id Hasher
+}
+
+func (m *MultiHasher) Hash(ctx context.Context, data []byte) ([]byte, error) {
+	return m.Hasher.Hash(ctx, data)
+}
+
+func (m *MultiHasher) Compare(ctx context.Context, hash, data []byte) error {
+	return m.hasherFor(hash).Compare(ctx, hash, data)
+}
+
+func (m *MultiHasher) hasherFor(hash []byte) Hasher {
+	switch {
+	case m.Argon2id != nil && bytes.HasPrefix(hash, argon2idPrefix):
+		return m.Argon2id
+	case m.BCrypt != nil && bytes.HasPrefix(hash, []byte("$2")):
+		return m.BCrypt
+	default:
+		return m.Hasher
+	}
+}
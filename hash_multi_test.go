@@ -0,0 +1,45 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiHasher(t *testing.T) {
+	ctx := context.Background()
+	bcrypt := &BCrypt{Config: &Config{HashCost: 4}}
+	argon2id := &Argon2id{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+	bcryptHash, err := bcrypt.Hash(ctx, []byte("old-secret"))
+	require.NoError(t, err)
+	argon2idHash, err := argon2id.Hash(ctx, []byte("new-secret"))
+	require.NoError(t, err)
+
+	hasher := &MultiHasher{Hasher: argon2id, BCrypt: bcrypt, Argon2id: argon2id}
+
+	t.Run("case=should create hashes with the primary hasher", func(t *testing.T) {
+		hash, err := hasher.Hash(ctx, []byte("secret"))
+		require.NoError(t, err)
+		assert.NoError(t, argon2id.Compare(ctx, hash, []byte("secret")))
+	})
+
+	t.Run("case=should compare hashes of both algorithms", func(t *testing.T) {
+		assert.NoError(t, hasher.Compare(ctx, bcryptHash, []byte("old-secret")))
+		assert.NoError(t, hasher.Compare(ctx, argon2idHash, []byte("new-secret")))
+
+		assert.Error(t, hasher.Compare(ctx, bcryptHash, []byte("new-secret")))
+		assert.Error(t, hasher.Compare(ctx, argon2idHash, []byte("old-secret")))
+	})
+
+	t.Run("case=should compare argon2id hashes by default", func(t *testing.T) {
+		hasher := new(Config).GetSecretsHasher(ctx)
+		assert.NoError(t, hasher.Compare(ctx, bcryptHash, []byte("old-secret")))
+		assert.NoError(t, hasher.Compare(ctx, argon2idHash, []byte("new-secret")))
+	})
+}
@@ -0,0 +1,136 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package integration_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	goauth "golang.org/x/oauth2"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/compose"
+	"github.com/ory/fosite/handler/oauth2"
+	"github.com/ory/fosite/storage"
+)
+
+type auditRecorder struct {
+	sync.Mutex
+	events []fosite.AuditEvent
+}
+
+func (a *auditRecorder) Audit(_ context.Context, event fosite.AuditEvent) error {
+	a.Lock()
+	defer a.Unlock()
+	a.events = append(a.events, event)
+	return nil
+}
+
+// take returns the recorded events and forgets them.
+func (a *auditRecorder) take() []fosite.AuditEvent {
+	a.Lock()
+	defer a.Unlock()
+	events := a.events
+	a.events = nil
+	return events
+}
+
+func TestAuditEvents(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewExampleStore()
+	sink := new(auditRecorder)
+	config := &fosite.Config{AuditSink: sink, RefreshTokenScopes: []string{}}
+	f := compose.Compose(
+		config,
+		store,
+		hmacStrategy,
+		compose.OAuth2ResourceOwnerPasswordCredentialsFactory,
+		compose.OAuth2RefreshTokenGrantFactory,
+	)
+	ts := mockServer(t, f, &fosite.DefaultSession{})
+	defer ts.Close()
+
+	oauthClient := newOAuth2Client(ts)
+	revoker := &oauth2.BulkTokenRevoker{Storage: store, Config: config}
+
+	refresh := func(refreshToken string) (*goauth.Token, error) {
+		return oauthClient.TokenSource(ctx, &goauth.Token{RefreshToken: refreshToken}).Token()
+	}
+
+	// issue returns the tokens and the event of their issuance.
+	issue := func(t *testing.T) (*goauth.Token, fosite.AuditEvent) {
+		token, err := oauthClient.PasswordCredentialsToken(ctx, "peter", "secret")
+		require.NoError(t, err)
+
+		events := sink.take()
+		require.Len(t, events, 1)
+		issued := events[0]
+		assert.Equal(t, fosite.AuditEventTokenIssued, issued.Type)
+		assert.Equal(t, hmacStrategy.AccessTokenSignature(ctx, token.AccessToken), issued.AccessTokenSignature)
+		assert.Equal(t, hmacStrategy.RefreshTokenSignature(ctx, token.RefreshToken), issued.RefreshTokenSignature)
+		return token, issued
+	}
+
+	bothTokens := []fosite.TokenType{fosite.AccessToken, fosite.RefreshToken}
+
+	t.Run("case=should record the revocation by refresh token rotation", func(t *testing.T) {
+		token, issued := issue(t)
+
+		refreshed, err := refresh(token.RefreshToken)
+		require.NoError(t, err)
+
+		events := sink.take()
+		require.Len(t, events, 2)
+		assert.Equal(t, fosite.AuditEventTokenRevoked, events[0].Type)
+		assert.Equal(t, issued.RequestID, events[0].RequestID)
+		assert.Equal(t, issued.RefreshTokenSignature, events[0].RefreshTokenSignature)
+		assert.Equal(t, bothTokens, events[0].TokenTypes)
+
+		assert.Equal(t, fosite.AuditEventTokenIssued, events[1].Type)
+		assert.Equal(t, hmacStrategy.RefreshTokenSignature(ctx, refreshed.RefreshToken), events[1].RefreshTokenSignature)
+	})
+
+	t.Run("case=should record the revocation by refresh token reuse", func(t *testing.T) {
+		token, issued := issue(t)
+
+		_, err := refresh(token.RefreshToken)
+		require.NoError(t, err)
+		sink.take()
+
+		_, err = refresh(token.RefreshToken)
+		require.Error(t, err)
+
+		events := sink.take()
+		require.Len(t, events, 1)
+		assert.Equal(t, fosite.AuditEventTokenRevoked, events[0].Type)
+		assert.Equal(t, issued.RequestID, events[0].RequestID)
+		assert.Equal(t, issued.RefreshTokenSignature, events[0].RefreshTokenSignature)
+		assert.Equal(t, bothTokens, events[0].TokenTypes)
+	})
+
+	t.Run("case=should record the revocation of the tokens of a subject", func(t *testing.T) {
+		_, issued := issue(t)
+		require.NotEmpty(t, issued.Subject)
+
+		require.NoError(t, revoker.RevokeAllForSubject(ctx, issued.Subject))
+		assert.Equal(t, []fosite.AuditEvent{{Type: fosite.AuditEventTokenRevoked, Subject: issued.Subject, TokenTypes: bothTokens}}, sink.take())
+	})
+
+	t.Run("case=should record the revocation of the tokens of a client", func(t *testing.T) {
+		_, issued := issue(t)
+
+		require.NoError(t, revoker.RevokeAllForClient(ctx, issued.ClientID))
+		assert.Equal(t, []fosite.AuditEvent{{Type: fosite.AuditEventTokenRevoked, ClientID: issued.ClientID, TokenTypes: bothTokens}}, sink.take())
+	})
+
+	t.Run("case=should record the revocation of a session", func(t *testing.T) {
+		_, issued := issue(t)
+
+		require.NoError(t, f.RevokeSession(ctx, issued.RequestID))
+		assert.Equal(t, []fosite.AuditEvent{{Type: fosite.AuditEventTokenRevoked, RequestID: issued.RequestID, TokenTypes: []fosite.TokenType{fosite.RefreshToken, fosite.AccessToken}}}, sink.take())
+	})
+}
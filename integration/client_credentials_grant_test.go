@@ -4,6 +4,7 @@
 package integration_test
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,6 +12,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -148,3 +150,171 @@ func runClientCredentialsGrantTest(t *testing.T, strategy oauth2.AccessTokenStra
 		})
 	}
 }
+
+type capturedLogEvent struct {
+	event  string
+	fields fosite.LogFields
+}
+
+type capturingLogger struct {
+	sync.Mutex
+	events []capturedLogEvent
+}
+
+func (l *capturingLogger) LogEvent(_ context.Context, event string, fields fosite.LogFields) {
+	l.Lock()
+	defer l.Unlock()
+	l.events = append(l.events, capturedLogEvent{event: event, fields: fields})
+}
+
+func (l *capturingLogger) reset() []capturedLogEvent {
+	l.Lock()
+	defer l.Unlock()
+	events := l.events
+	l.events = nil
+	return events
+}
+
+func TestClientCredentialsFlowLogging(t *testing.T) {
+	logger := new(capturingLogger)
+	f := compose.Compose(&fosite.Config{Logger: logger}, fositeStore, hmacStrategy, compose.OAuth2ClientCredentialsGrantFactory)
+	ts := mockServer(t, f, &fosite.DefaultSession{})
+	defer ts.Close()
+
+	oauthClient := newOAuth2AppClient(ts)
+	oauthClient.AuthStyle = goauth.AuthStyleInHeader
+	assertNoSecrets := func(t *testing.T, events []capturedLogEvent) {
+		for _, e := range events {
+			for _, v := range e.fields {
+				assert.NotContains(t, fmt.Sprintf("%v", v), oauthClient.ClientSecret)
+			}
+		}
+	}
+
+	t.Run("case=should log failed client authentication", func(t *testing.T) {
+		logger.reset()
+		client := *oauthClient
+		client.ClientSecret = "wrong-secret"
+		_, err := client.Token(goauth.NoContext)
+		require.Error(t, err)
+
+		events := logger.reset()
+		require.Len(t, events, 1)
+		assert.Equal(t, fosite.LogEventClientAuthenticationFailed, events[0].event)
+		assert.Equal(t, fosite.LogFields{
+			fosite.LogFieldClientID:  "my-client",
+			fosite.LogFieldGrantType: "client_credentials",
+			fosite.LogFieldOutcome:   fosite.LogOutcomeFailure,
+			fosite.LogFieldError:     "invalid_client",
+		}, events[0].fields)
+		assertNoSecrets(t, events)
+	})
+
+	t.Run("case=should log denied scopes", func(t *testing.T) {
+		logger.reset()
+		client := *oauthClient
+		client.Scopes = []string{"unknown"}
+		_, err := client.Token(goauth.NoContext)
+		require.Error(t, err)
+
+		events := logger.reset()
+		require.Len(t, events, 2)
+		assert.Equal(t, fosite.LogEventClientAuthenticated, events[0].event)
+		assert.Equal(t, fosite.LogOutcomeSuccess, events[0].fields[fosite.LogFieldOutcome])
+		assert.Equal(t, fosite.LogEventScopeDenied, events[1].event)
+		assert.Equal(t, fosite.LogFields{
+			fosite.LogFieldClientID:  "my-client",
+			fosite.LogFieldGrantType: "client_credentials",
+			fosite.LogFieldOutcome:   fosite.LogOutcomeFailure,
+			fosite.LogFieldError:     "invalid_scope",
+		}, events[1].fields)
+		assertNoSecrets(t, events)
+	})
+
+	t.Run("case=should log token issuance", func(t *testing.T) {
+		logger.reset()
+		token, err := oauthClient.Token(goauth.NoContext)
+		require.NoError(t, err)
+
+		events := logger.reset()
+		require.Len(t, events, 2)
+		assert.Equal(t, fosite.LogEventClientAuthenticated, events[0].event)
+		assert.Equal(t, fosite.LogEventTokenIssued, events[1].event)
+		assert.Equal(t, "my-client", events[1].fields[fosite.LogFieldClientID])
+		assertNoSecrets(t, events)
+		for _, e := range events {
+			for _, v := range e.fields {
+				assert.NotContains(t, fmt.Sprintf("%v", v), token.AccessToken)
+			}
+		}
+	})
+}
+
+func TestClientCredentialsFlowWithUnknownScopePolicy(t *testing.T) {
+	for k, c := range []struct {
+		policy    fosite.UnknownScopePolicy
+		expectErr bool
+	}{
+		{policy: "", expectErr: true},
+		{policy: fosite.UnknownScopePolicyReject, expectErr: true},
+		{policy: fosite.UnknownScopePolicyIgnore},
+	} {
+		t.Run(fmt.Sprintf("case=%d/policy=%s", k, c.policy), func(t *testing.T) {
+			f := compose.Compose(&fosite.Config{UnknownScopePolicy: c.policy}, fositeStore, hmacStrategy, compose.OAuth2ClientCredentialsGrantFactory)
+			ts := mockServer(t, f, &fosite.DefaultSession{})
+			defer ts.Close()
+
+			oauthClient := newOAuth2AppClient(ts)
+			oauthClient.AuthStyle = goauth.AuthStyleInHeader
+			oauthClient.Scopes = []string{"fosite", "unknown"}
+			token, err := oauthClient.Token(goauth.NoContext)
+			if c.expectErr {
+				var retrieveErr *goauth.RetrieveError
+				require.ErrorAs(t, err, &retrieveErr)
+				assert.Equal(t, "invalid_scope", retrieveErr.ErrorCode)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "fosite", token.Extra("scope"))
+		})
+	}
+}
+
+func TestClientCredentialsFlowWithoutScopes(t *testing.T) {
+	fositeStore.Clients["scopeless-client"] = &fosite.DefaultClient{
+		ID:         "scopeless-client",
+		Secret:     []byte(`$2a$10$IxMdI6d.LIRZPpSfEwNoeu4rY3FhDREsxFJXikcgdRRAStxUlsuEO`), // = "foobar"
+		GrantTypes: []string{"client_credentials"},
+	}
+	defer delete(fositeStore.Clients, "scopeless-client")
+
+	f := compose.Compose(new(fosite.Config), fositeStore, hmacStrategy, compose.OAuth2ClientCredentialsGrantFactory, compose.OAuth2TokenIntrospectionFactory)
+	ts := mockServer(t, f, &fosite.DefaultSession{})
+	defer ts.Close()
+
+	oauthClient := newOAuth2AppClient(ts)
+	oauthClient.ClientID = "scopeless-client"
+
+	t.Run("case=should issue a token without scopes", func(t *testing.T) {
+		oauthClient.Scopes = nil
+		token, err := oauthClient.Token(goauth.NoContext)
+		require.NoError(t, err)
+		require.NotEmpty(t, token.AccessToken)
+		assert.Empty(t, token.Extra("scope"))
+
+		var j json.RawMessage
+		introspect(t, ts, token.AccessToken, &j, oauthClient.ClientID, oauthClient.ClientSecret)
+		assert.True(t, gjson.GetBytes(j, "active").Bool())
+		assert.Equal(t, oauthClient.ClientID, gjson.GetBytes(j, "client_id").String())
+		assert.False(t, gjson.GetBytes(j, "scope").Exists())
+	})
+
+	t.Run("case=should fail to request a scope", func(t *testing.T) {
+		oauthClient.Scopes = []string{"fosite"}
+		_, err := oauthClient.Token(goauth.NoContext)
+		var retrieveErr *goauth.RetrieveError
+		require.ErrorAs(t, err, &retrieveErr)
+		assert.Equal(t, "invalid_scope", retrieveErr.ErrorCode)
+	})
+}
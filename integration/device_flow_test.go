@@ -0,0 +1,137 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package integration_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/compose"
+	"github.com/ory/fosite/handler/rfc8628"
+)
+
+func TestDeviceFlow(t *testing.T) {
+	config := &fosite.Config{
+		GlobalSecret:                   []byte("some-super-cool-secret-that-nobody-knows"),
+		DeviceVerificationURL:          "https://auth.example.com/device",
+		DeviceAuthTokenPollingInterval: time.Second,
+		RefreshTokenScopes:             []string{"offline"},
+	}
+	strategy := &compose.CommonStrategy{
+		CoreStrategy:        hmacStrategy,
+		RFC8628CodeStrategy: compose.NewDeviceStrategy(config),
+	}
+	provider := compose.Compose(
+		config,
+		fositeStore,
+		strategy,
+		compose.RFC8628DeviceFactory,
+		compose.RFC8628DeviceAuthorizationTokenFactory,
+	)
+	ts := mockServer(t, provider, &fosite.DefaultSession{})
+	defer ts.Close()
+
+	verifier := &rfc8628.DeviceAuthHandler{Strategy: strategy, Storage: fositeStore, Config: config}
+
+	post := func(t *testing.T, path string, form url.Values) (*http.Response, map[string]interface{}) {
+		form.Set("client_id", "device-client")
+		res, err := http.PostForm(ts.URL+path, form)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+		return res, body
+	}
+
+	authorize := func(t *testing.T) map[string]interface{} {
+		res, body := post(t, "/device", url.Values{"scope": {"fosite offline"}})
+		require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+		require.NotEmpty(t, body["device_code"])
+		require.NotEmpty(t, body["user_code"])
+		assert.Equal(t, "https://auth.example.com/device", body["verification_uri"])
+		assert.True(t, strings.HasPrefix(body["verification_uri_complete"].(string), "https://auth.example.com/device?user_code="))
+		assert.EqualValues(t, 600, body["expires_in"])
+		assert.EqualValues(t, 1, body["interval"])
+		return body
+	}
+
+	poll := func(t *testing.T, deviceCode interface{}) (*http.Response, map[string]interface{}) {
+		return post(t, tokenRelativePath, url.Values{
+			"grant_type":  {string(fosite.GrantTypeDeviceCode)},
+			"device_code": {deviceCode.(string)},
+		})
+	}
+
+	decide := func(t *testing.T, userCode interface{}, accept bool) {
+		ctx := context.Background()
+		request, err := verifier.GetUserCodeRequest(ctx, userCode.(string), &fosite.DefaultSession{})
+		require.NoError(t, err)
+
+		if !accept {
+			require.NoError(t, verifier.RejectUserCode(ctx, userCode.(string), request))
+			return
+		}
+
+		request.SetSession(&fosite.DefaultSession{Subject: "peter"})
+		for _, scope := range request.GetRequestedScopes() {
+			request.GrantScope(scope)
+		}
+		require.NoError(t, verifier.AcceptUserCode(ctx, userCode.(string), request))
+	}
+
+	t.Run("case=should issue tokens once the user code was accepted", func(t *testing.T) {
+		device := authorize(t)
+
+		res, body := poll(t, device["device_code"])
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Equal(t, "authorization_pending", body["error"])
+
+		res, body = poll(t, device["device_code"])
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Equal(t, "slow_down", body["error"])
+
+		decide(t, device["user_code"], true)
+		time.Sleep(time.Second)
+
+		res, body = poll(t, device["device_code"])
+		require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+		assert.NotEmpty(t, body["access_token"])
+		assert.NotEmpty(t, body["refresh_token"])
+		assert.Equal(t, "bearer", body["token_type"])
+		assert.Equal(t, "fosite offline", body["scope"])
+
+		time.Sleep(time.Second)
+		res, body = poll(t, device["device_code"])
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Equal(t, "invalid_grant", body["error"])
+
+		_, err := verifier.GetUserCodeRequest(context.Background(), device["user_code"].(string), &fosite.DefaultSession{})
+		assert.ErrorIs(t, err, fosite.ErrInvalidGrant)
+	})
+
+	t.Run("case=should deny access once the user code was rejected", func(t *testing.T) {
+		device := authorize(t)
+		decide(t, device["user_code"], false)
+
+		res, body := poll(t, device["device_code"])
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		assert.Equal(t, "access_denied", body["error"])
+	})
+
+	t.Run("case=should reject unknown device codes", func(t *testing.T) {
+		res, body := poll(t, "foo.bar")
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Equal(t, "invalid_grant", body["error"])
+	})
+}
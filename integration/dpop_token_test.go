@@ -0,0 +1,216 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package integration_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/compose"
+	"github.com/ory/fosite/handler/dpop"
+)
+
+type dpopProofClaims struct {
+	jwt.Claims
+	Method string `json:"htm"`
+	URI    string `json:"htu"`
+}
+
+type dpopTokenSuite struct {
+	suite.Suite
+
+	key *ecdsa.PrivateKey
+	ts  string
+}
+
+func (s *dpopTokenSuite) TestValidProof() {
+	t := s.T()
+	proof := s.newProof(t, uuid.New().String(), s.ts+tokenRelativePath)
+
+	res, body := s.requestToken(t, proof)
+	require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+	assert.Equal(t, dpop.TokenType, body["token_type"])
+	require.NotEmpty(t, body["access_token"])
+
+	jwk := jose.JSONWebKey{Key: s.key.Public()}
+	sum, err := jwk.Thumbprint(crypto.SHA256)
+	require.NoError(t, err)
+
+	introspection := s.introspect(t, body["access_token"].(string))
+	assert.Equal(t, true, introspection["active"])
+	assert.Equal(t, map[string]interface{}{"jkt": base64.RawURLEncoding.EncodeToString(sum)}, introspection["cnf"])
+}
+
+func (s *dpopTokenSuite) TestMismatchedHTU() {
+	t := s.T()
+	proof := s.newProof(t, uuid.New().String(), s.ts+"/auth")
+
+	res, body := s.requestToken(t, proof)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	assert.Equal(t, "invalid_dpop_proof", body["error"])
+}
+
+func (s *dpopTokenSuite) TestReplayedJTI() {
+	t := s.T()
+	proof := s.newProof(t, uuid.New().String(), s.ts+tokenRelativePath)
+
+	res, body := s.requestToken(t, proof)
+	require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+
+	res, body = s.requestToken(t, proof)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	assert.Equal(t, "invalid_dpop_proof", body["error"])
+}
+
+func (s *dpopTokenSuite) TestUnknownGrantType() {
+	t := s.T()
+	proof := s.newProof(t, uuid.New().String(), s.ts+tokenRelativePath)
+
+	res, body := s.postToken(t, proof, url.Values{"grant_type": {"urn:example:unknown"}}, true)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	assert.Equal(t, "invalid_request", body["error"])
+}
+
+func (s *dpopTokenSuite) TestRefreshBoundGrantWithoutProof() {
+	t := s.T()
+	res, body := s.postToken(t, s.newProof(t, uuid.New().String(), s.ts+tokenRelativePath), url.Values{
+		"grant_type": {"password"},
+		"username":   {"peter"},
+		"password":   {"secret"},
+		"scope":      {"fosite"},
+	}, true)
+	require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+	assert.Equal(t, dpop.TokenType, body["token_type"])
+	require.NotEmpty(t, body["refresh_token"])
+
+	res, body = s.postToken(t, "", url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {body["refresh_token"].(string)},
+	}, true)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	assert.Equal(t, "invalid_dpop_proof", body["error"])
+}
+
+func (s *dpopTokenSuite) TestMissingClientAuthentication() {
+	t := s.T()
+	proof := s.newProof(t, uuid.New().String(), s.ts+tokenRelativePath)
+
+	res, body := s.postToken(t, proof, url.Values{"grant_type": {"client_credentials"}, "client_id": {"my-client"}}, false)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	assert.Equal(t, "invalid_client", body["error"])
+}
+
+func (s *dpopTokenSuite) newProof(t *testing.T, jti, htu string) string {
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.ES256, Key: s.key},
+		(&jose.SignerOptions{EmbedJWK: true}).WithType("dpop+jwt"),
+	)
+	require.NoError(t, err)
+
+	proof, err := jwt.Signed(signer).Claims(&dpopProofClaims{
+		Claims: jwt.Claims{
+			ID:       jti,
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+		},
+		Method: http.MethodPost,
+		URI:    htu,
+	}).CompactSerialize()
+	require.NoError(t, err)
+	return proof
+}
+
+func (s *dpopTokenSuite) requestToken(t *testing.T, proof string) (*http.Response, map[string]interface{}) {
+	return s.postToken(t, proof, url.Values{
+		"grant_type": {"client_credentials"},
+		"scope":      {"fosite"},
+	}, true)
+}
+
+func (s *dpopTokenSuite) postToken(t *testing.T, proof string, form url.Values, authenticate bool) (*http.Response, map[string]interface{}) {
+	req, err := http.NewRequest(http.MethodPost, s.ts+tokenRelativePath, strings.NewReader(form.Encode()))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if proof != "" {
+		req.Header.Set(dpop.HeaderName, proof)
+	}
+	if authenticate {
+		req.SetBasicAuth("my-client", "foobar")
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+	return res, body
+}
+
+func (s *dpopTokenSuite) introspect(t *testing.T, token string) map[string]interface{} {
+	req, err := http.NewRequest(http.MethodPost, s.ts+"/introspect", strings.NewReader(url.Values{
+		"token": {token},
+	}.Encode()))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("my-client", "foobar")
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+	return body
+}
+
+func TestDPoPTokenSuite(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	for _, strategy := range []struct {
+		description string
+		strategy    interface{}
+	}{
+		{description: "hmac", strategy: hmacStrategy},
+		{description: "jwt", strategy: jwtStrategy},
+	} {
+		t.Run("strategy="+strategy.description, func(t *testing.T) {
+			// Refresh tokens are issued regardless of the granted scopes.
+			config := &fosite.Config{RefreshTokenScopes: []string{}}
+			provider := compose.Compose(
+				config,
+				fositeStore,
+				strategy.strategy,
+				compose.OAuth2ClientCredentialsGrantFactory,
+				compose.OAuth2ResourceOwnerPasswordCredentialsFactory,
+				compose.OAuth2RefreshTokenGrantFactory,
+				compose.OAuth2TokenIntrospectionFactory,
+				compose.DPoPFactory,
+			)
+			testServer := mockServer(t, provider, &fosite.DefaultSession{})
+			defer testServer.Close()
+			config.TokenURL = testServer.URL + tokenRelativePath
+
+			suite.Run(t, &dpopTokenSuite{key: key, ts: testServer.URL})
+		})
+	}
+}
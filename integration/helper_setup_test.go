@@ -71,6 +71,21 @@ var fositeStore = &storage.MemoryStore{
 			},
 			TokenLifespans: &internal.TestLifespans,
 		},
+		"token-exchange-client": &fosite.DefaultClient{
+			ID:         "token-exchange-client",
+			Secret:     []byte(`$2a$10$IxMdI6d.LIRZPpSfEwNoeu4rY3FhDREsxFJXikcgdRRAStxUlsuEO`), // = "foobar"
+			GrantTypes: []string{"client_credentials", "urn:ietf:params:oauth:grant-type:token-exchange"},
+			Scopes:     []string{"fosite", "offline", "openid"},
+			Audience:   []string{tokenURL},
+		},
+		"device-client": &fosite.DefaultClient{
+			ID:         "device-client",
+			Secret:     []byte{},
+			Public:     true,
+			GrantTypes: []string{"refresh_token", "urn:ietf:params:oauth:grant-type:device_code"},
+			Scopes:     []string{"fosite", "offline", "openid"},
+			Audience:   []string{tokenURL},
+		},
 		"public-client": &fosite.DefaultClient{
 			ID:            "public-client",
 			Secret:        []byte{},
@@ -113,6 +128,9 @@ var fositeStore = &storage.MemoryStore{
 	AccessTokenRequestIDs:  map[string]string{},
 	RefreshTokenRequestIDs: map[string]string{},
 	PARSessions:            map[string]fosite.AuthorizeRequester{},
+	DeviceCodes:            map[string]fosite.DeviceRequester{},
+	UserCodes:              map[string]fosite.DeviceRequester{},
+	DeviceCodeRequestIDs:   map[string]string{},
 }
 
 type defaultSession struct {
@@ -204,6 +222,7 @@ func mockServer(t *testing.T, f fosite.OAuth2Provider, session fosite.Session) *
 	router.HandleFunc("/introspect", tokenIntrospectionHandler(t, f, session))
 	router.HandleFunc("/revoke", tokenRevocationHandler(t, f, session))
 	router.HandleFunc("/par", pushedAuthorizeRequestHandler(t, f, session))
+	router.HandleFunc("/device", deviceAuthorizationHandler(t, f, session))
 
 	ts := httptest.NewServer(router)
 	return ts
@@ -4,9 +4,14 @@
 package integration_test
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/parnurzeal/gorequest"
 	"github.com/stretchr/testify/assert"
@@ -125,3 +130,122 @@ func runIntrospectTokenTest(t *testing.T, strategy oauth2.AccessTokenStrategy, i
 		})
 	}
 }
+
+func TestIntrospectInactiveTokens(t *testing.T) {
+	f := compose.Compose(&fosite.Config{IntrospectionRespondInactiveOnError: true}, fositeStore, hmacStrategy, compose.OAuth2TokenIntrospectionFactory)
+	ts := mockServer(t, f, &fosite.DefaultSession{})
+	defer ts.Close()
+
+	ctx := context.Background()
+	client, err := fositeStore.GetClient(ctx, "my-client")
+	require.NoError(t, err)
+
+	newToken := func(t *testing.T, expiresAt time.Time, store bool) string {
+		request := fosite.NewAccessRequest(&fosite.DefaultSession{})
+		request.Client = client
+		request.GetSession().SetExpiresAt(fosite.AccessToken, expiresAt)
+
+		token, signature, err := hmacStrategy.GenerateAccessToken(ctx, request)
+		require.NoError(t, err)
+		if store {
+			require.NoError(t, fositeStore.CreateAccessTokenSession(ctx, signature, request))
+		}
+		return token
+	}
+
+	introspect := func(t *testing.T, form url.Values, secret string) (int, map[string]interface{}) {
+		req, err := http.NewRequest(http.MethodPost, ts.URL+"/introspect", strings.NewReader(form.Encode()))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth("my-client", secret)
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+		return res.StatusCode, body
+	}
+
+	for _, c := range []struct {
+		description string
+		form        url.Values
+	}{
+		{description: "malformed token", form: url.Values{"token": {"not-a-token"}}},
+		{description: "expired token", form: url.Values{"token": {newToken(t, time.Now().UTC().Add(-time.Hour), true)}}},
+		{description: "unknown token", form: url.Values{"token": {newToken(t, time.Now().UTC().Add(time.Hour), false)}}},
+		{description: "empty request", form: url.Values{}},
+	} {
+		t.Run("case="+c.description, func(t *testing.T) {
+			status, body := introspect(t, c.form, "foobar")
+			assert.Equal(t, http.StatusOK, status)
+			assert.Equal(t, map[string]interface{}{"active": false}, body)
+		})
+	}
+
+	t.Run("case=active token", func(t *testing.T) {
+		status, body := introspect(t, url.Values{"token": {newToken(t, time.Now().UTC().Add(time.Hour), true)}}, "foobar")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, true, body["active"])
+	})
+
+	t.Run("case=failed caller authentication", func(t *testing.T) {
+		status, body := introspect(t, url.Values{"token": {"not-a-token"}}, "wrong-secret")
+		assert.Equal(t, http.StatusUnauthorized, status)
+		assert.Equal(t, "request_unauthorized", body["error"])
+	})
+}
+
+func TestIntrospectTokenScopes(t *testing.T) {
+	f := compose.Compose(new(fosite.Config), fositeStore, hmacStrategy, compose.OAuth2TokenIntrospectionFactory)
+	ts := mockServer(t, f, &fosite.DefaultSession{})
+	defer ts.Close()
+
+	ctx := context.Background()
+	client, err := fositeStore.GetClient(ctx, "my-client")
+	require.NoError(t, err)
+
+	request := fosite.NewAccessRequest(&fosite.DefaultSession{})
+	request.Client = client
+	request.GrantScope("fosite")
+	request.GrantScope("offline")
+	request.GrantScope("openid")
+	request.GetSession().SetExpiresAt(fosite.AccessToken, time.Now().UTC().Add(time.Hour))
+
+	token, signature, err := hmacStrategy.GenerateAccessToken(ctx, request)
+	require.NoError(t, err)
+	require.NoError(t, fositeStore.CreateAccessTokenSession(ctx, signature, request))
+
+	for _, c := range []struct {
+		description   string
+		scope         string
+		expectActive  bool
+		expectedScope string
+	}{
+		{description: "no scope requested", scope: "", expectActive: true, expectedScope: "fosite offline openid"},
+		{description: "token has the requested scopes", scope: "openid fosite", expectActive: true, expectedScope: "openid fosite"},
+		{description: "token lacks a requested scope", scope: "fosite foo", expectActive: false},
+	} {
+		t.Run("case="+c.description, func(t *testing.T) {
+			form := url.Values{"token": {token}, "scope": {c.scope}}
+			req, err := http.NewRequest(http.MethodPost, ts.URL+"/introspect", strings.NewReader(form.Encode()))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.SetBasicAuth("my-client", "foobar")
+
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+			assert.Equal(t, c.expectActive, body["active"])
+			if c.expectActive {
+				assert.Equal(t, c.expectedScope, body["scope"])
+			} else {
+				assert.NotContains(t, body, "scope")
+			}
+		})
+	}
+}
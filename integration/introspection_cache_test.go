@@ -0,0 +1,160 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package integration_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parnurzeal/gorequest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	goauth "golang.org/x/oauth2"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/compose"
+	"github.com/ory/fosite/handler/oauth2"
+	"github.com/ory/fosite/storage"
+)
+
+func TestIntrospectionCache(t *testing.T) {
+	store := storage.NewExampleStore()
+	config := &fosite.Config{IntrospectionCache: fosite.NewMemoryIntrospectionCache(time.Minute, 0)}
+	f := compose.Compose(config, store, hmacStrategy, compose.OAuth2ClientCredentialsGrantFactory, compose.OAuth2TokenIntrospectionFactory, compose.OAuth2TokenRevocationFactory)
+	ts := mockServer(t, f, &fosite.DefaultSession{})
+	defer ts.Close()
+
+	oauthClient := newOAuth2AppClient(ts)
+	oauthClient.AuthStyle = goauth.AuthStyleInHeader
+
+	isActive := func(t *testing.T, token string) bool {
+		var res struct {
+			Active bool `json:"active"`
+		}
+		introspect(t, ts, token, &res, oauthClient.ClientID, oauthClient.ClientSecret)
+		return res.Active
+	}
+
+	revoke := func(t *testing.T, token string) {
+		resp, _, errs := gorequest.New().Post(ts.URL+"/revoke").
+			SetBasicAuth(oauthClient.ClientID, oauthClient.ClientSecret).
+			Type("form").
+			SendStruct(map[string]string{"token": token}).End()
+		require.Len(t, errs, 0)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	t.Run("case=should serve cached results", func(t *testing.T) {
+		token, err := oauthClient.Token(context.Background())
+		require.NoError(t, err)
+		require.True(t, isActive(t, token.AccessToken))
+
+		// Removing the token from the storage directly bypasses the cache invalidation.
+		require.NoError(t, store.DeleteAccessTokenSession(context.Background(), strings.Split(token.AccessToken, ".")[1]))
+		assert.True(t, isActive(t, token.AccessToken))
+	})
+
+	t.Run("case=should invalidate cached results on revocation", func(t *testing.T) {
+		token, err := oauthClient.Token(context.Background())
+		require.NoError(t, err)
+		require.True(t, isActive(t, token.AccessToken))
+
+		revoke(t, token.AccessToken)
+		assert.False(t, isActive(t, token.AccessToken))
+	})
+}
+
+func TestIntrospectionCacheInvalidation(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewExampleStore()
+	config := &fosite.Config{IntrospectionCache: fosite.NewMemoryIntrospectionCache(time.Minute, 0), RefreshTokenScopes: []string{}}
+	f := compose.Compose(
+		config,
+		store,
+		hmacStrategy,
+		compose.OAuth2ResourceOwnerPasswordCredentialsFactory,
+		compose.OAuth2RefreshTokenGrantFactory,
+		compose.OAuth2TokenIntrospectionFactory,
+	)
+	ts := mockServer(t, f, &fosite.DefaultSession{})
+	defer ts.Close()
+
+	oauthClient := newOAuth2Client(ts)
+	revoker := &oauth2.BulkTokenRevoker{Storage: store, Config: config}
+
+	issue := func(t *testing.T) *goauth.Token {
+		token, err := oauthClient.PasswordCredentialsToken(ctx, "peter", "secret")
+		require.NoError(t, err)
+		require.NotEmpty(t, token.RefreshToken)
+		return token
+	}
+
+	refresh := func(refreshToken string) (*goauth.Token, error) {
+		return oauthClient.TokenSource(ctx, &goauth.Token{RefreshToken: refreshToken}).Token()
+	}
+
+	// introspect caches the request of the access token, if it is active.
+	introspect := func(t *testing.T, token *goauth.Token) (fosite.Requester, error) {
+		_, ar, err := f.IntrospectToken(ctx, token.AccessToken, fosite.AccessToken, &fosite.DefaultSession{})
+		return ar, err
+	}
+
+	for _, c := range []struct {
+		description string
+		revoke      func(t *testing.T, token *goauth.Token, ar fosite.Requester)
+	}{
+		{
+			description: "refreshing the token",
+			revoke: func(t *testing.T, token *goauth.Token, _ fosite.Requester) {
+				_, err := refresh(token.RefreshToken)
+				require.NoError(t, err)
+			},
+		},
+		{
+			description: "reusing the refresh token",
+			revoke: func(t *testing.T, token *goauth.Token, _ fosite.Requester) {
+				refreshed, err := refresh(token.RefreshToken)
+				require.NoError(t, err)
+				_, err = introspect(t, refreshed)
+				require.NoError(t, err)
+
+				_, err = refresh(token.RefreshToken)
+				require.Error(t, err)
+				_, err = introspect(t, refreshed)
+				assert.Error(t, err, "the refreshed access token must be revoked as well")
+			},
+		},
+		{
+			description: "revoking the tokens of the subject",
+			revoke: func(t *testing.T, _ *goauth.Token, ar fosite.Requester) {
+				require.NoError(t, revoker.RevokeAllForSubject(ctx, ar.GetSession().GetSubject()))
+			},
+		},
+		{
+			description: "revoking the tokens of the client",
+			revoke: func(t *testing.T, _ *goauth.Token, ar fosite.Requester) {
+				require.NoError(t, revoker.RevokeAllForClient(ctx, ar.GetClient().GetID()))
+			},
+		},
+		{
+			description: "revoking the session",
+			revoke: func(t *testing.T, _ *goauth.Token, ar fosite.Requester) {
+				require.NoError(t, f.RevokeSession(ctx, ar.GetID()))
+			},
+		},
+	} {
+		t.Run("case=should invalidate cached results when "+c.description, func(t *testing.T) {
+			token := issue(t)
+			ar, err := introspect(t, token)
+			require.NoError(t, err)
+
+			c.revoke(t, token, ar)
+			_, err = introspect(t, token)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package integration_test
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/compose"
+	"github.com/ory/fosite/internal/gen"
+)
+
+const clientCertificateHeader = "X-Client-Cert"
+
+func TestMutualTLSClientAuthentication(t *testing.T) {
+	key := gen.MustES256Key()
+	cert := gen.MustSelfSignedCertificate(key, "mtls-client")
+	otherCert := gen.MustSelfSignedCertificate(gen.MustES256Key(), "other-client")
+
+	fositeStore.Clients["mtls-client"] = &fosite.DefaultOpenIDConnectClient{
+		DefaultClient: &fosite.DefaultClient{
+			ID:         "mtls-client",
+			GrantTypes: []string{"client_credentials"},
+			Scopes:     []string{"fosite"},
+		},
+		TokenEndpointAuthMethod: fosite.ClientAuthMethodTLS,
+		TLSClientAuthSubjectDN:  cert.Subject.String(),
+	}
+	defer delete(fositeStore.Clients, "mtls-client")
+
+	config := &fosite.Config{ClientCertificateExtractor: fosite.ClientCertificateFromHeader(clientCertificateHeader)}
+	f := compose.Compose(
+		config,
+		fositeStore,
+		hmacStrategy,
+		compose.OAuth2ClientCredentialsGrantFactory,
+		compose.OAuth2TokenIntrospectionFactory,
+		compose.RFC8705CertificateBoundTokenFactory,
+	)
+	ts := mockServer(t, f, &fosite.DefaultSession{})
+	defer ts.Close()
+
+	requestToken := func(t *testing.T, cert *x509.Certificate, grantType string) (*http.Response, map[string]interface{}) {
+		req, err := http.NewRequest("POST", ts.URL+tokenRelativePath, strings.NewReader(url.Values{
+			"grant_type": {grantType},
+			"client_id":  {"mtls-client"},
+			"scope":      {"fosite"},
+		}.Encode()))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set(clientCertificateHeader, url.QueryEscape(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))))
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+		return res, body
+	}
+
+	t.Run("case=should issue a certificate-bound token", func(t *testing.T) {
+		res, body := requestToken(t, cert, "client_credentials")
+		require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+		assert.Equal(t, "bearer", body["token_type"])
+		require.NotEmpty(t, body["access_token"])
+
+		var introspection map[string]interface{}
+		introspect(t, ts, body["access_token"].(string), &introspection, "my-client", "foobar")
+		assert.Equal(t, true, introspection["active"])
+		assert.Equal(t, "mtls-client", introspection["client_id"])
+		assert.Equal(t, map[string]interface{}{"x5t#S256": fosite.CertificateThumbprint(cert)}, introspection["cnf"])
+	})
+
+	t.Run("case=should reject a mismatched certificate", func(t *testing.T) {
+		res, body := requestToken(t, otherCert, "client_credentials")
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+		assert.Equal(t, "invalid_client", body["error"])
+	})
+
+	t.Run("case=should reject an unknown grant type", func(t *testing.T) {
+		res, body := requestToken(t, cert, "urn:example:unknown")
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Equal(t, "invalid_request", body["error"])
+	})
+}
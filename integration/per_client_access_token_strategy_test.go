@@ -0,0 +1,85 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package integration_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/parnurzeal/gorequest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	goauth "golang.org/x/oauth2"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/compose"
+	"github.com/ory/fosite/handler/oauth2"
+	"github.com/ory/fosite/storage"
+)
+
+func TestPerClientAccessTokenStrategy(t *testing.T) {
+	store := storage.NewExampleStore()
+	store.Clients["jwt-client"] = &fosite.DefaultAccessTokenStrategyClient{
+		DefaultClient: &fosite.DefaultClient{
+			ID:         "jwt-client",
+			Secret:     []byte(`$2a$10$IxMdI6d.LIRZPpSfEwNoeu4rY3FhDREsxFJXikcgdRRAStxUlsuEO`), // = "foobar"
+			GrantTypes: []string{"client_credentials"},
+			Scopes:     []string{"fosite"},
+		},
+		AccessTokenStrategy: fosite.AccessTokenStrategyJWT,
+	}
+
+	strategy := &oauth2.PerClientAccessTokenStrategy{
+		HMACSHAStrategy: hmacStrategy,
+		JWTStrategy:     jwtStrategy,
+	}
+	f := compose.Compose(
+		new(fosite.Config),
+		store,
+		strategy,
+		compose.OAuth2ClientCredentialsGrantFactory,
+		compose.OAuth2TokenIntrospectionFactory,
+		compose.OAuth2TokenRevocationFactory,
+	)
+	ts := mockServer(t, f, &fosite.DefaultSession{})
+	defer ts.Close()
+
+	for _, c := range []struct {
+		clientID  string
+		expectJWT bool
+	}{
+		{clientID: "my-client"},
+		{clientID: "jwt-client", expectJWT: true},
+	} {
+		t.Run("client="+c.clientID, func(t *testing.T) {
+			oauthClient := newOAuth2AppClient(ts)
+			oauthClient.ClientID = c.clientID
+			oauthClient.AuthStyle = goauth.AuthStyleInHeader
+			token, err := oauthClient.Token(context.Background())
+			require.NoError(t, err)
+
+			if c.expectJWT {
+				assert.Len(t, strings.Split(token.AccessToken, "."), 3)
+			} else {
+				assert.True(t, strings.HasPrefix(token.AccessToken, "ory_at_"), "%s", token.AccessToken)
+			}
+
+			_, ar, err := f.IntrospectToken(context.Background(), token.AccessToken, fosite.AccessToken, &oauth2.JWTSession{})
+			require.NoError(t, err)
+			assert.Equal(t, c.clientID, ar.GetClient().GetID())
+
+			resp, _, errs := gorequest.New().Post(ts.URL+"/revoke").
+				SetBasicAuth(oauthClient.ClientID, oauthClient.ClientSecret).
+				Type("form").
+				SendStruct(map[string]string{"token": token.AccessToken}).End()
+			require.Len(t, errs, 0)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			_, _, err = f.IntrospectToken(context.Background(), token.AccessToken, fosite.AccessToken, &oauth2.JWTSession{})
+			assert.Error(t, err)
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -29,6 +30,68 @@ func TestPushedAuthorizeCodeFlow(t *testing.T) {
 	}
 }
 
+func TestPushedAuthorizeRequestURILifecycle(t *testing.T) {
+	f := compose.Compose(new(fosite.Config), fositeStore, hmacStrategy, compose.OAuth2AuthorizeExplicitFactory, compose.PushedAuthorizeHandlerFactory)
+	ts := mockServer(t, f, &fosite.DefaultSession{Subject: "foo-sub"})
+	defer ts.Close()
+
+	oauthClient := newOAuth2Client(ts)
+	fositeStore.Clients["my-client"].(*fosite.DefaultClient).RedirectURIs[0] = ts.URL + "/callback"
+
+	t.Run("case=request_uri can only be used once", func(t *testing.T) {
+		requestURI := pushAuthorizeRequest(t, ts.URL, oauthClient)
+
+		resp := authorizeWithRequestURI(t, ts.URL, oauthClient.ClientID, requestURI)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NotEmpty(t, resp.Request.URL.Query().Get("code"))
+
+		resp = authorizeWithRequestURI(t, ts.URL, oauthClient.ClientID, requestURI)
+		assert.NotEqual(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("case=request_uri must not be expired", func(t *testing.T) {
+		requestURI := pushAuthorizeRequest(t, ts.URL, oauthClient)
+		fositeStore.PARSessions[requestURI].GetSession().SetExpiresAt(fosite.PushedAuthorizeRequestContext, time.Now().UTC().Add(-time.Minute))
+
+		resp := authorizeWithRequestURI(t, ts.URL, oauthClient.ClientID, requestURI)
+		assert.NotEqual(t, http.StatusOK, resp.StatusCode)
+		assert.NotContains(t, fositeStore.PARSessions, requestURI)
+	})
+}
+
+func pushAuthorizeRequest(t *testing.T, ts string, oauthClient *goauth.Config) string {
+	data := url.Values{}
+	data.Set("client_id", oauthClient.ClientID)
+	data.Set("client_secret", oauthClient.ClientSecret)
+	data.Set("response_type", "code")
+	data.Set("state", "12345678901234567890")
+	data.Set("scope", strings.Join(oauthClient.Scopes, " "))
+	data.Set("redirect_uri", oauthClient.RedirectURL)
+
+	resp, err := http.PostForm(ts+"/par", data)
+	require.NoError(t, err)
+
+	body, err := checkStatusAndGetBody(t, resp, http.StatusCreated)
+	require.NoError(t, err)
+
+	var m struct {
+		RequestURI string `json:"request_uri"`
+	}
+	require.NoError(t, json.Unmarshal(body, &m))
+	require.NotEmpty(t, m.RequestURI)
+	return m.RequestURI
+}
+
+func authorizeWithRequestURI(t *testing.T, ts, clientID, requestURI string) *http.Response {
+	resp, err := http.PostForm(ts+"/auth", url.Values{
+		"client_id":   {clientID},
+		"request_uri": {requestURI},
+	})
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	return resp
+}
+
 func runPushedAuthorizeCodeGrantTest(t *testing.T, strategy interface{}) {
 	f := compose.Compose(new(fosite.Config), fositeStore, strategy, compose.OAuth2AuthorizeExplicitFactory, compose.OAuth2TokenIntrospectionFactory, compose.PushedAuthorizeHandlerFactory)
 	ts := mockServer(t, f, &fosite.DefaultSession{Subject: "foo-sub"})
@@ -22,6 +22,7 @@ import (
 	"github.com/ory/fosite"
 	"github.com/ory/fosite/compose"
 	"github.com/ory/fosite/handler/openid"
+	"github.com/ory/fosite/storage"
 	"github.com/ory/fosite/token/jwt"
 )
 
@@ -266,3 +267,37 @@ func TestRefreshTokenFlow(t *testing.T) {
 		})
 	}
 }
+
+func TestRefreshTokenFlowRequiresOriginalClient(t *testing.T) {
+	store := storage.NewExampleStore()
+	store.Clients["other-client"] = &fosite.DefaultClient{
+		ID:         "other-client",
+		Secret:     []byte(`$2a$10$IxMdI6d.LIRZPpSfEwNoeu4rY3FhDREsxFJXikcgdRRAStxUlsuEO`), // = "foobar"
+		GrantTypes: []string{"refresh_token", "password"},
+		Scopes:     []string{"fosite", "offline"},
+	}
+
+	f := compose.Compose(&fosite.Config{RefreshTokenScopes: []string{}}, store, hmacStrategy, compose.OAuth2ResourceOwnerPasswordCredentialsFactory, compose.OAuth2RefreshTokenGrantFactory)
+	ts := mockServer(t, f, &fosite.DefaultSession{})
+	defer ts.Close()
+
+	oauthClient := newOAuth2Client(ts)
+	token, err := oauthClient.PasswordCredentialsToken(context.Background(), "peter", "secret")
+	require.NoError(t, err)
+	require.NotEmpty(t, token.RefreshToken)
+
+	t.Run("case=should reject a refresh by another client", func(t *testing.T) {
+		otherClient := newOAuth2Client(ts)
+		otherClient.ClientID = "other-client"
+		_, err := otherClient.TokenSource(context.Background(), &oauth2.Token{RefreshToken: token.RefreshToken}).Token()
+		var retrieveErr *oauth2.RetrieveError
+		require.ErrorAs(t, err, &retrieveErr)
+		assert.Equal(t, "invalid_grant", retrieveErr.ErrorCode)
+	})
+
+	t.Run("case=should refresh by the original client", func(t *testing.T) {
+		refreshed, err := oauthClient.TokenSource(context.Background(), &oauth2.Token{RefreshToken: token.RefreshToken}).Token()
+		require.NoError(t, err)
+		assert.NotEqual(t, token.RefreshToken, refreshed.RefreshToken)
+	})
+}
@@ -0,0 +1,101 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package integration_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	goauth "golang.org/x/oauth2"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/compose"
+	"github.com/ory/fosite/handler/oauth2"
+	"github.com/ory/fosite/storage"
+)
+
+func TestRefreshTokenLineageRetention(t *testing.T) {
+	for _, c := range []struct {
+		d      string
+		config *fosite.Config
+		pruned []int
+		// retained is the index of a retained refresh token whose reuse is detected, or -1.
+		retained int
+	}{
+		{
+			d:        "should retain all inactive refresh tokens by default",
+			config:   &fosite.Config{},
+			retained: 0,
+		},
+		{
+			d:        "should prune all but the most recent inactive refresh token",
+			config:   &fosite.Config{RefreshTokenLineageMaxCount: 1},
+			pruned:   []int{0, 1},
+			retained: 2,
+		},
+		{
+			d:        "should retain inactive refresh tokens within the maximum age",
+			config:   &fosite.Config{RefreshTokenLineageMaxAge: time.Hour},
+			retained: 0,
+		},
+		{
+			d:        "should prune inactive refresh tokens exceeding the maximum age",
+			config:   &fosite.Config{RefreshTokenLineageMaxAge: time.Nanosecond},
+			pruned:   []int{0, 1, 2},
+			retained: -1,
+		},
+	} {
+		t.Run(c.d, func(t *testing.T) {
+			store := storage.NewExampleStore()
+			c.config.RefreshTokenScopes = []string{}
+			f := compose.Compose(c.config, store, hmacStrategy, compose.OAuth2ResourceOwnerPasswordCredentialsFactory, compose.OAuth2RefreshTokenGrantFactory)
+			ts := mockServer(t, f, &fosite.DefaultSession{})
+			defer ts.Close()
+
+			oauthClient := newOAuth2Client(ts)
+			refresh := func(token *goauth.Token) (*goauth.Token, error) {
+				return oauthClient.TokenSource(context.Background(), &goauth.Token{RefreshToken: token.RefreshToken}).Token()
+			}
+
+			// Rotating the refresh token three times leaves a chain of three inactive refresh tokens.
+			token, err := oauthClient.PasswordCredentialsToken(context.Background(), "peter", "secret")
+			require.NoError(t, err)
+			chain := []*goauth.Token{token}
+			for i := 0; i < 3; i++ {
+				token, err = refresh(token)
+				require.NoError(t, err)
+				chain = append(chain, token)
+			}
+
+			require.NoError(t, oauth2.PruneRefreshTokenLineage(context.Background(), c.config, store))
+
+			for _, i := range c.pruned {
+				_, err := refresh(chain[i])
+				var retrieveErr *goauth.RetrieveError
+				require.ErrorAs(t, err, &retrieveErr)
+				assert.Equal(t, "invalid_grant", retrieveErr.ErrorCode, "refresh token %d should have been pruned", i)
+			}
+
+			// Pruned refresh tokens do not revoke the chain.
+			if c.retained < 0 {
+				_, err := refresh(chain[len(chain)-1])
+				assert.NoError(t, err)
+				return
+			}
+
+			// Reusing a retained refresh token is detected and revokes the chain.
+			_, err = refresh(chain[c.retained])
+			var retrieveErr *goauth.RetrieveError
+			require.ErrorAs(t, err, &retrieveErr)
+			assert.Equal(t, http.StatusUnauthorized, retrieveErr.Response.StatusCode, "refresh token %d should have been retained", c.retained)
+
+			_, err = refresh(chain[len(chain)-1])
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package integration_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	goauth "golang.org/x/oauth2"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/compose"
+	"github.com/ory/fosite/handler/oauth2"
+	"github.com/ory/fosite/storage"
+)
+
+func TestRevokeAllTokens(t *testing.T) {
+	store := storage.NewExampleStore()
+	f := compose.Compose(
+		&fosite.Config{RefreshTokenScopes: []string{}},
+		store,
+		hmacStrategy,
+		compose.OAuth2ResourceOwnerPasswordCredentialsFactory,
+		compose.OAuth2TokenIntrospectionFactory,
+	)
+	ts := mockServer(t, f, &fosite.DefaultSession{})
+	defer ts.Close()
+
+	revoker := &oauth2.BulkTokenRevoker{Storage: store}
+	ctx := context.Background()
+
+	issue := func(t *testing.T, clientID string) *goauth.Token {
+		oauthClient := newOAuth2Client(ts)
+		oauthClient.ClientID = clientID
+		token, err := oauthClient.PasswordCredentialsToken(ctx, "peter", "secret")
+		require.NoError(t, err)
+		require.NotEmpty(t, token.AccessToken)
+		require.NotEmpty(t, token.RefreshToken)
+		return token
+	}
+
+	subjectOf := func(t *testing.T, token *goauth.Token) string {
+		_, ar, err := f.IntrospectToken(ctx, token.AccessToken, fosite.AccessToken, &oauth2.JWTSession{})
+		require.NoError(t, err)
+		require.NotEmpty(t, ar.GetSession().GetSubject())
+		return ar.GetSession().GetSubject()
+	}
+
+	assertActive := func(t *testing.T, token *goauth.Token, active bool) {
+		for tokenUse, value := range map[fosite.TokenUse]string{fosite.AccessToken: token.AccessToken, fosite.RefreshToken: token.RefreshToken} {
+			_, _, err := f.IntrospectToken(ctx, value, tokenUse, &oauth2.JWTSession{})
+			if active {
+				assert.NoError(t, err, "%s", tokenUse)
+			} else {
+				assert.Error(t, err, "%s", tokenUse)
+			}
+		}
+	}
+
+	t.Run("case=should revoke all tokens of a subject", func(t *testing.T) {
+		// The example store authenticates every password grant as a new subject.
+		first, second := issue(t, "my-client"), issue(t, "my-client")
+		subject := subjectOf(t, first)
+		require.NotEqual(t, subject, subjectOf(t, second))
+
+		require.NoError(t, revoker.RevokeAllForSubject(ctx, subject))
+		assertActive(t, first, false)
+		assertActive(t, second, true)
+
+		require.NoError(t, revoker.RevokeAllForSubject(ctx, subject), "revoking the tokens again must succeed")
+		assertActive(t, first, false)
+	})
+
+	t.Run("case=should revoke all tokens of a client", func(t *testing.T) {
+		first, second := issue(t, "my-client"), issue(t, "my-client")
+		other := issue(t, "custom-lifespan-client")
+
+		require.NoError(t, revoker.RevokeAllForClient(ctx, "my-client"))
+		assertActive(t, first, false)
+		assertActive(t, second, false)
+		assertActive(t, other, true)
+
+		require.NoError(t, revoker.RevokeAllForClient(ctx, "my-client"), "revoking the tokens again must succeed")
+	})
+
+	t.Run("case=should reject an empty subject or client", func(t *testing.T) {
+		assert.ErrorIs(t, revoker.RevokeAllForSubject(ctx, ""), fosite.ErrInvalidRequest)
+		assert.ErrorIs(t, revoker.RevokeAllForClient(ctx, ""), fosite.ErrInvalidRequest)
+	})
+}
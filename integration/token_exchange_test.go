@@ -0,0 +1,212 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package integration_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/compose"
+	"github.com/ory/fosite/handler/oauth2"
+	"github.com/ory/fosite/handler/rfc8693"
+	"github.com/ory/fosite/token/jwt"
+)
+
+type tokenExchangeSuite struct {
+	suite.Suite
+
+	strategy oauth2.AccessTokenStrategy
+	ts       string
+}
+
+func (s *tokenExchangeSuite) TestImpersonation() {
+	t := s.T()
+	subjectToken := s.issueAccessToken(t, "peter", "fosite", "offline")
+
+	res, body := s.exchange(t, url.Values{
+		"subject_token":      {subjectToken},
+		"subject_token_type": {rfc8693.AccessTokenType},
+	})
+	require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+	assert.Equal(t, "bearer", body["token_type"])
+	assert.Equal(t, rfc8693.AccessTokenType, body["issued_token_type"])
+	assert.Equal(t, "fosite offline", body["scope"])
+	require.NotEmpty(t, body["access_token"])
+	assert.NotEqual(t, subjectToken, body["access_token"])
+
+	introspection := s.introspect(t, body["access_token"].(string))
+	assert.Equal(t, true, introspection["active"])
+	assert.Equal(t, "peter", introspection["sub"])
+	assert.Equal(t, "token-exchange-client", introspection["client_id"])
+	assert.Nil(t, introspection["act"])
+}
+
+func (s *tokenExchangeSuite) TestScopeNarrowing() {
+	t := s.T()
+	subjectToken := s.issueAccessToken(t, "peter", "fosite", "offline")
+
+	res, body := s.exchange(t, url.Values{
+		"subject_token":      {subjectToken},
+		"subject_token_type": {rfc8693.AccessTokenType},
+		"scope":              {"fosite"},
+	})
+	require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+	assert.Equal(t, "fosite", body["scope"])
+
+	introspection := s.introspect(t, body["access_token"].(string))
+	assert.Equal(t, true, introspection["active"])
+	assert.Equal(t, "fosite", introspection["scope"])
+}
+
+func (s *tokenExchangeSuite) TestScopeWidening() {
+	t := s.T()
+	subjectToken := s.issueAccessToken(t, "peter", "fosite")
+
+	res, body := s.exchange(t, url.Values{
+		"subject_token":      {subjectToken},
+		"subject_token_type": {rfc8693.AccessTokenType},
+		"scope":              {"fosite offline"},
+	})
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	assert.Equal(t, "invalid_scope", body["error"])
+}
+
+func (s *tokenExchangeSuite) TestDelegation() {
+	t := s.T()
+	subjectToken := s.issueAccessToken(t, "peter", "fosite")
+	actorToken := s.issueAccessToken(t, "service", "fosite")
+
+	res, body := s.exchange(t, url.Values{
+		"subject_token":      {subjectToken},
+		"subject_token_type": {rfc8693.AccessTokenType},
+		"actor_token":        {actorToken},
+		"actor_token_type":   {rfc8693.AccessTokenType},
+	})
+	require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+
+	introspection := s.introspect(t, body["access_token"].(string))
+	assert.Equal(t, true, introspection["active"])
+	assert.Equal(t, "peter", introspection["sub"])
+	assert.Equal(t, map[string]interface{}{"sub": "service"}, introspection["act"])
+}
+
+func (s *tokenExchangeSuite) TestRequestedJWT() {
+	t := s.T()
+	subjectToken := s.issueAccessToken(t, "peter", "fosite")
+
+	res, body := s.exchange(t, url.Values{
+		"subject_token":        {subjectToken},
+		"subject_token_type":   {rfc8693.AccessTokenType},
+		"requested_token_type": {rfc8693.JWTTokenType},
+	})
+	if _, ok := s.strategy.(*oauth2.DefaultJWTStrategy); !ok {
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Equal(t, "invalid_request", body["error"])
+		return
+	}
+
+	require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+	assert.Equal(t, rfc8693.JWTTokenType, body["issued_token_type"])
+	assert.Len(t, strings.Split(body["access_token"].(string), "."), 3)
+}
+
+func (s *tokenExchangeSuite) TestInvalidSubjectToken() {
+	t := s.T()
+
+	res, body := s.exchange(t, url.Values{
+		"subject_token":      {"foo.bar"},
+		"subject_token_type": {rfc8693.AccessTokenType},
+	})
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	assert.Equal(t, "invalid_request", body["error"])
+}
+
+func (s *tokenExchangeSuite) issueAccessToken(t *testing.T, subject string, scopes ...string) string {
+	ctx := context.Background()
+	client, err := fositeStore.GetClient(ctx, "my-client")
+	require.NoError(t, err)
+
+	request := fosite.NewAccessRequest(&oauth2.JWTSession{
+		JWTClaims: &jwt.JWTClaims{Subject: subject},
+		Subject:   subject,
+	})
+	request.Client = client
+	request.GetSession().SetExpiresAt(fosite.AccessToken, time.Now().UTC().Add(time.Hour))
+	for _, scope := range scopes {
+		request.GrantScope(scope)
+	}
+
+	token, signature, err := s.strategy.GenerateAccessToken(ctx, request)
+	require.NoError(t, err)
+	require.NoError(t, fositeStore.CreateAccessTokenSession(ctx, signature, request))
+	return token
+}
+
+func (s *tokenExchangeSuite) exchange(t *testing.T, form url.Values) (*http.Response, map[string]interface{}) {
+	form.Set("grant_type", string(fosite.GrantTypeTokenExchange))
+	req, err := http.NewRequest(http.MethodPost, s.ts+tokenRelativePath, strings.NewReader(form.Encode()))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("token-exchange-client", "foobar")
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+	return res, body
+}
+
+func (s *tokenExchangeSuite) introspect(t *testing.T, token string) map[string]interface{} {
+	req, err := http.NewRequest(http.MethodPost, s.ts+"/introspect", strings.NewReader(url.Values{
+		"token": {token},
+	}.Encode()))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("my-client", "foobar")
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+	return body
+}
+
+func TestTokenExchangeSuite(t *testing.T) {
+	for _, strategy := range []struct {
+		description string
+		strategy    oauth2.AccessTokenStrategy
+	}{
+		{description: "hmac", strategy: hmacStrategy},
+		{description: "jwt", strategy: jwtStrategy},
+	} {
+		t.Run("strategy="+strategy.description, func(t *testing.T) {
+			provider := compose.Compose(
+				&fosite.Config{TokenExchangeRequestedTokenTypes: []string{rfc8693.AccessTokenType, rfc8693.JWTTokenType}},
+				fositeStore,
+				strategy.strategy,
+				compose.OAuth2TokenIntrospectionFactory,
+				compose.RFC8693TokenExchangeFactory,
+			)
+			testServer := mockServer(t, provider, &fosite.DefaultSession{})
+			defer testServer.Close()
+
+			suite.Run(t, &tokenExchangeSuite{strategy: strategy.strategy, ts: testServer.URL})
+		})
+	}
+}
@@ -63,5 +63,13 @@ func (f *Fosite) IntrospectToken(ctx context.Context, token string, tokenUse Tok
 		return "", nil, errorsx.WithStack(ErrRequestUnauthorized.WithHint("Unable to find a suitable validation strategy for the token, thus it is invalid."))
 	}
 
+	if f.Config.GetRejectTokensWithEmptySubject(ctx) && (ar.GetSession() == nil || ar.GetSession().GetSubject() == "") {
+		return "", nil, errorsx.WithStack(ErrInactiveToken.WithHint("The token is not associated with a subject."))
+	}
+
+	if err := f.validateNetworkBinding(ctx, ar.GetSession()); err != nil {
+		return "", nil, err
+	}
+
 	return foundTokenUse, ar, nil
 }
@@ -6,6 +6,7 @@ package fosite_test
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"testing"
 
@@ -15,6 +16,7 @@ import (
 
 	. "github.com/ory/fosite"
 	"github.com/ory/fosite/compose"
+	"github.com/ory/fosite/handler/oauth2"
 	"github.com/ory/fosite/internal"
 	"github.com/ory/fosite/storage"
 )
@@ -112,3 +114,93 @@ func TestIntrospect(t *testing.T) {
 		})
 	}
 }
+
+func TestIntrospectWithEmptySubject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	validator := internal.NewMockTokenIntrospector(ctrl)
+	defer ctrl.Finish()
+
+	config := new(Config)
+	f := compose.ComposeAllEnabled(config, storage.NewMemoryStore(), nil).(*Fosite)
+	config.TokenIntrospectionHandlers = TokenIntrospectionHandlers{validator}
+
+	for k, c := range []struct {
+		description string
+		strict      bool
+		subject     string
+		expectErr   error
+	}{
+		{description: "should pass with an empty subject by default"},
+		{description: "should fail with an empty subject in strict mode", strict: true, expectErr: ErrInactiveToken},
+		{description: "should pass with a subject in strict mode", strict: true, subject: "peter"},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.description), func(t *testing.T) {
+			config.RejectTokensWithEmptySubject = c.strict
+			validator.EXPECT().IntrospectToken(gomock.Any(), "some-token", gomock.Any(), gomock.Any(), gomock.Any()).Return(AccessToken, nil)
+
+			_, _, err := f.IntrospectToken(context.Background(), "some-token", AccessToken, &DefaultSession{Subject: c.subject})
+			if c.expectErr != nil {
+				require.ErrorIs(t, err, c.expectErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestIntrospectWithNetworkBinding(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	validator := internal.NewMockTokenIntrospector(ctrl)
+	defer ctrl.Finish()
+
+	config := new(Config)
+	f := compose.ComposeAllEnabled(config, storage.NewMemoryStore(), nil).(*Fosite)
+	config.TokenIntrospectionHandlers = TokenIntrospectionHandlers{validator}
+
+	_, network, err := net.ParseCIDR("10.0.0.0/8")
+	require.NoError(t, err)
+
+	for k, c := range []struct {
+		description string
+		bound       bool
+		remoteAddr  string
+		extractor   CallerNetworkExtractor
+		expectErr   error
+	}{
+		{description: "should pass if the token is not bound", remoteAddr: "192.168.0.1:1234"},
+		{description: "should pass if the caller is within the bound network", bound: true, remoteAddr: "10.1.2.3:1234"},
+		{description: "should fail if the caller is outside of the bound network", bound: true, remoteAddr: "192.168.0.1:1234", expectErr: ErrInactiveToken},
+		{description: "should fail if the network of the caller is unknown", bound: true, expectErr: ErrInactiveToken},
+		{
+			description: "should use the configured extractor",
+			bound:       true,
+			remoteAddr:  "192.168.0.1:1234",
+			extractor: func(r *http.Request) (net.IP, error) {
+				return net.ParseIP(r.Header.Get("X-Client-IP")), nil
+			},
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.description), func(t *testing.T) {
+			config.CallerNetworkExtractor = c.extractor
+			validator.EXPECT().IntrospectToken(gomock.Any(), "some-token", gomock.Any(), gomock.Any(), gomock.Any()).Return(AccessToken, nil)
+
+			session := &DefaultSession{Subject: "peter"}
+			if c.bound {
+				require.NoError(t, oauth2.BindToNetwork(session, network))
+			}
+
+			ctx := context.Background()
+			if c.remoteAddr != "" {
+				r := &http.Request{RemoteAddr: c.remoteAddr, Header: http.Header{"X-Client-Ip": {"10.1.2.3"}}}
+				ctx = context.WithValue(ctx, RequestContextKey, r)
+			}
+
+			_, _, err := f.IntrospectToken(ctx, "some-token", AccessToken, session)
+			if c.expectErr != nil {
+				require.ErrorIs(t, err, c.expectErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// DefaultMemoryIntrospectionCacheMaxEntries is the number of entries a MemoryIntrospectionCache holds if no limit is
+// given.
+const DefaultMemoryIntrospectionCacheMaxEntries = 10000
+
+// IntrospectionCache caches the requests of introspected access tokens by their signature, which saves the storage
+// lookup when the same token is introspected repeatedly. Implementations may be backed by a store shared between
+// instances. Entries are invalidated whenever fosite revokes access tokens, that is on revocation, refresh, refresh
+// token or authorization code reuse, bulk revocation and session revocation. Tokens removed from the storage by other
+// means may be reported active until the entry expires, so the time-to-live should be short.
+type IntrospectionCache interface {
+	// Get returns a copy of the cached request of the access token with the given signature. Like
+	// oauth2.CoreStorage.GetAccessTokenSession, the session of the request is read into the given session, if it is
+	// not nil. Changes to the returned request must not affect the cached request.
+	Get(ctx context.Context, signature string, session Session) (Requester, bool)
+
+	// Set caches the request of the access token with the given signature.
+	Set(ctx context.Context, signature string, requester Requester)
+
+	// Invalidate removes the entries of all tokens issued by the request with the given ID. It must have returned
+	// before the revocation is acknowledged.
+	Invalidate(ctx context.Context, requestID string)
+
+	// InvalidateSubject removes the entries of all tokens issued to the subject.
+	InvalidateSubject(ctx context.Context, subject string)
+
+	// InvalidateClient removes the entries of all tokens issued to the client.
+	InvalidateClient(ctx context.Context, clientID string)
+}
+
+// InvalidateIntrospectionCache removes the entries of all tokens issued by the request with the given ID from the
+// introspection cache, if one is configured.
+func InvalidateIntrospectionCache(ctx context.Context, config IntrospectionCacheProvider, requestID string) {
+	if config == nil {
+		return
+	}
+	if cache := config.GetIntrospectionCache(ctx); cache != nil {
+		cache.Invalidate(ctx, requestID)
+	}
+}
+
+type memoryIntrospectionCacheEntry struct {
+	signature string
+	requester Requester
+	expiresAt time.Time
+}
+
+// MemoryIntrospectionCache is an IntrospectionCache which keeps a bounded number of entries in memory for a fixed
+// time-to-live. If the cache is full, the oldest entry is evicted.
+type MemoryIntrospectionCache struct {
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List
+	mutex      sync.Mutex
+}
+
+var _ IntrospectionCache = (*MemoryIntrospectionCache)(nil)
+
+// NewMemoryIntrospectionCache returns a MemoryIntrospectionCache whose entries expire after ttl and which holds at
+// most maxEntries entries. If maxEntries is not positive, DefaultMemoryIntrospectionCacheMaxEntries is used.
+func NewMemoryIntrospectionCache(ttl time.Duration, maxEntries int) *MemoryIntrospectionCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMemoryIntrospectionCacheMaxEntries
+	}
+	return &MemoryIntrospectionCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+func (c *MemoryIntrospectionCache) Get(_ context.Context, signature string, session Session) (Requester, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[signature]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*memoryIntrospectionCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.remove(element)
+		return nil, false
+	}
+
+	requester, err := cloneRequester(entry.requester, session)
+	if err != nil {
+		return nil, false
+	}
+	return requester, true
+}
+
+func (c *MemoryIntrospectionCache) Set(_ context.Context, signature string, requester Requester) {
+	// The entry is a copy, so that the caller can not change the cached request.
+	cached, err := cloneRequester(requester, nil)
+	if err != nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.entries[signature]; ok {
+		c.remove(element)
+	}
+
+	// Entries share the time-to-live, so the oldest entries are at the front.
+	now := time.Now()
+	for front := c.order.Front(); front != nil && (c.order.Len() >= c.maxEntries || now.After(front.Value.(*memoryIntrospectionCacheEntry).expiresAt)); front = c.order.Front() {
+		c.remove(front)
+	}
+
+	c.entries[signature] = c.order.PushBack(&memoryIntrospectionCacheEntry{signature: signature, requester: cached, expiresAt: now.Add(c.ttl)})
+}
+
+func (c *MemoryIntrospectionCache) Invalidate(_ context.Context, requestID string) {
+	c.removeWhere(func(requester Requester) bool {
+		return requester.GetID() == requestID
+	})
+}
+
+func (c *MemoryIntrospectionCache) InvalidateSubject(_ context.Context, subject string) {
+	c.removeWhere(func(requester Requester) bool {
+		return requester.GetSession() != nil && requester.GetSession().GetSubject() == subject
+	})
+}
+
+func (c *MemoryIntrospectionCache) InvalidateClient(_ context.Context, clientID string) {
+	c.removeWhere(func(requester Requester) bool {
+		return requester.GetClient() != nil && requester.GetClient().GetID() == clientID
+	})
+}
+
+func (c *MemoryIntrospectionCache) removeWhere(matches func(requester Requester) bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for element := c.order.Front(); element != nil; {
+		next := element.Next()
+		if matches(element.Value.(*memoryIntrospectionCacheEntry).requester) {
+			c.remove(element)
+		}
+		element = next
+	}
+}
+
+func (c *MemoryIntrospectionCache) remove(element *list.Element) {
+	delete(c.entries, element.Value.(*memoryIntrospectionCacheEntry).signature)
+	c.order.Remove(element)
+}
+
+// cloneRequester returns a copy of the request. If session is not nil, the session of the request is read into it,
+// otherwise the session is copied using Session.Clone.
+func cloneRequester(requester Requester, session Session) (Requester, error) {
+	var clone Requester
+	switch r := requester.(type) {
+	case *AccessRequest:
+		clone = r.Clone()
+	case *Request:
+		clone = r.Clone()
+	default:
+		allowed := make([]string, 0, len(requester.GetRequestForm()))
+		for k := range requester.GetRequestForm() {
+			allowed = append(allowed, k)
+		}
+		clone = requester.Sanitize(allowed)
+		if requester.GetSession() != nil {
+			clone.SetSession(requester.GetSession().Clone())
+		}
+	}
+	// The ID is generated on first use, so it is set explicitly to keep the copies consistent.
+	clone.SetID(requester.GetID())
+
+	if session == nil || requester.GetSession() == nil {
+		return clone, nil
+	}
+
+	data, err := json.Marshal(requester.GetSession())
+	if err != nil {
+		return nil, err
+	} else if err := json.Unmarshal(data, session); err != nil {
+		return nil, err
+	}
+	clone.SetSession(session)
+	return clone, nil
+}
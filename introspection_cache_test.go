@@ -0,0 +1,86 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ory/fosite"
+)
+
+func TestMemoryIntrospectionCache(t *testing.T) {
+	ctx := context.Background()
+
+	newRequest := func(id, subject, clientID string) *AccessRequest {
+		ar := NewAccessRequest(&DefaultSession{Subject: subject})
+		ar.ID = id
+		ar.Client = &DefaultClient{ID: clientID}
+		ar.GrantScope("foo")
+		return ar
+	}
+
+	t.Run("case=should return a copy read into the given session", func(t *testing.T) {
+		cache := NewMemoryIntrospectionCache(time.Minute, 0)
+		cached := newRequest("request", "peter", "foo")
+		cache.Set(ctx, "signature", cached)
+		cached.GrantScope("bar")
+
+		session := new(DefaultSession)
+		r, ok := cache.Get(ctx, "signature", session)
+		require.True(t, ok)
+		assert.Equal(t, Arguments{"foo"}, r.GetGrantedScopes())
+		assert.Same(t, session, r.GetSession())
+		assert.Equal(t, "peter", session.Subject)
+
+		r.GrantScope("baz")
+		r.GetSession().(*DefaultSession).Subject = "alice"
+		r, ok = cache.Get(ctx, "signature", nil)
+		require.True(t, ok)
+		assert.Equal(t, Arguments{"foo"}, r.GetGrantedScopes())
+		assert.Equal(t, "peter", r.GetSession().GetSubject())
+	})
+
+	t.Run("case=should expire entries", func(t *testing.T) {
+		cache := NewMemoryIntrospectionCache(-time.Second, 0)
+		cache.Set(ctx, "signature", newRequest("request", "peter", "foo"))
+		_, ok := cache.Get(ctx, "signature", nil)
+		assert.False(t, ok)
+	})
+
+	t.Run("case=should evict the oldest entries if full", func(t *testing.T) {
+		cache := NewMemoryIntrospectionCache(time.Minute, 2)
+		cache.Set(ctx, "first", newRequest("first", "peter", "foo"))
+		cache.Set(ctx, "second", newRequest("second", "peter", "foo"))
+		cache.Set(ctx, "third", newRequest("third", "peter", "foo"))
+
+		_, ok := cache.Get(ctx, "first", nil)
+		assert.False(t, ok)
+		for _, signature := range []string{"second", "third"} {
+			_, ok := cache.Get(ctx, signature, nil)
+			assert.True(t, ok, signature)
+		}
+	})
+
+	t.Run("case=should invalidate entries", func(t *testing.T) {
+		cache := NewMemoryIntrospectionCache(time.Minute, 0)
+		cache.Set(ctx, "request", newRequest("request", "peter", "foo"))
+		cache.Set(ctx, "subject", newRequest("subject", "alice", "foo"))
+		cache.Set(ctx, "client", newRequest("client", "peter", "bar"))
+		cache.Set(ctx, "other", newRequest("other", "peter", "foo"))
+
+		cache.Invalidate(ctx, "request")
+		cache.InvalidateSubject(ctx, "alice")
+		cache.InvalidateClient(ctx, "bar")
+
+		for signature, expected := range map[string]bool{"request": false, "subject": false, "client": false, "other": true} {
+			_, ok := cache.Get(ctx, signature, nil)
+			assert.Equal(t, expected, ok, signature)
+		}
+	})
+}
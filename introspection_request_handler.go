@@ -101,7 +101,9 @@ func (f *Fosite) NewIntrospectionRequest(ctx context.Context, r *http.Request, s
 
 	ctx = context.WithValue(ctx, RequestContextKey, r)
 
-	if r.Method != "POST" {
+	if err := f.checkTLS(ctx, r); err != nil {
+		return &IntrospectionResponse{Active: false}, err
+	} else if r.Method != "POST" {
 		return &IntrospectionResponse{Active: false}, errorsx.WithStack(ErrInvalidRequest.WithHintf("HTTP method is '%s' but expected 'POST'.", r.Method))
 	} else if err := r.ParseMultipartForm(1 << 20); err != nil && err != http.ErrNotMultipart {
 		return &IntrospectionResponse{Active: false}, errorsx.WithStack(ErrInvalidRequest.WithHint("Unable to parse HTTP body, make sure to send a properly formatted form request body.").WithWrap(err).WithDebug(err.Error()))
@@ -149,10 +151,21 @@ func (f *Fosite) NewIntrospectionRequest(ctx context.Context, r *http.Request, s
 		}
 	}
 
-	tu, ar, err := f.IntrospectToken(ctx, token, TokenUse(tokenTypeHint), session, RemoveEmpty(strings.Split(scope, " "))...)
+	if err := f.validateTokenTypeHint(ctx, TokenUse(tokenTypeHint)); err != nil {
+		return &IntrospectionResponse{Active: false}, err
+	}
+
+	scopes := RemoveEmpty(strings.Split(scope, " "))
+	tu, ar, err := f.IntrospectToken(ctx, token, TokenUse(tokenTypeHint), session, scopes...)
 	if err != nil {
 		return &IntrospectionResponse{Active: false}, errorsx.WithStack(ErrInactiveToken.WithHint("An introspection strategy indicated that the token is inactive.").WithWrap(err).WithDebug(err.Error()))
 	}
+
+	// The token is only active if it was granted all requested scopes. In that case, report only the requested
+	// scopes so that the response does not disclose the other scopes of the token.
+	if r, ok := ar.(*AccessRequest); ok && len(scopes) > 0 {
+		r.GrantedScope = Arguments(scopes)
+	}
 	accessTokenType := ""
 
 	if tu == AccessToken {
@@ -5,6 +5,7 @@ package fosite_test
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -205,6 +206,64 @@ func TestNewIntrospectionRequest(t *testing.T) {
 			},
 			isActive: true,
 		},
+		{
+			description: "should pass and ignore an unknown token type hint",
+			setup: func() {
+				config.TokenIntrospectionHandlers = TokenIntrospectionHandlers{validator}
+				httpreq = &http.Request{
+					Method: "POST",
+					Header: http.Header{
+						"Authorization": []string{"bearer some-token"},
+					},
+					PostForm: url.Values{
+						"token":           []string{"introspect-token"},
+						"token_type_hint": []string{"unknown_token"},
+					},
+				}
+				validator.EXPECT().IntrospectToken(ctx, "some-token", gomock.Any(), gomock.Any(), gomock.Any()).Return(TokenUse(""), nil)
+				validator.EXPECT().IntrospectToken(ctx, "introspect-token", gomock.Any(), gomock.Any(), gomock.Any()).Return(AccessToken, nil)
+			},
+			isActive: true,
+		},
+		{
+			description: "should fail because the token type hint is unknown in strict mode",
+			setup: func() {
+				config.TokenIntrospectionHandlers = TokenIntrospectionHandlers{validator}
+				config.RejectUnknownTokenTypeHint = true
+				httpreq = &http.Request{
+					Method: "POST",
+					Header: http.Header{
+						"Authorization": []string{"bearer some-token"},
+					},
+					PostForm: url.Values{
+						"token":           []string{"introspect-token"},
+						"token_type_hint": []string{"unknown_token"},
+					},
+				}
+				validator.EXPECT().IntrospectToken(ctx, "some-token", gomock.Any(), gomock.Any(), gomock.Any()).Return(TokenUse(""), nil)
+			},
+			expectErr: ErrInvalidRequest,
+		},
+		{
+			description: "should pass with a known token type hint in strict mode",
+			setup: func() {
+				config.TokenIntrospectionHandlers = TokenIntrospectionHandlers{validator}
+				config.RejectUnknownTokenTypeHint = true
+				httpreq = &http.Request{
+					Method: "POST",
+					Header: http.Header{
+						"Authorization": []string{"bearer some-token"},
+					},
+					PostForm: url.Values{
+						"token":           []string{"introspect-token"},
+						"token_type_hint": []string{"refresh_token"},
+					},
+				}
+				validator.EXPECT().IntrospectToken(ctx, "some-token", gomock.Any(), gomock.Any(), gomock.Any()).Return(TokenUse(""), nil)
+				validator.EXPECT().IntrospectToken(ctx, "introspect-token", gomock.Any(), gomock.Any(), gomock.Any()).Return(RefreshToken, nil)
+			},
+			isActive: true,
+		},
 	} {
 		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
 			c.setup()
@@ -219,3 +278,38 @@ func TestNewIntrospectionRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestNewIntrospectionRequestWithRequireTLS(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	validator := internal.NewMockTokenIntrospector(ctrl)
+	defer ctrl.Finish()
+
+	config := &Config{RequireTLS: true}
+	f := compose.ComposeAllEnabled(config, storage.NewExampleStore(), nil).(*Fosite)
+	config.TokenIntrospectionHandlers = TokenIntrospectionHandlers{validator}
+
+	newRequest := func() *http.Request {
+		return &http.Request{
+			Method:   "POST",
+			Header:   http.Header{"Authorization": []string{"bearer some-token"}},
+			PostForm: url.Values{"token": []string{"introspect-token"}},
+		}
+	}
+
+	t.Run("case=plaintext request is rejected", func(t *testing.T) {
+		res, err := f.NewIntrospectionRequest(context.TODO(), newRequest(), &DefaultSession{})
+		require.ErrorIs(t, err, ErrInvalidRequest)
+		assert.False(t, res.IsActive())
+	})
+
+	t.Run("case=request over TLS is accepted", func(t *testing.T) {
+		validator.EXPECT().IntrospectToken(gomock.Any(), "some-token", gomock.Any(), gomock.Any(), gomock.Any()).Return(TokenUse(""), nil)
+		validator.EXPECT().IntrospectToken(gomock.Any(), "introspect-token", gomock.Any(), gomock.Any(), gomock.Any()).Return(AccessToken, nil)
+
+		r := newRequest()
+		r.TLS = new(tls.ConnectionState)
+		res, err := f.NewIntrospectionRequest(context.TODO(), r, &DefaultSession{})
+		require.NoError(t, err)
+		assert.True(t, res.IsActive())
+	})
+}
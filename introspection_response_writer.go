@@ -9,9 +9,16 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/ory/x/errorsx"
 	"github.com/pkg/errors"
 )
 
+// IntrospectionResponseModifier is called before an introspection response is written. It may add fields to the
+// response by setting extra claims on the session of the access requester, which is a copy of the introspected
+// request. The access requester is nil if the token is inactive. The modifier may deactivate an active token, but
+// it can not activate an inactive one.
+type IntrospectionResponseModifier func(ctx context.Context, response IntrospectionResponder, requester AccessRequester) error
+
 // WriteIntrospectionError responds with token metadata discovered by token introspection as defined in
 // https://tools.ietf.org/search/rfc7662#section-2.2
 //
@@ -38,8 +45,10 @@ func (f *Fosite) WriteIntrospectionError(ctx context.Context, rw http.ResponseWr
 		return
 	}
 
-	// Inactive token errors should never written out as an error.
-	if !errors.Is(err, ErrInactiveToken) && (errors.Is(err, ErrInvalidRequest) || errors.Is(err, ErrRequestUnauthorized)) {
+	// Inactive token errors should never written out as an error. Invalid requests are not written out as an error
+	// either, if the caller authenticated and an inactive token response was configured for failed requests.
+	invalidRequest := errors.Is(err, ErrInvalidRequest) && !f.Config.GetIntrospectionRespondInactiveOnError(ctx)
+	if !errors.Is(err, ErrInactiveToken) && (invalidRequest || errors.Is(err, ErrRequestUnauthorized)) {
 		f.writeJsonError(ctx, rw, nil, err)
 		return
 	}
@@ -183,7 +192,31 @@ func (f *Fosite) WriteIntrospectionResponse(ctx context.Context, rw http.Respons
 	rw.Header().Set("Cache-Control", "no-store")
 	rw.Header().Set("Pragma", "no-cache")
 
-	if !r.IsActive() {
+	active := r.IsActive()
+	if modifier := f.Config.GetIntrospectionResponseModifier(ctx); modifier != nil {
+		modified := &IntrospectionResponse{
+			Active:          active,
+			TokenUse:        r.GetTokenUse(),
+			AccessTokenType: r.GetAccessTokenType(),
+		}
+		if active {
+			modified.AccessRequester = r.GetAccessRequester()
+			if ar, ok := modified.AccessRequester.(*AccessRequest); ok {
+				// Work on a copy so that the modifier can not change the stored request.
+				modified.AccessRequester = ar.Clone()
+			}
+		}
+
+		if err := modifier(ctx, modified, modified.AccessRequester); err != nil {
+			f.writeJsonError(ctx, rw, nil, errorsx.WithStack(ErrServerError.WithWrap(err).WithDebug(err.Error())))
+			return
+		}
+
+		active = active && modified.IsActive()
+		r = modified
+	}
+
+	if !active {
 		_ = json.NewEncoder(rw).Encode(&struct {
 			Active bool `json:"active"`
 		}{Active: false})
@@ -200,7 +233,7 @@ func (f *Fosite) WriteIntrospectionResponse(ctx context.Context, rw http.Respons
 		for name, value := range extraClaims {
 			switch name {
 			// We do not allow these to be set through extra claims.
-			case "exp", "client_id", "scope", "iat", "sub", "aud", "username":
+			case "exp", "client_id", "scope", "iat", "sub", "aud", "username", "authorization_details":
 				continue
 			default:
 				response[name] = value
@@ -214,8 +247,9 @@ func (f *Fosite) WriteIntrospectionResponse(ctx context.Context, rw http.Respons
 	if r.GetAccessRequester().GetClient().GetID() != "" {
 		response["client_id"] = r.GetAccessRequester().GetClient().GetID()
 	}
-	if len(r.GetAccessRequester().GetGrantedScopes()) > 0 {
-		response["scope"] = strings.Join(r.GetAccessRequester().GetGrantedScopes(), " ")
+	// Clients may treat an empty scope differently from an absent one, so the scope is omitted if none were granted.
+	if scope := strings.Join(RemoveEmpty(r.GetAccessRequester().GetGrantedScopes()), " "); scope != "" {
+		response["scope"] = scope
 	}
 	if !r.GetAccessRequester().GetRequestedAt().IsZero() {
 		response["iat"] = r.GetAccessRequester().GetRequestedAt().Unix()
@@ -229,6 +263,14 @@ func (f *Fosite) WriteIntrospectionResponse(ctx context.Context, rw http.Respons
 	if r.GetAccessRequester().GetSession().GetUsername() != "" {
 		response["username"] = r.GetAccessRequester().GetSession().GetUsername()
 	}
+	if details, ok := r.GetAccessRequester().(AuthorizationDetailsRequester); ok && len(details.GetGrantedAuthorizationDetails()) > 0 {
+		response["authorization_details"] = details.GetGrantedAuthorizationDetails()
+	}
+	if f.Config.GetIntrospectionIncludeIssuer(ctx) {
+		if issuer := f.Config.GetAccessTokenIssuer(ctx); issuer != "" {
+			response["iss"] = issuer
+		}
+	}
 
 	_ = json.NewEncoder(rw).Encode(response)
 }
@@ -6,8 +6,10 @@ package fosite_test
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
@@ -44,10 +46,18 @@ func TestWriteIntrospectionError(t *testing.T) {
 	f.WriteIntrospectionError(context.Background(), rw, errorsx.WithStack(ErrInactiveToken.WithWrap(ErrRequestUnauthorized)))
 
 	f.WriteIntrospectionError(context.Background(), rw, nil)
+
+	f.Config.(*Config).IntrospectionRespondInactiveOnError = true
+	rw.EXPECT().Write([]byte("{\"active\":false}\n"))
+	f.WriteIntrospectionError(context.Background(), rw, errorsx.WithStack(ErrInvalidRequest))
+
+	rw.EXPECT().WriteHeader(http.StatusUnauthorized)
+	rw.EXPECT().Write(gomock.Any())
+	f.WriteIntrospectionError(context.Background(), rw, errorsx.WithStack(ErrRequestUnauthorized))
 }
 
 func TestWriteIntrospectionResponse(t *testing.T) {
-	f := new(Fosite)
+	f := &Fosite{Config: new(Config)}
 	c := gomock.NewController(t)
 	defer c.Finish()
 
@@ -60,7 +70,7 @@ func TestWriteIntrospectionResponse(t *testing.T) {
 }
 
 func TestWriteIntrospectionResponseBody(t *testing.T) {
-	f := new(Fosite)
+	f := &Fosite{Config: new(Config)}
 	ires := &IntrospectionResponse{}
 	rw := httptest.NewRecorder()
 
@@ -170,3 +180,167 @@ func TestWriteIntrospectionResponseBody(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteIntrospectionResponseIsStable(t *testing.T) {
+	f := &Fosite{Config: new(Config)}
+	sess := &DefaultSession{Subject: "peter"}
+	for _, claim := range []string{"zeta", "alpha", "mu", "beta", "omega", "gamma"} {
+		sess.GetExtraClaims()[claim] = map[string]interface{}{"b": claim, "a": claim}
+	}
+	ar := NewAccessRequest(sess)
+	ar.Client = &DefaultClient{ID: "foo"}
+	ar.GrantedScope = Arguments{"foo", "bar"}
+	ar.GrantedAudience = Arguments{"https://api.example.com"}
+	ires := &IntrospectionResponse{Active: true, AccessRequester: ar}
+
+	var expected string
+	for i := 0; i < 20; i++ {
+		rw := httptest.NewRecorder()
+		f.WriteIntrospectionResponse(context.Background(), rw, ires)
+		require.Equal(t, http.StatusOK, rw.Code)
+		if i == 0 {
+			expected = rw.Body.String()
+			continue
+		}
+		assert.Equal(t, expected, rw.Body.String())
+	}
+
+	// encoding/json sorts the keys of maps.
+	assert.Equal(t, `{"active":true,"alpha":{"a":"alpha","b":"alpha"},"aud":["https://api.example.com"],"beta":{"a":"beta","b":"beta"},"client_id":"foo",`+
+		`"gamma":{"a":"gamma","b":"gamma"},"iat":`+strconv.FormatInt(ar.RequestedAt.Unix(), 10)+`,"mu":{"a":"mu","b":"mu"},"omega":{"a":"omega","b":"omega"},`+
+		`"scope":"foo bar","sub":"peter","zeta":{"a":"zeta","b":"zeta"}}`+"\n", expected)
+}
+
+func TestWriteIntrospectionResponseWithModifier(t *testing.T) {
+	newRequester := func() *AccessRequest {
+		ar := NewAccessRequest(&DefaultSession{Subject: "peter"})
+		ar.Client = &DefaultClient{ID: "foo"}
+		return ar
+	}
+
+	t.Run("case=modifier adds a custom field", func(t *testing.T) {
+		config := &Config{IntrospectionResponseModifier: func(ctx context.Context, response IntrospectionResponder, requester AccessRequester) error {
+			requester.GetSession().(ExtraClaimsSession).GetExtraClaims()["tenant"] = "acme"
+			return nil
+		}}
+		f := &Fosite{Config: config}
+		ar := newRequester()
+
+		rw := httptest.NewRecorder()
+		f.WriteIntrospectionResponse(context.Background(), rw, &IntrospectionResponse{Active: true, AccessRequester: ar})
+		require.Equal(t, http.StatusOK, rw.Code)
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &body))
+		assert.Equal(t, true, body["active"])
+		assert.Equal(t, "acme", body["tenant"])
+		assert.Equal(t, "peter", body["sub"])
+		assert.Empty(t, ar.GetSession().(ExtraClaimsSession).GetExtraClaims(), "the introspected request must not be modified")
+	})
+
+	t.Run("case=modifier can not activate an inactive token", func(t *testing.T) {
+		var called bool
+		config := &Config{IntrospectionResponseModifier: func(ctx context.Context, response IntrospectionResponder, requester AccessRequester) error {
+			called = true
+			assert.Nil(t, requester)
+			response.(*IntrospectionResponse).Active = true
+			response.(*IntrospectionResponse).AccessRequester = newRequester()
+			return nil
+		}}
+		f := &Fosite{Config: config}
+
+		rw := httptest.NewRecorder()
+		f.WriteIntrospectionResponse(context.Background(), rw, &IntrospectionResponse{Active: false})
+		require.Equal(t, http.StatusOK, rw.Code)
+		assert.True(t, called)
+		assert.JSONEq(t, `{"active":false}`, rw.Body.String())
+	})
+
+	t.Run("case=modifier can deactivate an active token", func(t *testing.T) {
+		config := &Config{IntrospectionResponseModifier: func(ctx context.Context, response IntrospectionResponder, requester AccessRequester) error {
+			response.(*IntrospectionResponse).Active = false
+			return nil
+		}}
+		f := &Fosite{Config: config}
+
+		rw := httptest.NewRecorder()
+		f.WriteIntrospectionResponse(context.Background(), rw, &IntrospectionResponse{Active: true, AccessRequester: newRequester()})
+		assert.JSONEq(t, `{"active":false}`, rw.Body.String())
+	})
+
+	t.Run("case=modifier fails", func(t *testing.T) {
+		config := &Config{IntrospectionResponseModifier: func(ctx context.Context, response IntrospectionResponder, requester AccessRequester) error {
+			return errors.New("some error")
+		}}
+		f := &Fosite{Config: config}
+
+		rw := httptest.NewRecorder()
+		f.WriteIntrospectionResponse(context.Background(), rw, &IntrospectionResponse{Active: true, AccessRequester: newRequester()})
+		assert.Equal(t, http.StatusInternalServerError, rw.Code)
+		assert.NotContains(t, rw.Body.String(), "peter")
+	})
+}
+
+func TestWriteIntrospectionResponseWithIssuer(t *testing.T) {
+	for k, c := range []struct {
+		d       string
+		config  *Config
+		claims  map[string]interface{}
+		expects interface{}
+	}{
+		{d: "should omit the issuer by default", config: &Config{AccessTokenIssuer: "https://auth.example.com"}},
+		{d: "should include the issuer if enabled", config: &Config{AccessTokenIssuer: "https://auth.example.com", IntrospectionIncludeIssuer: true}, expects: "https://auth.example.com"},
+		{d: "should omit an empty issuer", config: &Config{IntrospectionIncludeIssuer: true}},
+		{
+			d:       "should not allow extra claims to override the issuer",
+			config:  &Config{AccessTokenIssuer: "https://auth.example.com", IntrospectionIncludeIssuer: true},
+			claims:  map[string]interface{}{"iss": "https://spoofed.example.com"},
+			expects: "https://auth.example.com",
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.d), func(t *testing.T) {
+			f := &Fosite{Config: c.config}
+			ar := NewAccessRequest(&DefaultSession{Subject: "peter", Extra: c.claims})
+			ar.Client = &DefaultClient{ID: "foo"}
+
+			rw := httptest.NewRecorder()
+			f.WriteIntrospectionResponse(context.Background(), rw, &IntrospectionResponse{Active: true, AccessRequester: ar})
+			require.Equal(t, http.StatusOK, rw.Code)
+
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &body))
+			assert.Equal(t, c.expects, body["iss"])
+		})
+	}
+}
+
+func TestWriteIntrospectionResponseOmitsEmptyScope(t *testing.T) {
+	for k, c := range []struct {
+		d       string
+		scopes  Arguments
+		expects interface{}
+	}{
+		{d: "should omit the scope if none were granted"},
+		{d: "should omit the scope if only empty scopes were granted", scopes: Arguments{""}},
+		{d: "should include the granted scopes", scopes: Arguments{"foo", "", "bar"}, expects: "foo bar"},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.d), func(t *testing.T) {
+			f := &Fosite{Config: new(Config)}
+			ar := NewAccessRequest(&DefaultSession{Subject: "peter"})
+			ar.Client = &DefaultClient{ID: "foo"}
+			for _, scope := range c.scopes {
+				ar.GrantScope(scope)
+			}
+
+			rw := httptest.NewRecorder()
+			f.WriteIntrospectionResponse(context.Background(), rw, &IntrospectionResponse{Active: true, AccessRequester: ar})
+			require.Equal(t, http.StatusOK, rw.Code)
+
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &body))
+			scope, ok := body["scope"]
+			assert.Equal(t, c.expects != nil, ok)
+			assert.Equal(t, c.expects, scope)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Events emitted to the Logger at the decision points of the token endpoint.
+const (
+	LogEventClientAuthenticated        = "client_authenticated"
+	LogEventClientAuthenticationFailed = "client_authentication_failed"
+	LogEventScopeDenied                = "scope_denied"
+	LogEventGrantRejected              = "grant_rejected"
+	LogEventTokenIssued                = "token_issued"
+)
+
+// Fields passed to the Logger.
+const (
+	LogFieldClientID  = "client_id"
+	LogFieldGrantType = "grant_type"
+	LogFieldOutcome   = "outcome"
+	LogFieldError     = "error"
+)
+
+// Outcomes passed to the Logger in the LogFieldOutcome field.
+const (
+	LogOutcomeSuccess = "success"
+	LogOutcomeFailure = "failure"
+)
+
+// LogFields are the key/value pairs attached to a log event. They never contain secrets, credentials or token
+// values.
+type LogFields map[string]interface{}
+
+// Logger receives structured events from the OAuth 2.0 flows, which allows correlating failures with the client
+// and grant involved. Only the token endpoint emits events, the authorization, introspection, revocation and other
+// endpoints do not.
+type Logger interface {
+	// LogEvent is called with the name of the event and its fields.
+	LogEvent(ctx context.Context, event string, fields LogFields)
+}
+
+type noopLogger struct{}
+
+func (noopLogger) LogEvent(context.Context, string, LogFields) {}
+
+// accessRequestLogFields returns the fields describing the access request. The client ID is taken from the
+// authenticated client, or from the request if the client could not be authenticated.
+func accessRequestLogFields(r *http.Request, ar AccessRequester, err error) LogFields {
+	fields := LogFields{LogFieldOutcome: LogOutcomeSuccess}
+	if err != nil {
+		fields[LogFieldOutcome] = LogOutcomeFailure
+		fields[LogFieldError] = ErrorToRFC6749Error(err).ErrorField
+	}
+
+	if ar == nil {
+		return fields
+	}
+
+	fields[LogFieldGrantType] = strings.Join(ar.GetGrantTypes(), " ")
+	if client := ar.GetClient(); client != nil && client.GetID() != "" {
+		fields[LogFieldClientID] = client.GetID()
+	} else if r != nil {
+		if id, _, ok := r.BasicAuth(); ok {
+			// The client ID is URL encoded in the Authorization header, see AuthenticateClient.
+			if id, err := url.QueryUnescape(id); err == nil {
+				fields[LogFieldClientID] = id
+			}
+		} else if id := r.PostForm.Get("client_id"); id != "" {
+			fields[LogFieldClientID] = id
+		}
+	}
+
+	return fields
+}
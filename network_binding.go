@@ -0,0 +1,74 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/ory/x/errorsx"
+)
+
+// NetworkBindingMember is the member of the "cnf" claim which carries the network, in CIDR notation, a token is
+// bound to. Tokens bound to a network are only active if they are used by a caller from within that network.
+const NetworkBindingMember = "net"
+
+// CallerNetworkExtractor returns the IP address of the caller of the request. For token introspection requests, the
+// caller is the client which presented the token to the resource server, so the extractor must read the address the
+// resource server forwards, for example from an HTTP header which can not be set by clients.
+type CallerNetworkExtractor func(r *http.Request) (net.IP, error)
+
+// DefaultCallerNetworkExtractor returns the IP address of the remote address of the request.
+func DefaultCallerNetworkExtractor(r *http.Request) (net.IP, error) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return net.ParseIP(host), nil
+}
+
+// GetNetworkBinding returns the network ("cnf.net") the session is bound to, or an empty string if the session is not
+// bound to a network.
+func GetNetworkBinding(session Session) string {
+	s, ok := session.(ExtraClaimsSession)
+	if !ok {
+		return ""
+	}
+
+	cnf, ok := s.GetExtraClaims()["cnf"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	network, _ := cnf[NetworkBindingMember].(string)
+	return network
+}
+
+// validateNetworkBinding checks that a token bound to a network is used by a caller from within that network. Tokens
+// which are not bound are accepted.
+func (f *Fosite) validateNetworkBinding(ctx context.Context, session Session) error {
+	network := GetNetworkBinding(session)
+	if network == "" {
+		return nil
+	}
+
+	_, bound, err := net.ParseCIDR(network)
+	if err != nil {
+		return errorsx.WithStack(ErrInactiveToken.WithHint("The token is bound to a malformed network.").WithWrap(err).WithDebug(err.Error()))
+	}
+
+	r, _ := ctx.Value(RequestContextKey).(*http.Request)
+	if r == nil {
+		return errorsx.WithStack(ErrInactiveToken.WithHint("The token is bound to a network, but the network of the caller is unknown."))
+	}
+
+	ip, err := f.Config.GetCallerNetworkExtractor(ctx)(r)
+	if err != nil {
+		return errorsx.WithStack(ErrInactiveToken.WithHint("Unable to determine the network of the caller.").WithWrap(err).WithDebug(err.Error()))
+	} else if ip == nil || !bound.Contains(ip) {
+		return errorsx.WithStack(ErrInactiveToken.WithHint("The token is bound to a different network than the one of the caller."))
+	}
+	return nil
+}
@@ -25,13 +25,23 @@ const (
 	IDToken       TokenType = "id_token"
 	// PushedAuthorizeRequestContext represents the PAR context object
 	PushedAuthorizeRequestContext TokenType = "par_context"
+	// DeviceCode represents the device code of a device authorization request
+	DeviceCode TokenType = "device_code"
+	// UserCode represents the user code of a device authorization request
+	UserCode TokenType = "user_code"
 
 	GrantTypeImplicit          GrantType = "implicit"
 	GrantTypeRefreshToken      GrantType = "refresh_token"
 	GrantTypeAuthorizationCode GrantType = "authorization_code"
 	GrantTypePassword          GrantType = "password"
 	GrantTypeClientCredentials GrantType = "client_credentials"
-	GrantTypeJWTBearer         GrantType = "urn:ietf:params:oauth:grant-type:jwt-bearer" //nolint:gosec // this is not a hardcoded credential
+	GrantTypeJWTBearer         GrantType = "urn:ietf:params:oauth:grant-type:jwt-bearer"     //nolint:gosec // this is not a hardcoded credential
+	GrantTypeTokenExchange     GrantType = "urn:ietf:params:oauth:grant-type:token-exchange" //nolint:gosec // this is not a hardcoded credential
+	GrantTypeDeviceCode        GrantType = "urn:ietf:params:oauth:grant-type:device_code"    //nolint:gosec // this is not a hardcoded credential
+
+	// GrantTypeAny is declared by token endpoint handlers which may handle requests of any grant type, see
+	// TokenEndpointGrantTypesHandler.
+	GrantTypeAny GrantType = "*"
 
 	BearerAccessToken string = "bearer"
 )
@@ -167,6 +177,28 @@ type OAuth2Provider interface {
 
 	// WritePushedAuthorizeError writes the PAR error
 	WritePushedAuthorizeError(ctx context.Context, rw http.ResponseWriter, ar AuthorizeRequester, err error)
+
+	// NewDeviceRequest validates the request at the device authorization endpoint as defined in
+	// https://datatracker.ietf.org/doc/html/rfc8628#section-3.1
+	NewDeviceRequest(ctx context.Context, r *http.Request) (DeviceRequester, error)
+
+	// NewDeviceResponse executes the device endpoint handlers and builds the response
+	NewDeviceResponse(ctx context.Context, requester DeviceRequester, session Session) (DeviceResponder, error)
+
+	// WriteDeviceResponse writes the device authorization response as defined in
+	// https://datatracker.ietf.org/doc/html/rfc8628#section-3.2
+	WriteDeviceResponse(ctx context.Context, rw http.ResponseWriter, requester DeviceRequester, responder DeviceResponder)
+
+	// WriteDeviceError writes the device authorization error
+	WriteDeviceError(ctx context.Context, rw http.ResponseWriter, requester DeviceRequester, err error)
+
+	// ListSubjectSessions returns the active refresh token sessions of the subject, if the storage implements
+	// SubjectSessionStorage.
+	ListSubjectSessions(ctx context.Context, subject string) ([]SessionInfo, error)
+
+	// RevokeSession revokes the refresh token and the access tokens of the session with the given ID, as returned
+	// by ListSubjectSessions, if the storage implements SessionRevocationStorage.
+	RevokeSession(ctx context.Context, sessionID string) error
 }
 
 // IntrospectionResponder is the response object that will be returned when token introspection was successful,
@@ -360,6 +392,67 @@ type PushedAuthorizeResponder interface {
 	ToMap() map[string]interface{}
 }
 
+// DeviceRequester is a device authorization request, see https://datatracker.ietf.org/doc/html/rfc8628#section-3.1
+type DeviceRequester interface {
+	// GetUserCodeState returns whether the end user accepted or rejected the request.
+	GetUserCodeState() UserCodeState
+
+	// SetUserCodeState sets whether the end user accepted or rejected the request.
+	SetUserCodeState(state UserCodeState)
+
+	// GetLastPolledAt returns when the client last polled the token endpoint with the device code.
+	GetLastPolledAt() time.Time
+
+	// SetLastPolledAt sets when the client last polled the token endpoint with the device code.
+	SetLastPolledAt(polledAt time.Time)
+
+	Requester
+}
+
+// DeviceResponder is the response object of the device authorization endpoint, see
+// https://datatracker.ietf.org/doc/html/rfc8628#section-3.2
+type DeviceResponder interface {
+	// GetDeviceCode returns the device_code
+	GetDeviceCode() string
+	// SetDeviceCode sets the device_code
+	SetDeviceCode(code string)
+	// GetUserCode returns the user_code
+	GetUserCode() string
+	// SetUserCode sets the user_code
+	SetUserCode(code string)
+	// GetVerificationURI returns the verification_uri
+	GetVerificationURI() string
+	// SetVerificationURI sets the verification_uri
+	SetVerificationURI(uri string)
+	// GetVerificationURIComplete returns the verification_uri_complete
+	GetVerificationURIComplete() string
+	// SetVerificationURIComplete sets the verification_uri_complete
+	SetVerificationURIComplete(uri string)
+	// GetExpiresIn returns the expires_in
+	GetExpiresIn() int64
+	// SetExpiresIn sets the expires_in
+	SetExpiresIn(seconds int64)
+	// GetInterval returns the interval
+	GetInterval() int
+	// SetInterval sets the interval
+	SetInterval(seconds int)
+
+	// GetHeader returns the response's header
+	GetHeader() (header http.Header)
+
+	// AddHeader adds an header key value pair to the response
+	AddHeader(key, value string)
+
+	// SetExtra sets a key value pair for the response.
+	SetExtra(key string, value interface{})
+
+	// GetExtra returns a key's value.
+	GetExtra(key string) interface{}
+
+	// ToMap converts the response to a map.
+	ToMap() map[string]interface{}
+}
+
 // G11NContext is the globalization context
 type G11NContext interface {
 	// GetLang returns the current language in the context
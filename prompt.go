@@ -0,0 +1,48 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"strings"
+
+	"github.com/ory/x/errorsx"
+)
+
+// The values of the OpenID Connect "prompt" parameter, see
+// https://openid.net/specs/openid-connect-core-1_0.html#AuthRequest.
+const (
+	// PromptNone requires that no authentication or consent user interface is displayed. An error is returned if
+	// the end-user is not already authenticated or has not pre-configured consent.
+	PromptNone = "none"
+
+	// PromptLogin requires that the end-user is re-authenticated.
+	PromptLogin = "login"
+
+	// PromptConsent requires that the end-user is asked for consent before information is returned to the client.
+	PromptConsent = "consent"
+
+	// PromptSelectAccount requires that the end-user is asked to select a user account.
+	PromptSelectAccount = "select_account"
+
+	// PromptCreate asks that the end-user is shown the account registration user interface, see
+	// https://openid.net/specs/openid-connect-prompt-create-1_0.html.
+	PromptCreate = "create"
+)
+
+// ParsePrompt parses the space-delimited, case-sensitive value of the "prompt" request parameter. It returns
+// ErrInvalidRequest if "none" is combined with any other value.
+func ParsePrompt(raw string) (Arguments, error) {
+	prompts := Arguments(RemoveEmpty(strings.Split(raw, " ")))
+	if prompts.Has(PromptNone) && len(prompts) > 1 {
+		return nil, errorsx.WithStack(ErrInvalidRequest.WithHint("Parameter 'prompt' was set to 'none', but contains other values as well which is not allowed."))
+	}
+	return prompts, nil
+}
+
+// GetPrompts returns the prompts requested by the "prompt" request parameter, so that the login and consent
+// endpoints of the authorization server can decide whether to re-authenticate the end-user, ask for consent, let
+// the end-user select an account, route the end-user to registration, or fail because interaction is required but "none" was requested.
+func GetPrompts(requester Requester) (Arguments, error) {
+	return ParsePrompt(requester.GetRequestForm().Get("prompt"))
+}
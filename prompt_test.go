@@ -0,0 +1,52 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite_test
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ory/fosite"
+)
+
+func TestParsePrompt(t *testing.T) {
+	for k, c := range []struct {
+		d      string
+		prompt string
+		expect Arguments
+		err    error
+	}{
+		{d: "no prompt", prompt: ""},
+		{d: "none", prompt: "none", expect: Arguments{PromptNone}},
+		{d: "login", prompt: "login", expect: Arguments{PromptLogin}},
+		{d: "consent", prompt: "consent", expect: Arguments{PromptConsent}},
+		{d: "select_account", prompt: "select_account", expect: Arguments{PromptSelectAccount}},
+		{d: "create", prompt: "create", expect: Arguments{PromptCreate}},
+		{d: "login and consent", prompt: "login  consent", expect: Arguments{PromptLogin, PromptConsent}},
+		{d: "create and none", prompt: "create none", err: ErrInvalidRequest},
+		{d: "none and login", prompt: "none login", err: ErrInvalidRequest},
+		{d: "consent and none", prompt: "consent none", err: ErrInvalidRequest},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.d), func(t *testing.T) {
+			prompts, err := ParsePrompt(c.prompt)
+			if c.err != nil {
+				require.ErrorIs(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.expect, prompts)
+		})
+	}
+
+	t.Run("case=should return the prompts of the request", func(t *testing.T) {
+		prompts, err := GetPrompts(&Request{Form: url.Values{"prompt": {"login select_account"}}})
+		require.NoError(t, err)
+		assert.True(t, prompts.Has(PromptLogin, PromptSelectAccount))
+		assert.False(t, prompts.Has(PromptNone))
+	})
+}
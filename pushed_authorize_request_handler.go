@@ -65,7 +65,7 @@ func (f *Fosite) NewPushedAuthorizeRequest(ctx context.Context, r *http.Request)
 	}
 
 	// Validate as if this is a new authorize request
-	fr, err := f.newAuthorizeRequest(ctx, r, true)
+	fr, err := f.newAuthorizeRequest(ctx, r, true, false)
 	if err != nil {
 		return fr, err
 	}
@@ -13,16 +13,20 @@ import (
 
 // Request is an implementation of Requester
 type Request struct {
-	ID                string       `json:"id" gorethink:"id"`
-	RequestedAt       time.Time    `json:"requestedAt" gorethink:"requestedAt"`
-	Client            Client       `json:"client" gorethink:"client"`
-	RequestedScope    Arguments    `json:"scopes" gorethink:"scopes"`
-	GrantedScope      Arguments    `json:"grantedScopes" gorethink:"grantedScopes"`
-	Form              url.Values   `json:"form" gorethink:"form"`
-	Session           Session      `json:"session" gorethink:"session"`
-	RequestedAudience Arguments    `json:"requestedAudience"`
-	GrantedAudience   Arguments    `json:"grantedAudience"`
-	Lang              language.Tag `json:"-"`
+	ID                string     `json:"id" gorethink:"id"`
+	RequestedAt       time.Time  `json:"requestedAt" gorethink:"requestedAt"`
+	Client            Client     `json:"client" gorethink:"client"`
+	RequestedScope    Arguments  `json:"scopes" gorethink:"scopes"`
+	GrantedScope      Arguments  `json:"grantedScopes" gorethink:"grantedScopes"`
+	Form              url.Values `json:"form" gorethink:"form"`
+	Session           Session    `json:"session" gorethink:"session"`
+	RequestedAudience Arguments  `json:"requestedAudience"`
+	GrantedAudience   Arguments  `json:"grantedAudience"`
+
+	RequestedAuthorizationDetails AuthorizationDetails `json:"requestedAuthorizationDetails,omitempty"`
+	GrantedAuthorizationDetails   AuthorizationDetails `json:"grantedAuthorizationDetails,omitempty"`
+
+	Lang language.Tag `json:"-"`
 }
 
 func NewRequest() *Request {
@@ -109,6 +113,22 @@ func (a *Request) GrantAudience(audience string) {
 	a.GrantedAudience = append(a.GrantedAudience, audience)
 }
 
+func (a *Request) GetRequestedAuthorizationDetails() AuthorizationDetails {
+	return a.RequestedAuthorizationDetails
+}
+
+func (a *Request) SetRequestedAuthorizationDetails(details AuthorizationDetails) {
+	a.RequestedAuthorizationDetails = details
+}
+
+func (a *Request) GetGrantedAuthorizationDetails() AuthorizationDetails {
+	return a.GrantedAuthorizationDetails
+}
+
+func (a *Request) GrantAuthorizationDetail(detail AuthorizationDetail) {
+	a.GrantedAuthorizationDetails = a.GrantedAuthorizationDetails.append(detail)
+}
+
 func (a *Request) GetGrantedScopes() Arguments {
 	return a.GrantedScope
 }
@@ -149,6 +169,15 @@ func (a *Request) Merge(request Requester) {
 		a.GrantAudience(aud)
 	}
 
+	if details, ok := request.(AuthorizationDetailsRequester); ok {
+		for _, detail := range details.GetRequestedAuthorizationDetails() {
+			a.RequestedAuthorizationDetails = a.RequestedAuthorizationDetails.append(detail)
+		}
+		for _, detail := range details.GetGrantedAuthorizationDetails() {
+			a.GrantAuthorizationDetail(detail)
+		}
+	}
+
 	a.ID = request.GetID()
 	a.RequestedAt = request.GetRequestedAt()
 	a.Client = request.GetClient()
@@ -180,6 +209,38 @@ func (a *Request) Sanitize(allowedParameters []string) Requester {
 	return b
 }
 
+// Clone returns a copy of the request which can be modified without affecting the original. The scopes, audiences
+// and form values are deep copied, and the session is copied using Session.Clone. The client is shared with the
+// original request, as it is not modified during the request.
+func (a *Request) Clone() *Request {
+	b := *a
+	b.RequestedScope = cloneArguments(a.RequestedScope)
+	b.GrantedScope = cloneArguments(a.GrantedScope)
+	b.RequestedAudience = cloneArguments(a.RequestedAudience)
+	b.GrantedAudience = cloneArguments(a.GrantedAudience)
+	b.RequestedAuthorizationDetails = a.RequestedAuthorizationDetails.clone()
+	b.GrantedAuthorizationDetails = a.GrantedAuthorizationDetails.clone()
+
+	if a.Form != nil {
+		b.Form = make(url.Values, len(a.Form))
+		for k, v := range a.Form {
+			b.Form[k] = cloneArguments(v)
+		}
+	}
+
+	if a.Session != nil {
+		b.Session = a.Session.Clone()
+	}
+	return &b
+}
+
+func cloneArguments(args []string) []string {
+	if args == nil {
+		return nil
+	}
+	return append(make([]string, 0, len(args)), args...)
+}
+
 func (a *Request) GetLang() language.Tag {
 	return a.Lang
 }
@@ -102,6 +102,42 @@ func TestSanitizeRequest(t *testing.T) {
 	assert.Equal(t, "read", a.GetRequestForm().Get("scope"))
 }
 
+func TestCloneRequest(t *testing.T) {
+	a := &Request{
+		ID:                "123",
+		RequestedAt:       time.Now().UTC(),
+		Client:            &DefaultClient{ID: "123"},
+		RequestedScope:    Arguments{"foo", "bar"},
+		GrantedScope:      Arguments{"foo"},
+		RequestedAudience: Arguments{"aud"},
+		GrantedAudience:   Arguments{"aud"},
+		Form:              url.Values{"foo": []string{"bar", "baz"}},
+		Session:           &DefaultSession{Subject: "peter"},
+	}
+
+	b := a.Clone()
+	assert.Equal(t, a, b)
+
+	b.GrantScope("bar")
+	b.GrantedScope[0] = "changed"
+	b.RequestedScope[0] = "changed"
+	b.GrantAudience("other")
+	b.RequestedAudience[0] = "changed"
+	b.Form["foo"][0] = "changed"
+	b.Form.Set("new", "value")
+	b.Session.(*DefaultSession).Subject = "alice"
+	b.ID = "456"
+
+	assert.Equal(t, "123", a.ID)
+	assert.Equal(t, Arguments{"foo", "bar"}, a.RequestedScope)
+	assert.Equal(t, Arguments{"foo"}, a.GrantedScope)
+	assert.Equal(t, Arguments{"aud"}, a.RequestedAudience)
+	assert.Equal(t, Arguments{"aud"}, a.GrantedAudience)
+	assert.Equal(t, url.Values{"foo": []string{"bar", "baz"}}, a.Form)
+	assert.Equal(t, "peter", a.Session.GetSubject())
+	assert.Same(t, a.Client, b.Client)
+}
+
 func TestIdentifyRequest(t *testing.T) {
 	a := &Request{
 		RequestedAt:    time.Now().UTC(),
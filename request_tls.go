@@ -0,0 +1,32 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/ory/x/errorsx"
+)
+
+// checkTLS returns ErrInvalidRequest if TLS is required but the request did not arrive over TLS. If a forwarded
+// protocol header is configured, the request is also considered to be secure if the header's value is "https",
+// which is the case when a TLS-terminating proxy forwards the request.
+func (f *Fosite) checkTLS(ctx context.Context, r *http.Request) error {
+	if !f.Config.GetRequireTLS(ctx) || r.TLS != nil {
+		return nil
+	}
+
+	if header := f.Config.GetTLSForwardedProtoHeader(ctx); header != "" {
+		// The header may contain a comma separated list if the request passed several proxies, of which the first
+		// value is the protocol used by the client.
+		proto, _, _ := strings.Cut(r.Header.Get(header), ",")
+		if strings.EqualFold(strings.TrimSpace(proto), "https") {
+			return nil
+		}
+	}
+
+	return errorsx.WithStack(ErrInvalidRequest.WithHint("The request must be sent over TLS."))
+}
@@ -0,0 +1,93 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/ory/x/errorsx"
+)
+
+// ResourceMatchingStrategy validates the resource indicators (https://datatracker.ietf.org/doc/html/rfc8707) in
+// "needle" against the resources in "haystack" the client is allowed to request.
+type ResourceMatchingStrategy func(haystack []string, needle []string) error
+
+// DefaultResourceMatchingStrategy requires every resource indicator to be an absolute URI without a fragment
+// component and to be matched by one of the allowed resources, using the same rules as
+// DefaultAudienceMatchingStrategy.
+func DefaultResourceMatchingStrategy(haystack []string, needle []string) error {
+	if err := validateResourceIndicators(needle); err != nil {
+		return err
+	}
+
+	for _, n := range needle {
+		if err := DefaultAudienceMatchingStrategy(haystack, []string{n}); err != nil {
+			return errorsx.WithStack(ErrInvalidTarget.WithHintf("Requested resource '%s' has not been whitelisted by the OAuth 2.0 Client.", n).WithWrap(err).WithDebug(err.Error()))
+		}
+	}
+
+	return nil
+}
+
+// ExactResourceMatchingStrategy requires every resource indicator to be an absolute URI without a fragment
+// component and to be present in "haystack" as-is.
+func ExactResourceMatchingStrategy(haystack []string, needle []string) error {
+	if err := validateResourceIndicators(needle); err != nil {
+		return err
+	}
+
+	for _, n := range needle {
+		if err := ExactAudienceMatchingStrategy(haystack, []string{n}); err != nil {
+			return errorsx.WithStack(ErrInvalidTarget.WithHintf(`Requested resource "%s" has not been whitelisted by the OAuth 2.0 Client.`, n).WithWrap(err).WithDebug(err.Error()))
+		}
+	}
+
+	return nil
+}
+
+// GetResources returns the resource indicators of a request. Unlike "audience", the "resource" parameter may
+// only be repeated and is never split by space.
+func GetResources(form url.Values) []string {
+	return RemoveEmpty(form["resource"])
+}
+
+// validateResourceIndicators checks that every resource is an absolute URI without a fragment component
+// (https://datatracker.ietf.org/doc/html/rfc8707#section-2).
+func validateResourceIndicators(resources []string) error {
+	for _, resource := range resources {
+		u, err := url.Parse(resource)
+		if err != nil {
+			return errorsx.WithStack(ErrInvalidTarget.WithHintf("Unable to parse requested resource '%s'.", resource).WithWrap(err).WithDebug(err.Error()))
+		}
+
+		if !u.IsAbs() {
+			return errorsx.WithStack(ErrInvalidTarget.WithHintf("Requested resource '%s' must be an absolute URI.", resource))
+		}
+
+		if strings.Contains(resource, "#") {
+			return errorsx.WithStack(ErrInvalidTarget.WithHintf("Requested resource '%s' must not contain a fragment component.", resource))
+		}
+	}
+
+	return nil
+}
+
+// appendResources appends the resources to the audience, skipping values which are already present.
+func appendResources(audience []string, resources []string) []string {
+	for _, resource := range resources {
+		var found bool
+		for _, a := range audience {
+			if a == resource {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			audience = append(audience, resource)
+		}
+	}
+	return audience
+}
@@ -0,0 +1,116 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultResourceMatchingStrategy(t *testing.T) {
+	for k, tc := range []struct {
+		h   []string
+		n   []string
+		err error
+	}{
+		{
+			h: []string{"https://api.example.com"},
+			n: []string{},
+		},
+		{
+			h: []string{"https://api.example.com"},
+			n: []string{"https://api.example.com/users"},
+		},
+		{
+			h: []string{"https://api.example.com/users", "https://files.example.com"},
+			n: []string{"https://api.example.com/users", "https://files.example.com"},
+		},
+		{
+			h:   []string{"https://api.example.com/users"},
+			n:   []string{"https://api.example.com/users", "https://files.example.com"},
+			err: ErrInvalidTarget,
+		},
+		{
+			h:   []string{"https://api.example.com"},
+			n:   []string{"/users"},
+			err: ErrInvalidTarget,
+		},
+		{
+			h:   []string{"api.example.com"},
+			n:   []string{"api.example.com"},
+			err: ErrInvalidTarget,
+		},
+		{
+			h:   []string{"https://api.example.com"},
+			n:   []string{"https://api.example.com#users"},
+			err: ErrInvalidTarget,
+		},
+		{
+			h:   []string{"https://api.example.com"},
+			n:   []string{"https://api.example.com/users#"},
+			err: ErrInvalidTarget,
+		},
+		{
+			h:   []string{"https://api.example.com"},
+			n:   []string{"https://%zz"},
+			err: ErrInvalidTarget,
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			err := DefaultResourceMatchingStrategy(tc.h, tc.n)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestExactResourceMatchingStrategy(t *testing.T) {
+	for k, tc := range []struct {
+		h   []string
+		n   []string
+		err error
+	}{
+		{
+			h: []string{"https://api.example.com/users"},
+			n: []string{"https://api.example.com/users"},
+		},
+		{
+			h:   []string{"https://api.example.com"},
+			n:   []string{"https://api.example.com/users"},
+			err: ErrInvalidTarget,
+		},
+		{
+			h:   []string{"urn:example:api"},
+			n:   []string{"urn:example:api#foo"},
+			err: ErrInvalidTarget,
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			err := ExactResourceMatchingStrategy(tc.h, tc.n)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGetResources(t *testing.T) {
+	assert.Empty(t, GetResources(url.Values{}))
+	assert.Equal(t, []string{"https://api.example.com", "https://files.example.com"}, GetResources(url.Values{
+		"resource": {"https://api.example.com", "", "https://files.example.com"},
+	}))
+	assert.Equal(t, []string{"https://api.example.com", "https://files.example.com"}, appendResources(
+		[]string{"https://api.example.com"},
+		[]string{"https://api.example.com", "https://files.example.com"},
+	))
+}
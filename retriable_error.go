@@ -0,0 +1,43 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+// RetriableError is implemented by errors, typically returned by the storage, which are caused by a temporary
+// condition such as an unavailable database. The authorize endpoint responds to requests which failed because of a
+// retriable error with the temporarily_unavailable error and a Retry-After header.
+type RetriableError interface {
+	error
+
+	// IsRetriable returns true if the request may succeed when it is retried.
+	IsRetriable() bool
+
+	// RetryAfter returns how long the client should wait before retrying the request, or zero to use the configured
+	// delay.
+	RetryAfter() time.Duration
+}
+
+// retryAfter returns how long the client should wait before retrying if the error is caused by a retriable error.
+func (f *Fosite) retryAfter(ctx context.Context, err error) (time.Duration, bool) {
+	var retriable RetriableError
+	if !errors.As(err, &retriable) || !retriable.IsRetriable() {
+		return 0, false
+	}
+
+	if retryAfter := retriable.RetryAfter(); retryAfter > 0 {
+		return retryAfter, true
+	}
+	return f.Config.GetTemporarilyUnavailableRetryAfter(ctx), true
+}
+
+// retryAfterSeconds returns the delay in whole seconds as used by the Retry-After header, rounded up.
+func retryAfterSeconds(retryAfter time.Duration) int {
+	return int(math.Ceil(retryAfter.Seconds()))
+}
@@ -54,6 +54,9 @@ func (f *Fosite) NewRevocationRequest(ctx context.Context, r *http.Request) (err
 
 	token := r.PostForm.Get("token")
 	tokenTypeHint := TokenType(r.PostForm.Get("token_type_hint"))
+	if err := f.validateTokenTypeHint(ctx, tokenTypeHint); err != nil {
+		return err
+	}
 
 	var found = false
 	for _, loader := range f.Config.GetRevocationHandlers(ctx) {
@@ -73,6 +76,16 @@ func (f *Fosite) NewRevocationRequest(ctx context.Context, r *http.Request) (err
 	return nil
 }
 
+// validateTokenTypeHint rejects a "token_type_hint" other than "access_token" or "refresh_token" if configured to do
+// so. Otherwise, an unknown hint is ignored as required by https://tools.ietf.org/html/rfc7009#section-2.2 and
+// https://tools.ietf.org/html/rfc7662#section-2.1.
+func (f *Fosite) validateTokenTypeHint(ctx context.Context, hint TokenType) error {
+	if hint == "" || hint == AccessToken || hint == RefreshToken || !f.Config.GetRejectUnknownTokenTypeHint(ctx) {
+		return nil
+	}
+	return errorsx.WithStack(ErrInvalidRequest.WithHintf("The 'token_type_hint' parameter value '%s' is not supported.", hint))
+}
+
 // WriteRevocationResponse writes a token revocation response as specified in:
 // https://tools.ietf.org/html/rfc7009#section-2.2
 //
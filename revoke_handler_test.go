@@ -180,6 +180,25 @@ func TestNewRevocationRequest(t *testing.T) {
 			},
 			handlers: RevocationHandlers{handler},
 		},
+		{
+			header: http.Header{
+				"Authorization": {basicAuth("foo", "bar")},
+			},
+			method: "POST",
+			form: url.Values{
+				"token":           {"foo"},
+				"token_type_hint": {"bar"},
+			},
+			expectErr: ErrInvalidRequest,
+			mock: func() {
+				config.RejectUnknownTokenTypeHint = true
+				store.EXPECT().GetClient(gomock.Any(), gomock.Eq("foo")).Return(client, nil)
+				client.Secret = []byte("foo")
+				client.Public = false
+				hasher.EXPECT().Compare(gomock.Any(), gomock.Eq([]byte("foo")), gomock.Eq([]byte("bar"))).Return(nil)
+			},
+			handlers: RevocationHandlers{handler},
+		},
 	} {
 		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
 			r := &http.Request{
@@ -3,7 +3,12 @@
 
 package fosite
 
-import "strings"
+import (
+	"context"
+	"strings"
+
+	"github.com/ory/x/errorsx"
+)
 
 // ScopeStrategy is a strategy for matching scopes.
 type ScopeStrategy func(haystack []string, needle string) bool
@@ -83,3 +88,112 @@ func WildcardScopeStrategy(matchers []string, needle string) bool {
 
 	return false
 }
+
+// ScopeExclusionPrefix marks a scope pattern as an exclusion, see WildcardScopeStrategyWithExclusions.
+const ScopeExclusionPrefix = "-"
+
+// WildcardScopeStrategyWithExclusions matches like WildcardScopeStrategy, but patterns prefixed with
+// ScopeExclusionPrefix exclude the scopes they match. For example, "admin.*" and "-admin.billing" match
+// "admin.users" but not "admin.billing". Exclusions take precedence over all other patterns, so the order of the
+// patterns does not matter. Exclusions are wildcard patterns themselves: use "-admin.billing.*" to also exclude
+// the scopes below "admin.billing".
+func WildcardScopeStrategyWithExclusions(matchers []string, needle string) bool {
+	if strings.HasPrefix(needle, ScopeExclusionPrefix) {
+		return false
+	}
+
+	var includes, excludes []string
+	for _, matcher := range matchers {
+		if strings.HasPrefix(matcher, ScopeExclusionPrefix) {
+			excludes = append(excludes, strings.TrimPrefix(matcher, ScopeExclusionPrefix))
+		} else {
+			includes = append(includes, matcher)
+		}
+	}
+
+	if WildcardScopeStrategy(excludes, needle) {
+		return false
+	}
+	return WildcardScopeStrategy(includes, needle)
+}
+
+// RefreshTokenScopeStrategy returns the scopes granted to the access token issued by a refresh token grant, given
+// the scopes granted to the refresh token and the scopes requested in the refresh request. Returning an error, usually
+// ErrInvalidScope, rejects the refresh request.
+type RefreshTokenScopeStrategy func(ctx context.Context, granted Arguments, requested Arguments) (Arguments, error)
+
+// DefaultRefreshTokenScopeStrategy grants all scopes of the refresh token if no scope was requested, and the
+// requested scopes otherwise. Requesting a scope which was not granted to the refresh token is an error, see
+// https://tools.ietf.org/html/rfc6749#section-6
+func DefaultRefreshTokenScopeStrategy(_ context.Context, granted Arguments, requested Arguments) (Arguments, error) {
+	if len(requested) == 0 {
+		return granted, nil
+	}
+
+	for _, scope := range requested {
+		if !granted.Has(scope) {
+			return nil, errorsx.WithStack(ErrInvalidScope.WithHintf("The requested scope '%s' was not originally granted by the resource owner.", scope))
+		}
+	}
+
+	return requested, nil
+}
+
+// UnknownScopePolicy decides how requested scopes which the client is not allowed to request are treated.
+type UnknownScopePolicy string
+
+const (
+	// UnknownScopePolicyReject rejects the request with ErrInvalidScope.
+	UnknownScopePolicyReject UnknownScopePolicy = "reject"
+
+	// UnknownScopePolicyIgnore drops the unknown scopes from the request and continues with the remaining ones.
+	UnknownScopePolicyIgnore UnknownScopePolicy = "ignore-unknown"
+)
+
+// removeUnknownScopes returns the scopes which the client is allowed to request if UnknownScopePolicyIgnore is
+// configured. Otherwise, all scopes are returned and have to be validated by the caller.
+func removeUnknownScopes(ctx context.Context, config interface {
+	ScopeStrategyProvider
+	UnknownScopePolicyProvider
+}, client Client, scopes Arguments) Arguments {
+	if config.GetUnknownScopePolicy(ctx) != UnknownScopePolicyIgnore {
+		return scopes
+	}
+
+	known := Arguments{}
+	for _, scope := range scopes {
+		if config.GetScopeStrategy(ctx)(client.GetScopes(), scope) {
+			known = append(known, scope)
+		}
+	}
+	return known
+}
+
+// withRequestedScopeCasing returns the scopes in the casing the client requested them in, which is taken from the
+// "scope" request parameter and the requested scopes. Scopes which were not requested are returned as they are.
+func withRequestedScopeCasing(requester Requester, scopes Arguments) Arguments {
+	requested := map[string]string{}
+	for _, scope := range append(RemoveEmpty(strings.Split(requester.GetRequestForm().Get("scope"), " ")), requester.GetRequestedScopes()...) {
+		if _, ok := requested[strings.ToLower(scope)]; !ok {
+			requested[strings.ToLower(scope)] = scope
+		}
+	}
+
+	result := make(Arguments, len(scopes))
+	for k, scope := range scopes {
+		if original, ok := requested[strings.ToLower(scope)]; ok {
+			result[k] = original
+		} else {
+			result[k] = scope
+		}
+	}
+	return result
+}
+
+// validateScopeCount returns ErrInvalidScope if there are more scopes than allowed by MaxScopeCountProvider.
+func validateScopeCount(ctx context.Context, config MaxScopeCountProvider, scopes Arguments) error {
+	if max := config.GetMaxScopeCount(ctx); max > 0 && len(scopes) > max {
+		return errorsx.WithStack(ErrInvalidScope.WithHintf("The number of scopes must not exceed %d.", max))
+	}
+	return nil
+}
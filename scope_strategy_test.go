@@ -4,6 +4,7 @@
 package fosite
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
@@ -117,6 +118,63 @@ func TestWildcardScopeStrategy(t *testing.T) {
 	assert.True(t, strategy(scopes, "openid"))
 }
 
+func TestWildcardScopeStrategyWithExclusions(t *testing.T) {
+	var strategy ScopeStrategy = WildcardScopeStrategyWithExclusions
+
+	for k, c := range []struct {
+		d       string
+		scopes  []string
+		allowed []string
+		denied  []string
+	}{
+		{
+			d:       "should match wildcard grants without exclusions",
+			scopes:  []string{"admin.*", "openid"},
+			allowed: []string{"admin.users", "admin.billing", "admin.billing.read", "openid"},
+			denied:  []string{"admin", "offline"},
+		},
+		{
+			d:       "should deny an excluded subscope",
+			scopes:  []string{"admin.*", "-admin.billing"},
+			allowed: []string{"admin.users", "admin.billing.read"},
+			denied:  []string{"admin.billing", "-admin.billing"},
+		},
+		{
+			d:       "should deny subscopes excluded by a wildcard",
+			scopes:  []string{"admin.*", "-admin.billing.*"},
+			allowed: []string{"admin.users", "admin.billing"},
+			denied:  []string{"admin.billing.read", "admin.billing.write.all"},
+		},
+		{
+			d:       "should let exclusions take precedence over overlapping includes",
+			scopes:  []string{"-admin.billing", "admin.*", "admin.billing"},
+			allowed: []string{"admin.users"},
+			denied:  []string{"admin.billing"},
+		},
+		{
+			d:      "should not match if only exclusions are given",
+			scopes: []string{"-admin.billing"},
+			denied: []string{"admin.users", "admin.billing", "admin"},
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.d), func(t *testing.T) {
+			reversed := make([]string, len(c.scopes))
+			for i, scope := range c.scopes {
+				reversed[len(c.scopes)-1-i] = scope
+			}
+
+			for _, scopes := range [][]string{c.scopes, reversed} {
+				for _, needle := range c.allowed {
+					assert.True(t, strategy(scopes, needle), "%v should match %s", scopes, needle)
+				}
+				for _, needle := range c.denied {
+					assert.False(t, strategy(scopes, needle), "%v should not match %s", scopes, needle)
+				}
+			}
+		})
+	}
+}
+
 func TestExactScopeStrategy2ScopeStrategy(t *testing.T) {
 	var strategy ScopeStrategy = ExactScopeStrategy
 
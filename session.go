@@ -38,6 +38,11 @@ type DefaultSession struct {
 	Username  string                  `json:"username"`
 	Subject   string                  `json:"subject"`
 	Extra     map[string]interface{}  `json:"extra"`
+
+	// Version is the serialization format version of the session. It is set when the session is serialized using
+	// MarshalSession and upgraded by UnmarshalSession, see SessionMigrator. A zero version is the current version, so
+	// sessions serialized using json.Marshal are not mistaken for sessions of version 1.
+	Version int `json:"version"`
 }
 
 func (s *DefaultSession) SetExpiresAt(key TokenType, exp time.Time) {
@@ -0,0 +1,159 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/ory/x/errorsx"
+	"github.com/pkg/errors"
+)
+
+// ErrUnknownSessionVersion is returned when a serialized session is of a version which can not be migrated to the
+// current version, for example because it was written by a newer release.
+var ErrUnknownSessionVersion = errors.New("the session serialization format version is not supported")
+
+// SessionMigration upgrades a serialized session from the version it was registered for to the next version.
+type SessionMigration func(data []byte) ([]byte, error)
+
+// SessionMigrator versions the JSON serialization format of sessions and upgrades sessions of previous versions on
+// read. The version is stored in the "version" field of the serialized session. Sessions serialized without a version
+// are of version 1, sessions of version 0 are of the current version, and the current version is one more than the
+// highest version a migration is registered for.
+//
+// The DefaultSessionMigrator is used for DefaultSession. Custom sessions should use their own SessionMigrator: register
+// a migration whenever the shape of the session changes, and serialize the session using Marshal and Unmarshal.
+//
+// The zero value is ready to use.
+type SessionMigrator struct {
+	mu         sync.RWMutex
+	migrations map[int]SessionMigration
+}
+
+// DefaultSessionMigrator is the SessionMigrator of DefaultSession.
+var DefaultSessionMigrator = new(SessionMigrator)
+
+// RegisterSessionMigration registers a migration of serialized sessions from the given version to the next version
+// with the DefaultSessionMigrator.
+func RegisterSessionMigration(fromVersion int, fn func([]byte) ([]byte, error)) {
+	DefaultSessionMigrator.Register(fromVersion, fn)
+}
+
+// MarshalSession serializes the session using the DefaultSessionMigrator.
+func MarshalSession(session Session) ([]byte, error) {
+	return DefaultSessionMigrator.Marshal(session)
+}
+
+// UnmarshalSession deserializes the session using the DefaultSessionMigrator, upgrading it to the current version.
+func UnmarshalSession(data []byte, session Session) error {
+	return DefaultSessionMigrator.Unmarshal(data, session)
+}
+
+// Register registers a migration of serialized sessions from the given version to the next version. The migration
+// does not need to update the "version" field.
+func (m *SessionMigrator) Register(fromVersion int, migration SessionMigration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.migrations == nil {
+		m.migrations = make(map[int]SessionMigration)
+	}
+	m.migrations[fromVersion] = migration
+}
+
+// Version returns the current version of the serialization format.
+func (m *SessionMigrator) Version() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	version := 1
+	for from := range m.migrations {
+		if from >= version {
+			version = from + 1
+		}
+	}
+	return version
+}
+
+// Marshal serializes the session as a JSON object of the current version.
+func (m *SessionMigrator) Marshal(session interface{}) ([]byte, error) {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return nil, errorsx.WithStack(err)
+	}
+	return setSessionVersion(data, m.Version())
+}
+
+// Unmarshal deserializes the session, upgrading it from the version it was serialized with to the current version.
+func (m *SessionMigrator) Unmarshal(data []byte, session interface{}) error {
+	var serialized struct {
+		Version *int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &serialized); err != nil {
+		return errorsx.WithStack(err)
+	}
+
+	current := m.Version()
+	version := 1
+	if serialized.Version != nil {
+		version = *serialized.Version
+	}
+
+	// Sessions serialized using json.Marshal instead of Marshal have not been assigned a version yet.
+	if version == 0 {
+		version = current
+		var err error
+		if data, err = setSessionVersion(data, current); err != nil {
+			return err
+		}
+	}
+
+	if version > current {
+		return errors.Wrapf(ErrUnknownSessionVersion, "session version %d is newer than the current version %d", version, current)
+	}
+
+	if version < current {
+		m.mu.RLock()
+		migrations := make([]SessionMigration, 0, current-version)
+		for v := version; v < current; v++ {
+			migration, ok := m.migrations[v]
+			if !ok {
+				m.mu.RUnlock()
+				return errors.Wrapf(ErrUnknownSessionVersion, "no migration of session version %d is registered", v)
+			}
+			migrations = append(migrations, migration)
+		}
+		m.mu.RUnlock()
+
+		var err error
+		for k, migration := range migrations {
+			if data, err = migration(data); err != nil {
+				return errors.Wrapf(err, "unable to migrate session version %d", version+k)
+			}
+		}
+
+		if data, err = setSessionVersion(data, current); err != nil {
+			return err
+		}
+	}
+
+	return errorsx.WithStack(json.Unmarshal(data, session))
+}
+
+func setSessionVersion(data []byte, version int) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, errorsx.WithStack(err)
+	}
+
+	v, err := json.Marshal(version)
+	if err != nil {
+		return nil, errorsx.WithStack(err)
+	}
+	fields["version"] = v
+
+	data, err = json.Marshal(fields)
+	return data, errorsx.WithStack(err)
+}
@@ -0,0 +1,103 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionMigrator(t *testing.T) {
+	// Version 2 renames "username" to "user".
+	type sessionV2 struct {
+		Subject string `json:"subject"`
+		User    string `json:"user"`
+		Version int    `json:"version"`
+	}
+
+	m := new(SessionMigrator)
+	assert.Equal(t, 1, m.Version())
+	m.Register(1, func(data []byte) ([]byte, error) {
+		var fields map[string]interface{}
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, err
+		}
+		fields["user"] = fields["username"]
+		delete(fields, "username")
+		return json.Marshal(fields)
+	})
+	assert.Equal(t, 2, m.Version())
+
+	t.Run("case=migrates a version 1 session to version 2", func(t *testing.T) {
+		var s sessionV2
+		require.NoError(t, m.Unmarshal([]byte(`{"subject":"peter","username":"Peter"}`), &s))
+		assert.Equal(t, sessionV2{Subject: "peter", User: "Peter", Version: 2}, s)
+	})
+
+	t.Run("case=does not migrate a session serialized without the migrator", func(t *testing.T) {
+		data, err := json.Marshal(&sessionV2{Subject: "peter", User: "Peter"})
+		require.NoError(t, err)
+
+		var s sessionV2
+		require.NoError(t, m.Unmarshal(data, &s))
+		assert.Equal(t, sessionV2{Subject: "peter", User: "Peter", Version: 2}, s)
+	})
+
+	t.Run("case=does not migrate a current session", func(t *testing.T) {
+		data, err := m.Marshal(&sessionV2{Subject: "peter", User: "Peter"})
+		require.NoError(t, err)
+
+		var s sessionV2
+		require.NoError(t, m.Unmarshal(data, &s))
+		assert.Equal(t, sessionV2{Subject: "peter", User: "Peter", Version: 2}, s)
+	})
+
+	t.Run("case=fails if a migration is missing", func(t *testing.T) {
+		m := new(SessionMigrator)
+		m.Register(2, func(data []byte) ([]byte, error) { return data, nil })
+
+		var s sessionV2
+		err := m.Unmarshal([]byte(`{"subject":"peter"}`), &s)
+		require.ErrorIs(t, err, ErrUnknownSessionVersion)
+		assert.Contains(t, err.Error(), "no migration of session version 1 is registered")
+	})
+}
+
+func TestUnmarshalSession(t *testing.T) {
+	t.Run("case=loads a session serialized without a version", func(t *testing.T) {
+		var s DefaultSession
+		require.NoError(t, UnmarshalSession([]byte(`{"subject":"peter","username":"Peter"}`), &s))
+		assert.Equal(t, DefaultSession{Subject: "peter", Username: "Peter"}, s)
+	})
+
+	t.Run("case=round trips a session", func(t *testing.T) {
+		data, err := MarshalSession(&DefaultSession{Subject: "peter"})
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"version":1`)
+
+		var s DefaultSession
+		require.NoError(t, UnmarshalSession(data, &s))
+		assert.Equal(t, DefaultSession{Subject: "peter", Version: 1}, s)
+	})
+
+	t.Run("case=loads a session serialized using json.Marshal as the current version", func(t *testing.T) {
+		data, err := json.Marshal(&DefaultSession{Subject: "peter"})
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"version":0`)
+
+		var s DefaultSession
+		require.NoError(t, UnmarshalSession(data, &s))
+		assert.Equal(t, DefaultSession{Subject: "peter", Version: 1}, s)
+	})
+
+	t.Run("case=fails on a session of an unknown future version", func(t *testing.T) {
+		var s DefaultSession
+		err := UnmarshalSession([]byte(`{"subject":"peter","version":3}`), &s)
+		require.ErrorIs(t, err, ErrUnknownSessionVersion)
+		assert.Contains(t, err.Error(), "session version 3 is newer than the current version 1")
+	})
+}
@@ -3,7 +3,10 @@
 
 package fosite
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Storage defines fosite's minimal storage interface.
 type Storage interface {
@@ -19,3 +22,30 @@ type PARStorage interface {
 	// DeletePARSession deletes the context.
 	DeletePARSession(ctx context.Context, requestURI string) (err error)
 }
+
+// AuthorizeParameterReuseStorage keeps track of the "state" and "nonce" values of authorization requests so that
+// their reuse can be detected.
+type AuthorizeParameterReuseStorage interface {
+	// IsAuthorizeParameterUsed returns true if the client has used the value for the parameter before.
+	IsAuthorizeParameterUsed(ctx context.Context, clientID, parameter, value string) (bool, error)
+	// MarkAuthorizeParameterUsedForTime marks the value of the parameter as used by the client until exp. It may
+	// return ErrJTIKnown if the value is already marked as used.
+	MarkAuthorizeParameterUsedForTime(ctx context.Context, clientID, parameter, value string, exp time.Time) error
+}
+
+// SubjectSessionStorage lists the sessions of a subject, for example to let end-users review and revoke the clients
+// they granted access to.
+type SubjectSessionStorage interface {
+	// ListSubjectSessions returns the sessions of all active refresh tokens issued for the given subject.
+	ListSubjectSessions(ctx context.Context, subject string) ([]SessionInfo, error)
+}
+
+// SessionRevocationStorage revokes the tokens of a single session, identified by the ID of the request it was issued
+// by. Revoking the tokens of an unknown or already revoked session must not return an error.
+type SessionRevocationStorage interface {
+	// RevokeRefreshToken revokes the refresh token issued by the request with the given ID.
+	RevokeRefreshToken(ctx context.Context, requestID string) error
+
+	// RevokeAccessToken revokes the access tokens issued by the request with the given ID.
+	RevokeAccessToken(ctx context.Context, requestID string) error
+}
@@ -6,6 +6,8 @@ package storage
 import (
 	"context"
 	"errors"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -45,12 +47,24 @@ type MemoryStore struct {
 	PKCES           map[string]fosite.Requester
 	Users           map[string]MemoryUserRelation
 	BlacklistedJTIs map[string]time.Time
+	// Revoked JWT access tokens by jti.
+	RevokedJTIs map[string]time.Time
+	// Used DPoP proofs by jti.
+	DPoPProofJTIs map[string]time.Time
+	// Issued JWT access tokens by jti.
+	AccessTokenJTIs map[string]time.Time
 	// In-memory request ID to token signatures
 	AccessTokenRequestIDs  map[string]string
 	RefreshTokenRequestIDs map[string]string
 	// Public keys to check signature in auth grant jwt assertion.
 	IssuerPublicKeys map[string]IssuerPublicKeys
-	PARSessions      map[string]fosite.AuthorizeRequester
+	// Issuers whose jwt assertions a client may present, by client ID.
+	ClientAssertionIssuers map[string][]string
+	PARSessions            map[string]fosite.AuthorizeRequester
+	DeviceCodes            map[string]fosite.DeviceRequester
+	UserCodes              map[string]fosite.DeviceRequester
+	// In-memory request ID to device code signatures
+	DeviceCodeRequestIDs map[string]string
 
 	clientsMutex                sync.RWMutex
 	authorizeCodesMutex         sync.RWMutex
@@ -60,10 +74,16 @@ type MemoryStore struct {
 	pkcesMutex                  sync.RWMutex
 	usersMutex                  sync.RWMutex
 	blacklistedJTIsMutex        sync.RWMutex
+	revokedJTIsMutex            sync.RWMutex
+	dpopProofJTIsMutex          sync.RWMutex
+	accessTokenJTIsMutex        sync.RWMutex
 	accessTokenRequestIDsMutex  sync.RWMutex
 	refreshTokenRequestIDsMutex sync.RWMutex
 	issuerPublicKeysMutex       sync.RWMutex
+	clientAssertionIssuersMutex sync.RWMutex
 	parSessionsMutex            sync.RWMutex
+	deviceCodesMutex            sync.RWMutex
+	userCodesMutex              sync.RWMutex
 }
 
 func NewMemoryStore() *MemoryStore {
@@ -78,8 +98,15 @@ func NewMemoryStore() *MemoryStore {
 		AccessTokenRequestIDs:  make(map[string]string),
 		RefreshTokenRequestIDs: make(map[string]string),
 		BlacklistedJTIs:        make(map[string]time.Time),
+		RevokedJTIs:            make(map[string]time.Time),
+		DPoPProofJTIs:          make(map[string]time.Time),
+		AccessTokenJTIs:        make(map[string]time.Time),
 		IssuerPublicKeys:       make(map[string]IssuerPublicKeys),
+		ClientAssertionIssuers: make(map[string][]string),
 		PARSessions:            make(map[string]fosite.AuthorizeRequester),
+		DeviceCodes:            make(map[string]fosite.DeviceRequester),
+		UserCodes:              make(map[string]fosite.DeviceRequester),
+		DeviceCodeRequestIDs:   make(map[string]string),
 	}
 }
 
@@ -89,7 +116,8 @@ type StoreAuthorizeCode struct {
 }
 
 type StoreRefreshToken struct {
-	active bool
+	active        bool
+	inactiveSince time.Time
 	fosite.Requester
 }
 
@@ -142,8 +170,15 @@ func NewExampleStore() *MemoryStore {
 		PKCES:                  map[string]fosite.Requester{},
 		AccessTokenRequestIDs:  map[string]string{},
 		RefreshTokenRequestIDs: map[string]string{},
+		RevokedJTIs:            map[string]time.Time{},
+		DPoPProofJTIs:          map[string]time.Time{},
+		AccessTokenJTIs:        map[string]time.Time{},
 		IssuerPublicKeys:       map[string]IssuerPublicKeys{},
+		ClientAssertionIssuers: map[string][]string{},
 		PARSessions:            map[string]fosite.AuthorizeRequester{},
+		DeviceCodes:            map[string]fosite.DeviceRequester{},
+		UserCodes:              map[string]fosite.DeviceRequester{},
+		DeviceCodeRequestIDs:   map[string]string{},
 	}
 }
 
@@ -226,6 +261,29 @@ func (s *MemoryStore) SetClientAssertionJWT(_ context.Context, jti string, exp t
 	return nil
 }
 
+func (s *MemoryStore) BlacklistJTI(_ context.Context, jti string, exp time.Time) error {
+	s.revokedJTIsMutex.Lock()
+	defer s.revokedJTIsMutex.Unlock()
+
+	// delete expired jtis
+	for j, e := range s.RevokedJTIs {
+		if e.Before(time.Now()) {
+			delete(s.RevokedJTIs, j)
+		}
+	}
+
+	s.RevokedJTIs[jti] = exp
+	return nil
+}
+
+func (s *MemoryStore) IsJTIBlacklisted(_ context.Context, jti string) (bool, error) {
+	s.revokedJTIsMutex.RLock()
+	defer s.revokedJTIsMutex.RUnlock()
+
+	exp, exists := s.RevokedJTIs[jti]
+	return exists && exp.After(time.Now()), nil
+}
+
 func (s *MemoryStore) CreateAuthorizeCodeSession(_ context.Context, code string, req fosite.Requester) error {
 	s.authorizeCodesMutex.Lock()
 	defer s.authorizeCodesMutex.Unlock()
@@ -374,17 +432,58 @@ func (s *MemoryStore) RevokeRefreshToken(ctx context.Context, requestID string)
 	s.refreshTokenRequestIDsMutex.Lock()
 	defer s.refreshTokenRequestIDsMutex.Unlock()
 
+	s.refreshTokensMutex.Lock()
+	defer s.refreshTokensMutex.Unlock()
+
 	if signature, exists := s.RefreshTokenRequestIDs[requestID]; exists {
 		rel, ok := s.RefreshTokens[signature]
 		if !ok {
 			return fosite.ErrNotFound
 		}
-		rel.active = false
+		if rel.active {
+			rel.active = false
+			rel.inactiveSince = time.Now().UTC()
+		}
 		s.RefreshTokens[signature] = rel
 	}
 	return nil
 }
 
+func (s *MemoryStore) PruneInactiveRefreshTokens(_ context.Context, notAfter time.Time, maxCount int) error {
+	s.refreshTokensMutex.Lock()
+	defer s.refreshTokensMutex.Unlock()
+
+	inactive := map[string][]string{}
+	for signature, rel := range s.RefreshTokens {
+		if rel.active {
+			continue
+		} else if !notAfter.IsZero() && rel.inactiveSince.Before(notAfter) {
+			delete(s.RefreshTokens, signature)
+			continue
+		}
+		inactive[rel.GetID()] = append(inactive[rel.GetID()], signature)
+	}
+
+	if maxCount <= 0 {
+		return nil
+	}
+
+	for _, signatures := range inactive {
+		if len(signatures) <= maxCount {
+			continue
+		}
+
+		// Keep the most recently deactivated refresh tokens.
+		sort.Slice(signatures, func(i, j int) bool {
+			return s.RefreshTokens[signatures[i]].inactiveSince.After(s.RefreshTokens[signatures[j]].inactiveSince)
+		})
+		for _, signature := range signatures[maxCount:] {
+			delete(s.RefreshTokens, signature)
+		}
+	}
+	return nil
+}
+
 func (s *MemoryStore) RevokeRefreshTokenMaybeGracePeriod(ctx context.Context, requestID string, signature string) error {
 	// no configuration option is available; grace period is not available with memory store
 	return s.RevokeRefreshToken(ctx, requestID)
@@ -402,6 +501,114 @@ func (s *MemoryStore) RevokeAccessToken(ctx context.Context, requestID string) e
 	return nil
 }
 
+func (s *MemoryStore) RevokeAccessTokensForSubject(_ context.Context, subject string) error {
+	s.revokeAccessTokens(func(req fosite.Requester) bool {
+		return req.GetSession() != nil && req.GetSession().GetSubject() == subject
+	})
+	return nil
+}
+
+func (s *MemoryStore) RevokeRefreshTokensForSubject(_ context.Context, subject string) error {
+	s.revokeRefreshTokens(func(req fosite.Requester) bool {
+		return req.GetSession() != nil && req.GetSession().GetSubject() == subject
+	})
+	return nil
+}
+
+func (s *MemoryStore) RevokeAccessTokensForClient(_ context.Context, clientID string) error {
+	s.revokeAccessTokens(func(req fosite.Requester) bool {
+		return req.GetClient() != nil && req.GetClient().GetID() == clientID
+	})
+	return nil
+}
+
+func (s *MemoryStore) RevokeRefreshTokensForClient(_ context.Context, clientID string) error {
+	s.revokeRefreshTokens(func(req fosite.Requester) bool {
+		return req.GetClient() != nil && req.GetClient().GetID() == clientID
+	})
+	return nil
+}
+
+func (s *MemoryStore) GetActiveRequestsBySubject(_ context.Context, subject string) ([]fosite.Requester, error) {
+	matches := func(req fosite.Requester) bool {
+		return subject == "" || (req.GetSession() != nil && req.GetSession().GetSubject() == subject)
+	}
+
+	var requests []fosite.Requester
+	s.accessTokensMutex.RLock()
+	for _, req := range s.AccessTokens {
+		if matches(req) {
+			requests = append(requests, req)
+		}
+	}
+	s.accessTokensMutex.RUnlock()
+
+	s.refreshTokensMutex.RLock()
+	for _, rel := range s.RefreshTokens {
+		if rel.active && matches(rel.Requester) {
+			requests = append(requests, rel.Requester)
+		}
+	}
+	s.refreshTokensMutex.RUnlock()
+
+	return requests, nil
+}
+
+func (s *MemoryStore) ListSubjectSessions(_ context.Context, subject string) ([]fosite.SessionInfo, error) {
+	s.refreshTokensMutex.RLock()
+	defer s.refreshTokensMutex.RUnlock()
+
+	now := time.Now().UTC()
+	sessions := []fosite.SessionInfo{}
+	for _, rel := range s.RefreshTokens {
+		if !rel.active || rel.GetSession() == nil || rel.GetSession().GetSubject() != subject {
+			continue
+		}
+
+		expiresAt := rel.GetSession().GetExpiresAt(fosite.RefreshToken)
+		if !expiresAt.IsZero() && expiresAt.Before(now) {
+			continue
+		}
+
+		sessions = append(sessions, fosite.SessionInfo{
+			RequestID:     rel.GetID(),
+			ClientID:      rel.GetClient().GetID(),
+			GrantedScopes: rel.GetGrantedScopes(),
+			IssuedAt:      rel.GetRequestedAt(),
+			ExpiresAt:     expiresAt,
+		})
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].IssuedAt.Before(sessions[j].IssuedAt)
+	})
+	return sessions, nil
+}
+
+func (s *MemoryStore) revokeAccessTokens(matches func(req fosite.Requester) bool) {
+	s.accessTokensMutex.Lock()
+	defer s.accessTokensMutex.Unlock()
+
+	for signature, req := range s.AccessTokens {
+		if matches(req) {
+			delete(s.AccessTokens, signature)
+		}
+	}
+}
+
+func (s *MemoryStore) revokeRefreshTokens(matches func(req fosite.Requester) bool) {
+	s.refreshTokensMutex.Lock()
+	defer s.refreshTokensMutex.Unlock()
+
+	for signature, rel := range s.RefreshTokens {
+		if rel.active && matches(rel.Requester) {
+			rel.active = false
+			rel.inactiveSince = time.Now().UTC()
+			s.RefreshTokens[signature] = rel
+		}
+	}
+}
+
 func (s *MemoryStore) GetPublicKey(ctx context.Context, issuer string, subject string, keyId string) (*jose.JSONWebKey, error) {
 	s.issuerPublicKeysMutex.RLock()
 	defer s.issuerPublicKeysMutex.RUnlock()
@@ -453,6 +660,17 @@ func (s *MemoryStore) GetPublicKeyScopes(ctx context.Context, issuer string, sub
 	return nil, fosite.ErrNotFound
 }
 
+func (s *MemoryStore) GetClientAssertionIssuers(ctx context.Context, clientID string) ([]string, error) {
+	s.clientAssertionIssuersMutex.RLock()
+	defer s.clientAssertionIssuersMutex.RUnlock()
+
+	if issuers, ok := s.ClientAssertionIssuers[clientID]; ok {
+		return issuers, nil
+	}
+
+	return nil, fosite.ErrNotFound
+}
+
 func (s *MemoryStore) IsJWTUsed(ctx context.Context, jti string) (bool, error) {
 	err := s.ClientAssertionJWTValid(ctx, jti)
 	if err != nil {
@@ -466,6 +684,70 @@ func (s *MemoryStore) MarkJWTUsedForTime(ctx context.Context, jti string, exp ti
 	return s.SetClientAssertionJWT(ctx, jti, exp)
 }
 
+func (s *MemoryStore) IsDPoPProofUsed(_ context.Context, jti string) (bool, error) {
+	s.dpopProofJTIsMutex.RLock()
+	defer s.dpopProofJTIsMutex.RUnlock()
+
+	exp, exists := s.DPoPProofJTIs[jti]
+	return exists && exp.After(time.Now()), nil
+}
+
+func (s *MemoryStore) MarkDPoPProofUsedForTime(_ context.Context, jti string, exp time.Time) error {
+	s.dpopProofJTIsMutex.Lock()
+	defer s.dpopProofJTIsMutex.Unlock()
+
+	// delete expired jtis
+	for j, e := range s.DPoPProofJTIs {
+		if e.Before(time.Now()) {
+			delete(s.DPoPProofJTIs, j)
+		}
+	}
+
+	if _, exists := s.DPoPProofJTIs[jti]; exists {
+		return fosite.ErrJTIKnown
+	}
+
+	if s.DPoPProofJTIs == nil {
+		s.DPoPProofJTIs = make(map[string]time.Time)
+	}
+	s.DPoPProofJTIs[jti] = exp
+	return nil
+}
+
+func (s *MemoryStore) MarkAccessTokenJTIUsedForTime(_ context.Context, jti string, exp time.Time) error {
+	s.accessTokenJTIsMutex.Lock()
+	defer s.accessTokenJTIsMutex.Unlock()
+
+	// delete expired jtis
+	for j, e := range s.AccessTokenJTIs {
+		if e.Before(time.Now()) {
+			delete(s.AccessTokenJTIs, j)
+		}
+	}
+
+	if _, exists := s.AccessTokenJTIs[jti]; exists {
+		return fosite.ErrJTIKnown
+	}
+
+	if s.AccessTokenJTIs == nil {
+		s.AccessTokenJTIs = make(map[string]time.Time)
+	}
+	s.AccessTokenJTIs[jti] = exp
+	return nil
+}
+
+func (s *MemoryStore) IsAuthorizeParameterUsed(ctx context.Context, clientID, parameter, value string) (bool, error) {
+	return s.IsJWTUsed(ctx, authorizeParameterKey(clientID, parameter, value))
+}
+
+func (s *MemoryStore) MarkAuthorizeParameterUsedForTime(ctx context.Context, clientID, parameter, value string, exp time.Time) error {
+	return s.SetClientAssertionJWT(ctx, authorizeParameterKey(clientID, parameter, value), exp)
+}
+
+func authorizeParameterKey(clientID, parameter, value string) string {
+	return strings.Join([]string{clientID, parameter, value}, "\x00")
+}
+
 // CreatePARSession stores the pushed authorization request context. The requestURI is used to derive the key.
 func (s *MemoryStore) CreatePARSession(ctx context.Context, requestURI string, request fosite.AuthorizeRequester) error {
 	s.parSessionsMutex.Lock()
@@ -497,3 +779,93 @@ func (s *MemoryStore) DeletePARSession(ctx context.Context, requestURI string) (
 	delete(s.PARSessions, requestURI)
 	return nil
 }
+
+// CreateDeviceCodeSession stores the device authorization request. The signature of the device code is used as key.
+func (s *MemoryStore) CreateDeviceCodeSession(_ context.Context, signature string, request fosite.DeviceRequester) error {
+	s.deviceCodesMutex.Lock()
+	defer s.deviceCodesMutex.Unlock()
+
+	s.DeviceCodes[signature] = request
+	s.DeviceCodeRequestIDs[request.GetID()] = signature
+	return nil
+}
+
+// GetDeviceCodeSession returns the device authorization request of the device code signature.
+func (s *MemoryStore) GetDeviceCodeSession(_ context.Context, signature string, _ fosite.Session) (fosite.DeviceRequester, error) {
+	s.deviceCodesMutex.RLock()
+	defer s.deviceCodesMutex.RUnlock()
+
+	r, ok := s.DeviceCodes[signature]
+	if !ok {
+		return nil, fosite.ErrNotFound
+	}
+	return r, nil
+}
+
+// UpdateDeviceCodeSessionByRequestID replaces the device authorization request with the given request ID.
+func (s *MemoryStore) UpdateDeviceCodeSessionByRequestID(_ context.Context, requestID string, request fosite.DeviceRequester) error {
+	s.deviceCodesMutex.Lock()
+	defer s.deviceCodesMutex.Unlock()
+
+	signature, ok := s.DeviceCodeRequestIDs[requestID]
+	if !ok {
+		return fosite.ErrNotFound
+	}
+	s.DeviceCodes[signature] = request
+	return nil
+}
+
+// UpdateDeviceCodeSessionLastPolledAt sets the time the device code was last polled at.
+func (s *MemoryStore) UpdateDeviceCodeSessionLastPolledAt(_ context.Context, signature string, lastPolledAt time.Time) error {
+	s.deviceCodesMutex.Lock()
+	defer s.deviceCodesMutex.Unlock()
+
+	r, ok := s.DeviceCodes[signature]
+	if !ok {
+		return fosite.ErrNotFound
+	}
+	r.SetLastPolledAt(lastPolledAt)
+	return nil
+}
+
+// InvalidateDeviceCodeSession removes the device code.
+func (s *MemoryStore) InvalidateDeviceCodeSession(_ context.Context, signature string) error {
+	s.deviceCodesMutex.Lock()
+	defer s.deviceCodesMutex.Unlock()
+
+	if r, ok := s.DeviceCodes[signature]; ok {
+		delete(s.DeviceCodeRequestIDs, r.GetID())
+	}
+	delete(s.DeviceCodes, signature)
+	return nil
+}
+
+// CreateUserCodeSession stores the device authorization request. The signature of the user code is used as key.
+func (s *MemoryStore) CreateUserCodeSession(_ context.Context, signature string, request fosite.DeviceRequester) error {
+	s.userCodesMutex.Lock()
+	defer s.userCodesMutex.Unlock()
+
+	s.UserCodes[signature] = request
+	return nil
+}
+
+// GetUserCodeSession returns the device authorization request of the user code signature.
+func (s *MemoryStore) GetUserCodeSession(_ context.Context, signature string, _ fosite.Session) (fosite.DeviceRequester, error) {
+	s.userCodesMutex.RLock()
+	defer s.userCodesMutex.RUnlock()
+
+	r, ok := s.UserCodes[signature]
+	if !ok {
+		return nil, fosite.ErrNotFound
+	}
+	return r, nil
+}
+
+// InvalidateUserCodeSession removes the user code.
+func (s *MemoryStore) InvalidateUserCodeSession(_ context.Context, signature string) error {
+	s.userCodesMutex.Lock()
+	defer s.userCodesMutex.Unlock()
+
+	delete(s.UserCodes, signature)
+	return nil
+}
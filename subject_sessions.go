@@ -0,0 +1,77 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+	"time"
+
+	"github.com/ory/x/errorsx"
+	"github.com/pkg/errors"
+)
+
+// SessionInfo describes an active refresh token session of a subject.
+type SessionInfo struct {
+	// RequestID is the ID of the request the session was issued by. It is shared by all tokens of the grant and can
+	// be used to revoke them.
+	RequestID string `json:"request_id"`
+
+	// ClientID is the ID of the client the session was issued to.
+	ClientID string `json:"client_id"`
+
+	// GrantedScopes are the scopes granted to the session.
+	GrantedScopes Arguments `json:"scope"`
+
+	// IssuedAt is the time the session was issued at.
+	IssuedAt time.Time `json:"issued_at"`
+
+	// ExpiresAt is the time the refresh token expires at, or zero if it does not expire.
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+func (f *Fosite) ListSubjectSessions(ctx context.Context, subject string) ([]SessionInfo, error) {
+	storage, ok := f.Store.(SubjectSessionStorage)
+	if !ok {
+		return nil, errorsx.WithStack(ErrServerError.WithDebug("The storage does not implement fosite.SubjectSessionStorage."))
+	}
+
+	if subject == "" {
+		return nil, errorsx.WithStack(ErrInvalidRequest.WithHint("The subject must not be empty."))
+	}
+
+	sessions, err := storage.ListSubjectSessions(ctx, subject)
+	if err != nil {
+		return nil, errorsx.WithStack(ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+	return sessions, nil
+}
+
+func (f *Fosite) RevokeSession(ctx context.Context, sessionID string) error {
+	storage, ok := f.Store.(SessionRevocationStorage)
+	if !ok {
+		return errorsx.WithStack(ErrServerError.WithDebug("The storage does not implement fosite.SessionRevocationStorage."))
+	}
+
+	if sessionID == "" {
+		return errorsx.WithStack(ErrInvalidRequest.WithHint("The session ID must not be empty."))
+	}
+
+	if err := storage.RevokeRefreshToken(ctx, sessionID); err != nil && !errors.Is(err, ErrNotFound) {
+		return errorsx.WithStack(ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	event := AuditEvent{Type: AuditEventTokenRevoked, RequestID: sessionID, TokenTypes: []TokenType{RefreshToken}}
+	if f.Config.GetKeepAccessTokensOnSessionRevocation(ctx) {
+		return RecordAuditEvent(ctx, f.Config, event)
+	}
+
+	err := storage.RevokeAccessToken(ctx, sessionID)
+	InvalidateIntrospectionCache(ctx, f.Config, sessionID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return errorsx.WithStack(ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	event.TokenTypes = append(event.TokenTypes, AccessToken)
+	return RecordAuditEvent(ctx, f.Config, event)
+}
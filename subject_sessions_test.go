@@ -0,0 +1,134 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ory/fosite"
+	"github.com/ory/fosite/storage"
+)
+
+func TestListSubjectSessions(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryStore()
+	provider := &Fosite{Store: store, Config: new(Config)}
+
+	now := time.Now().UTC().Round(time.Second)
+	createSession := func(t *testing.T, requestID, subject, clientID string, requestedAt, expiresAt time.Time, scopes ...string) {
+		session := &DefaultSession{Subject: subject}
+		session.SetExpiresAt(RefreshToken, expiresAt)
+
+		req := NewRequest()
+		req.ID = requestID
+		req.RequestedAt = requestedAt
+		req.Client = &DefaultClient{ID: clientID}
+		req.GrantedScope = scopes
+		req.Session = session
+		require.NoError(t, store.CreateRefreshTokenSession(ctx, "signature-"+requestID, req))
+	}
+
+	createSession(t, "peter-foo", "peter", "foo", now.Add(-time.Hour), now.Add(time.Hour), "openid", "offline")
+	createSession(t, "peter-bar", "peter", "bar", now.Add(-time.Minute), time.Time{}, "photos")
+	createSession(t, "peter-revoked", "peter", "baz", now.Add(-time.Minute), now.Add(time.Hour))
+	createSession(t, "peter-expired", "peter", "baz", now.Add(-2*time.Hour), now.Add(-time.Hour))
+	createSession(t, "alice-foo", "alice", "foo", now.Add(-time.Minute), now.Add(time.Hour), "openid")
+	require.NoError(t, store.RevokeRefreshToken(ctx, "peter-revoked"))
+
+	t.Run("case=should return the active sessions of the subject", func(t *testing.T) {
+		sessions, err := provider.ListSubjectSessions(ctx, "peter")
+		require.NoError(t, err)
+		assert.Equal(t, []SessionInfo{
+			{RequestID: "peter-foo", ClientID: "foo", GrantedScopes: Arguments{"openid", "offline"}, IssuedAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)},
+			{RequestID: "peter-bar", ClientID: "bar", GrantedScopes: Arguments{"photos"}, IssuedAt: now.Add(-time.Minute)},
+		}, sessions)
+	})
+
+	t.Run("case=should return no sessions for an unknown subject", func(t *testing.T) {
+		sessions, err := provider.ListSubjectSessions(ctx, "unknown")
+		require.NoError(t, err)
+		assert.Empty(t, sessions)
+	})
+
+	t.Run("case=should fail because the subject is empty", func(t *testing.T) {
+		_, err := provider.ListSubjectSessions(ctx, "")
+		assert.ErrorIs(t, err, ErrInvalidRequest)
+	})
+
+	t.Run("case=should fail because the storage does not support listing sessions", func(t *testing.T) {
+		_, err := (&Fosite{Store: struct{ Storage }{store}, Config: new(Config)}).ListSubjectSessions(ctx, "peter")
+		assert.ErrorIs(t, err, ErrServerError)
+	})
+}
+
+func TestRevokeSession(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) *storage.MemoryStore {
+		store := storage.NewMemoryStore()
+		for _, id := range []string{"foo", "bar"} {
+			req := NewRequest()
+			req.ID = id
+			req.Client = &DefaultClient{ID: "client-" + id}
+			req.Session = &DefaultSession{Subject: "peter"}
+			require.NoError(t, store.CreateRefreshTokenSession(ctx, "refresh-"+id, req))
+			require.NoError(t, store.CreateAccessTokenSession(ctx, "access-"+id, req))
+		}
+		return store
+	}
+
+	t.Run("case=should revoke the tokens of the session only", func(t *testing.T) {
+		store := setup(t)
+		provider := &Fosite{Store: store, Config: new(Config)}
+
+		require.NoError(t, provider.RevokeSession(ctx, "foo"))
+
+		_, err := store.GetRefreshTokenSession(ctx, "refresh-foo", nil)
+		assert.ErrorIs(t, err, ErrInactiveToken)
+		_, err = store.GetAccessTokenSession(ctx, "access-foo", nil)
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		_, err = store.GetRefreshTokenSession(ctx, "refresh-bar", nil)
+		assert.NoError(t, err)
+		_, err = store.GetAccessTokenSession(ctx, "access-bar", nil)
+		assert.NoError(t, err)
+
+		sessions, err := provider.ListSubjectSessions(ctx, "peter")
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		assert.Equal(t, "bar", sessions[0].RequestID)
+	})
+
+	t.Run("case=should keep the access tokens if configured", func(t *testing.T) {
+		store := setup(t)
+		provider := &Fosite{Store: store, Config: &Config{KeepAccessTokensOnSessionRevocation: true}}
+
+		require.NoError(t, provider.RevokeSession(ctx, "foo"))
+
+		_, err := store.GetRefreshTokenSession(ctx, "refresh-foo", nil)
+		assert.ErrorIs(t, err, ErrInactiveToken)
+		_, err = store.GetAccessTokenSession(ctx, "access-foo", nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("case=should pass for an unknown session", func(t *testing.T) {
+		provider := &Fosite{Store: setup(t), Config: new(Config)}
+		assert.NoError(t, provider.RevokeSession(ctx, "unknown"))
+	})
+
+	t.Run("case=should fail because the session ID is empty", func(t *testing.T) {
+		provider := &Fosite{Store: setup(t), Config: new(Config)}
+		assert.ErrorIs(t, provider.RevokeSession(ctx, ""), ErrInvalidRequest)
+	})
+
+	t.Run("case=should fail because the storage does not support revoking sessions", func(t *testing.T) {
+		provider := &Fosite{Store: struct{ Storage }{setup(t)}, Config: new(Config)}
+		assert.ErrorIs(t, provider.RevokeSession(ctx, "foo"), ErrServerError)
+	})
+}
@@ -0,0 +1,36 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"github.com/pkg/errors"
+)
+
+// SubjectValidator validates a subject before it is set on the session. It returns an error if the subject must
+// not be used.
+type SubjectValidator func(subject string) error
+
+// MaxSubjectLength is the maximum length of a subject accepted by DefaultSubjectValidator, see
+// https://openid.net/specs/openid-connect-core-1_0.html#IDToken
+const MaxSubjectLength = 255
+
+// DefaultSubjectValidator requires the subject to be non-empty, to consist of printable ASCII characters only and
+// to not exceed MaxSubjectLength characters.
+func DefaultSubjectValidator(subject string) error {
+	if subject == "" {
+		return errors.New("the subject must not be empty")
+	}
+
+	if len(subject) > MaxSubjectLength {
+		return errors.Errorf("the subject must not exceed %d characters", MaxSubjectLength)
+	}
+
+	for _, c := range subject {
+		if c < 0x20 || c > 0x7e {
+			return errors.Errorf("the subject contains the invalid character %q", c)
+		}
+	}
+
+	return nil
+}
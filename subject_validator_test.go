@@ -0,0 +1,39 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/ory/fosite"
+)
+
+func TestDefaultSubjectValidator(t *testing.T) {
+	for k, tc := range []struct {
+		subject string
+		valid   bool
+	}{
+		{subject: "peter", valid: true},
+		{subject: "248289761001", valid: true},
+		{subject: "urn:example:user:peter@example.com", valid: true},
+		{subject: strings.Repeat("a", MaxSubjectLength), valid: true},
+		{subject: strings.Repeat("a", MaxSubjectLength+1)},
+		{subject: ""},
+		{subject: "peter\n"},
+		{subject: "pëter"},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			err := DefaultSubjectValidator(tc.subject)
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
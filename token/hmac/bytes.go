@@ -8,13 +8,20 @@ import (
 	"io"
 
 	"github.com/ory/x/errorsx"
+	"github.com/pkg/errors"
 )
 
 // RandomBytes returns n random bytes by reading from crypto/rand.Reader
 func RandomBytes(n int) ([]byte, error) {
+	return RandomBytesFrom(rand.Reader, n)
+}
+
+// RandomBytesFrom returns n random bytes by reading from the given reader. It fails if the reader returns an error
+// or fewer than n bytes, so that a failing source of randomness never results in a weak secret.
+func RandomBytesFrom(r io.Reader, n int) ([]byte, error) {
 	bytes := make([]byte, n)
-	if _, err := io.ReadFull(rand.Reader, bytes); err != nil {
-		return nil, errorsx.WithStack(err)
+	if _, err := io.ReadFull(r, bytes); err != nil {
+		return nil, errorsx.WithStack(errors.Wrapf(err, "unable to read %d random bytes", n))
 	}
 	return bytes, nil
 }
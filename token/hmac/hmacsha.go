@@ -9,9 +9,11 @@ package hmac
 import (
 	"context"
 	"crypto/hmac"
+	"crypto/rand"
 	"crypto/sha512"
 	"encoding/base64"
 	"fmt"
+	"io"
 	"strings"
 	"sync"
 
@@ -33,6 +35,12 @@ type HMACStrategyConfigurator interface {
 type HMACStrategy struct {
 	sync.Mutex
 	Config HMACStrategyConfigurator
+
+	// Rand is the source of randomness of the generated tokens, for example an approved random number generator in
+	// FIPS environments. Defaults to the random source of the configuration if it implements
+	// fosite.RandomSourceProvider, or crypto/rand.Reader. It is only read while holding the lock of the strategy, so it
+	// does not need to be safe for concurrent use itself.
+	Rand io.Reader
 }
 
 const (
@@ -72,7 +80,7 @@ func (c *HMACStrategy) Generate(ctx context.Context) (string, string, error) {
 	// constructed from a cryptographically strong random or pseudo-random
 	// number sequence (see [RFC4086] for best current practice) generated
 	// by the authorization server.
-	tokenKey, err := RandomBytes(entropy)
+	tokenKey, err := RandomBytesFrom(c.getRand(ctx), entropy)
 	if err != nil {
 		return "", "", errorsx.WithStack(err)
 	}
@@ -84,6 +92,18 @@ func (c *HMACStrategy) Generate(ctx context.Context) (string, string, error) {
 	return encodedToken, encodedSignature, nil
 }
 
+func (c *HMACStrategy) getRand(ctx context.Context) io.Reader {
+	if c.Rand != nil {
+		return c.Rand
+	}
+	if p, ok := c.Config.(fosite.RandomSourceProvider); ok {
+		if r := p.GetRandomSource(ctx); r != nil {
+			return r
+		}
+	}
+	return rand.Reader
+}
+
 // Validate validates a token and returns its signature or an error if the token is not valid.
 func (c *HMACStrategy) Validate(ctx context.Context, token string) (err error) {
 	var keys [][]byte
@@ -157,6 +177,24 @@ func (c *HMACStrategy) validate(ctx context.Context, secret []byte, token string
 	return nil
 }
 
+// GenerateHMACForString returns the keyed hash of text using the global secret. Unlike Generate, it does not add
+// entropy and is therefore meant to derive lookup keys for values such as user codes.
+func (c *HMACStrategy) GenerateHMACForString(ctx context.Context, text string) (string, error) {
+	globalSecret, err := c.Config.GetGlobalSecret(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if len(globalSecret) < minimumSecretLength {
+		return "", errors.Errorf("secret for signing HMAC-SHA512/256 is expected to be 32 byte long, got %d byte", len(globalSecret))
+	}
+
+	var signingKey [32]byte
+	copy(signingKey[:], globalSecret)
+
+	return b64.EncodeToString(c.generateHMAC(ctx, []byte(text), &signingKey)), nil
+}
+
 func (c *HMACStrategy) Signature(token string) string {
 	_, sig, ok := strings.Cut(token, ".")
 	if !ok {
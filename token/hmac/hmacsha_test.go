@@ -4,10 +4,16 @@
 package hmac
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha512"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
+	"sync"
 	"testing"
+	"testing/iotest"
 
 	"github.com/ory/fosite"
 
@@ -132,3 +138,120 @@ func TestCustomHMAC(t *testing.T) {
 	require.NoError(t, sha512Hasher.Validate(ctx, token512))
 	require.ErrorIs(t, defaultHasher.Validate(ctx, token512), fosite.ErrTokenSignatureMismatch)
 }
+
+func TestGenerateHMACForString(t *testing.T) {
+	ctx := context.Background()
+	cg := HMACStrategy{Config: &fosite.Config{
+		GlobalSecret: []byte("1234567890123456789012345678901234567890"),
+	}}
+
+	first, err := cg.GenerateHMACForString(ctx, "BCDFGHJK")
+	require.NoError(t, err)
+	require.NotEmpty(t, first)
+
+	second, err := cg.GenerateHMACForString(ctx, "BCDFGHJK")
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+
+	other, err := cg.GenerateHMACForString(ctx, "BCDFGHJL")
+	require.NoError(t, err)
+	assert.NotEqual(t, first, other)
+
+	short := HMACStrategy{Config: &fosite.Config{GlobalSecret: []byte("foo")}}
+	_, err = short.GenerateHMACForString(ctx, "BCDFGHJK")
+	require.Error(t, err)
+}
+
+// countingReader is a deterministic source of "randomness" which repeats after 256 bytes and is not safe for
+// concurrent use.
+type countingReader struct {
+	next byte
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = r.next
+		r.next++
+	}
+	return len(p), nil
+}
+
+func TestGenerateWithCustomRand(t *testing.T) {
+	ctx := context.Background()
+	config := &fosite.Config{GlobalSecret: []byte("1234567890123456789012345678901234567890")}
+
+	t.Run("case=should generate reproducible tokens from a deterministic reader", func(t *testing.T) {
+		a := HMACStrategy{Config: config, Rand: new(countingReader)}
+		b := HMACStrategy{Config: config, Rand: new(countingReader)}
+
+		tokenA, signatureA, err := a.Generate(ctx)
+		require.NoError(t, err)
+		tokenB, signatureB, err := b.Generate(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, tokenA, tokenB)
+		assert.Equal(t, signatureA, signatureB)
+
+		expected, err := RandomBytesFrom(new(countingReader), minimumEntropy)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(tokenA, b64.EncodeToString(expected)+"."))
+		require.NoError(t, a.Validate(ctx, tokenA))
+
+		next, _, err := a.Generate(ctx)
+		require.NoError(t, err)
+		assert.NotEqual(t, tokenA, next)
+	})
+
+	for k, r := range []io.Reader{
+		bytes.NewReader(make([]byte, minimumEntropy-1)),
+		iotest.ErrReader(errors.New("the random number generator failed")),
+	} {
+		t.Run(fmt.Sprintf("case=%d/should fail because the reader is short or failing", k), func(t *testing.T) {
+			cg := HMACStrategy{Config: config, Rand: r}
+			token, signature, err := cg.Generate(ctx)
+			require.Error(t, err)
+			assert.Empty(t, token)
+			assert.Empty(t, signature)
+		})
+	}
+
+	t.Run("case=should use the random source of the configuration", func(t *testing.T) {
+		cg := HMACStrategy{Config: &fosite.Config{GlobalSecret: config.GlobalSecret, RandomSource: new(countingReader)}}
+		token, _, err := cg.Generate(ctx)
+		require.NoError(t, err)
+
+		expected, err := RandomBytesFrom(new(countingReader), minimumEntropy)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(token, b64.EncodeToString(expected)+"."))
+	})
+
+	t.Run("case=should default to crypto/rand without a random source provider", func(t *testing.T) {
+		cg := HMACStrategy{Config: struct{ HMACStrategyConfigurator }{config}}
+		token, _, err := cg.Generate(ctx)
+		require.NoError(t, err)
+		require.NoError(t, cg.Validate(ctx, token))
+	})
+
+	t.Run("case=should be safe for concurrent use", func(t *testing.T) {
+		cg := HMACStrategy{Config: config, Rand: new(countingReader)}
+
+		var wg sync.WaitGroup
+		tokens := make([]string, 8)
+		for i := range tokens {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				token, _, err := cg.Generate(ctx)
+				assert.NoError(t, err)
+				tokens[i] = token
+			}(i)
+		}
+		wg.Wait()
+
+		unique := map[string]struct{}{}
+		for _, token := range tokens {
+			unique[token] = struct{}{}
+		}
+		assert.Len(t, unique, len(tokens))
+	})
+}
@@ -171,21 +171,8 @@ func (c *JWTClaims) FromMap(m map[string]interface{}) {
 		case "exp":
 			c.ExpiresAt = toTime(v, c.ExpiresAt)
 		case "scp":
-			switch s := v.(type) {
-			case []string:
-				c.Scope = s
-				if c.ScopeField == JWTScopeFieldString {
-					c.ScopeField = JWTScopeFieldBoth
-				} else if c.ScopeField == JWTScopeFieldUnset {
-					c.ScopeField = JWTScopeFieldList
-				}
-			case []interface{}:
-				c.Scope = make([]string, len(s))
-				for i, vi := range s {
-					if s, ok := vi.(string); ok {
-						c.Scope[i] = s
-					}
-				}
+			if scope, ok := scopeFromClaim(v); ok {
+				c.Scope = scope
 				if c.ScopeField == JWTScopeFieldString {
 					c.ScopeField = JWTScopeFieldBoth
 				} else if c.ScopeField == JWTScopeFieldUnset {
@@ -193,8 +180,8 @@ func (c *JWTClaims) FromMap(m map[string]interface{}) {
 				}
 			}
 		case "scope":
-			if s, ok := v.(string); ok {
-				c.Scope = strings.Split(s, " ")
+			if scope, ok := scopeFromClaim(v); ok {
+				c.Scope = scope
 				if c.ScopeField == JWTScopeFieldList {
 					c.ScopeField = JWTScopeFieldBoth
 				} else if c.ScopeField == JWTScopeFieldUnset {
@@ -207,6 +194,26 @@ func (c *JWTClaims) FromMap(m map[string]interface{}) {
 	}
 }
 
+// scopeFromClaim reads the scopes of the "scp" or "scope" claim, which issuers encode either as an array or as a
+// space-delimited string.
+func scopeFromClaim(v interface{}) ([]string, bool) {
+	switch s := v.(type) {
+	case string:
+		return strings.Fields(s), true
+	case []string:
+		return s, true
+	case []interface{}:
+		scope := make([]string, 0, len(s))
+		for _, vi := range s {
+			if s, ok := vi.(string); ok {
+				scope = append(scope, s)
+			}
+		}
+		return scope, true
+	}
+	return nil, false
+}
+
 func toTime(v interface{}, def time.Time) (t time.Time) {
 	t = def
 	switch a := v.(type) {
@@ -46,36 +46,54 @@ func (j *DefaultSigner) Generate(ctx context.Context, claims MapClaims, header M
 		return "", "", err
 	}
 
+	alg, err := signingAlgorithm(key)
+	if err != nil {
+		return "", "", err
+	}
+	return generateToken(claims, header, alg, key)
+}
+
+// GetSigningAlgorithm returns the algorithm the signer signs tokens with, which depends on its private key.
+func (j *DefaultSigner) GetSigningAlgorithm(ctx context.Context) (string, error) {
+	key, err := j.GetPrivateKey(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	alg, err := signingAlgorithm(key)
+	if err != nil {
+		return "", err
+	}
+	return string(alg), nil
+}
+
+func signingAlgorithm(key interface{}) (jose.SignatureAlgorithm, error) {
 	switch t := key.(type) {
 	case *jose.JSONWebKey:
-		return generateToken(claims, header, jose.SignatureAlgorithm(t.Algorithm), t)
+		return jose.SignatureAlgorithm(t.Algorithm), nil
 	case jose.JSONWebKey:
-		return generateToken(claims, header, jose.SignatureAlgorithm(t.Algorithm), t)
+		return jose.SignatureAlgorithm(t.Algorithm), nil
 	case *rsa.PrivateKey:
-		return generateToken(claims, header, jose.RS256, t)
+		return jose.RS256, nil
 	case *ecdsa.PrivateKey:
-		return generateToken(claims, header, jose.ES256, t)
+		return jose.ES256, nil
 	case jose.OpaqueSigner:
 		switch tt := t.Public().Key.(type) {
 		case *rsa.PrivateKey:
-			alg := jose.RS256
 			if len(t.Algs()) > 0 {
-				alg = t.Algs()[0]
+				return t.Algs()[0], nil
 			}
-
-			return generateToken(claims, header, alg, t)
+			return jose.RS256, nil
 		case *ecdsa.PrivateKey:
-			alg := jose.ES256
 			if len(t.Algs()) > 0 {
-				alg = t.Algs()[0]
+				return t.Algs()[0], nil
 			}
-
-			return generateToken(claims, header, alg, t)
+			return jose.ES256, nil
 		default:
-			return "", "", errors.Errorf("unsupported private / public key pairs: %T, %T", t, tt)
+			return "", errors.Errorf("unsupported private / public key pairs: %T, %T", t, tt)
 		}
 	default:
-		return "", "", errors.Errorf("unsupported private key type: %T", t)
+		return "", errors.Errorf("unsupported private key type: %T", t)
 	}
 }
 
@@ -0,0 +1,91 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+)
+
+// TokenEndpointHandlerIndex maps grant types to the token endpoint handlers which may handle them, so that the
+// token endpoint does not need to ask every handler about every request. Handlers which do not implement
+// TokenEndpointGrantTypesHandler, or which declare GrantTypeAny, are candidates for every grant type. The order of
+// the handlers is retained.
+type TokenEndpointHandlerIndex struct {
+	handlers    TokenEndpointHandlers
+	byGrantType map[GrantType]TokenEndpointHandlers
+	fallback    TokenEndpointHandlers
+}
+
+// NewTokenEndpointHandlerIndex builds the index for the given handlers.
+func NewTokenEndpointHandlerIndex(handlers TokenEndpointHandlers) *TokenEndpointHandlerIndex {
+	declared := make([]map[GrantType]bool, len(handlers))
+	index := &TokenEndpointHandlerIndex{
+		handlers:    append(TokenEndpointHandlers(nil), handlers...),
+		byGrantType: map[GrantType]TokenEndpointHandlers{},
+	}
+
+	for i, h := range handlers {
+		gh, ok := h.(TokenEndpointGrantTypesHandler)
+		if !ok {
+			continue
+		}
+
+		declared[i] = map[GrantType]bool{}
+		for _, grantType := range gh.HandledGrantTypes() {
+			declared[i][grantType] = true
+			if grantType != GrantTypeAny {
+				index.byGrantType[grantType] = nil
+			}
+		}
+	}
+
+	for i, h := range handlers {
+		if declared[i] == nil || declared[i][GrantTypeAny] {
+			index.fallback = append(index.fallback, h)
+			for grantType := range index.byGrantType {
+				index.byGrantType[grantType] = append(index.byGrantType[grantType], h)
+			}
+			continue
+		}
+
+		for grantType := range declared[i] {
+			index.byGrantType[grantType] = append(index.byGrantType[grantType], h)
+		}
+	}
+
+	return index
+}
+
+// Handlers returns the handlers which may handle a request of the given grant types.
+func (i *TokenEndpointHandlerIndex) Handlers(grantTypes Arguments) TokenEndpointHandlers {
+	switch len(grantTypes) {
+	case 0:
+		return i.fallback
+	case 1:
+		if handlers, ok := i.byGrantType[GrantType(grantTypes[0])]; ok {
+			return handlers
+		}
+		return i.fallback
+	default:
+		// Requests with several grant types are rare enough to not be worth indexing.
+		return i.handlers
+	}
+}
+
+// IndexTokenEndpointHandlers builds the index of the configured token endpoint handlers. Compose calls it once all
+// handlers are registered. It must be called again if the token endpoint handlers change afterwards, as the index is
+// not rebuilt per request. Until it is called, every handler is asked about every request.
+func (f *Fosite) IndexTokenEndpointHandlers(ctx context.Context) {
+	f.tokenEndpointHandlerIndex.Store(NewTokenEndpointHandlerIndex(f.Config.GetTokenEndpointHandlers(ctx)))
+}
+
+// getTokenEndpointHandlers returns the token endpoint handlers which may handle the request.
+func (f *Fosite) getTokenEndpointHandlers(ctx context.Context, requester AccessRequester) TokenEndpointHandlers {
+	index := f.tokenEndpointHandlerIndex.Load()
+	if requester == nil || index == nil {
+		return f.Config.GetTokenEndpointHandlers(ctx)
+	}
+
+	return index.Handlers(requester.GetGrantTypes())
+}
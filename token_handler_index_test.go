@@ -0,0 +1,133 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type grantTypeTokenHandler struct {
+	grantType GrantType
+}
+
+func (h *grantTypeTokenHandler) PopulateTokenEndpointResponse(context.Context, AccessRequester, AccessResponder) error {
+	return nil
+}
+
+func (h *grantTypeTokenHandler) HandleTokenEndpointRequest(context.Context, AccessRequester) error {
+	return nil
+}
+
+func (h *grantTypeTokenHandler) CanSkipClientAuth(context.Context, AccessRequester) bool {
+	return false
+}
+
+func (h *grantTypeTokenHandler) CanHandleTokenEndpointRequest(_ context.Context, requester AccessRequester) bool {
+	return h.grantType == GrantTypeAny || requester.GetGrantTypes().ExactOne(string(h.grantType))
+}
+
+type declaringGrantTypeTokenHandler struct {
+	grantTypeTokenHandler
+}
+
+func (h *declaringGrantTypeTokenHandler) HandledGrantTypes() []GrantType {
+	return []GrantType{h.grantType}
+}
+
+func TestTokenEndpointHandlerIndex(t *testing.T) {
+	code := &declaringGrantTypeTokenHandler{grantTypeTokenHandler{grantType: GrantTypeAuthorizationCode}}
+	refresh := &declaringGrantTypeTokenHandler{grantTypeTokenHandler{grantType: GrantTypeRefreshToken}}
+	any := &declaringGrantTypeTokenHandler{grantTypeTokenHandler{grantType: GrantTypeAny}}
+	undeclared := &grantTypeTokenHandler{grantType: GrantTypeClientCredentials}
+	handlers := TokenEndpointHandlers{code, undeclared, refresh, any}
+
+	index := NewTokenEndpointHandlerIndex(handlers)
+	for k, tc := range []struct {
+		grantTypes Arguments
+		expected   TokenEndpointHandlers
+	}{
+		{grantTypes: Arguments{}, expected: TokenEndpointHandlers{undeclared, any}},
+		{grantTypes: Arguments{"authorization_code"}, expected: TokenEndpointHandlers{code, undeclared, any}},
+		{grantTypes: Arguments{"refresh_token"}, expected: TokenEndpointHandlers{undeclared, refresh, any}},
+		{grantTypes: Arguments{"client_credentials"}, expected: TokenEndpointHandlers{undeclared, any}},
+		{grantTypes: Arguments{"authorization_code", "refresh_token"}, expected: handlers},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			assert.Equal(t, tc.expected, index.Handlers(tc.grantTypes))
+		})
+	}
+
+	t.Run("case=should ask all handlers without an index", func(t *testing.T) {
+		f := &Fosite{Config: &Config{TokenEndpointHandlers: handlers}}
+
+		request := NewAccessRequest(nil)
+		request.GrantTypes = Arguments{"refresh_token"}
+		assert.Equal(t, handlers, f.getTokenEndpointHandlers(context.Background(), request))
+	})
+
+	t.Run("case=should use the index until the handlers are indexed again", func(t *testing.T) {
+		config := &Config{TokenEndpointHandlers: TokenEndpointHandlers{code}}
+		f := &Fosite{Config: config}
+		f.IndexTokenEndpointHandlers(context.Background())
+
+		request := NewAccessRequest(nil)
+		request.GrantTypes = Arguments{"refresh_token"}
+		assert.Empty(t, f.getTokenEndpointHandlers(context.Background(), request))
+
+		config.TokenEndpointHandlers = append(config.TokenEndpointHandlers, refresh)
+		assert.Empty(t, f.getTokenEndpointHandlers(context.Background(), request))
+
+		f.IndexTokenEndpointHandlers(context.Background())
+		assert.Equal(t, TokenEndpointHandlers{refresh}, f.getTokenEndpointHandlers(context.Background(), request))
+	})
+
+	t.Run("case=should return all handlers without a request", func(t *testing.T) {
+		f := &Fosite{Config: &Config{TokenEndpointHandlers: handlers}}
+		f.IndexTokenEndpointHandlers(context.Background())
+		assert.Equal(t, handlers, f.getTokenEndpointHandlers(context.Background(), nil))
+	})
+}
+
+func BenchmarkTokenEndpointHandlerDispatch(b *testing.B) {
+	grantTypes := []GrantType{
+		GrantTypeAuthorizationCode, GrantTypeRefreshToken, GrantTypeClientCredentials, GrantTypePassword,
+		GrantTypeJWTBearer, GrantTypeTokenExchange, GrantTypeDeviceCode,
+	}
+
+	var handlers TokenEndpointHandlers
+	for i := 0; i < 12; i++ {
+		handlers = append(handlers, &declaringGrantTypeTokenHandler{grantTypeTokenHandler{grantType: grantTypes[i%len(grantTypes)]}})
+	}
+
+	ctx := context.Background()
+	request := NewAccessRequest(nil)
+	request.GrantTypes = Arguments{string(GrantTypeDeviceCode)}
+
+	b.Run("case=linear", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, h := range handlers {
+				if h.CanHandleTokenEndpointRequest(ctx, request) {
+					_ = h.HandleTokenEndpointRequest(ctx, request)
+				}
+			}
+		}
+	})
+
+	b.Run("case=indexed", func(b *testing.B) {
+		f := &Fosite{Config: &Config{TokenEndpointHandlers: handlers}}
+		f.IndexTokenEndpointHandlers(ctx)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			for _, h := range f.getTokenEndpointHandlers(ctx, request) {
+				if h.CanHandleTokenEndpointRequest(ctx, request) {
+					_ = h.HandleTokenEndpointRequest(ctx, request)
+				}
+			}
+		}
+	})
+}